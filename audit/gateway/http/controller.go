@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
+)
+
+// Controller that handles the relation between the server and the service.
+type Controller struct {
+	service audit.Service
+}
+
+// New retrieves a brand new contoller wrapping around the given service.
+func New(service audit.Service) server.ControllerWrapper {
+	return server.ControllerWrapper{
+		Controller: &Controller{
+			service: service,
+		},
+	}
+}
+
+// RecordDto defines how an audit record must be exposed.
+type RecordDto struct {
+	ID        string          `json:"id"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+type readAllResponseDto struct {
+	Records       []*RecordDto `json:"records"`
+	NextPageToken string       `json:"next_page_token,omitempty"`
+	Total         int          `json:"total"`
+}
+
+// ReadAll retrieves a page of the audit records, optionally restricted by the
+// entity kind, the entity id, the actor and a time range (RFC 3339, both bounds
+// inclusive).
+func (ctrl *Controller) ReadAll(ctx *gin.Context) {
+	query, err := parseQuery(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	records, info, err := ctrl.service.ReadAll(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	dtos := make([]*RecordDto, len(records))
+	for i, record := range records {
+		dtos[i] = toRecord(record)
+	}
+
+	ctx.JSON(http.StatusOK, &readAllResponseDto{
+		Records:       dtos,
+		NextPageToken: model.EncodePageToken(info.Next),
+		Total:         info.Total,
+	})
+}
+
+func parseQuery(ctx *gin.Context) (audit.Query, error) {
+	query := audit.Query{
+		Entity:   ctx.Query("entity"),
+		EntityID: ctx.Query("id"),
+		Actor:    ctx.Query("actor"),
+	}
+
+	if query.Entity != "" && !audit.IsValidEntity(query.Entity) {
+		return query, errors.NewInvalidParameter("entity", query.Entity)
+	}
+
+	var err error
+	if query.From, err = parseTime(ctx, "from"); err != nil {
+		return query, err
+	}
+
+	if query.To, err = parseTime(ctx, "to"); err != nil {
+		return query, err
+	}
+
+	query.Page, err = server.ParsePage(ctx)
+
+	return query, err
+}
+
+func parseTime(ctx *gin.Context, name string) (time.Time, error) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errors.NewInvalidParameter(name, raw)
+	}
+
+	return value, nil
+}
+
+func toRecord(record *model.AuditRecord) *RecordDto {
+	return &RecordDto{
+		ID:        record.ID,
+		Entity:    record.Entity,
+		EntityID:  record.EntityID,
+		Action:    record.Action,
+		Actor:     record.Actor,
+		RequestID: record.RequestID,
+		Timestamp: record.Timestamp,
+		Before:    record.Before,
+		After:     record.After,
+	}
+}
+
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
+func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
+	for _, api := range server.NamespacedGroups(routerGroup, "/audit") {
+		api.GET("", ctrl.ReadAll)
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	audit_service "github.com/rghiorghisor/basic-go-rest-api/audit/service"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadAll(t *testing.T) {
+	router, service := setup()
+
+	timestamp := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	records := []*model.AuditRecord{{
+		ID:        "1",
+		Entity:    audit.EntityProperty,
+		EntityID:  "test.name",
+		Action:    audit.ActionUpdate,
+		Actor:     "alice",
+		RequestID: "req-1",
+		Timestamp: timestamp,
+		Before:    []byte(`{"value":"a"}`),
+		After:     []byte(`{"value":"b"}`),
+	}}
+	query := audit.Query{
+		Entity:   audit.EntityProperty,
+		EntityID: "test.name",
+		Actor:    "alice",
+		From:     time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+		Page:     model.Page{Limit: 1},
+	}
+	service.On("ReadAll", query).Return(records, model.PageInfo{Total: 2, Next: "1"}, nil)
+
+	w := perform("GET", "/api/audit?entity=property&id=test.name&actor=alice&from=2020-10-01T00:00:00Z&limit=1", router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"records":[{"id":"1","entity":"property","entity_id":"test.name","action":"update","actor":"alice","request_id":"req-1","timestamp":"2020-10-01T12:00:00Z","before":{"value":"a"},"after":{"value":"b"}}],"next_page_token":"MQ","total":2}`, w.Body.String())
+}
+
+func TestReadAllInvalid(t *testing.T) {
+	router, service := setup()
+
+	w := perform("GET", "/api/audit?entity=webhook", router)
+	assert.Equal(t, 400, w.Code)
+
+	w = perform("GET", "/api/audit?from=yesterday", router)
+	assert.Equal(t, 400, w.Code)
+
+	w = perform("GET", "/api/audit?to=2020-10-01", router)
+	assert.Equal(t, 400, w.Code)
+
+	service.AssertNotCalled(t, "ReadAll")
+}
+
+func setup() (r *gin.Engine, serviceMock *audit_service.AuditServiceMock) {
+	router := gin.Default()
+	router.Use(
+		jsonAppErrorHandler(),
+	)
+	api := router.Group("/api")
+
+	service := new(audit_service.AuditServiceMock)
+	controller := New(service).Controller
+	controller.Register(api)
+
+	return router, service
+}
+
+func perform(method string, uri string, router *gin.Engine) (rr *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(method, uri, new(bytes.Buffer))
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func jsonAppErrorHandler() gin.HandlerFunc {
+	return handle(gin.ErrorTypeAny)
+}
+
+func handle(errType gin.ErrorType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		detectedErrors := c.Errors
+
+		if len(detectedErrors) > 0 {
+			err := detectedErrors[0].Err
+
+			switch err.(type) {
+			case *apperrors.Error:
+				oError := err.(*apperrors.Error)
+				c.AbortWithError(oError.Code, oError)
+			default:
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+
+			return
+		}
+	}
+}
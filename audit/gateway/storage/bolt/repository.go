@@ -0,0 +1,114 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+)
+
+// AuditRepository is a representation of the audit repository for Bolt DBs.
+type AuditRepository struct {
+	db *storm.DB
+}
+
+type auditRecordDto struct {
+	ID        string `storm:"id"`
+	Namespace string
+	Entity    string
+	EntityID  string
+	Action    string
+	Actor     string
+	RequestID string
+	Timestamp time.Time
+	Before    []byte
+	After     []byte
+}
+
+// New retrieves a new repository object ready to be used.
+func New(db *storm.DB) storage.Repository {
+	repo := &AuditRepository{
+		db: db,
+	}
+	db.Init(&auditRecordDto{})
+
+	return repo
+}
+
+// Create a new entry based on the provided record.
+func (repository AuditRepository) Create(ctx context.Context, record *model.AuditRecord) error {
+	return repository.node(ctx).Save(convertToDto(record))
+}
+
+// ReadAll retrieves the given page of the records within the given namespace
+// that match the given filter, sorted by id.
+func (repository AuditRepository) ReadAll(ctx context.Context, namespace string, filter storage.Filter, page model.Page) ([]*model.AuditRecord, model.PageInfo, error) {
+	var dtos []auditRecordDto
+	err := repository.node(ctx).Select(q.Eq("Namespace", namespace)).Find(&dtos)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, model.PageInfo{}, err
+	}
+
+	records := make([]*model.AuditRecord, 0, len(dtos))
+	for i := range dtos {
+		record := convertToModel(&dtos[i])
+		if filter.Matches(record) {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return page.Less(records[i].ID, records[j].ID)
+	})
+
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+
+	from, to, info := page.Window(ids)
+
+	return records[from:to], info, nil
+}
+
+func convertToDto(record *model.AuditRecord) *auditRecordDto {
+	return &auditRecordDto{
+		ID:        record.ID,
+		Namespace: record.Namespace,
+		Entity:    record.Entity,
+		EntityID:  record.EntityID,
+		Action:    record.Action,
+		Actor:     record.Actor,
+		RequestID: record.RequestID,
+		Timestamp: record.Timestamp,
+		Before:    record.Before,
+		After:     record.After,
+	}
+}
+
+func convertToModel(dto *auditRecordDto) *model.AuditRecord {
+	return &model.AuditRecord{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		Entity:    dto.Entity,
+		EntityID:  dto.EntityID,
+		Action:    dto.Action,
+		Actor:     dto.Actor,
+		RequestID: dto.RequestID,
+		Timestamp: dto.Timestamp,
+		Before:    dto.Before,
+		After:     dto.After,
+	}
+}
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository AuditRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
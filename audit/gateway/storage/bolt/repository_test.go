@@ -0,0 +1,63 @@
+package bolt
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var defaultDir = "../../../../tests/local-repo"
+var defaultDB = "../../../../tests/local-repo/auditdb"
+
+func TestReadAll(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	records := []*model.AuditRecord{
+		{ID: "1", Namespace: "payments", Entity: "property", EntityID: "a", Action: "create", Actor: "jane", RequestID: "r1", Timestamp: start, After: []byte(`{"name":"a"}`)},
+		{ID: "2", Namespace: "payments", Entity: "set", EntityID: "common", Action: "create", Actor: "john", Timestamp: start.Add(time.Minute)},
+		{ID: "3", Namespace: "payments", Entity: "property", EntityID: "a", Action: "update", Actor: "john", Timestamp: start.Add(2 * time.Minute)},
+		{ID: "4", Namespace: "", Entity: "property", EntityID: "b", Action: "create", Actor: "jane", Timestamp: start},
+	}
+	for _, record := range records {
+		assert.Equal(t, nil, repo.Create(context.Background(), record))
+	}
+
+	all, info, err := repo.ReadAll(context.Background(), "payments", storage.Filter{}, model.Page{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, info.Total)
+	assert.Equal(t, records[:3], all)
+
+	found, _, _ := repo.ReadAll(context.Background(), "payments", storage.Filter{Entity: "property", EntityID: "a", Actor: "john"}, model.Page{})
+	assert.Equal(t, []*model.AuditRecord{records[2]}, found)
+
+	found, _, _ = repo.ReadAll(context.Background(), "payments", storage.Filter{From: start.Add(time.Minute), To: start.Add(time.Minute)}, model.Page{})
+	assert.Equal(t, []*model.AuditRecord{records[1]}, found)
+
+	found, info, _ = repo.ReadAll(context.Background(), "payments", storage.Filter{}, model.Page{Limit: 2, Descending: true})
+	assert.Equal(t, []*model.AuditRecord{records[2], records[1]}, found)
+	assert.Equal(t, "2", info.Next)
+}
+
+func setup() *AuditRepository {
+	util.CreateParentFolder(defaultDB)
+
+	db, _ := storm.Open(defaultDB)
+
+	return New(db).(*AuditRepository)
+}
+
+func tearDown(repo *AuditRepository) {
+	repo.db.Close()
+
+	os.Remove(defaultDB)
+	os.Remove(defaultDir)
+}
@@ -0,0 +1,158 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const auditCollection = "audit_collection"
+
+type auditRecordDto struct {
+	ID        string    `bson:"_id"`
+	Namespace string    `bson:"namespace"`
+	Entity    string    `bson:"entity"`
+	EntityID  string    `bson:"entity_id"`
+	Action    string    `bson:"action"`
+	Actor     string    `bson:"actor"`
+	RequestID string    `bson:"request_id,omitempty"`
+	Timestamp time.Time `bson:"timestamp"`
+	Before    []byte    `bson:"before,omitempty"`
+	After     []byte    `bson:"after,omitempty"`
+}
+
+// AuditRepository is a representation of the audit repository for a mongo DBs.
+type AuditRepository struct {
+	dbCollection *mongo.Collection
+}
+
+// New retrieves a new repository object ready to be used.
+func New(db *mongo.Database) storage.Repository {
+	return &AuditRepository{
+		dbCollection: db.Collection(auditCollection),
+	}
+}
+
+// Create a new entry based on the provided record.
+func (repository AuditRepository) Create(ctx context.Context, record *model.AuditRecord) error {
+	_, err := repository.dbCollection.InsertOne(ctx, convertToDto(record))
+
+	return err
+}
+
+// ReadAll retrieves the given page of the records within the given namespace
+// that match the given filter, sorted by id.
+func (repository AuditRepository) ReadAll(ctx context.Context, namespace string, filter storage.Filter, page model.Page) ([]*model.AuditRecord, model.PageInfo, error) {
+	query := toQuery(namespace, filter)
+
+	total, err := repository.dbCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	order := 1
+	operator := "$gt"
+	if page.Descending {
+		order = -1
+		operator = "$lt"
+	}
+
+	if page.After != "" {
+		query["_id"] = bson.M{operator: page.After}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: order}})
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit + 1))
+	}
+
+	cursor, err := repository.dbCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+	defer cursor.Close(ctx)
+
+	records := make([]*model.AuditRecord, 0)
+	for cursor.Next(ctx) {
+		dto := new(auditRecordDto)
+		if err := cursor.Decode(dto); err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		records = append(records, convertToModel(dto))
+	}
+
+	info := model.PageInfo{Total: int(total)}
+	if page.Limit > 0 && len(records) > page.Limit {
+		records = records[:page.Limit]
+		info.Next = records[page.Limit-1].ID
+	}
+
+	return records, info, nil
+}
+
+func toQuery(namespace string, filter storage.Filter) bson.M {
+	query := bson.M{"namespace": namespace}
+
+	if filter.Entity != "" {
+		query["entity"] = filter.Entity
+	}
+
+	if filter.EntityID != "" {
+		query["entity_id"] = filter.EntityID
+	}
+
+	if filter.Actor != "" {
+		query["actor"] = filter.Actor
+	}
+
+	timestamp := bson.M{}
+	if !filter.From.IsZero() {
+		timestamp["$gte"] = filter.From
+	}
+
+	if !filter.To.IsZero() {
+		timestamp["$lte"] = filter.To
+	}
+
+	if len(timestamp) > 0 {
+		query["timestamp"] = timestamp
+	}
+
+	return query
+}
+
+func convertToDto(record *model.AuditRecord) *auditRecordDto {
+	return &auditRecordDto{
+		ID:        record.ID,
+		Namespace: record.Namespace,
+		Entity:    record.Entity,
+		EntityID:  record.EntityID,
+		Action:    record.Action,
+		Actor:     record.Actor,
+		RequestID: record.RequestID,
+		Timestamp: record.Timestamp,
+		Before:    record.Before,
+		After:     record.After,
+	}
+}
+
+func convertToModel(dto *auditRecordDto) *model.AuditRecord {
+	return &model.AuditRecord{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		Entity:    dto.Entity,
+		EntityID:  dto.EntityID,
+		Action:    dto.Action,
+		Actor:     dto.Actor,
+		RequestID: dto.RequestID,
+		Timestamp: dto.Timestamp,
+		Before:    dto.Before,
+		After:     dto.After,
+	}
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Filter restricts the records retrieved from a repository. Empty fields do not
+// restrict the records.
+type Filter struct {
+	Entity   string
+	EntityID string
+	Actor    string
+
+	// From and To bound the time of the records, both inclusive.
+	From time.Time
+	To   time.Time
+}
+
+// Matches checks whether the given record satisfies the filter.
+func (filter Filter) Matches(record *model.AuditRecord) bool {
+	if filter.Entity != "" && record.Entity != filter.Entity {
+		return false
+	}
+
+	if filter.EntityID != "" && record.EntityID != filter.EntityID {
+		return false
+	}
+
+	if filter.Actor != "" && record.Actor != filter.Actor {
+		return false
+	}
+
+	if !filter.From.IsZero() && record.Timestamp.Before(filter.From) {
+		return false
+	}
+
+	return filter.To.IsZero() || !record.Timestamp.After(filter.To)
+}
+
+// Repository interface defining the functionality of a basic implementations.
+//
+// Records are never changed once created. They are sorted by id, which sorts as
+// the records were recorded.
+type Repository interface {
+	Create(ctx context.Context, record *model.AuditRecord) error
+
+	ReadAll(ctx context.Context, namespace string, filter Filter, page model.Page) ([]*model.AuditRecord, model.PageInfo, error)
+}
@@ -0,0 +1,21 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Query restricts the audit records to be retrieved. Empty fields do not
+// restrict the records.
+type Query struct {
+	Entity   string
+	EntityID string
+	Actor    string
+
+	// From and To bound the time of the records, both inclusive.
+	From time.Time
+	To   time.Time
+
+	Page model.Page
+}
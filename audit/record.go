@@ -0,0 +1,153 @@
+/*
+Package audit implements the audit log of the changes made to properties and
+sets.
+
+Each change is recorded along with its caller and the snapshots of the entity
+before and after the change. The records are written within the context of the
+change, so that the changes made within a transaction are recorded only if the
+transaction is committed.
+*/
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// All kinds of audited entities.
+const (
+	EntityProperty = "property"
+	EntitySet      = "set"
+)
+
+// All kinds of audited changes.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionExpire  = "expire"
+)
+
+// IsValidEntity checks whether the given entity kind is audited.
+func IsValidEntity(entity string) bool {
+	return entity == EntityProperty || entity == EntitySet
+}
+
+type propertySnapshot struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Type        string              `json:"type,omitempty"`
+	Value       string              `json:"value"`
+	Secret      bool                `json:"secret,omitempty"`
+	Labels      map[string]string   `json:"labels,omitempty"`
+	Overrides   map[string]string   `json:"overrides,omitempty"`
+	Constraint  *constraintSnapshot `json:"constraint,omitempty"`
+	ExpiresAt   *time.Time          `json:"expires_at,omitempty"`
+	Revision    int                 `json:"revision,omitempty"`
+}
+
+type constraintSnapshot struct {
+	Min     *float64 `json:"min,omitempty"`
+	Max     *float64 `json:"max,omitempty"`
+	Enum    []string `json:"enum,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+	Schema  string   `json:"schema,omitempty"`
+}
+
+type setSnapshot struct {
+	Name     string   `json:"name"`
+	Values   []string `json:"values"`
+	Includes []string `json:"includes,omitempty"`
+	Version  int      `json:"version,omitempty"`
+}
+
+// PropertyChange retrieves the record of a change of a property, given its
+// states before and after the change, either of which may be nil. The
+// namespace, the caller and the time of the change are filled in by the
+// service.
+func PropertyChange(action string, before *model.Property, after *model.Property) *model.AuditRecord {
+	record := &model.AuditRecord{Entity: EntityProperty, Action: action}
+
+	if before != nil {
+		record.EntityID = before.ID
+		record.Before = propertyToSnapshot(before)
+	}
+
+	if after != nil {
+		record.EntityID = after.ID
+		record.After = propertyToSnapshot(after)
+	}
+
+	return record
+}
+
+// SetChange retrieves the record of a change of a set, given its states before
+// and after the change, either of which may be nil. The namespace, the caller
+// and the time of the change are filled in by the service.
+func SetChange(action string, before *model.PropertySet, after *model.PropertySet) *model.AuditRecord {
+	record := &model.AuditRecord{Entity: EntitySet, Action: action}
+
+	if before != nil {
+		record.EntityID = before.Name
+		record.Before = setToSnapshot(before)
+	}
+
+	if after != nil {
+		record.EntityID = after.Name
+		record.After = setToSnapshot(after)
+	}
+
+	return record
+}
+
+func propertyToSnapshot(prop *model.Property) []byte {
+	snapshot := &propertySnapshot{
+		ID:          prop.ID,
+		Name:        prop.Name,
+		Description: prop.Description,
+		Type:        string(prop.Type),
+		Value:       prop.Value,
+		Secret:      prop.Secret,
+		Labels:      prop.Labels,
+		Overrides:   prop.Overrides,
+		Revision:    prop.Revision,
+	}
+
+	if prop.Constraint != nil {
+		snapshot.Constraint = &constraintSnapshot{
+			Min:     prop.Constraint.Min,
+			Max:     prop.Constraint.Max,
+			Enum:    prop.Constraint.Enum,
+			Pattern: prop.Constraint.Pattern,
+			Schema:  prop.Constraint.Schema,
+		}
+	}
+
+	if !prop.ExpiresAt.IsZero() {
+		snapshot.ExpiresAt = &prop.ExpiresAt
+	}
+
+	if prop.Secret {
+		snapshot.Value = model.MaskedValue
+		snapshot.Overrides = make(map[string]string, len(prop.Overrides))
+		for profile := range prop.Overrides {
+			snapshot.Overrides[profile] = model.MaskedValue
+		}
+	}
+
+	// The snapshot holds only strings, booleans, numbers and times, so it can
+	// always be marshaled.
+	data, _ := json.Marshal(snapshot)
+
+	return data
+}
+
+func setToSnapshot(set *model.PropertySet) []byte {
+	data, _ := json.Marshal(&setSnapshot{Name: set.Name, Values: set.Values, Includes: set.Includes, Version: set.Version})
+
+	return data
+}
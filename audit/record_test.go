@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPropertyChange(t *testing.T) {
+	before := &model.Property{ID: "1", Namespace: "payments", Name: "test.name", Value: "old", Revision: 1}
+	after := &model.Property{ID: "1", Namespace: "payments", Name: "test.name", Value: "new", Revision: 2}
+
+	record := PropertyChange(ActionUpdate, before, after)
+
+	assert.Equal(t, EntityProperty, record.Entity)
+	assert.Equal(t, "1", record.EntityID)
+	assert.JSONEq(t, `{"id":"1","name":"test.name","value":"old","revision":1}`, string(record.Before))
+	assert.JSONEq(t, `{"id":"1","name":"test.name","value":"new","revision":2}`, string(record.After))
+}
+
+func TestPropertyChangeSecret(t *testing.T) {
+	prop := &model.Property{ID: "1", Name: "test.name", Value: "s3cr3t", Secret: true, Overrides: map[string]string{"prod": "other"}}
+
+	record := PropertyChange(ActionDelete, prop, nil)
+
+	assert.JSONEq(t, `{"id":"1","name":"test.name","value":"******","secret":true,"overrides":{"prod":"******"}}`, string(record.Before))
+	assert.Nil(t, record.After)
+}
+
+func TestPropertyChangeConstraint(t *testing.T) {
+	max := 10.0
+	prop := &model.Property{ID: "1", Name: "test.name", Type: model.TypeInt, Value: "5", Constraint: &model.Constraint{Max: &max}}
+
+	record := PropertyChange(ActionCreate, nil, prop)
+
+	assert.JSONEq(t, `{"id":"1","name":"test.name","type":"int","value":"5","constraint":{"max":10}}`, string(record.After))
+}
+
+func TestSetChange(t *testing.T) {
+	record := SetChange(ActionCreate, nil, &model.PropertySet{Namespace: "payments", Name: "common", Values: []string{"a"}, Version: 1})
+
+	assert.Equal(t, EntitySet, record.Entity)
+	assert.Equal(t, "common", record.EntityID)
+	assert.Nil(t, record.Before)
+	assert.JSONEq(t, `{"name":"common","values":["a"],"version":1}`, string(record.After))
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Service defines the use case available for the audit log.
+type Service interface {
+	Record(ctx context.Context, record *model.AuditRecord) error
+
+	ReadAll(ctx context.Context, query Query) ([]*model.AuditRecord, model.PageInfo, error)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/caller"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+)
+
+// idLayout formats the time part of the record ids, so that the ids sort as the
+// records were recorded.
+const idLayout = "20060102T150405.000000000Z"
+
+// AuditService defines the service handling the audit log.
+type AuditService struct {
+	repository storage.Repository
+}
+
+// New creates an AuditService.
+//
+// As this service needs access to a repository to perform action, it is the
+// responsibility of the service to get the correct repo from the storage parameter.
+func New(storage *serverstorage.Storage) audit.Service {
+	return AuditService{
+		repository: storage.AuditRepository,
+	}
+}
+
+// Record adds the given record to the audit log, on behalf of the caller of the
+// given context and within the namespace of the given context. The record is
+// written within the context, i.e. within its transaction, if any.
+func (service AuditService) Record(ctx context.Context, record *model.AuditRecord) error {
+	origin := caller.FromContext(ctx)
+
+	record.Namespace = namespace.FromContext(ctx)
+	record.Actor = origin.Actor
+	record.RequestID = origin.RequestID
+	record.Timestamp = time.Now().UTC()
+	record.ID = record.Timestamp.Format(idLayout) + "-" + uuid.New().String()[:8]
+
+	return service.repository.Create(ctx, record)
+}
+
+// ReadAll retrieves a page of the records of the namespace of the given context
+// that match the given query.
+func (service AuditService) ReadAll(ctx context.Context, query audit.Query) ([]*model.AuditRecord, model.PageInfo, error) {
+	filter := storage.Filter{
+		Entity:   query.Entity,
+		EntityID: query.EntityID,
+		Actor:    query.Actor,
+		From:     query.From,
+		To:       query.To,
+	}
+
+	return service.repository.ReadAll(ctx, namespace.FromContext(ctx), filter, query.Page)
+}
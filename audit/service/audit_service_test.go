@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/caller"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRecord(t *testing.T) {
+	srv, repo := setup()
+
+	repo.On("Create", mock.Anything).Return(nil)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	ctx = caller.NewContext(ctx, caller.Caller{Actor: "alice", RequestID: "req-1"})
+	record := audit.PropertyChange(audit.ActionCreate, nil, &model.Property{Name: "test.name", Value: "value"})
+
+	assert.Nil(t, srv.Record(ctx, record))
+	assert.Equal(t, "payments", record.Namespace)
+	assert.Equal(t, "alice", record.Actor)
+	assert.Equal(t, "req-1", record.RequestID)
+	assert.False(t, record.Timestamp.IsZero())
+	assert.NotEqual(t, "", record.ID)
+	repo.AssertCalled(t, "Create", record)
+}
+
+func TestRecordSystem(t *testing.T) {
+	srv, repo := setup()
+
+	repo.On("Create", mock.Anything).Return(nil)
+
+	record := audit.SetChange(audit.ActionDelete, &model.PropertySet{Name: "common"}, nil)
+
+	assert.Nil(t, srv.Record(context.Background(), record))
+	assert.Equal(t, caller.System, record.Actor)
+	assert.Equal(t, "", record.RequestID)
+}
+
+func TestRecordOrdered(t *testing.T) {
+	srv, repo := setup()
+
+	repo.On("Create", mock.Anything).Return(nil)
+
+	first := audit.PropertyChange(audit.ActionCreate, nil, &model.Property{Name: "test.name"})
+	second := audit.PropertyChange(audit.ActionDelete, &model.Property{Name: "test.name"}, nil)
+	srv.Record(context.Background(), first)
+	time.Sleep(time.Millisecond)
+	srv.Record(context.Background(), second)
+
+	assert.True(t, first.ID < second.ID)
+}
+
+func TestReadAll(t *testing.T) {
+	srv, repo := setup()
+
+	from := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	records := []*model.AuditRecord{{ID: "1", Entity: audit.EntitySet, EntityID: "common"}}
+	filter := storage.Filter{Entity: audit.EntitySet, EntityID: "common", Actor: "alice", From: from}
+	repo.On("ReadAll", "payments", filter, model.Page{Limit: 1}).Return(records, model.PageInfo{Total: 1}, nil)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	actual, info, err := srv.ReadAll(ctx, audit.Query{Entity: audit.EntitySet, EntityID: "common", Actor: "alice", From: from, Page: model.Page{Limit: 1}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, records, actual)
+	assert.Equal(t, 1, info.Total)
+}
+
+func setup() (service audit.Service, repo *AuditRepositoryMock) {
+	repoMock := new(AuditRepositoryMock)
+	service = New(&serverstorage.Storage{AuditRepository: repoMock})
+
+	return service, repoMock
+}
+
+type AuditRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *AuditRepositoryMock) Create(ctx context.Context, record *model.AuditRecord) error {
+	args := m.Called(record)
+
+	return args.Error(0)
+}
+
+func (m *AuditRepositoryMock) ReadAll(ctx context.Context, namespace string, filter storage.Filter, page model.Page) ([]*model.AuditRecord, model.PageInfo, error) {
+	args := m.Called(namespace, filter, page)
+
+	return args.Get(0).([]*model.AuditRecord), args.Get(1).(model.PageInfo), args.Error(2)
+}
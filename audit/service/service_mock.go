@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// AuditServiceMock retrieves a new mock for AuditService.
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+// Record mock function.
+func (m *AuditServiceMock) Record(ctx context.Context, record *model.AuditRecord) error {
+	args := m.Called(record)
+
+	return args.Error(0)
+}
+
+// ReadAll mock function.
+func (m *AuditServiceMock) ReadAll(ctx context.Context, query audit.Query) ([]*model.AuditRecord, model.PageInfo, error) {
+	args := m.Called(query)
+
+	return args.Get(0).([]*model.AuditRecord), args.Get(1).(model.PageInfo), args.Error(2)
+}
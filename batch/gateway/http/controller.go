@@ -0,0 +1,231 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/batch"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	property_controller "github.com/rghiorghisor/basic-go-rest-api/property/gateway/http"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
+)
+
+// Controller that handles the relation between the server and the service.
+type Controller struct {
+	service batch.Service
+}
+
+// New retrieves a brand new contoller wrapping around the given service.
+func New(service batch.Service) server.ControllerWrapper {
+	return server.ControllerWrapper{
+		Controller: &Controller{
+			service: service,
+		},
+	}
+}
+
+type requestDto struct {
+	Operations []operationDto `json:"operations"`
+}
+
+// operationDto defines a single operation. The value holds the entity to be
+// created or updated, in the same form as accepted by the entity endpoints.
+type operationDto struct {
+	Action  string          `json:"action"`
+	Entity  string          `json:"entity"`
+	ID      string          `json:"id"`
+	Version int             `json:"version"`
+	Value   json.RawMessage `json:"value"`
+}
+
+type setDto struct {
+	Name     string   `json:"name"`
+	Values   []string `json:"values"`
+	Includes []string `json:"includes"`
+}
+
+type responseDto struct {
+	Committed bool        `json:"committed"`
+	Results   []resultDto `json:"results"`
+}
+
+type resultDto struct {
+	Action  string `json:"action"`
+	Entity  string `json:"entity"`
+	ID      string `json:"id,omitempty"`
+	Version int    `json:"version,omitempty"`
+	Status  int    `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Apply performs a list of create, update and delete operations on properties
+// and sets, all-or-nothing. On success, the response holds the result of each
+// operation. Otherwise, nothing is changed and the response has the status of
+// the failed operation, holding the results up to the failed operation.
+func (ctrl *Controller) Apply(ctx *gin.Context) {
+	// Read input (must be JSON valid)
+	dto := new(requestDto)
+	if err := ctx.BindJSON(dto); err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	operations, err := toOperations(dto.Operations)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Call service (business logic).
+	results, err := ctrl.service.Apply(ctx.Request.Context(), operations)
+
+	// Respond with either error either success.
+	if err == nil {
+		ctx.JSON(http.StatusOK, &responseDto{Committed: true, Results: toResults(operations, results)})
+		return
+	}
+
+	batchErr, ok := err.(*batch.Error)
+	if !ok {
+		ctx.Error(err)
+		return
+	}
+
+	code := http.StatusInternalServerError
+	message := batchErr.Err.Error()
+	if castError, ok := batchErr.Err.(*errors.Error); ok {
+		code = castError.Code
+		message = castError.Message
+	}
+
+	failed := operations[batchErr.Index]
+	response := &responseDto{Committed: false, Results: toResults(operations, results)}
+	response.Results = append(response.Results, resultDto{
+		Action: string(failed.Action),
+		Entity: string(failed.Entity),
+		ID:     failed.ID,
+		Status: code,
+		Error:  message,
+	})
+
+	ctx.JSON(code, response)
+}
+
+func toOperations(dtos []operationDto) ([]*batch.Operation, error) {
+	if len(dtos) == 0 {
+		return nil, errors.NewInvalidParameter("operations", "")
+	}
+
+	operations := make([]*batch.Operation, len(dtos))
+	for i, dto := range dtos {
+		operation, err := toOperation(dto)
+		if err != nil {
+			return nil, errors.NewInvalidParameter(fmt.Sprintf("operations[%d]", i), err.Error())
+		}
+
+		operations[i] = operation
+	}
+
+	return operations, nil
+}
+
+func toOperation(dto operationDto) (*batch.Operation, error) {
+	operation := &batch.Operation{
+		Action: batch.Action(dto.Action),
+		Entity: batch.Entity(dto.Entity),
+		ID:     dto.ID,
+	}
+
+	if !batch.IsValidAction(operation.Action) {
+		return nil, fmt.Errorf("unknown action '%s'", dto.Action)
+	}
+
+	if !batch.IsValidEntity(operation.Entity) {
+		return nil, fmt.Errorf("unknown entity '%s'", dto.Entity)
+	}
+
+	if operation.Action != batch.ActionCreate && operation.ID == "" {
+		return nil, fmt.Errorf("missing id")
+	}
+
+	if operation.Action == batch.ActionDelete {
+		operation.Version = dto.Version
+		return operation, nil
+	}
+
+	if operation.Entity == batch.EntitySet {
+		set, err := toSet(dto)
+		operation.Set = set
+
+		return operation, err
+	}
+
+	prop, err := toProperty(dto)
+	operation.Property = prop
+
+	return operation, err
+}
+
+func toProperty(dto operationDto) (*model.Property, error) {
+	prop, err := property_controller.DecodeProperty(dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	prop.ID = dto.ID
+	prop.Revision = dto.Version
+
+	return prop, nil
+}
+
+func toSet(dto operationDto) (*model.PropertySet, error) {
+	value := new(setDto)
+	if err := json.Unmarshal(dto.Value, value); err != nil {
+		return nil, fmt.Errorf("invalid set value")
+	}
+
+	set := &model.PropertySet{Name: value.Name, Values: value.Values, Includes: value.Includes, Version: dto.Version}
+	if dto.Action == string(batch.ActionUpdate) {
+		set.Name = dto.ID
+	}
+
+	return set, nil
+}
+
+func toResults(operations []*batch.Operation, results []*batch.Result) []resultDto {
+	dtos := make([]resultDto, len(results))
+	for i, result := range results {
+		operation := operations[i]
+		dtos[i] = resultDto{
+			Action:  string(operation.Action),
+			Entity:  string(operation.Entity),
+			ID:      result.ID,
+			Version: result.Version,
+			Status:  status(operation.Action),
+		}
+	}
+
+	return dtos
+}
+
+func status(action batch.Action) int {
+	switch action {
+	case batch.ActionCreate:
+		return http.StatusCreated
+	case batch.ActionDelete:
+		return http.StatusNoContent
+	}
+
+	return http.StatusOK
+}
+
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
+func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
+	for _, api := range server.NamespacedGroups(routerGroup, "/batch") {
+		api.POST("", ctrl.Apply)
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/batch"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestApply(t *testing.T) {
+	router, service := setup()
+
+	operations := []*batch.Operation{
+		{Action: batch.ActionCreate, Entity: batch.EntityProperty, Property: &model.Property{Name: "test.int", Type: model.TypeInt, Value: "42", Overrides: map[string]string{"prod": "43"}}},
+		{Action: batch.ActionUpdate, Entity: batch.EntitySet, ID: "common", Set: &model.PropertySet{Name: "common", Values: []string{"test.int"}, Version: 2}},
+		{Action: batch.ActionDelete, Entity: batch.EntityProperty, ID: "testid", Version: 3},
+	}
+	results := []*batch.Result{{ID: "newid", Version: 1}, {ID: "common", Version: 3}, {ID: "testid"}}
+	service.On("Apply", operations).Return(results, nil)
+
+	// Perform action.
+	body := `{"operations":[
+		{"action":"create","entity":"property","value":{"name":"test.int","type":"int","value":42,"overrides":{"prod":43}}},
+		{"action":"update","entity":"set","id":"common","version":2,"value":{"values":["test.int"]}},
+		{"action":"delete","entity":"property","id":"testid","version":3}
+	]}`
+	w := perform("POST", "/api/batch", []byte(body), router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"committed":true,"results":[{"action":"create","entity":"property","id":"newid","version":1,"status":201},{"action":"update","entity":"set","id":"common","version":3,"status":200},{"action":"delete","entity":"property","id":"testid","status":204}]}`, w.Body.String())
+}
+
+func TestApplyConstrained(t *testing.T) {
+	router, service := setup()
+
+	max := 65535.0
+	operations := []*batch.Operation{
+		{Action: batch.ActionUpdate, Entity: batch.EntityProperty, ID: "testid", Property: &model.Property{
+			ID:         "testid",
+			Name:       "test.port",
+			Type:       model.TypeInt,
+			Value:      "8080",
+			Constraint: &model.Constraint{Max: &max},
+			Targeting:  &model.Targeting{Rules: []model.TargetingRule{{Conditions: []model.TargetingCondition{{Attribute: "region", Operator: model.ConditionIn, Values: []string{"eu"}}}, Variant: "8443"}}},
+			Revision:   2,
+		}},
+	}
+	service.On("Apply", operations).Return([]*batch.Result{{ID: "testid", Version: 3}}, nil)
+
+	// Perform action.
+	body := `{"operations":[{"action":"update","entity":"property","id":"testid","version":2,"value":{"name":"test.port","type":"int","value":8080,"constraint":{"max":65535},"targeting":{"rules":[{"conditions":[{"attribute":"region","operator":"in","values":["eu"]}],"variant":8443}]}}}]}`
+	w := perform("POST", "/api/batch", []byte(body), router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestApplyRolledBack(t *testing.T) {
+	router, service := setup()
+
+	operations := []*batch.Operation{
+		{Action: batch.ActionCreate, Entity: batch.EntitySet, Set: &model.PropertySet{Name: "common"}},
+		{Action: batch.ActionDelete, Entity: batch.EntitySet, ID: "other"},
+	}
+	results := []*batch.Result{{ID: "common", Version: 1}}
+	service.On("Apply", operations).Return(results, &batch.Error{Index: 1, Err: apperrors.NewEntityNotFound(model.PropertySet{}, "other")})
+
+	// Perform action.
+	body := `{"operations":[{"action":"create","entity":"set","value":{"name":"common"}},{"action":"delete","entity":"set","id":"other"}]}`
+	w := perform("POST", "/api/batch", []byte(body), router)
+
+	// Test result.
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, `{"committed":false,"results":[{"action":"create","entity":"set","id":"common","version":1,"status":201},{"action":"delete","entity":"set","id":"other","status":404,"error":"Cannot find model.PropertySet entity (id='other')"}]}`, w.Body.String())
+}
+
+func TestApplyUnexpected(t *testing.T) {
+	router, service := setup()
+
+	service.On("Apply", mock.Anything).Return(nil, errors.New("unexpected"))
+
+	w := perform("POST", "/api/batch", []byte(`{"operations":[{"action":"delete","entity":"set","id":"common"}]}`), router)
+
+	assert.Equal(t, 500, w.Code)
+}
+
+func TestApplyInvalid(t *testing.T) {
+	router, service := setup()
+
+	invalid := []string{
+		`{"operations":[]}`,
+		`{"operations":[{"action":"merge","entity":"set","id":"common"}]}`,
+		`{"operations":[{"action":"delete","entity":"flag","id":"common"}]}`,
+		`{"operations":[{"action":"update","entity":"property","value":{"name":"test.name"}}]}`,
+		`{"operations":[{"action":"create","entity":"property"}]}`,
+		`{"operations":`,
+	}
+
+	for _, body := range invalid {
+		w := perform("POST", "/api/batch", []byte(body), router)
+
+		assert.Equal(t, 400, w.Code, body)
+	}
+
+	service.AssertNotCalled(t, "Apply", mock.Anything)
+}
+
+func TestApplyNamespaced(t *testing.T) {
+	router, service := setup()
+
+	service.On("Apply", mock.Anything).Return([]*batch.Result{{ID: "common"}}, nil)
+
+	w := perform("POST", "/api/ns/payments/batch", []byte(`{"operations":[{"action":"delete","entity":"set","id":"common"}]}`), router)
+
+	assert.Equal(t, 200, w.Code)
+}
+
+func perform(method string, uri string, body []byte, router *gin.Engine) (rr *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(method, uri, bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func setup() (r *gin.Engine, serviceMock *BatchServiceMock) {
+	router := gin.Default()
+	router.Use(
+		jsonAppErrorHandler(),
+	)
+	api := router.Group("/api")
+
+	service := new(BatchServiceMock)
+	controller := New(service).Controller
+	controller.Register(api)
+
+	return router, service
+}
+
+type BatchServiceMock struct {
+	mock.Mock
+}
+
+func (m *BatchServiceMock) Apply(ctx context.Context, operations []*batch.Operation) ([]*batch.Result, error) {
+	args := m.Called(operations)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*batch.Result), args.Error(1)
+}
+
+func jsonAppErrorHandler() gin.HandlerFunc {
+	return handle(gin.ErrorTypeAny)
+}
+
+func handle(errType gin.ErrorType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		detectedErrors := c.Errors
+
+		if len(detectedErrors) > 0 {
+			err := detectedErrors[0].Err
+
+			switch err.(type) {
+			case *apperrors.Error:
+				oError := err.(*apperrors.Error)
+				c.AbortWithError(oError.Code, oError)
+			default:
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package batch
+
+import (
+	"fmt"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Action defines what an operation does to its entity.
+type Action string
+
+// All actions that can be performed by an operation.
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Entity defines the kind of entity an operation is performed on.
+type Entity string
+
+// All entities that can be changed by an operation.
+const (
+	EntityProperty Entity = "property"
+	EntitySet      Entity = "set"
+)
+
+// Operation is a single change of a batch.
+type Operation struct {
+	Action Action
+	Entity Entity
+
+	// Property is the property to be created or updated, if the entity is a
+	// property. Unless its revision is zero, an updated property must still
+	// have that revision.
+	Property *model.Property
+
+	// Set is the set to be created or updated, if the entity is a set. Unless
+	// its version is zero, an updated set must still have that version.
+	Set *model.PropertySet
+
+	// ID identifies the entity to be deleted.
+	ID string
+
+	// Version is the version the entity to be deleted must still have. Zero
+	// if any version can be deleted.
+	Version int
+}
+
+// IsValidAction checks whether the given action is known.
+func IsValidAction(action Action) bool {
+	return action == ActionCreate || action == ActionUpdate || action == ActionDelete
+}
+
+// IsValidEntity checks whether the given entity is known.
+func IsValidEntity(entity Entity) bool {
+	return entity == EntityProperty || entity == EntitySet
+}
+
+// Result describes the outcome of a successful operation.
+type Result struct {
+	// ID identifies the changed entity.
+	ID string
+
+	// Version is the version of the entity after the operation. Zero for
+	// deleted entities.
+	Version int
+}
+
+// Error signals that a batch was rolled back because one of its operations
+// failed.
+type Error struct {
+	// Index is the position of the failed operation within the batch.
+	Index int
+
+	// Err is the error of the failed operation.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("operation %d failed: %s", e.Index, e.Err)
+}
@@ -0,0 +1,10 @@
+package batch
+
+import (
+	"context"
+)
+
+// Service defines the use case available for batches.
+type Service interface {
+	Apply(ctx context.Context, operations []*Operation) ([]*Result, error)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/batch"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+)
+
+// BatchService defines the service applying batches of operations.
+type BatchService struct {
+	transactor transaction.Transactor
+
+	propertyService property.Service
+	setService      propertyset.Service
+}
+
+// New creates a BatchService.
+//
+// The operations are delegated to the services of their entities, so they are
+// validated exactly as the single operations are. The transactor is taken from
+// the storage parameter.
+func New(storage *serverstorage.Storage, propertyService property.Service, setService propertyset.Service) batch.Service {
+	return BatchService{
+		transactor:      storage.Transactor,
+		propertyService: propertyService,
+		setService:      setService,
+	}
+}
+
+// Apply performs the given operations, in order, all-or-nothing. If any of the
+// operations fails, the changes of all operations are rolled back and a
+// batch.Error pointing to the failed operation is retrieved, along with the
+// results of the operations that preceded it.
+func (service BatchService) Apply(ctx context.Context, operations []*batch.Operation) ([]*batch.Result, error) {
+	var results []*batch.Result
+
+	err := service.transactor.Run(ctx, func(ctx context.Context) error {
+		// The unit of work may be retried, so it must start from scratch: the
+		// services fill in the entities they change (e.g. their ids and
+		// revisions), so each attempt is given copies of the operations.
+		results = make([]*batch.Result, 0, len(operations))
+		for i, operation := range operations {
+			result, err := service.apply(ctx, copyOperation(operation))
+			if err != nil {
+				return &batch.Error{Index: i, Err: err}
+			}
+
+			results = append(results, result)
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// copyOperation retrieves a copy of the given operation, whose entities can be
+// changed without changing the ones of the given operation.
+func copyOperation(operation *batch.Operation) *batch.Operation {
+	copied := *operation
+
+	if operation.Property != nil {
+		prop := *operation.Property
+		copied.Property = &prop
+	}
+
+	if operation.Set != nil {
+		set := *operation.Set
+		set.Values = append([]string(nil), operation.Set.Values...)
+		set.Includes = append([]string(nil), operation.Set.Includes...)
+		copied.Set = &set
+	}
+
+	return &copied
+}
+
+func (service BatchService) apply(ctx context.Context, operation *batch.Operation) (*batch.Result, error) {
+	if operation.Entity == batch.EntitySet {
+		return service.applySet(ctx, operation)
+	}
+
+	switch operation.Action {
+	case batch.ActionCreate:
+		if err := service.propertyService.Create(ctx, operation.Property); err != nil {
+			return nil, err
+		}
+	case batch.ActionUpdate:
+		if err := service.propertyService.Update(ctx, operation.Property); err != nil {
+			return nil, err
+		}
+	case batch.ActionDelete:
+		if err := service.propertyService.Delete(ctx, operation.ID, operation.Version); err != nil {
+			return nil, err
+		}
+
+		return &batch.Result{ID: operation.ID}, nil
+	}
+
+	return &batch.Result{ID: operation.Property.ID, Version: operation.Property.Revision}, nil
+}
+
+func (service BatchService) applySet(ctx context.Context, operation *batch.Operation) (*batch.Result, error) {
+	switch operation.Action {
+	case batch.ActionCreate:
+		if err := service.setService.Create(ctx, operation.Set); err != nil {
+			return nil, err
+		}
+	case batch.ActionUpdate:
+		if err := service.setService.Update(ctx, operation.Set); err != nil {
+			return nil, err
+		}
+	case batch.ActionDelete:
+		if err := service.setService.Delete(ctx, operation.ID, operation.Version); err != nil {
+			return nil, err
+		}
+
+		return &batch.Result{ID: operation.ID}, nil
+	}
+
+	return &batch.Result{ID: operation.Set.Name, Version: operation.Set.Version}, nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/rghiorghisor/basic-go-rest-api/batch"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	"github.com/rghiorghisor/basic-go-rest-api/tests/fixture"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultDB = "../../tests/local-repo/batchdb"
+
+type testContext struct {
+	services        *fixture.Services
+	service         batch.Service
+	propertyService property.Service
+	setService      propertyset.Service
+}
+
+func TestApply(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	existing := &model.Property{Name: "test.existing", Value: "old"}
+	tc.propertyService.Create(ctx, existing)
+
+	results, err := tc.service.Apply(ctx, []*batch.Operation{
+		{Action: batch.ActionCreate, Entity: batch.EntityProperty, Property: &model.Property{Name: "test.new", Type: model.TypeInt, Value: "42"}},
+		{Action: batch.ActionUpdate, Entity: batch.EntityProperty, Property: &model.Property{ID: existing.ID, Name: "test.existing", Value: "new", Revision: 1}},
+		{Action: batch.ActionCreate, Entity: batch.EntitySet, Set: &model.PropertySet{Name: "common", Values: []string{"test.new", "test.existing"}}},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, &batch.Result{ID: existing.ID, Version: 2}, results[1])
+	assert.Equal(t, &batch.Result{ID: "common", Version: 1}, results[2])
+
+	props, _, _ := tc.propertyService.ReadAll(ctx, property.Query{Set: "common"})
+	assert.Equal(t, 2, len(props))
+}
+
+func TestApplyConstrained(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	max := 10.0
+	existing := &model.Property{Name: "test.limit", Type: model.TypeInt, Value: "5", Constraint: &model.Constraint{Max: &max}}
+	tc.propertyService.Create(ctx, existing)
+
+	_, err := tc.service.Apply(ctx, []*batch.Operation{
+		{Action: batch.ActionUpdate, Entity: batch.EntityProperty, Property: &model.Property{ID: existing.ID, Name: "test.limit", Type: model.TypeInt, Value: "11", Constraint: &model.Constraint{Max: &max}, Revision: 1}},
+	})
+
+	assert.Equal(t, 0, err.(*batch.Error).Index)
+
+	_, err = tc.service.Apply(ctx, []*batch.Operation{
+		{Action: batch.ActionUpdate, Entity: batch.EntityProperty, Property: &model.Property{ID: existing.ID, Name: "test.limit", Type: model.TypeInt, Value: "8", Constraint: &model.Constraint{Max: &max}, Revision: 1}},
+	})
+	assert.Nil(t, err)
+
+	actual, _ := tc.propertyService.FindByID(ctx, existing.ID)
+	assert.Equal(t, "8", actual.Value)
+	assert.Equal(t, &model.Constraint{Max: &max}, actual.Constraint)
+}
+
+func TestApplyRollback(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	existing := &model.Property{Name: "test.existing", Value: "old"}
+	tc.propertyService.Create(ctx, existing)
+
+	results, err := tc.service.Apply(ctx, []*batch.Operation{
+		{Action: batch.ActionCreate, Entity: batch.EntityProperty, Property: &model.Property{Name: "test.new", Value: "value"}},
+		{Action: batch.ActionDelete, Entity: batch.EntityProperty, ID: existing.ID},
+		{Action: batch.ActionCreate, Entity: batch.EntitySet, Set: &model.PropertySet{Name: "common", Values: []string{"test.new"}}},
+		{Action: batch.ActionCreate, Entity: batch.EntityProperty, Property: &model.Property{Name: "test.new", Value: "other"}},
+	})
+
+	assert.Equal(t, &batch.Error{Index: 3, Err: apperrors.NewConflict(reflect.TypeOf(&model.Property{}), "name", "test.new")}, err)
+	assert.Equal(t, 3, len(results))
+
+	props, _, _ := tc.propertyService.ReadAll(ctx, property.EmptyQuery)
+	assert.Equal(t, []*model.Property{existing}, props)
+
+	_, err = tc.setService.FindByID(ctx, "common")
+	assert.True(t, apperrors.IsNotFound(err))
+}
+
+func TestApplyRetried(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	existing := &model.Property{Name: "test.existing", Value: "old"}
+	tc.propertyService.Create(ctx, existing)
+
+	srv := BatchService{
+		transactor:      retryingTransactor{Transactor: tc.services.Storage.Transactor},
+		propertyService: tc.propertyService,
+		setService:      tc.setService,
+	}
+	update := &model.Property{ID: existing.ID, Name: "test.existing", Value: "new", Revision: 1}
+	created := &model.Property{Name: "test.new", Value: "value"}
+
+	results, err := srv.Apply(ctx, []*batch.Operation{
+		{Action: batch.ActionUpdate, Entity: batch.EntityProperty, Property: update},
+		{Action: batch.ActionCreate, Entity: batch.EntityProperty, Property: created},
+		{Action: batch.ActionCreate, Entity: batch.EntitySet, Set: &model.PropertySet{Name: "common", Values: []string{"test.new"}}},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &batch.Result{ID: existing.ID, Version: 2}, results[0])
+	assert.Equal(t, 1, update.Revision)
+	assert.Equal(t, "", created.ID)
+
+	actual, _ := tc.propertyService.FindByID(ctx, existing.ID)
+	assert.Equal(t, "new", actual.Value)
+	assert.Equal(t, 2, actual.Revision)
+}
+
+// retryingTransactor runs each unit of work twice, rolling back the first
+// attempt, as a transaction retried after a transient error.
+type retryingTransactor struct {
+	transaction.Transactor
+}
+
+func (t retryingTransactor) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	retry := errors.New("retry")
+	if err := t.Transactor.Run(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+
+		return retry
+	}); err != retry {
+		return err
+	}
+
+	return t.Transactor.Run(ctx, fn)
+}
+
+func setup() *testContext {
+	services := fixture.New(defaultDB, trash_service.New)
+
+	return &testContext{
+		services:        services,
+		service:         New(services.Storage, services.Properties, services.Sets),
+		propertyService: services.Properties,
+		setService:      services.Sets,
+	}
+}
+
+func tearDown(tc *testContext) {
+	tc.services.Close()
+}
@@ -0,0 +1,41 @@
+/*
+Package caller implements the identification of the caller of an operation.
+
+The caller of the current operation is carried along by means of the request
+context, like the namespace, so that the services can record who changed what.
+*/
+package caller
+
+import "context"
+
+// System is the actor of the operations that are not requested by a caller
+// (e.g. background jobs).
+const System = "system"
+
+// Caller identifies the origin of an operation.
+type Caller struct {
+	// Actor identifies who requested the operation.
+	Actor string
+
+	// RequestID identifies the request that triggered the operation. Empty if
+	// the operation was not triggered by a request.
+	RequestID string
+}
+
+type contextKey struct{}
+
+// NewContext retrieves a copy of the given context carrying the given caller.
+func NewContext(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, caller)
+}
+
+// FromContext retrieves the caller carried by the given context. If the
+// context carries no caller, the System actor is retrieved.
+func FromContext(ctx context.Context) Caller {
+	caller, ok := ctx.Value(contextKey{}).(Caller)
+	if !ok {
+		return Caller{Actor: System}
+	}
+
+	return caller
+}
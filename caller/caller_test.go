@@ -0,0 +1,15 @@
+package caller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext(t *testing.T) {
+	ctx := NewContext(context.Background(), Caller{Actor: "jane", RequestID: "123"})
+
+	assert.Equal(t, Caller{Actor: "jane", RequestID: "123"}, FromContext(ctx))
+	assert.Equal(t, Caller{Actor: System}, FromContext(context.Background()))
+}
@@ -6,18 +6,32 @@ import (
 	"github.com/rghiorghisor/basic-go-rest-api/container"
 	"github.com/rghiorghisor/basic-go-rest-api/logger"
 
+	audit_controller "github.com/rghiorghisor/basic-go-rest-api/audit/gateway/http"
+	audit_service "github.com/rghiorghisor/basic-go-rest-api/audit/service"
+	batch_controller "github.com/rghiorghisor/basic-go-rest-api/batch/gateway/http"
+	batch_service "github.com/rghiorghisor/basic-go-rest-api/batch/service"
+	flag_controller "github.com/rghiorghisor/basic-go-rest-api/flag/gateway/http"
+	flag_service "github.com/rghiorghisor/basic-go-rest-api/flag/service"
 	property_controller "github.com/rghiorghisor/basic-go-rest-api/property/gateway/http"
 	property_service "github.com/rghiorghisor/basic-go-rest-api/property/service"
 	propertyset_controller "github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/http"
 	propertyset_service "github.com/rghiorghisor/basic-go-rest-api/propertyset/service"
+	release_service "github.com/rghiorghisor/basic-go-rest-api/release/service"
+	schedule_service "github.com/rghiorghisor/basic-go-rest-api/schedule/service"
 	"github.com/rghiorghisor/basic-go-rest-api/server/http"
 	server_storage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	trash_controller "github.com/rghiorghisor/basic-go-rest-api/trash/gateway/http"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+	webhook_controller "github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/http"
+	webhook_service "github.com/rghiorghisor/basic-go-rest-api/webhook/service"
 )
 
 func main() {
 	appServer := appserver.New()
 	appServer.LoadConfig()
 
+	setupConfiguration(appServer.Configuration, appServer.Container)
 	setupStorage(appServer.Configuration, appServer.Container)
 	setupServices(appServer.Container)
 	setupControllers(appServer.Container)
@@ -26,6 +40,21 @@ func main() {
 	appServer.Start()
 }
 
+func setupConfiguration(appConfiguration *config.AppConfiguration, c *container.Container) {
+	c.Provide(func() *config.TrashConfiguration {
+		return appConfiguration.Trash
+	})
+	c.Provide(func() *config.ExpiryConfiguration {
+		return appConfiguration.Expiry
+	})
+	c.Provide(func() *config.SchedulerConfiguration {
+		return appConfiguration.Scheduler
+	})
+	c.Provide(func() *config.IntegrityConfiguration {
+		return appConfiguration.Integrity
+	})
+}
+
 func setupStorage(appConfiguration *config.AppConfiguration, c *container.Container) {
 	c.Provide(func() (*server_storage.Storage, error) {
 		storage := server_storage.New()
@@ -42,8 +71,16 @@ func setupServer(c *container.Container) {
 }
 
 func setupServices(c *container.Container) {
+	c.Provide(watch.NewHub)
+	c.Provide(audit_service.New)
+	c.Provide(trash_service.New)
+	c.Provide(flag_service.New)
 	c.Provide(property_service.New)
 	c.Provide(propertyset_service.New)
+	c.Provide(batch_service.New)
+	c.Provide(webhook_service.New)
+	c.Provide(schedule_service.New)
+	c.Provide(release_service.New)
 
 	// Add here additional services...
 }
@@ -51,6 +88,11 @@ func setupServices(c *container.Container) {
 func setupControllers(c *container.Container) {
 	c.Provide(property_controller.New)
 	c.Provide(propertyset_controller.New)
+	c.Provide(batch_controller.New)
+	c.Provide(webhook_controller.New)
+	c.Provide(audit_controller.New)
+	c.Provide(trash_controller.New)
+	c.Provide(flag_controller.New)
 
 	// Add here additional controllers...
 }
@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/rghiorghisor/basic-go-rest-api/util"
@@ -44,10 +45,14 @@ type appFlag struct {
 type AppConfiguration struct {
 	Environment *Environment `yaml:"none"`
 	Settings    *ConfigurationSettings
-	Application *ApplicationSettings  `yaml:"application"`
-	Loggers     *LoggersConfiguration `yaml:"logger"`
-	Server      *ServerConfiguration  `yaml:"server"`
-	Storage     *StorageConfiguration `yaml:"storage"`
+	Application *ApplicationSettings    `yaml:"application"`
+	Loggers     *LoggersConfiguration   `yaml:"logger"`
+	Server      *ServerConfiguration    `yaml:"server"`
+	Storage     *StorageConfiguration   `yaml:"storage"`
+	Trash       *TrashConfiguration     `yaml:"trash"`
+	Expiry      *ExpiryConfiguration    `yaml:"expiry"`
+	Scheduler   *SchedulerConfiguration `yaml:"scheduler"`
+	Integrity   *IntegrityConfiguration `yaml:"integrity"`
 	stats       *stats
 }
 
@@ -99,11 +104,17 @@ type HTTPServerConfiguration struct {
 	Port         int `yaml:"port"`
 	ReadTimeout  int `yaml:"read-timeout"`
 	WriteTimeout int `yaml:"write-timeout"`
+
+	// TrustedProxies lists the addresses (IP addresses or CIDR ranges) of the
+	// authenticating proxies whose X-Actor and X-Forwarded-For headers are
+	// trusted to identify the caller.
+	TrustedProxies []string `yaml:"trusted-proxies"`
 }
 
 // StorageConfiguration holds any settings regarding the application's storage options.
 type StorageConfiguration struct {
 	Type                string                `yaml:"type"`
+	EncryptionKeyFile   string                `yaml:"encryption-key-file"`
 	BoltDbConfiguration *BoltDbConfiguration  `yaml:"bolt"`
 	DbConfiguration     *MongoDbConfiguration `yaml:"mongo"`
 }
@@ -119,6 +130,46 @@ type MongoDbConfiguration struct {
 	Name string `yaml:"name"`
 }
 
+// TrashConfiguration holds settings regarding the deleted properties and sets,
+// which are kept in the trash for a while before being permanently removed.
+type TrashConfiguration struct {
+	Retention     time.Duration `yaml:"retention"`
+	PurgeInterval time.Duration `yaml:"purge-interval"`
+}
+
+// ExpiryConfiguration holds settings regarding the properties that have an
+// expiry time, which are removed once they expire.
+type ExpiryConfiguration struct {
+	SweepInterval time.Duration `yaml:"sweep-interval"`
+}
+
+// SchedulerConfiguration holds settings regarding the scheduled changes of the
+// property values, which are applied once they are due.
+type SchedulerConfiguration struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// The referential integrity modes between the property sets and the properties
+// they contain.
+const (
+	// IntegrityReport accepts any names within the sets; the names that no
+	// longer resolve are only reported as orphans.
+	IntegrityReport = "report"
+
+	// IntegrityReject rejects the sets referencing unknown properties.
+	IntegrityReject = "reject"
+
+	// IntegrityCascade propagates the renaming and deletion of the properties
+	// to the sets containing them.
+	IntegrityCascade = "cascade"
+)
+
+// IntegrityConfiguration holds settings regarding the referential integrity
+// between the property sets and the properties they contain.
+type IntegrityConfiguration struct {
+	Mode string `yaml:"mode"`
+}
+
 type stats struct {
 	loaded         bool
 	loadedFromDir  string
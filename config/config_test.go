@@ -5,6 +5,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,6 +19,9 @@ func TestLoad(t *testing.T) {
 
 	assert.Equal(t, "mongodb://localhost:27017", appConfiguration.Storage.DbConfiguration.URI)
 	assert.Equal(t, "testdb", appConfiguration.Storage.DbConfiguration.Name)
+	assert.Equal(t, "local-storage/secret.key", appConfiguration.Storage.EncryptionKeyFile)
+
+	assert.Equal(t, 48*time.Hour, appConfiguration.Trash.Retention)
 }
 
 func TestLoadNotFound(t *testing.T) {
@@ -58,6 +62,11 @@ func TestLoadDefaults(t *testing.T) {
 	assert.Equal(t, "basic-go-rest-api", appConfiguration.Loggers.MainLogger.FileName)
 	assert.Equal(t, false, appConfiguration.Loggers.MainLogger.WithConsole)
 
+	assert.Equal(t, time.Hour, appConfiguration.Trash.PurgeInterval)
+	assert.Equal(t, time.Minute, appConfiguration.Expiry.SweepInterval)
+	assert.Equal(t, 10*time.Second, appConfiguration.Scheduler.Interval)
+	assert.Equal(t, IntegrityReport, appConfiguration.Integrity.Mode)
+
 	assert.Equal(t, developCode, appConfiguration.Environment.code)
 }
 
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // NewDefault creates an AppConfiguration object that contains all the default
 // configuration values.
 func NewDefault() *AppConfiguration {
@@ -9,6 +11,10 @@ func NewDefault() *AppConfiguration {
 		Loggers:     newDefaultLoggersConfiguration(),
 		Storage:     newDefaultStorageConfiguration(),
 		Server:      newDefaultServerConfiguration(),
+		Trash:       newDefaultTrashConfiguration(),
+		Expiry:      newDefaultExpiryConfiguration(),
+		Scheduler:   newDefaultSchedulerConfiguration(),
+		Integrity:   newDefaultIntegrityConfiguration(),
 	}
 }
 
@@ -81,3 +87,28 @@ func newDefaultBoltDbConfiguration() *BoltDbConfiguration {
 		Name: "local-storage/boltdb",
 	}
 }
+
+func newDefaultTrashConfiguration() *TrashConfiguration {
+	return &TrashConfiguration{
+		Retention:     30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+}
+
+func newDefaultExpiryConfiguration() *ExpiryConfiguration {
+	return &ExpiryConfiguration{
+		SweepInterval: time.Minute,
+	}
+}
+
+func newDefaultSchedulerConfiguration() *SchedulerConfiguration {
+	return &SchedulerConfiguration{
+		Interval: 10 * time.Second,
+	}
+}
+
+func newDefaultIntegrityConfiguration() *IntegrityConfiguration {
+	return &IntegrityConfiguration{
+		Mode: IntegrityReport,
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Package encryption implements the symmetric encryption used for keeping
+sensitive values (e.g. secret properties) encrypted at rest.
+*/
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// ErrNoKey signals that an encryption operation was requested, but no key was
+// configured.
+var ErrNoKey = errors.New("no encryption key configured")
+
+// ErrInvalidCiphertext signals that a value cannot be decrypted, either because
+// it is malformed or because it was encrypted with a different key.
+var ErrInvalidCiphertext = errors.New("invalid encrypted value")
+
+// Cipher encrypts and decrypts values using AES-GCM. A nil Cipher can be used
+// safely, but all its operations will fail with ErrNoKey.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New creates a Cipher using the given key. The key must be 16, 24 or 32 bytes
+// long, in order to select AES-128, AES-192 or AES-256.
+func New(key []byte) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// NewFromKeyFile creates a Cipher using the key found in the given file. The
+// file must contain the base64 encoded key; any surrounding whitespace is ignored.
+func NewFromKeyFile(fileName string) (*Cipher, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	return New(key)
+}
+
+// Encrypt the given value. The result is base64 encoded and contains the random
+// nonce used for the encryption.
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if c == nil {
+		return "", ErrNoKey
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt the given value, previously obtained by means of Encrypt.
+func (c *Cipher) Decrypt(ciphertext string) (string, error) {
+	if c == nil {
+		return "", ErrNoKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", ErrInvalidCiphertext
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,94 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestEncryptDecrypt(t *testing.T) {
+	c, err := New(testKey)
+	assert.Nil(t, err)
+
+	encrypted, err := c.Encrypt("secret value")
+	assert.Nil(t, err)
+	assert.NotEqual(t, "secret value", encrypted)
+
+	decrypted, err := c.Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret value", decrypted)
+}
+
+func TestEncryptRandomNonce(t *testing.T) {
+	c, _ := New(testKey)
+
+	first, _ := c.Encrypt("secret value")
+	second, _ := c.Encrypt("secret value")
+
+	assert.NotEqual(t, first, second)
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	c, _ := New(testKey)
+	other, _ := New([]byte("fedcba9876543210fedcba9876543210"))
+
+	encrypted, _ := c.Encrypt("secret value")
+	_, err := other.Decrypt(encrypted)
+
+	assert.Equal(t, ErrInvalidCiphertext, err)
+}
+
+func TestDecryptMalformed(t *testing.T) {
+	c, _ := New(testKey)
+
+	_, err := c.Decrypt("not base64!")
+	assert.Equal(t, ErrInvalidCiphertext, err)
+
+	_, err = c.Decrypt("c2hvcnQ=")
+	assert.Equal(t, ErrInvalidCiphertext, err)
+}
+
+func TestNilCipher(t *testing.T) {
+	var c *Cipher
+
+	_, err := c.Encrypt("secret value")
+	assert.Equal(t, ErrNoKey, err)
+
+	_, err = c.Decrypt("c2VjcmV0")
+	assert.Equal(t, ErrNoKey, err)
+}
+
+func TestInvalidKey(t *testing.T) {
+	_, err := New([]byte("short"))
+
+	assert.NotNil(t, err)
+}
+
+func TestNewFromKeyFile(t *testing.T) {
+	fileName := "../tests/local-keys/test.key"
+	util.CreateParentFolder(fileName)
+	defer os.RemoveAll("../tests/local-keys")
+
+	ioutil.WriteFile(fileName, []byte(base64.StdEncoding.EncodeToString(testKey)+"\n"), 0600)
+
+	c, err := NewFromKeyFile(fileName)
+	assert.Nil(t, err)
+
+	reference, _ := New(testKey)
+	encrypted, _ := reference.Encrypt("secret value")
+	decrypted, err := c.Decrypt(encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "secret value", decrypted)
+}
+
+func TestNewFromKeyFileNotFound(t *testing.T) {
+	_, err := NewFromKeyFile("../tests/local-keys/missing.key")
+
+	assert.NotNil(t, err)
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 )
 
 // Error signals that something went wrong during the business actions.
@@ -10,8 +11,18 @@ import (
 // Such errors may signal both client and server errors.
 // For the time being, here there are used the same codes as the HTTP response codes.
 type Error struct {
-	Code    int
-	Message string
+	Code       int
+	Message    string
+	Violations []Violation
+}
+
+// Violation describes a single reason for which an entity is not valid. The
+// Field names the invalid field of the entity and the Path, if any, locates the
+// invalid part of the field value as a JSON pointer.
+type Violation struct {
+	Field   string `json:"field"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
 }
 
 const (
@@ -19,6 +30,11 @@ const (
 	conflict          = 101
 	invalidEmptyField = 102
 	invalidCustom     = 103
+	invalidParameter  = 104
+	preconditionFail  = 105
+	unsupportedMedia  = 106
+	deliveryFailed    = 107
+	invalidViolations = 108
 )
 
 var errorTemplates = map[int]errorTemplate{
@@ -26,6 +42,11 @@ var errorTemplates = map[int]errorTemplate{
 	conflict:          errorTemplate{409, "Found %s with same unique property (%s='%s')"},
 	invalidEmptyField: errorTemplate{400, "Invalid %s entity. Property '%s' cannot be empty"},
 	invalidCustom:     errorTemplate{400, "Invalid %s entity. %s"},
+	invalidParameter:  errorTemplate{400, "Invalid value for parameter '%s' ('%s')"},
+	preconditionFail:  errorTemplate{412, "Modified %s entity (id='%s'). Expected version '%s' is outdated"},
+	unsupportedMedia:  errorTemplate{415, "Unsupported content type '%s'"},
+	deliveryFailed:    errorTemplate{502, "Cannot deliver %s entity (id='%s'): %s"},
+	invalidViolations: errorTemplate{400, "Invalid %s entity. Found %d constraint violation(s)"},
 }
 
 func (e *Error) Error() string {
@@ -60,6 +81,52 @@ func NewInvalidEntityCustom(entity interface{}, message string) error {
 	return createError(invalidCustom, entity, message)
 }
 
+// NewInvalidParameter retrieves a new Error, signaling that a request parameter
+// (e.g. a query parameter) has a value that cannot be processed.
+func NewInvalidParameter(name string, value string) error {
+	errorTemplate := errorTemplates[invalidParameter]
+
+	return &Error{Code: errorTemplate.code, Message: fmt.Sprintf(errorTemplate.message, name, value)}
+}
+
+// NewPreconditionFailed retrieves a new Error, signaling that an entity cannot
+// be modified because it no longer has the expected version.
+func NewPreconditionFailed(entity interface{}, identifier string, version int) error {
+	return createError(preconditionFail, entity, identifier, strconv.Itoa(version))
+}
+
+// NewUnsupportedMediaType retrieves a new Error, signaling that the request body
+// has a content type that cannot be processed.
+func NewUnsupportedMediaType(contentType string) error {
+	errorTemplate := errorTemplates[unsupportedMedia]
+
+	return &Error{Code: errorTemplate.code, Message: fmt.Sprintf(errorTemplate.message, contentType)}
+}
+
+// NewDeliveryFailed retrieves a new Error, signaling that an entity could not
+// be delivered to an external endpoint, for the given reason.
+func NewDeliveryFailed(entity interface{}, identifier string, reason string) error {
+
+	return createError(deliveryFailed, entity, identifier, reason)
+}
+
+// NewViolations retrieves a new Error, signaling that a certain entity is not
+// valid for each of the given reasons.
+func NewViolations(entity interface{}, violations []Violation) error {
+	err := createError(invalidViolations, entity, len(violations)).(*Error)
+	err.Violations = violations
+
+	return err
+}
+
+// IsNotFound checks whether the given error signals that an entity is not
+// available.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+
+	return ok && e.Code == errorTemplates[notFound].code
+}
+
 func createError(errorType int, entity interface{}, args ...interface{}) error {
 	errorTemplate := errorTemplates[errorType]
 
@@ -68,7 +135,7 @@ func createError(errorType int, entity interface{}, args ...interface{}) error {
 	args = append([]interface{}{typ}, args...)
 	message = fmt.Sprintf(message, args...)
 
-	return &Error{errorTemplate.code, message}
+	return &Error{Code: errorTemplate.code, Message: message}
 }
 
 type errorTemplate struct {
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,3 +40,50 @@ func TestInvalidEntityCustom(t *testing.T) {
 	assert.Equal(t, "Invalid model.Property entity. test message", actual.Message)
 	assert.Equal(t, "[code=400][Invalid model.Property entity. test message]", actual.Error())
 }
+
+func TestInvalidParameter(t *testing.T) {
+	err := NewInvalidParameter("asOf", "yesterday")
+	actual := err.(*Error)
+	assert.Equal(t, 400, actual.Code)
+	assert.Equal(t, "Invalid value for parameter 'asOf' ('yesterday')", actual.Message)
+	assert.Equal(t, "[code=400][Invalid value for parameter 'asOf' ('yesterday')]", actual.Error())
+}
+
+func TestPreconditionFailed(t *testing.T) {
+	err := NewPreconditionFailed(model.Property{}, "123", 2)
+	actual := err.(*Error)
+	assert.Equal(t, 412, actual.Code)
+	assert.Equal(t, "Modified model.Property entity (id='123'). Expected version '2' is outdated", actual.Message)
+	assert.Equal(t, "[code=412][Modified model.Property entity (id='123'). Expected version '2' is outdated]", actual.Error())
+}
+
+func TestUnsupportedMediaType(t *testing.T) {
+	err := NewUnsupportedMediaType("text/plain")
+	actual := err.(*Error)
+	assert.Equal(t, 415, actual.Code)
+	assert.Equal(t, "Unsupported content type 'text/plain'", actual.Message)
+	assert.Equal(t, "[code=415][Unsupported content type 'text/plain']", actual.Error())
+}
+
+func TestDeliveryFailed(t *testing.T) {
+	err := NewDeliveryFailed(model.DeadLetter{}, "123", "unexpected status 500")
+	actual := err.(*Error)
+	assert.Equal(t, 502, actual.Code)
+	assert.Equal(t, "Cannot deliver model.DeadLetter entity (id='123'): unexpected status 500", actual.Message)
+	assert.Equal(t, "[code=502][Cannot deliver model.DeadLetter entity (id='123'): unexpected status 500]", actual.Error())
+}
+
+func TestViolations(t *testing.T) {
+	violations := []Violation{{Field: "value", Path: "/port", Message: "is required"}}
+	err := NewViolations(model.Property{}, violations)
+	actual := err.(*Error)
+	assert.Equal(t, 400, actual.Code)
+	assert.Equal(t, "Invalid model.Property entity. Found 1 constraint violation(s)", actual.Message)
+	assert.Equal(t, violations, actual.Violations)
+}
+
+func TestIsNotFound(t *testing.T) {
+	assert.Equal(t, true, IsNotFound(NewEntityNotFound("", "123")))
+	assert.Equal(t, false, IsNotFound(NewConflict("", "name", "123")))
+	assert.Equal(t, false, IsNotFound(fmt.Errorf("not found")))
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/flag"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
+)
+
+// Controller that handles the relation between the server and the service.
+type Controller struct {
+	service flag.Service
+}
+
+// New retrieves a brand new contoller wrapping around the given service.
+func New(service flag.Service) server.ControllerWrapper {
+	return server.ControllerWrapper{
+		Controller: &Controller{
+			service: service,
+		},
+	}
+}
+
+type evaluateDto struct {
+	UserID     string            `json:"user_id"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// EvaluationDto defines how the result of a flag evaluation must be exposed. The
+// variant is natively typed, according to the property type. The rule is the
+// index of the targeting rule that served the variant and it is missing if the
+// property value was served.
+type EvaluationDto struct {
+	Flag    string      `json:"flag"`
+	Variant interface{} `json:"variant"`
+	Rule    *int        `json:"rule,omitempty"`
+	Reason  string      `json:"reason"`
+}
+
+// Evaluate retrieves the variant of a single flag served for the evaluation
+// context given by the request body. The 'profile' query parameter selects the
+// profile overrides, just like when reading the property.
+func (ctrl *Controller) Evaluate(ctx *gin.Context) {
+	// Read input (must be JSON valid)
+	dto := new(evaluateDto)
+	if err := ctx.BindJSON(dto); err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := toProfiles(ctx.Query("profile"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Call service (business logic).
+	evaluation, err := ctrl.service.Evaluate(ctx.Request.Context(), ctx.Param("name"), model.EvaluationContext{
+		UserID:     dto.UserID,
+		Attributes: dto.Attributes,
+		Profiles:   profiles,
+	})
+
+	// Respond with either error either success.
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toEvaluationDto(evaluation))
+}
+
+func toProfiles(profile string) ([]string, error) {
+	if profile == "" {
+		return nil, nil
+	}
+
+	var profiles []string
+	for _, p := range strings.Split(profile, ",") {
+		p = strings.TrimSpace(p)
+		if !model.IsValidProfile(p) {
+			return nil, errors.NewInvalidParameter("profile", profile)
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+func toEvaluationDto(evaluation *model.Evaluation) *EvaluationDto {
+	dto := &EvaluationDto{
+		Flag:   evaluation.Flag,
+		Reason: evaluation.Reason,
+	}
+
+	if typed, err := model.ParseValue(evaluation.Type, evaluation.Variant); err == nil {
+		dto.Variant = typed
+	} else {
+		dto.Variant = evaluation.Variant
+	}
+
+	if evaluation.Rule >= 0 {
+		rule := evaluation.Rule
+		dto.Rule = &rule
+	}
+
+	return dto
+}
+
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
+func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
+	for _, api := range server.NamespacedGroups(routerGroup, "/flag") {
+		api.POST("/:name/evaluate", ctrl.Evaluate)
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	flag_service "github.com/rghiorghisor/basic-go-rest-api/flag/service"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	router, service := setup()
+
+	evaluationContext := model.EvaluationContext{UserID: "u1", Attributes: map[string]string{"country": "RO"}}
+	service.On("Evaluate", "checkout.limit", evaluationContext).Return(&model.Evaluation{Flag: "checkout.limit", Type: model.TypeInt, Variant: "20", Rule: 1, Reason: model.ReasonRollout}, nil)
+
+	w := perform("POST", "/api/flag/checkout.limit/evaluate", []byte(`{"user_id":"u1","attributes":{"country":"RO"}}`), router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"flag":"checkout.limit","variant":20,"rule":1,"reason":"rollout"}`, w.Body.String())
+}
+
+func TestEvaluateDefault(t *testing.T) {
+	router, service := setup()
+
+	service.On("Evaluate", "checkout.enabled", model.EvaluationContext{}).Return(&model.Evaluation{Flag: "checkout.enabled", Type: model.TypeBool, Variant: "false", Rule: -1, Reason: model.ReasonDefault}, nil)
+
+	w := perform("POST", "/api/ns/payments/flag/checkout.enabled/evaluate", []byte(`{}`), router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"flag":"checkout.enabled","variant":false,"reason":"default"}`, w.Body.String())
+}
+
+func TestEvaluateProfile(t *testing.T) {
+	router, service := setup()
+
+	service.On("Evaluate", "checkout.limit", model.EvaluationContext{Profiles: []string{"prod", "eu"}}).Return(&model.Evaluation{Flag: "checkout.limit", Type: model.TypeInt, Variant: "50", Rule: -1, Reason: model.ReasonDefault}, nil)
+
+	w := perform("POST", "/api/flag/checkout.limit/evaluate?profile=prod,eu", []byte(`{}`), router)
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"flag":"checkout.limit","variant":50,"reason":"default"}`, w.Body.String())
+
+	w = perform("POST", "/api/flag/checkout.limit/evaluate?profile=prod,", []byte(`{}`), router)
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestEvaluateFailed(t *testing.T) {
+	router, service := setup()
+
+	service.On("Evaluate", "missing", model.EvaluationContext{}).Return(nil, apperrors.NewEntityNotFound(model.Property{}, "missing"))
+	service.On("Evaluate", "db.password", model.EvaluationContext{}).Return(nil, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "secret properties cannot be evaluated as flags."))
+
+	w := perform("POST", "/api/flag/missing/evaluate", []byte(`{}`), router)
+	assert.Equal(t, 404, w.Code)
+
+	w = perform("POST", "/api/flag/db.password/evaluate", []byte(`{}`), router)
+	assert.Equal(t, 400, w.Code)
+
+	w = perform("POST", "/api/flag/missing/evaluate", []byte(`{"user_id":`), router)
+	assert.Equal(t, 400, w.Code)
+}
+
+func setup() (r *gin.Engine, serviceMock *flag_service.FlagServiceMock) {
+	router := gin.Default()
+	router.Use(
+		jsonAppErrorHandler(),
+	)
+	api := router.Group("/api")
+
+	service := new(flag_service.FlagServiceMock)
+	controller := New(service).Controller
+	controller.Register(api)
+
+	return router, service
+}
+
+func perform(method string, uri string, body []byte, router *gin.Engine) (rr *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(method, uri, bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func jsonAppErrorHandler() gin.HandlerFunc {
+	return handle(gin.ErrorTypeAny)
+}
+
+func handle(errType gin.ErrorType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		detectedErrors := c.Errors
+
+		if len(detectedErrors) > 0 {
+			err := detectedErrors[0].Err
+
+			switch err.(type) {
+			case *apperrors.Error:
+				oError := err.(*apperrors.Error)
+				c.AbortWithError(oError.Code, oError)
+			default:
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+
+			return
+		}
+	}
+}
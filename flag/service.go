@@ -0,0 +1,20 @@
+/*
+Package flag implements the evaluation of properties as feature flags.
+
+A property is evaluated for a given context (e.g. a user and its attributes),
+by applying its targeting rules in order. The variants served by percentage
+rollouts are deterministic: the same context always gets the same variant of
+the same property, as long as the rules are not changed.
+*/
+package flag
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Service defines the use case available for feature flags.
+type Service interface {
+	Evaluate(ctx context.Context, name string, evaluationContext model.EvaluationContext) (*model.Evaluation, error)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/flag"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+)
+
+// FlagService defines the service evaluating the feature flags.
+type FlagService struct {
+	properties property.Service
+}
+
+// New creates a FlagService. The properties are read through the given property
+// service, so that a flag is served the same value as the property itself.
+func New(propertyService property.Service) flag.Service {
+	return FlagService{
+		properties: propertyService,
+	}
+}
+
+// Evaluate retrieves the variant of the property with the given name served for
+// the given evaluation context. The property is looked up within the namespace
+// of the given context, with its references interpolated and the override of
+// the evaluation context profiles served by default. Secret properties, as well
+// as the ones referencing secret properties, cannot be evaluated, as their
+// values would be served to any caller.
+func (service FlagService) Evaluate(ctx context.Context, name string, evaluationContext model.EvaluationContext) (*model.Evaluation, error) {
+	prop, err := service.properties.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if prop.Secret {
+		return nil, errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "secret properties cannot be evaluated as flags.")
+	}
+
+	return prop.Resolve(evaluationContext.Profiles).Evaluate(evaluationContext), nil
+}
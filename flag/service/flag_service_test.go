@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/tests/fixture"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultDir = "../../tests/local-repo"
+var defaultDB = "../../tests/local-repo/flagservicedb"
+
+type testContext struct {
+	services *fixture.Services
+	service  FlagService
+}
+
+func TestEvaluate(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	tc.services.Properties.Create(ctx, &model.Property{
+		Name:      "checkout.enabled",
+		Namespace: "payments",
+		Type:      model.TypeBool,
+		Value:     "false",
+		Targeting: &model.Targeting{Rules: []model.TargetingRule{
+			{Conditions: []model.TargetingCondition{{Attribute: "country", Operator: model.ConditionIn, Values: []string{"RO"}}}, Variant: "true"},
+		}},
+	})
+
+	evaluation, err := tc.service.Evaluate(ctx, "checkout.enabled", model.EvaluationContext{UserID: "u1", Attributes: map[string]string{"country": "RO"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "true", evaluation.Variant)
+	assert.Equal(t, 0, evaluation.Rule)
+	assert.Equal(t, model.ReasonRule, evaluation.Reason)
+
+	evaluation, err = tc.service.Evaluate(ctx, "checkout.enabled", model.EvaluationContext{UserID: "u1", Attributes: map[string]string{"country": "DE"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "false", evaluation.Variant)
+	assert.Equal(t, model.ReasonDefault, evaluation.Reason)
+
+	_, err = tc.service.Evaluate(context.Background(), "checkout.enabled", model.EvaluationContext{})
+	assert.True(t, apperrors.IsNotFound(err))
+}
+
+func TestEvaluateSecret(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	tc.services.Properties.Create(context.Background(), &model.Property{Name: "db.password", Value: "secret", Secret: true})
+
+	_, err := tc.service.Evaluate(context.Background(), "db.password", model.EvaluationContext{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.(*apperrors.Error).Code)
+}
+
+func TestEvaluateResolved(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	tc.services.Properties.Create(ctx, &model.Property{Name: "checkout.base", Type: model.TypeInt, Value: "10"})
+	tc.services.Properties.Create(ctx, &model.Property{Name: "checkout.limit", Type: model.TypeInt, Value: "${checkout.base}", Overrides: map[string]string{"prod": "50"}})
+	tc.services.Properties.Create(ctx, &model.Property{Name: "db.password", Value: "secret", Secret: true})
+	tc.services.Properties.Create(ctx, &model.Property{Name: "db.url", Value: "db://admin:${db.password}@localhost"})
+
+	evaluation, err := tc.service.Evaluate(ctx, "checkout.limit", model.EvaluationContext{})
+	assert.Nil(t, err)
+	assert.Equal(t, "10", evaluation.Variant)
+
+	evaluation, err = tc.service.Evaluate(ctx, "checkout.limit", model.EvaluationContext{Profiles: []string{"prod"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "50", evaluation.Variant)
+
+	_, err = tc.service.Evaluate(ctx, "db.url", model.EvaluationContext{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.(*apperrors.Error).Code)
+}
+
+func setup() *testContext {
+	services := fixture.New(defaultDB, trash_service.New)
+
+	return &testContext{
+		services: services,
+		service:  New(services.Properties).(FlagService),
+	}
+}
+
+func tearDown(tc *testContext) {
+	tc.services.Close()
+}
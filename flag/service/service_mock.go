@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// FlagServiceMock retrieves a new mock for FlagService.
+type FlagServiceMock struct {
+	mock.Mock
+}
+
+// Evaluate mock function.
+func (m *FlagServiceMock) Evaluate(ctx context.Context, name string, evaluationContext model.EvaluationContext) (*model.Evaluation, error) {
+	args := m.Called(name, evaluationContext)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Evaluation), args.Error(1)
+}
@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/asdine/storm/v3 v3.2.1
+	github.com/evanphx/json-patch v4.12.0+incompatible
 	github.com/gin-gonic/gin v1.6.3
 	github.com/go-playground/assert/v2 v2.0.1
 	github.com/google/uuid v1.1.2
@@ -23,4 +24,5 @@ require (
 	golang.org/x/sys v0.0.0-20200905004654-be1d3432aa8f // indirect
 	golang.org/x/tools v0.0.0-20200915031644-64986481280e // indirect
 	gopkg.in/go-playground/assert.v1 v1.2.1
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// AuditRecord describes a single change of either a property or a set: who
+// made it, when, and the state of the entity before and after the change.
+type AuditRecord struct {
+	// ID identifies the record. The ids sort as the records were recorded.
+	ID        string
+	Namespace string
+
+	// Entity is the kind of the changed entity, i.e. "property" or "set".
+	Entity   string
+	EntityID string
+
+	// Action is the kind of change, i.e. "create", "update", "delete",
+	// "restore" or "expire".
+	Action    string
+	Actor     string
+	RequestID string
+	Timestamp time.Time
+
+	// Before and After are the JSON snapshots of the entity before and after
+	// the change. Before is empty for creations and After for deletions. The
+	// values of secret properties are masked.
+	Before []byte
+	After  []byte
+}
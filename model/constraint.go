@@ -0,0 +1,204 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constraint restricts the values that a property can take.
+//
+// The Min and Max bounds apply to the numeric value of TypeInt and TypeFloat
+// properties, to the length of TypeString values and to the number of items of
+// TypeList values. The Enum and Pattern constraints apply to the values of all
+// types but TypeJSON, or to each item of TypeList values. The Schema is a JSON
+// Schema document and applies only to TypeJSON values.
+type Constraint struct {
+	Min     *float64
+	Max     *float64
+	Enum    []string
+	Pattern string
+	Schema  string
+}
+
+// ConstraintViolation describes how a value does not satisfy a constraint. The
+// Path is a JSON pointer to the violating part of the value, and it is empty if
+// the whole value is violating.
+type ConstraintViolation struct {
+	Path    string
+	Message string
+}
+
+// Check validates the constraint itself for the given property type.
+func (c *Constraint) Check(typ PropertyType) error {
+	if typ == TypeJSON {
+		if c.Min != nil || c.Max != nil || len(c.Enum) > 0 || c.Pattern != "" {
+			return fmt.Errorf("only 'schema' applies to %s values", typ)
+		}
+
+		if c.Schema == "" {
+			return nil
+		}
+
+		if _, err := ParseSchema(c.Schema); err != nil {
+			return fmt.Errorf("'schema' is not valid (%v)", err)
+		}
+
+		return nil
+	}
+
+	if c.Schema != "" {
+		return fmt.Errorf("'schema' applies only to %s values", TypeJSON)
+	}
+
+	if (c.Min != nil || c.Max != nil) && !hasBounds(typ) {
+		return fmt.Errorf("'min' and 'max' do not apply to %s values", typ)
+	}
+
+	if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+		return fmt.Errorf("'min' cannot be greater than 'max'")
+	}
+
+	if _, err := regexp.Compile(c.Pattern); err != nil {
+		return fmt.Errorf("'pattern' is not a valid regular expression")
+	}
+
+	for _, value := range c.Enum {
+		if _, err := ParseValue(itemType(typ), value); err != nil {
+			return fmt.Errorf("'enum' value '%s' is not a valid %s", value, itemType(typ))
+		}
+	}
+
+	return nil
+}
+
+// Validate retrieves the violations of the constraint by the given value, which
+// must be valid for the given property type. The constraint must be valid as
+// well, see Check.
+func (c *Constraint) Validate(typ PropertyType, value string) []ConstraintViolation {
+	if typ == TypeJSON {
+		return c.validateSchema(value)
+	}
+
+	var violations []ConstraintViolation
+	if violation := c.validateBounds(typ, value); violation != "" {
+		violations = append(violations, ConstraintViolation{Message: violation})
+	}
+
+	if typ != TypeList {
+		if violation := c.validateItem(typ, value); violation != "" {
+			violations = append(violations, ConstraintViolation{Message: violation})
+		}
+
+		return violations
+	}
+
+	for index, item := range parseList(value) {
+		if violation := c.validateItem(TypeString, item); violation != "" {
+			violations = append(violations, ConstraintViolation{Path: "/" + strconv.Itoa(index), Message: violation})
+		}
+	}
+
+	return violations
+}
+
+func (c *Constraint) validateBounds(typ PropertyType, value string) string {
+	if c.Min == nil && c.Max == nil {
+		return ""
+	}
+
+	var measure float64
+	var unit string
+	switch typ {
+	case TypeInt, TypeFloat:
+		measure, _ = strconv.ParseFloat(value, 64)
+	case "", TypeString:
+		measure, unit = float64(utf8.RuneCountInString(value)), " characters"
+	case TypeList:
+		measure, unit = float64(len(parseList(value))), " items"
+	default:
+		return ""
+	}
+
+	if c.Min != nil && measure < *c.Min {
+		if unit == "" {
+			return fmt.Sprintf("must be greater than or equal to %v", *c.Min)
+		}
+
+		return fmt.Sprintf("must have at least %v%s", *c.Min, unit)
+	}
+
+	if c.Max != nil && measure > *c.Max {
+		if unit == "" {
+			return fmt.Sprintf("must be less than or equal to %v", *c.Max)
+		}
+
+		return fmt.Sprintf("must have at most %v%s", *c.Max, unit)
+	}
+
+	return ""
+}
+
+func (c *Constraint) validateItem(typ PropertyType, value string) string {
+	if len(c.Enum) > 0 && !c.allows(typ, value) {
+		return fmt.Sprintf("must be one of '%s'", strings.Join(c.Enum, "', '"))
+	}
+
+	if c.Pattern != "" {
+		if pattern, err := regexp.Compile(c.Pattern); err == nil && !pattern.MatchString(value) {
+			return fmt.Sprintf("must match the pattern '%s'", c.Pattern)
+		}
+	}
+
+	return ""
+}
+
+// allows checks whether the given value is one of the enumerated values. The
+// values are compared by their typed values, so that, for instance, "1.0" and
+// "1" are equal float values.
+func (c *Constraint) allows(typ PropertyType, value string) bool {
+	typed, err := ParseValue(typ, value)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range c.Enum {
+		if typedAllowed, err := ParseValue(typ, allowed); err == nil && reflect.DeepEqual(typed, typedAllowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Constraint) validateSchema(value string) []ConstraintViolation {
+	if c.Schema == "" {
+		return nil
+	}
+
+	// The schemas stored before unsupported keywords were rejected are still
+	// applied, ignoring such keywords.
+	schema, err := parseSchema(c.Schema)
+	if err != nil {
+		return nil
+	}
+
+	return schema.Validate(value)
+}
+
+func hasBounds(typ PropertyType) bool {
+	return typ == "" || typ == TypeString || typ == TypeInt || typ == TypeFloat || typ == TypeList
+}
+
+// itemType retrieves the type of the values the enumerated values are compared
+// to: the items of TypeList values are strings.
+func itemType(typ PropertyType) PropertyType {
+	if typ == TypeList {
+		return TypeString
+	}
+
+	return typ
+}
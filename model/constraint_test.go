@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	low, high := 1.0, 10.0
+
+	assert.Nil(t, (&Constraint{Min: &low, Max: &high}).Check(TypeInt))
+	assert.Nil(t, (&Constraint{Enum: []string{"a", "b"}, Pattern: "^[a-z]$"}).Check(TypeList))
+	assert.Nil(t, (&Constraint{Schema: `{"type":"object"}`}).Check(TypeJSON))
+
+	assert.NotNil(t, (&Constraint{Min: &high, Max: &low}).Check(TypeInt))
+	assert.NotNil(t, (&Constraint{Min: &low}).Check(TypeBool))
+	assert.NotNil(t, (&Constraint{Enum: []string{"one"}}).Check(TypeInt))
+	assert.NotNil(t, (&Constraint{Pattern: "("}).Check(TypeString))
+	assert.NotNil(t, (&Constraint{Schema: `{"type":"object"}`}).Check(TypeString))
+	assert.NotNil(t, (&Constraint{Schema: `{"type":`}).Check(TypeJSON))
+	assert.NotNil(t, (&Constraint{Pattern: "a"}).Check(TypeJSON))
+}
+
+func TestConstraintValidate(t *testing.T) {
+	min, max := 1.0, 3.0
+	tests := []struct {
+		constraint *Constraint
+		typ        PropertyType
+		value      string
+		expected   []ConstraintViolation
+	}{
+		{&Constraint{Min: &min, Max: &max}, TypeInt, "2", nil},
+		{&Constraint{Min: &min, Max: &max}, TypeFloat, "3.5", []ConstraintViolation{{Message: "must be less than or equal to 3"}}},
+		{&Constraint{Min: &min}, TypeString, "", []ConstraintViolation{{Message: "must have at least 1 characters"}}},
+		{&Constraint{Max: &max}, TypeList, "a,b,c,d", []ConstraintViolation{{Message: "must have at most 3 items"}}},
+		{&Constraint{Enum: []string{"1", "2"}}, TypeFloat, "2.0", nil},
+		{&Constraint{Enum: []string{"debug", "info"}}, TypeString, "trace", []ConstraintViolation{{Message: "must be one of 'debug', 'info'"}}},
+		{&Constraint{Pattern: "^[a-z]+$"}, TypeList, "a, B, c", []ConstraintViolation{{Path: "/1", Message: "must match the pattern '^[a-z]+$'"}}},
+		{&Constraint{Schema: `{"type":"object","required":["port"]}`}, TypeJSON, `{}`, []ConstraintViolation{{Path: "/port", Message: "is required"}}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.constraint.Validate(test.typ, test.value))
+	}
+}
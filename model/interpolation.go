@@ -0,0 +1,161 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var referencePattern = regexp.MustCompile(`\$\{([^${}\s]+)\}`)
+
+// ReferenceCycleError is returned when property values reference each other in
+// a cycle, so that they cannot be interpolated.
+type ReferenceCycleError struct {
+	Names []string
+}
+
+func (e *ReferenceCycleError) Error() string {
+	return fmt.Sprintf("reference cycle %s", strings.Join(e.Names, " -> "))
+}
+
+// References retrieves the distinct names of the properties referenced by the
+// given value as '${name}', in order of appearance.
+func References(value string) []string {
+	var names []string
+	found := make(map[string]bool)
+
+	for _, match := range referencePattern.FindAllStringSubmatch(value, -1) {
+		if !found[match[1]] {
+			names = append(names, match[1])
+			found[match[1]] = true
+		}
+	}
+
+	return names
+}
+
+// HasReferences checks whether the given value references other properties.
+func HasReferences(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// Lookup retrieves the property with the given name, or nil if there is no such
+// property.
+type Lookup func(name string) (*Property, error)
+
+// Interpolator replaces the references of property values by the values of the
+// referenced properties, which are in turn interpolated. The interpolated values
+// are kept, so an Interpolator should be used for a single read.
+type Interpolator struct {
+	lookup   Lookup
+	resolved map[string]interpolated
+}
+
+type interpolated struct {
+	value  string
+	secret bool
+}
+
+// NewInterpolator creates an Interpolator retrieving the referenced properties
+// using the given lookup.
+func NewInterpolator(lookup Lookup) *Interpolator {
+	return &Interpolator{lookup: lookup, resolved: make(map[string]interpolated)}
+}
+
+// Interpolate retrieves a copy of the given property with the references of its
+// value and overrides replaced. The overrides of a profile are interpolated using
+// the overrides of the same profile of the referenced properties, if any. The
+// references to missing properties are kept as they are. A property referencing
+// a secret property becomes secret as well.
+//
+// A ReferenceCycleError is returned if the references form a cycle.
+func (i *Interpolator) Interpolate(property *Property) (*Property, error) {
+	path := []string{property.Name}
+
+	result := *property
+	value, err := i.interpolate(property.Value, "", path)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Value = value.value
+	result.Secret = result.Secret || value.secret
+
+	if len(property.Overrides) == 0 {
+		return &result, nil
+	}
+
+	result.Overrides = make(map[string]string, len(property.Overrides))
+	for profile, override := range property.Overrides {
+		value, err := i.interpolate(override, profile, path)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Overrides[profile] = value.value
+		result.Secret = result.Secret || value.secret
+	}
+
+	return &result, nil
+}
+
+func (i *Interpolator) interpolate(value string, profile string, path []string) (interpolated, error) {
+	result := interpolated{}
+
+	var err error
+	result.value = referencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		if err != nil {
+			return reference
+		}
+
+		resolved, found, e := i.resolve(reference[2:len(reference)-1], profile, path)
+		if e != nil {
+			err = e
+			return reference
+		}
+
+		if !found {
+			return reference
+		}
+
+		result.secret = result.secret || resolved.secret
+
+		return resolved.value
+	})
+
+	return result, err
+}
+
+func (i *Interpolator) resolve(name string, profile string, path []string) (interpolated, bool, error) {
+	for index, visited := range path {
+		if visited == name {
+			names := append(append([]string{}, path[index:]...), name)
+			return interpolated{}, false, &ReferenceCycleError{Names: names}
+		}
+	}
+
+	key := profile + "/" + name
+	if resolved, found := i.resolved[key]; found {
+		return resolved, true, nil
+	}
+
+	property, err := i.lookup(name)
+	if err != nil || property == nil {
+		return interpolated{}, false, err
+	}
+
+	value := property.Value
+	if override, found := property.Overrides[profile]; found && profile != "" {
+		value = override
+	}
+
+	resolved, err := i.interpolate(value, profile, append(append([]string{}, path...), name))
+	if err != nil {
+		return interpolated{}, false, err
+	}
+
+	resolved.secret = resolved.secret || property.Secret
+	i.resolved[key] = resolved
+
+	return resolved, true, nil
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferences(t *testing.T) {
+	assert.Equal(t, []string{"db.host", "db.port"}, References("jdbc:postgresql://${db.host}:${db.port}/${db.host}"))
+	assert.Nil(t, References("$db.host ${} ${ db.host}"))
+}
+
+func TestInterpolate(t *testing.T) {
+	props := map[string]*Property{
+		"db.host":   {Name: "db.host", Value: "${db.domain}", Overrides: map[string]string{"prod": "db.${db.domain}"}},
+		"db.domain": {Name: "db.domain", Value: "localhost", Overrides: map[string]string{"prod": "example.com"}},
+	}
+	interpolator := NewInterpolator(func(name string) (*Property, error) {
+		return props[name], nil
+	})
+
+	actual, err := interpolator.Interpolate(&Property{
+		Name:      "db.url",
+		Value:     "//${db.host}/${db.missing}",
+		Overrides: map[string]string{"prod": "//${db.host}", "test": "//${db.host}:1"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "//localhost/${db.missing}", actual.Value)
+	assert.Equal(t, map[string]string{"prod": "//db.example.com", "test": "//localhost:1"}, actual.Overrides)
+	assert.False(t, actual.Secret)
+}
+
+func TestInterpolateCycle(t *testing.T) {
+	props := map[string]*Property{
+		"a": {Name: "a", Value: "${b}"},
+		"b": {Name: "b", Value: "${a}"},
+	}
+	interpolator := NewInterpolator(func(name string) (*Property, error) {
+		return props[name], nil
+	})
+
+	_, err := interpolator.Interpolate(props["a"])
+	assert.Equal(t, &ReferenceCycleError{Names: []string{"a", "b", "a"}}, err)
+
+	_, err = interpolator.Interpolate(&Property{Name: "self", Value: "${self}"})
+	assert.Equal(t, &ReferenceCycleError{Names: []string{"self", "self"}}, err)
+}
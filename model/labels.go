@@ -0,0 +1,181 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SelectorOperator defines how a label requirement is matched.
+type SelectorOperator string
+
+// All operators that can be used in a label selector.
+const (
+	OperatorEquals       SelectorOperator = "="
+	OperatorNotEquals    SelectorOperator = "!="
+	OperatorIn           SelectorOperator = "in"
+	OperatorNotIn        SelectorOperator = "notin"
+	OperatorExists       SelectorOperator = "exists"
+	OperatorDoesNotExist SelectorOperator = "!"
+)
+
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]*[A-Za-z0-9])?$`)
+var labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?)?$`)
+var setRequirementPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// LabelRequirement is a single condition of a label selector.
+type LabelRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	Values   []string
+}
+
+// LabelSelector filters entities by their labels. An entity is selected only if
+// all requirements are met.
+type LabelSelector []LabelRequirement
+
+// IsValidLabelKey checks whether the given label key is valid.
+func IsValidLabelKey(key string) bool {
+	return labelKeyPattern.MatchString(key)
+}
+
+// IsValidLabelValue checks whether the given label value is valid. Empty values
+// are valid.
+func IsValidLabelValue(value string) bool {
+	return labelValuePattern.MatchString(value)
+}
+
+// ParseLabelSelector parses a Kubernetes-style label selector, such as
+// 'team=payments,tier!=dev,env in (prod,qa),!deprecated'.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	result := LabelSelector{}
+	if strings.TrimSpace(selector) == "" {
+		return result, nil
+	}
+
+	for _, term := range splitSelector(selector) {
+		requirement, err := parseRequirement(strings.TrimSpace(term))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *requirement)
+	}
+
+	return result, nil
+}
+
+// IsEmpty checks whether the selector has any requirements.
+func (selector LabelSelector) IsEmpty() bool {
+	return len(selector) == 0
+}
+
+// Matches checks whether the given labels meet all requirements of the selector.
+func (selector LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range selector {
+		if !requirement.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Matches checks whether the given labels meet the requirement.
+func (requirement LabelRequirement) Matches(labels map[string]string) bool {
+	value, found := labels[requirement.Key]
+
+	switch requirement.Operator {
+	case OperatorEquals:
+		return found && value == requirement.Values[0]
+	case OperatorNotEquals:
+		return !found || value != requirement.Values[0]
+	case OperatorIn:
+		return found && contains(requirement.Values, value)
+	case OperatorNotIn:
+		return !found || !contains(requirement.Values, value)
+	case OperatorExists:
+		return found
+	case OperatorDoesNotExist:
+		return !found
+	}
+
+	return false
+}
+
+// splitSelector splits the selector into its terms, ignoring the commas that
+// separate the values of set based requirements.
+func splitSelector(selector string) []string {
+	terms := make([]string, 0)
+	depth := 0
+	start := 0
+
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(terms, selector[start:])
+}
+
+func parseRequirement(term string) (*LabelRequirement, error) {
+	if strings.HasPrefix(term, "!") {
+		return newRequirement(strings.TrimSpace(term[1:]), OperatorDoesNotExist, nil)
+	}
+
+	if match := setRequirementPattern.FindStringSubmatch(term); match != nil {
+		values := strings.Split(match[3], ",")
+		for i, value := range values {
+			values[i] = strings.TrimSpace(value)
+		}
+
+		return newRequirement(match[1], SelectorOperator(match[2]), values)
+	}
+
+	if index := strings.Index(term, "!="); index >= 0 {
+		return newRequirement(strings.TrimSpace(term[:index]), OperatorNotEquals, []string{strings.TrimSpace(term[index+2:])})
+	}
+
+	if index := strings.Index(term, "=="); index >= 0 {
+		return newRequirement(strings.TrimSpace(term[:index]), OperatorEquals, []string{strings.TrimSpace(term[index+2:])})
+	}
+
+	if index := strings.Index(term, "="); index >= 0 {
+		return newRequirement(strings.TrimSpace(term[:index]), OperatorEquals, []string{strings.TrimSpace(term[index+1:])})
+	}
+
+	return newRequirement(term, OperatorExists, nil)
+}
+
+func newRequirement(key string, operator SelectorOperator, values []string) (*LabelRequirement, error) {
+	if !IsValidLabelKey(key) {
+		return nil, fmt.Errorf("invalid label key '%s'", key)
+	}
+
+	for _, value := range values {
+		if !IsValidLabelValue(value) {
+			return nil, fmt.Errorf("invalid label value '%s'", value)
+		}
+	}
+
+	return &LabelRequirement{Key: key, Operator: operator, Values: values}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	actual, err := ParseLabelSelector("team=payments, tier!=dev,env in (prod, qa),region notin (eu),app.io/name==api,critical,!deprecated")
+
+	assert.Nil(t, err)
+	assert.Equal(t, LabelSelector{
+		{Key: "team", Operator: OperatorEquals, Values: []string{"payments"}},
+		{Key: "tier", Operator: OperatorNotEquals, Values: []string{"dev"}},
+		{Key: "env", Operator: OperatorIn, Values: []string{"prod", "qa"}},
+		{Key: "region", Operator: OperatorNotIn, Values: []string{"eu"}},
+		{Key: "app.io/name", Operator: OperatorEquals, Values: []string{"api"}},
+		{Key: "critical", Operator: OperatorExists},
+		{Key: "deprecated", Operator: OperatorDoesNotExist},
+	}, actual)
+}
+
+func TestParseLabelSelectorEmpty(t *testing.T) {
+	actual, err := ParseLabelSelector(" ")
+
+	assert.Nil(t, err)
+	assert.Equal(t, true, actual.IsEmpty())
+}
+
+func TestParseLabelSelectorInvalid(t *testing.T) {
+	invalid := []string{"=payments", "team=pay ments", "team,,tier", "env in (prod,q a)", "!", "team=(payments)"}
+
+	for _, selector := range invalid {
+		_, err := ParseLabelSelector(selector)
+
+		assert.NotNil(t, err, selector)
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"team": "payments", "tier": "critical", "env": "prod"}
+
+	tests := []struct {
+		selector string
+		expected bool
+	}{
+		{"", true},
+		{"team=payments", true},
+		{"owner=", false},
+		{"team=billing", false},
+		{"tier!=dev", true},
+		{"owner!=dev", true},
+		{"env in (prod,qa)", true},
+		{"env notin (prod,qa)", false},
+		{"owner notin (prod)", true},
+		{"team", true},
+		{"owner", false},
+		{"!owner", true},
+		{"!team", false},
+		{"team=payments,tier!=critical", false},
+	}
+
+	for _, test := range tests {
+		selector, err := ParseLabelSelector(test.selector)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, selector.Matches(labels), test.selector)
+	}
+}
+
+func TestIsValidLabel(t *testing.T) {
+	assert.Equal(t, true, IsValidLabelKey("team"))
+	assert.Equal(t, true, IsValidLabelKey("app.io/name"))
+	assert.Equal(t, false, IsValidLabelKey(""))
+	assert.Equal(t, false, IsValidLabelKey("-team"))
+	assert.Equal(t, false, IsValidLabelKey("te am"))
+
+	assert.Equal(t, true, IsValidLabelValue(""))
+	assert.Equal(t, true, IsValidLabelValue("v1.2_beta"))
+	assert.Equal(t, false, IsValidLabelValue("a,b"))
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NamePattern matches property names. A pattern is given either as a glob,
+// where '*' matches any sequence of characters and '?' any single character,
+// e.g. 'payments.*', or as a regular expression enclosed in slashes, e.g.
+// '/^payments\.(db|api)\./'. A glob must match the whole name, while a regular
+// expression may match any part of it.
+type NamePattern struct {
+	Value      string
+	expression *regexp.Regexp
+}
+
+// IsNamePattern checks whether the given set value is a pattern rather than a
+// plain property name.
+func IsNamePattern(value string) bool {
+	return isRegexPattern(value) || strings.ContainsAny(value, "*?")
+}
+
+// ParseNamePattern parses the given pattern.
+func ParseNamePattern(value string) (*NamePattern, error) {
+	expression := globExpression(value)
+	if isRegexPattern(value) {
+		expression = value[1 : len(value)-1]
+	}
+
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s'", value)
+	}
+
+	return &NamePattern{Value: value, expression: compiled}, nil
+}
+
+// Matches checks whether the given name matches the pattern.
+func (pattern *NamePattern) Matches(name string) bool {
+	return pattern.expression.MatchString(name)
+}
+
+// Expression retrieves the regular expression equivalent to the pattern.
+func (pattern *NamePattern) Expression() string {
+	return pattern.expression.String()
+}
+
+func isRegexPattern(value string) bool {
+	return len(value) > 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/")
+}
+
+func globExpression(glob string) string {
+	var expression strings.Builder
+	expression.WriteString("^")
+	for _, c := range glob {
+		switch c {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expression.WriteString("$")
+
+	return expression.String()
+}
+
+// Membership tells which properties are members of a set, given the values of
+// the set, which are either plain names or name patterns.
+type Membership struct {
+	names    []string
+	index    map[string]bool
+	patterns []*NamePattern
+}
+
+// NewMembership creates the membership defined by the given set values. The
+// values that are not valid patterns are handled as plain names.
+func NewMembership(values []string) *Membership {
+	membership := &Membership{names: make([]string, 0), index: make(map[string]bool)}
+	for _, value := range values {
+		if IsNamePattern(value) {
+			if pattern, err := ParseNamePattern(value); err == nil {
+				membership.patterns = append(membership.patterns, pattern)
+				continue
+			}
+		}
+
+		if !membership.index[value] {
+			membership.names = append(membership.names, value)
+			membership.index[value] = true
+		}
+	}
+
+	return membership
+}
+
+// Names retrieves the plain names of the membership.
+func (membership *Membership) Names() []string {
+	return membership.names
+}
+
+// Patterns retrieves the name patterns of the membership.
+func (membership *Membership) Patterns() []*NamePattern {
+	return membership.patterns
+}
+
+// Contains checks whether the property with the given name is a member, i.e.
+// whether its name is one of the names or it matches one of the patterns.
+func (membership *Membership) Contains(name string) bool {
+	if membership.index[name] {
+		return true
+	}
+
+	for _, pattern := range membership.patterns {
+		if pattern.Matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
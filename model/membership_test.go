@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNamePattern(t *testing.T) {
+	assert.True(t, IsNamePattern("payments.*"))
+	assert.True(t, IsNamePattern("db.?ost"))
+	assert.True(t, IsNamePattern(`/^payments\./`))
+	assert.False(t, IsNamePattern("payments.url"))
+	assert.False(t, IsNamePattern("/"))
+}
+
+func TestNamePatternGlob(t *testing.T) {
+	pattern, err := ParseNamePattern("payments.*.url")
+
+	assert.Nil(t, err)
+	assert.Equal(t, `^payments\..*\.url$`, pattern.Expression())
+	assert.True(t, pattern.Matches("payments.db.url"))
+	assert.False(t, pattern.Matches("payments.db.url.old"))
+	assert.False(t, pattern.Matches("paymentsXdb.url"))
+}
+
+func TestNamePatternRegex(t *testing.T) {
+	pattern, err := ParseNamePattern(`/^payments\.(db|api)\./`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `^payments\.(db|api)\.`, pattern.Expression())
+	assert.True(t, pattern.Matches("payments.api.url"))
+	assert.False(t, pattern.Matches("payments.web.url"))
+}
+
+func TestNamePatternInvalid(t *testing.T) {
+	_, err := ParseNamePattern("/payments.(/")
+
+	assert.EqualError(t, err, "invalid pattern '/payments.(/'")
+}
+
+func TestMembership(t *testing.T) {
+	membership := NewMembership([]string{"db.host", "payments.*", "db.host", "/(/"})
+
+	assert.Equal(t, []string{"db.host", "/(/"}, membership.Names())
+	assert.Equal(t, 1, len(membership.Patterns()))
+	assert.True(t, membership.Contains("db.host"))
+	assert.True(t, membership.Contains("payments.url"))
+	assert.True(t, membership.Contains("/(/"))
+	assert.False(t, membership.Contains("db.port"))
+}
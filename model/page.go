@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Page restricts a list of results, sorted by name, to a single page. Pages are
+// cursor based: a page starts right after the name its token was issued for, so
+// that pages remain stable while entries are added or removed.
+type Page struct {
+	// Limit is the maximum number of results of the page. Zero means no limit.
+	Limit int
+
+	// After is the name after which the page starts. Empty for the first page.
+	After string
+
+	// Descending sorts the results by name in descending order.
+	Descending bool
+}
+
+// PageInfo describes a retrieved page.
+type PageInfo struct {
+	// Total is the number of results matching the query, across all pages.
+	Total int
+
+	// Next is the name after which the next page starts. Empty for the last
+	// page.
+	Next string
+}
+
+// Less reports whether the name a must be placed before the name b, according
+// to the sort order of the page.
+func (page Page) Less(a string, b string) bool {
+	if page.Descending {
+		return a > b
+	}
+
+	return a < b
+}
+
+// Window retrieves the bounds of the page within the given names, which must
+// already be sorted according to the page, along with the page information.
+func (page Page) Window(names []string) (int, int, PageInfo) {
+	info := PageInfo{Total: len(names)}
+
+	from := 0
+	if page.After != "" {
+		for from < len(names) && !page.Less(page.After, names[from]) {
+			from++
+		}
+	}
+
+	to := len(names)
+	if page.Limit > 0 && from+page.Limit < to {
+		to = from + page.Limit
+		info.Next = names[to-1]
+	}
+
+	return from, to, info
+}
+
+// EncodePageToken retrieves the opaque token of the page starting after the
+// given name.
+func EncodePageToken(after string) string {
+	if after == "" {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(after))
+}
+
+// DecodePageToken retrieves the name after which the page of the given token
+// starts.
+func DecodePageToken(token string) (string, error) {
+	after, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(after) == 0 {
+		return "", fmt.Errorf("invalid page token '%s'", token)
+	}
+
+	return string(after), nil
+}
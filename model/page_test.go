@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageWindow(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+
+	tests := []struct {
+		page     Page
+		from, to int
+		info     PageInfo
+	}{
+		{Page{}, 0, 5, PageInfo{Total: 5}},
+		{Page{Limit: 2}, 0, 2, PageInfo{Total: 5, Next: "b"}},
+		{Page{Limit: 2, After: "b"}, 2, 4, PageInfo{Total: 5, Next: "d"}},
+		{Page{Limit: 2, After: "d"}, 4, 5, PageInfo{Total: 5}},
+		{Page{Limit: 2, After: "bb"}, 2, 4, PageInfo{Total: 5, Next: "d"}},
+		{Page{Limit: 2, After: "e"}, 5, 5, PageInfo{Total: 5}},
+	}
+
+	for _, test := range tests {
+		from, to, info := test.page.Window(names)
+
+		assert.Equal(t, test.from, from)
+		assert.Equal(t, test.to, to)
+		assert.Equal(t, test.info, info)
+	}
+}
+
+func TestPageWindowDescending(t *testing.T) {
+	names := []string{"e", "d", "c", "b", "a"}
+
+	from, to, info := Page{Limit: 2, After: "d", Descending: true}.Window(names)
+
+	assert.Equal(t, 2, from)
+	assert.Equal(t, 4, to)
+	assert.Equal(t, PageInfo{Total: 5, Next: "b"}, info)
+}
+
+func TestPageToken(t *testing.T) {
+	token := EncodePageToken("payments.db/url")
+
+	after, err := DecodePageToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, "payments.db/url", after)
+
+	assert.Equal(t, "", EncodePageToken(""))
+
+	_, err = DecodePageToken("!!")
+	assert.NotNil(t, err)
+}
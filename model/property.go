@@ -1,9 +1,251 @@
 package model
 
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertyType defines how the value of a property must be interpreted.
+type PropertyType string
+
+// All property types that are available. A property without a type is handled
+// as a TypeString property.
+const (
+	TypeString   PropertyType = "string"
+	TypeInt      PropertyType = "int"
+	TypeFloat    PropertyType = "float"
+	TypeBool     PropertyType = "bool"
+	TypeDuration PropertyType = "duration"
+	TypeJSON     PropertyType = "json"
+	TypeList     PropertyType = "list"
+)
+
+// MaskedValue replaces the values (and overrides) of the secret properties
+// wherever they are exposed without being revealed, e.g. listings, exports,
+// audit snapshots and webhook payloads.
+const MaskedValue = "******"
+
+var profilePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+var propertyTypes = []PropertyType{TypeString, TypeInt, TypeFloat, TypeBool, TypeDuration, TypeJSON, TypeList}
+
 // Property is the central model struct of the property feature.
 type Property struct {
 	ID          string
+	Namespace   string
 	Name        string
 	Description string
+	Type        PropertyType
 	Value       string
+	Secret      bool
+	Labels      map[string]string
+	Overrides   map[string]string
+	Constraint  *Constraint
+	Targeting   *Targeting
+	ExpiresAt   time.Time
+	Revision    int
+}
+
+// PropertyRevision is an immutable snapshot of a property, recorded each time
+// the property is created or updated.
+type PropertyRevision struct {
+	PropertyID  string
+	Revision    int
+	Name        string
+	Description string
+	Type        PropertyType
+	Value       string
+	Secret      bool
+	Labels      map[string]string
+	Overrides   map[string]string
+	Constraint  *Constraint
+	Targeting   *Targeting
+	ExpiresAt   time.Time
+	Timestamp   time.Time
+}
+
+// NewPropertyRevision creates a snapshot of the given property, using the
+// property's current revision number.
+func NewPropertyRevision(property *Property, timestamp time.Time) *PropertyRevision {
+	return &PropertyRevision{
+		PropertyID:  property.ID,
+		Revision:    property.Revision,
+		Name:        property.Name,
+		Description: property.Description,
+		Type:        property.Type,
+		Value:       property.Value,
+		Secret:      property.Secret,
+		Labels:      property.Labels,
+		Overrides:   property.Overrides,
+		Constraint:  property.Constraint,
+		Targeting:   property.Targeting,
+		ExpiresAt:   property.ExpiresAt,
+		Timestamp:   timestamp,
+	}
+}
+
+// Property retrieves the property as it was captured by this revision.
+func (revision *PropertyRevision) Property() *Property {
+	return &Property{
+		ID:          revision.PropertyID,
+		Name:        revision.Name,
+		Description: revision.Description,
+		Type:        revision.Type,
+		Value:       revision.Value,
+		Secret:      revision.Secret,
+		Labels:      revision.Labels,
+		Overrides:   revision.Overrides,
+		Constraint:  revision.Constraint,
+		Targeting:   revision.Targeting,
+		ExpiresAt:   revision.ExpiresAt,
+		Revision:    revision.Revision,
+	}
+}
+
+// Masked checks whether the value or any override of the property is the
+// MaskedValue, i.e. whether the property comes from a listing that did not
+// reveal its secret values.
+func (property *Property) Masked() bool {
+	if property.Value == MaskedValue {
+		return true
+	}
+
+	for _, value := range property.Overrides {
+		if value == MaskedValue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve retrieves the property as seen by the given profiles. The profiles are
+// given in order of precedence: the value is replaced by the override of the
+// first profile that has one. If none of the profiles has an override, the
+// property is retrieved unchanged.
+func (property *Property) Resolve(profiles []string) *Property {
+	for _, profile := range profiles {
+		if value, found := property.Overrides[profile]; found {
+			resolved := *property
+			resolved.Value = value
+
+			return &resolved
+		}
+	}
+
+	return property
+}
+
+// Expired checks whether the property has expired at the given time. A property
+// without an expiry time never expires.
+func (property *Property) Expired(at time.Time) bool {
+	return !property.ExpiresAt.IsZero() && !property.ExpiresAt.After(at)
+}
+
+// IsValidProfile checks whether the given name can be used as a profile name.
+func IsValidProfile(profile string) bool {
+	return profilePattern.MatchString(profile)
+}
+
+// TypedValue retrieves the property value converted according to the property
+// type. See ParseValue for details.
+func (property *Property) TypedValue() (interface{}, error) {
+	return ParseValue(property.Type, property.Value)
+}
+
+// IsValidPropertyType retrieves true if the given type is known, false otherwise.
+// The empty type is valid and is handled as TypeString.
+func IsValidPropertyType(typ PropertyType) bool {
+	if typ == "" {
+		return true
+	}
+
+	for _, t := range propertyTypes {
+		if t == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseValue converts the given raw value according to the given type:
+//   - TypeString values are retrieved as they are;
+//   - TypeInt, TypeFloat and TypeBool values are retrieved as int64, float64 and bool;
+//   - TypeDuration values are retrieved in their canonical string form (e.g. "1m30s");
+//   - TypeJSON values are retrieved as json.RawMessage;
+//   - TypeList values are comma separated and are retrieved as []string.
+func ParseValue(typ PropertyType, value string) (interface{}, error) {
+	switch typ {
+	case "", TypeString:
+		return value, nil
+	case TypeInt:
+		return strconv.ParseInt(value, 10, 64)
+	case TypeFloat:
+		return strconv.ParseFloat(value, 64)
+	case TypeBool:
+		return strconv.ParseBool(value)
+	case TypeDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return d.String(), nil
+	case TypeJSON:
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("invalid JSON value '%s'", value)
+		}
+
+		return json.RawMessage(value), nil
+	case TypeList:
+		return parseList(value), nil
+	}
+
+	return nil, fmt.Errorf("unknown property type '%s'", typ)
+}
+
+// FormatValue converts a natively typed value (e.g. as decoded from JSON) to the
+// raw string form in which property values are kept. Lists of TypeList properties
+// are joined using commas; any other non string value is encoded as JSON.
+func FormatValue(typ PropertyType, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []interface{}:
+		if typ == TypeList {
+			values := make([]string, len(v))
+			for i, item := range v {
+				values[i] = fmt.Sprint(item)
+			}
+
+			return strings.Join(values, ","), nil
+		}
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+func parseList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return []string{}
+	}
+
+	values := strings.Split(value, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+
+	return values
 }
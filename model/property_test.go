@@ -0,0 +1,119 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		typ      PropertyType
+		value    string
+		expected interface{}
+	}{
+		{"", "plain", "plain"},
+		{TypeString, "plain", "plain"},
+		{TypeInt, "42", int64(42)},
+		{TypeFloat, "4.2", 4.2},
+		{TypeBool, "true", true},
+		{TypeDuration, "90s", "1m30s"},
+		{TypeJSON, `{"a":1}`, json.RawMessage(`{"a":1}`)},
+		{TypeList, "a, b,c", []string{"a", "b", "c"}},
+		{TypeList, "", []string{}},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseValue(test.typ, test.value)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestParseValueInvalid(t *testing.T) {
+	tests := []struct {
+		typ   PropertyType
+		value string
+	}{
+		{TypeInt, "4.2"},
+		{TypeFloat, "abc"},
+		{TypeBool, "yes please"},
+		{TypeDuration, "30"},
+		{TypeJSON, `{"a":}`},
+		{"unknown", "value"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseValue(test.typ, test.value)
+
+		assert.NotNil(t, err)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		typ      PropertyType
+		value    interface{}
+		expected string
+	}{
+		{TypeString, nil, ""},
+		{TypeString, "plain", "plain"},
+		{TypeInt, float64(42), "42"},
+		{TypeBool, true, "true"},
+		{TypeList, []interface{}{"a", "b"}, "a,b"},
+		{TypeJSON, []interface{}{"a", "b"}, `["a","b"]`},
+		{TypeJSON, map[string]interface{}{"a": float64(1)}, `{"a":1}`},
+	}
+
+	for _, test := range tests {
+		actual, err := FormatValue(test.typ, test.value)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected, actual)
+	}
+}
+
+func TestIsValidPropertyType(t *testing.T) {
+	assert.Equal(t, true, IsValidPropertyType(""))
+	assert.Equal(t, true, IsValidPropertyType(TypeDuration))
+	assert.Equal(t, false, IsValidPropertyType("date"))
+}
+
+func TestMasked(t *testing.T) {
+	assert.True(t, (&Property{Value: MaskedValue}).Masked())
+	assert.True(t, (&Property{Value: "x", Overrides: map[string]string{"prod": MaskedValue}}).Masked())
+	assert.False(t, (&Property{Value: "x", Overrides: map[string]string{"prod": "y"}}).Masked())
+}
+
+func TestResolve(t *testing.T) {
+	property := &Property{Name: "db.url", Value: "default", Overrides: map[string]string{"prod": "prod-value", "prod-eu": "prod-eu-value"}}
+
+	assert.Equal(t, "default", property.Resolve(nil).Value)
+	assert.Equal(t, "default", property.Resolve([]string{"dev"}).Value)
+	assert.Equal(t, "prod-value", property.Resolve([]string{"prod"}).Value)
+	assert.Equal(t, "prod-eu-value", property.Resolve([]string{"prod-eu", "prod"}).Value)
+	assert.Equal(t, "prod-value", property.Resolve([]string{"dev", "prod", "prod-eu"}).Value)
+
+	// The property itself is never changed.
+	assert.Equal(t, "default", property.Value)
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, false, (&Property{}).Expired(now))
+	assert.Equal(t, false, (&Property{ExpiresAt: now.Add(time.Second)}).Expired(now))
+	assert.Equal(t, true, (&Property{ExpiresAt: now}).Expired(now))
+	assert.Equal(t, true, (&Property{ExpiresAt: now.Add(-time.Second)}).Expired(now))
+}
+
+func TestIsValidProfile(t *testing.T) {
+	assert.Equal(t, true, IsValidProfile("prod"))
+	assert.Equal(t, true, IsValidProfile("prod-eu_1"))
+	assert.Equal(t, false, IsValidProfile(""))
+	assert.Equal(t, false, IsValidProfile("prod.eu"))
+	assert.Equal(t, false, IsValidProfile("-prod"))
+}
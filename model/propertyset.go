@@ -1,9 +1,115 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+)
+
 // PropertySet is the central model struct of the property set feature.
 // A set contains a collection of property names and can be used for searching
 // and processing only a set of properties.
+//
+// A set can include other sets of the same namespace, by name. The members of
+// the included sets are members of the including set as well, see Expand.
+//
+// The Version of a set is incremented by each update. Sets stored before
+// versions were introduced have version zero until their first update.
 type PropertySet struct {
-	Name   string
-	Values []string
+	Namespace string
+	Name      string
+	Values    []string
+	Includes  []string
+	Version   int
+}
+
+// SetMember is a member of the effective membership of a set, along with the
+// name of the set providing it.
+type SetMember struct {
+	Name string
+	Set  string
+}
+
+// SetCycleError is returned when sets include each other in a cycle, so that
+// they cannot be expanded.
+type SetCycleError struct {
+	Names []string
+}
+
+func (e *SetCycleError) Error() string {
+	return fmt.Sprintf("include cycle %s", strings.Join(e.Names, " -> "))
+}
+
+// SetLookup retrieves the set with the given name, or nil if there is no such
+// set.
+type SetLookup func(name string) (*PropertySet, error)
+
+// Expand retrieves the effective membership of the set, by recursively
+// expanding the included sets retrieved using the given lookup. The missing
+// included sets are ignored.
+//
+// The included sets are expanded in order, followed by the values of the set
+// itself. A name provided by several sets is a single member, placed where it
+// first appears, and it is provided by the last of these sets: the later
+// includes override the earlier ones and the values of the set override all
+// includes.
+//
+// A SetCycleError is returned if the includes form a cycle.
+func (set *PropertySet) Expand(lookup SetLookup) ([]SetMember, error) {
+	var members []SetMember
+	index := make(map[string]int)
+
+	err := set.expand(lookup, []string{set.Name}, func(member SetMember) {
+		if i, found := index[member.Name]; found {
+			members[i].Set = member.Set
+			return
+		}
+
+		index[member.Name] = len(members)
+		members = append(members, member)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (set *PropertySet) expand(lookup SetLookup, path []string, add func(SetMember)) error {
+	for _, name := range set.Includes {
+		for index, visited := range path {
+			if visited == name {
+				return &SetCycleError{Names: append(append([]string{}, path[index:]...), name)}
+			}
+		}
+
+		included, err := lookup(name)
+		if err != nil {
+			return err
+		}
+
+		if included == nil {
+			continue
+		}
+
+		if err := included.expand(lookup, append(append([]string{}, path...), name), add); err != nil {
+			return err
+		}
+	}
+
+	for _, value := range set.Values {
+		add(SetMember{Name: value, Set: set.Name})
+	}
+
+	return nil
+}
+
+// MemberNames retrieves the names of the given members.
+func MemberNames(members []SetMember) []string {
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.Name
+	}
+
+	return names
 }
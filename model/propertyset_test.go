@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	sets := map[string]*PropertySet{
+		"common":  {Name: "common", Values: []string{"db.host", "db.port", "log.level"}},
+		"logging": {Name: "logging", Values: []string{"log.level", "log.format"}},
+		"db":      {Name: "db", Values: []string{"db.port"}, Includes: []string{"common"}},
+	}
+	lookup := func(name string) (*PropertySet, error) {
+		return sets[name], nil
+	}
+
+	set := &PropertySet{Name: "payments", Values: []string{"payments.url", "db.host"}, Includes: []string{"db", "logging", "missing"}}
+	members, err := set.Expand(lookup)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []SetMember{
+		{Name: "db.host", Set: "payments"},
+		{Name: "db.port", Set: "db"},
+		{Name: "log.level", Set: "logging"},
+		{Name: "log.format", Set: "logging"},
+		{Name: "payments.url", Set: "payments"},
+	}, members)
+	assert.Equal(t, []string{"db.host", "db.port", "log.level", "log.format", "payments.url"}, MemberNames(members))
+}
+
+func TestExpandCycle(t *testing.T) {
+	sets := map[string]*PropertySet{
+		"a": {Name: "a", Includes: []string{"b"}},
+		"b": {Name: "b", Includes: []string{"c"}},
+		"c": {Name: "c", Includes: []string{"a"}},
+	}
+	lookup := func(name string) (*PropertySet, error) {
+		return sets[name], nil
+	}
+
+	_, err := sets["a"].Expand(lookup)
+	assert.Equal(t, &SetCycleError{Names: []string{"a", "b", "c", "a"}}, err)
+
+	_, err = (&PropertySet{Name: "self", Includes: []string{"self"}}).Expand(lookup)
+	assert.EqualError(t, err, "include cycle self -> self")
+}
+
+func TestExpandLookupError(t *testing.T) {
+	failure := errors.New("failure")
+	lookup := func(name string) (*PropertySet, error) {
+		return nil, failure
+	}
+
+	_, err := (&PropertySet{Name: "a", Includes: []string{"b"}}).Expand(lookup)
+	assert.Equal(t, failure, err)
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Release is an immutable snapshot of the properties of a property set. The
+// properties are frozen with their values and overrides as resolved when the
+// release was created, so that the release always serves the same content.
+//
+// The releases of a set are numbered from 1, in the order of their creation.
+type Release struct {
+	Namespace  string
+	Set        string
+	Number     int
+	Properties []*Property
+	CreatedAt  time.Time
+}
+
+// NewRelease creates a release of the given set holding frozen copies of the
+// given properties. Only the fields describing the content of the properties
+// are kept, i.e. the name, description, type, value, labels and overrides,
+// along with the secret flag.
+func NewRelease(namespace string, set string, properties []*Property) *Release {
+	frozen := make([]*Property, len(properties))
+	for i, property := range properties {
+		frozen[i] = &Property{
+			Namespace:   namespace,
+			Name:        property.Name,
+			Description: property.Description,
+			Type:        property.Type,
+			Value:       property.Value,
+			Secret:      property.Secret,
+			Labels:      property.Labels,
+			Overrides:   property.Overrides,
+		}
+	}
+
+	return &Release{Namespace: namespace, Set: set, Properties: frozen}
+}
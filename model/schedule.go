@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// ScheduledChange is a value staged for a property, which becomes the value of
+// the property once its activation time is reached.
+type ScheduledChange struct {
+	ID         string
+	Namespace  string
+	PropertyID string
+	Value      string
+
+	// Secret tells whether the change belongs to a secret property, in which
+	// case its value is handled as the value of the property.
+	Secret bool
+
+	ActivateAt time.Time
+	CreatedAt  time.Time
+}
+
+// Due checks whether the change must be applied at the given moment.
+func (change *ScheduledChange) Due(at time.Time) bool {
+	return !change.ActivateAt.After(at)
+}
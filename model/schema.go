@@ -0,0 +1,424 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a parsed JSON Schema document. The following keywords are
+// supported:
+//   - type, enum and const;
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum and multipleOf;
+//   - minLength, maxLength and pattern;
+//   - items, minItems, maxItems and uniqueItems;
+//   - properties, required, additionalProperties, minProperties and maxProperties;
+//   - allOf, anyOf, oneOf and not.
+//
+// The annotations ($schema, $id, $comment, title, description, default and
+// examples) are allowed and ignored; any other keyword (e.g. $ref, format,
+// patternProperties or if) is rejected. Patterns use the RE2 syntax, so that
+// lookarounds and backreferences are rejected as well.
+type Schema struct {
+	boolean *bool
+
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []interface{}      `json:"enum"`
+	Const                *json.RawMessage   `json:"const"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum"`
+	MultipleOf           *float64           `json:"multipleOf"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	UniqueItems          bool               `json:"uniqueItems"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	MinProperties        *int               `json:"minProperties"`
+	MaxProperties        *int               `json:"maxProperties"`
+	AllOf                []*Schema          `json:"allOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	Not                  *Schema            `json:"not"`
+
+	pattern *regexp.Regexp
+	unknown []string
+}
+
+// schemaKeywords holds the keywords that are either supported or ignored
+// annotations.
+var schemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"minLength": true, "maxLength": true, "pattern": true,
+	"items": true, "minItems": true, "maxItems": true, "uniqueItems": true,
+	"properties": true, "required": true, "additionalProperties": true, "minProperties": true, "maxProperties": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true,
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "default": true, "examples": true,
+}
+
+// ParseSchema parses the given JSON Schema document, which must only use the
+// supported keywords.
+func ParseSchema(document string) (*Schema, error) {
+	schema, err := parseSchema(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyword := schema.unsupported(); keyword != "" {
+		return nil, fmt.Errorf("unsupported keyword '%s'", keyword)
+	}
+
+	return schema, nil
+}
+
+// parseSchema parses the given JSON Schema document, ignoring the unsupported
+// keywords.
+func parseSchema(document string) (*Schema, error) {
+	schema := &Schema{}
+	if err := json.Unmarshal([]byte(document), schema); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// UnmarshalJSON parses a schema, which is either an object or a boolean.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var boolean bool
+	if err := json.Unmarshal(data, &boolean); err == nil {
+		*s = Schema{boolean: &boolean}
+		return nil
+	}
+
+	type plain Schema
+	var parsed plain
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+
+	*s = Schema(parsed)
+	for keyword := range keywords {
+		if !schemaKeywords[keyword] {
+			s.unknown = append(s.unknown, keyword)
+		}
+	}
+	sort.Strings(s.unknown)
+
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern '%s'", s.Pattern)
+		}
+
+		s.pattern = pattern
+	}
+
+	return nil
+}
+
+// Validate retrieves the violations of the schema by the given JSON value.
+func (s *Schema) Validate(value string) []ConstraintViolation {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return []ConstraintViolation{{Message: "must be a valid JSON value"}}
+	}
+
+	var violations []ConstraintViolation
+	s.validate(decoded, "", &violations)
+
+	return violations
+}
+
+func (s *Schema) validate(value interface{}, path string, violations *[]ConstraintViolation) {
+	violate := func(format string, args ...interface{}) {
+		*violations = append(*violations, ConstraintViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.boolean != nil {
+		if !*s.boolean {
+			violate("is not allowed")
+		}
+
+		return
+	}
+
+	if len(s.Type) > 0 && !s.Type.matches(value) {
+		violate("must be of type %s", strings.Join(s.Type, " or "))
+		return
+	}
+
+	if len(s.Enum) > 0 && !containsJSON(s.Enum, value) {
+		violate("must be one of the enumerated values")
+	}
+
+	if s.Const != nil {
+		var constant interface{}
+		if json.Unmarshal(*s.Const, &constant) == nil && !equalJSON(constant, value) {
+			violate("must be equal to %s", string(*s.Const))
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		s.validateNumber(v, violate)
+	case string:
+		s.validateString(v, violate)
+	case []interface{}:
+		s.validateArray(v, path, violations, violate)
+	case map[string]interface{}:
+		s.validateObject(v, path, violations, violate)
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(value, path, violations)
+	}
+
+	if len(s.AnyOf) > 0 && s.countMatches(s.AnyOf, value) == 0 {
+		violate("must match at least one schema of 'anyOf'")
+	}
+
+	if len(s.OneOf) > 0 && s.countMatches(s.OneOf, value) != 1 {
+		violate("must match exactly one schema of 'oneOf'")
+	}
+
+	if s.Not != nil && s.countMatches([]*Schema{s.Not}, value) == 1 {
+		violate("must not match the schema of 'not'")
+	}
+}
+
+func (s *Schema) validateNumber(value float64, violate func(string, ...interface{})) {
+	if s.Minimum != nil && value < *s.Minimum {
+		violate("must be greater than or equal to %v", *s.Minimum)
+	}
+
+	if s.Maximum != nil && value > *s.Maximum {
+		violate("must be less than or equal to %v", *s.Maximum)
+	}
+
+	if s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum {
+		violate("must be greater than %v", *s.ExclusiveMinimum)
+	}
+
+	if s.ExclusiveMaximum != nil && value >= *s.ExclusiveMaximum {
+		violate("must be less than %v", *s.ExclusiveMaximum)
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		quotient := value / *s.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			violate("must be a multiple of %v", *s.MultipleOf)
+		}
+	}
+}
+
+func (s *Schema) validateString(value string, violate func(string, ...interface{})) {
+	length := utf8.RuneCountInString(value)
+	if s.MinLength != nil && length < *s.MinLength {
+		violate("must have at least %d characters", *s.MinLength)
+	}
+
+	if s.MaxLength != nil && length > *s.MaxLength {
+		violate("must have at most %d characters", *s.MaxLength)
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(value) {
+		violate("must match the pattern '%s'", s.Pattern)
+	}
+}
+
+func (s *Schema) validateArray(value []interface{}, path string, violations *[]ConstraintViolation, violate func(string, ...interface{})) {
+	if s.MinItems != nil && len(value) < *s.MinItems {
+		violate("must have at least %d items", *s.MinItems)
+	}
+
+	if s.MaxItems != nil && len(value) > *s.MaxItems {
+		violate("must have at most %d items", *s.MaxItems)
+	}
+
+	if s.UniqueItems {
+		for i := range value {
+			if containsJSON(value[:i], value[i]) {
+				violate("must have unique items")
+				break
+			}
+		}
+	}
+
+	if s.Items != nil {
+		for index, item := range value {
+			s.Items.validate(item, path+"/"+strconv.Itoa(index), violations)
+		}
+	}
+}
+
+func (s *Schema) validateObject(value map[string]interface{}, path string, violations *[]ConstraintViolation, violate func(string, ...interface{})) {
+	if s.MinProperties != nil && len(value) < *s.MinProperties {
+		violate("must have at least %d properties", *s.MinProperties)
+	}
+
+	if s.MaxProperties != nil && len(value) > *s.MaxProperties {
+		violate("must have at most %d properties", *s.MaxProperties)
+	}
+
+	for _, name := range s.Required {
+		if _, found := value[name]; !found {
+			*violations = append(*violations, ConstraintViolation{Path: path + "/" + escapePointer(name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sub, found := s.Properties[name]
+		if !found {
+			sub = s.AdditionalProperties
+		}
+
+		if sub != nil {
+			sub.validate(value[name], path+"/"+escapePointer(name), violations)
+		}
+	}
+}
+
+// unsupported retrieves the first unsupported keyword used by the schema or by
+// any of its subschemas, if any.
+func (s *Schema) unsupported() string {
+	if len(s.unknown) > 0 {
+		return s.unknown[0]
+	}
+
+	subschemas := []*Schema{s.Items, s.AdditionalProperties, s.Not}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		subschemas = append(subschemas, s.Properties[name])
+	}
+	subschemas = append(subschemas, s.AllOf...)
+	subschemas = append(subschemas, s.AnyOf...)
+	subschemas = append(subschemas, s.OneOf...)
+
+	for _, sub := range subschemas {
+		if sub == nil {
+			continue
+		}
+
+		if keyword := sub.unsupported(); keyword != "" {
+			return keyword
+		}
+	}
+
+	return ""
+}
+
+// countMatches retrieves the number of the given schemas matched by the given
+// value.
+func (s *Schema) countMatches(schemas []*Schema, value interface{}) int {
+	count := 0
+	for _, sub := range schemas {
+		var violations []ConstraintViolation
+		if sub.validate(value, "", &violations); len(violations) == 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// schemaTypes holds the types allowed by a schema, given either as a single
+// type or as a list of types.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*t = multiple
+
+	return nil
+}
+
+func (t schemaTypes) matches(value interface{}) bool {
+	for _, typ := range t {
+		switch v := value.(type) {
+		case nil:
+			if typ == "null" {
+				return true
+			}
+		case bool:
+			if typ == "boolean" {
+				return true
+			}
+		case float64:
+			if typ == "number" || (typ == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case string:
+			if typ == "string" {
+				return true
+			}
+		case []interface{}:
+			if typ == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if typ == "object" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsJSON(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if equalJSON(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equalJSON checks whether the given decoded JSON values are equal.
+func equalJSON(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	schema, err := ParseSchema(`{
+		"type": "object",
+		"required": ["host", "port"],
+		"properties": {
+			"host": {"type": "string", "minLength": 1, "pattern": "^[a-z.]+$"},
+			"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+			"tags": {"type": "array", "items": {"enum": ["a", "b"]}, "uniqueItems": true},
+			"mode": {"anyOf": [{"const": "ro"}, {"const": "rw"}]}
+		},
+		"additionalProperties": false
+	}`)
+	assert.Nil(t, err)
+
+	assert.Nil(t, schema.Validate(`{"host":"db.local","port":5432,"tags":["a"],"mode":"ro"}`))
+	assert.Equal(t, []ConstraintViolation{
+		{Path: "/host", Message: "must match the pattern '^[a-z.]+$'"},
+		{Path: "/mode", Message: "must match at least one schema of 'anyOf'"},
+		{Path: "/other", Message: "is not allowed"},
+		{Path: "/port", Message: "must be less than or equal to 65535"},
+		{Path: "/tags", Message: "must have unique items"},
+		{Path: "/tags/2", Message: "must be one of the enumerated values"},
+	}, schema.Validate(`{"host":"DB","port":70000,"tags":["a","a","c"],"mode":"x","other":1}`))
+	assert.Equal(t, []ConstraintViolation{
+		{Path: "/port", Message: "is required"},
+		{Path: "/host", Message: "must be of type string"},
+	}, schema.Validate(`{"host":1}`))
+	assert.Equal(t, []ConstraintViolation{{Message: "must be of type object"}}, schema.Validate(`[]`))
+}
+
+func TestSchemaInvalid(t *testing.T) {
+	_, err := ParseSchema(`{"pattern": "("}`)
+	assert.NotNil(t, err)
+
+	_, err = ParseSchema(`{"type": 1}`)
+	assert.NotNil(t, err)
+
+	_, err = ParseSchema(`{"pattern": "^(?=a)"}`)
+	assert.NotNil(t, err)
+}
+
+func TestSchemaUnsupported(t *testing.T) {
+	unsupported := map[string]string{
+		`{"$ref": "#/definitions/port"}`:                               "$ref",
+		`{"type": "string", "format": "email"}`:                        "format",
+		`{"properties": {"hosts": {"patternProperties": {"^a": {}}}}}`: "patternProperties",
+		`{"allOf": [{"if": {}, "then": {}, "else": {}}]}`:              "else",
+		`{"items": {"dependencies": {"a": ["b"]}}}`:                    "dependencies",
+	}
+
+	for document, keyword := range unsupported {
+		_, err := ParseSchema(document)
+		assert.Equal(t, fmt.Errorf("unsupported keyword '%s'", keyword), err, document)
+	}
+
+	schema, err := ParseSchema(`{"$schema": "http://json-schema.org/draft-07/schema#", "title": "Port", "description": "A port", "default": 80, "type": "integer"}`)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(schema.Validate("8080")))
+}
@@ -0,0 +1,263 @@
+package model
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+)
+
+// ConditionOperator defines how a targeting condition compares an attribute to
+// its values.
+type ConditionOperator string
+
+// All operators that can be used by targeting conditions.
+const (
+	ConditionIn      ConditionOperator = "in"
+	ConditionNotIn   ConditionOperator = "not_in"
+	ConditionMatches ConditionOperator = "matches"
+)
+
+// UserIDAttribute is the name under which the user id of an evaluation context
+// can be referenced by targeting conditions and rollouts.
+const UserIDAttribute = "user_id"
+
+// All reasons for which a variant is served.
+const (
+	ReasonRule    = "rule"
+	ReasonRollout = "rollout"
+	ReasonDefault = "default"
+)
+
+// Targeting defines how the value of a property is served as a feature flag
+// variant. The rules are applied in order and the first rule whose conditions
+// are all met serves the variant; if no rule applies, the property value is
+// served.
+//
+// The rollouts split the evaluation contexts using the value of the attribute
+// named by Key, which defaults to the user id. The same attribute value always
+// falls in the same bucket of a property. A rollout rule is skipped for the
+// contexts that do not have that attribute.
+type Targeting struct {
+	Key   string
+	Rules []TargetingRule
+}
+
+// TargetingRule serves either a fixed variant or a percentage rollout of
+// variants to the evaluation contexts meeting all of its conditions.
+type TargetingRule struct {
+	Conditions []TargetingCondition
+	Variant    string
+	Rollout    []RolloutShare
+}
+
+// TargetingCondition compares an attribute of the evaluation context to the
+// given values, using the given operator.
+type TargetingCondition struct {
+	Attribute string
+	Operator  ConditionOperator
+	Values    []string
+}
+
+// RolloutShare serves a variant to the given percentage of the evaluation
+// contexts.
+type RolloutShare struct {
+	Variant string
+	Weight  int
+}
+
+// EvaluationContext describes the subject for which a flag is evaluated. The
+// profiles select the override served by default, as Property.Resolve does.
+type EvaluationContext struct {
+	UserID     string
+	Attributes map[string]string
+	Profiles   []string
+}
+
+// Evaluation is the result of the evaluation of a flag. The Rule is the index
+// of the rule that served the variant, or -1 if the default value was served.
+type Evaluation struct {
+	Flag    string
+	Type    PropertyType
+	Variant string
+	Rule    int
+	Reason  string
+}
+
+// Attribute retrieves the value of the attribute with the given name.
+func (ctx EvaluationContext) Attribute(name string) (string, bool) {
+	if name == UserIDAttribute {
+		return ctx.UserID, ctx.UserID != ""
+	}
+
+	value, found := ctx.Attributes[name]
+
+	return value, found
+}
+
+// Evaluate retrieves the variant of the property served for the given context.
+// A property without targeting always serves its value.
+func (property *Property) Evaluate(ctx EvaluationContext) *Evaluation {
+	evaluation := &Evaluation{Flag: property.Name, Type: property.Type, Variant: property.Value, Rule: -1, Reason: ReasonDefault}
+	if property.Targeting == nil {
+		return evaluation
+	}
+
+	for index, rule := range property.Targeting.Rules {
+		if !rule.matches(ctx) {
+			continue
+		}
+
+		if len(rule.Rollout) == 0 {
+			evaluation.Variant, evaluation.Rule, evaluation.Reason = rule.Variant, index, ReasonRule
+			return evaluation
+		}
+
+		key, found := ctx.Attribute(property.Targeting.key())
+		if !found {
+			continue
+		}
+
+		evaluation.Variant, evaluation.Rule, evaluation.Reason = rule.rollout(Bucket(property.Name, index, key)), index, ReasonRollout
+		return evaluation
+	}
+
+	return evaluation
+}
+
+// Check validates the targeting for the given property type: the variants must
+// be valid values of that type.
+func (t *Targeting) Check(typ PropertyType) error {
+	for index, rule := range t.Rules {
+		if err := rule.check(typ); err != nil {
+			return fmt.Errorf("rule %d %s", index, err)
+		}
+	}
+
+	return nil
+}
+
+// Bucket retrieves the bucket, from 0 to 99, in which the given key falls for
+// the rollout of the rule with the given index of the given flag.
+func Bucket(flag string, rule int, key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(fmt.Sprintf("%s/%d/%s", flag, rule, key)))
+
+	return int(hash.Sum32() % 100)
+}
+
+func (t *Targeting) key() string {
+	if t.Key == "" {
+		return UserIDAttribute
+	}
+
+	return t.Key
+}
+
+func (rule TargetingRule) matches(ctx EvaluationContext) bool {
+	for _, condition := range rule.Conditions {
+		if !condition.matches(ctx) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (rule TargetingRule) rollout(bucket int) string {
+	for _, share := range rule.Rollout {
+		if bucket < share.Weight {
+			return share.Variant
+		}
+
+		bucket -= share.Weight
+	}
+
+	return rule.Rollout[len(rule.Rollout)-1].Variant
+}
+
+func (rule TargetingRule) check(typ PropertyType) error {
+	if (rule.Variant == "") == (len(rule.Rollout) == 0) {
+		return fmt.Errorf("must have either a variant or a rollout")
+	}
+
+	for _, condition := range rule.Conditions {
+		if err := condition.check(); err != nil {
+			return err
+		}
+	}
+
+	if rule.Variant != "" {
+		if _, err := ParseValue(typ, rule.Variant); err != nil {
+			return fmt.Errorf("has variant '%s' that is not a valid %s", rule.Variant, typ)
+		}
+
+		return nil
+	}
+
+	total := 0
+	for _, share := range rule.Rollout {
+		if share.Weight <= 0 {
+			return fmt.Errorf("has rollout weights that are not positive")
+		}
+
+		if _, err := ParseValue(typ, share.Variant); err != nil {
+			return fmt.Errorf("has variant '%s' that is not a valid %s", share.Variant, typ)
+		}
+
+		total += share.Weight
+	}
+
+	if total != 100 {
+		return fmt.Errorf("has rollout weights that do not add up to 100")
+	}
+
+	return nil
+}
+
+func (condition TargetingCondition) matches(ctx EvaluationContext) bool {
+	value, found := ctx.Attribute(condition.Attribute)
+
+	switch condition.Operator {
+	case ConditionIn:
+		return found && contains(condition.Values, value)
+	case ConditionNotIn:
+		return !found || !contains(condition.Values, value)
+	case ConditionMatches:
+		if !found {
+			return false
+		}
+
+		for _, expression := range condition.Values {
+			if pattern, err := regexp.Compile(expression); err == nil && pattern.MatchString(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (condition TargetingCondition) check() error {
+	if condition.Attribute == "" {
+		return fmt.Errorf("has a condition without attribute")
+	}
+
+	if len(condition.Values) == 0 {
+		return fmt.Errorf("has a condition without values")
+	}
+
+	switch condition.Operator {
+	case ConditionIn, ConditionNotIn:
+		return nil
+	case ConditionMatches:
+		for _, expression := range condition.Values {
+			if _, err := regexp.Compile(expression); err != nil {
+				return fmt.Errorf("has a condition with invalid regular expression '%s'", expression)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("has a condition with unknown operator '%s'", condition.Operator)
+}
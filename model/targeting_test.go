@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	property := &Property{
+		Name:  "checkout.limit",
+		Type:  TypeInt,
+		Value: "10",
+		Targeting: &Targeting{Rules: []TargetingRule{
+			{Conditions: []TargetingCondition{{Attribute: UserIDAttribute, Operator: ConditionIn, Values: []string{"admin"}}}, Variant: "100"},
+			{Conditions: []TargetingCondition{{Attribute: "email", Operator: ConditionMatches, Values: []string{"@example\\.com$"}}}, Variant: "50"},
+			{Conditions: []TargetingCondition{{Attribute: "country", Operator: ConditionNotIn, Values: []string{"US"}}}, Rollout: []RolloutShare{{Variant: "20", Weight: 50}, {Variant: "30", Weight: 50}}},
+		}},
+	}
+
+	evaluation := property.Evaluate(EvaluationContext{UserID: "admin", Attributes: map[string]string{"email": "admin@example.com"}})
+	assert.Equal(t, &Evaluation{Flag: "checkout.limit", Type: TypeInt, Variant: "100", Rule: 0, Reason: ReasonRule}, evaluation)
+
+	evaluation = property.Evaluate(EvaluationContext{UserID: "u1", Attributes: map[string]string{"email": "u1@example.com"}})
+	assert.Equal(t, "50", evaluation.Variant)
+	assert.Equal(t, 1, evaluation.Rule)
+
+	evaluation = property.Evaluate(EvaluationContext{UserID: "u1", Attributes: map[string]string{"country": "RO"}})
+	assert.Equal(t, ReasonRollout, evaluation.Reason)
+	assert.Equal(t, 2, evaluation.Rule)
+	assert.Contains(t, []string{"20", "30"}, evaluation.Variant)
+	assert.Equal(t, evaluation, property.Evaluate(EvaluationContext{UserID: "u1", Attributes: map[string]string{"country": "RO"}}))
+
+	evaluation = property.Evaluate(EvaluationContext{UserID: "u1", Attributes: map[string]string{"country": "US"}})
+	assert.Equal(t, &Evaluation{Flag: "checkout.limit", Type: TypeInt, Variant: "10", Rule: -1, Reason: ReasonDefault}, evaluation)
+
+	// Rollouts are skipped for the contexts without the key attribute.
+	evaluation = property.Evaluate(EvaluationContext{})
+	assert.Equal(t, ReasonDefault, evaluation.Reason)
+}
+
+func TestEvaluateRolloutKey(t *testing.T) {
+	property := &Property{
+		Name:  "checkout.enabled",
+		Type:  TypeBool,
+		Value: "false",
+		Targeting: &Targeting{Key: "account", Rules: []TargetingRule{
+			{Rollout: []RolloutShare{{Variant: "true", Weight: 30}, {Variant: "false", Weight: 70}}},
+		}},
+	}
+
+	assert.Equal(t, ReasonDefault, property.Evaluate(EvaluationContext{UserID: "u1"}).Reason)
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		evaluation := property.Evaluate(EvaluationContext{Attributes: map[string]string{"account": string(rune('a'+i%26)) + string(rune('0'+i/26))}})
+		if evaluation.Variant == "true" {
+			enabled++
+		}
+	}
+
+	assert.InDelta(t, 300, enabled, 60)
+}
+
+func TestBucket(t *testing.T) {
+	bucket := Bucket("checkout.enabled", 0, "u1")
+
+	assert.True(t, bucket >= 0 && bucket < 100)
+	assert.Equal(t, bucket, Bucket("checkout.enabled", 0, "u1"))
+}
+
+func TestTargetingCheck(t *testing.T) {
+	in := []TargetingCondition{{Attribute: "country", Operator: ConditionIn, Values: []string{"RO"}}}
+
+	assert.Nil(t, (&Targeting{Rules: []TargetingRule{{Conditions: in, Variant: "5"}}}).Check(TypeInt))
+	assert.Nil(t, (&Targeting{Rules: []TargetingRule{{Rollout: []RolloutShare{{Variant: "1", Weight: 40}, {Variant: "2", Weight: 60}}}}}).Check(TypeInt))
+
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Conditions: in}}}).Check(TypeInt), "rule 0 must have either a variant or a rollout")
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Variant: "five"}}}).Check(TypeInt), "rule 0 has variant 'five' that is not a valid int")
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Variant: "1"}, {Rollout: []RolloutShare{{Variant: "1", Weight: 40}}}}}).Check(TypeInt), "rule 1 has rollout weights that do not add up to 100")
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Rollout: []RolloutShare{{Variant: "1", Weight: 0}, {Variant: "2", Weight: 100}}}}}).Check(TypeInt), "rule 0 has rollout weights that are not positive")
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Conditions: []TargetingCondition{{Attribute: "country", Operator: "like", Values: []string{"RO"}}}, Variant: "1"}}}).Check(TypeInt), "rule 0 has a condition with unknown operator 'like'")
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Conditions: []TargetingCondition{{Attribute: "email", Operator: ConditionMatches, Values: []string{"("}}}, Variant: "1"}}}).Check(TypeInt), "rule 0 has a condition with invalid regular expression '('")
+	assert.EqualError(t, (&Targeting{Rules: []TargetingRule{{Conditions: []TargetingCondition{{Operator: ConditionIn, Values: []string{"RO"}}}, Variant: "1"}}}).Check(TypeInt), "rule 0 has a condition without attribute")
+}
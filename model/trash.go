@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// TrashEntry is a property or a set that was deleted. The entry is kept until
+// it expires, so that the entity can be restored.
+type TrashEntry struct {
+	ID        string
+	Namespace string
+
+	// Entity is the kind of the deleted entity, i.e. "property" or "set", and
+	// Name its name.
+	Entity string
+	Name   string
+
+	// Property holds the deleted property and Set the deleted set; only the one
+	// matching the entity kind is present.
+	Property *Property
+	Set      *PropertySet
+
+	// Sets names the sets the deleted property was removed from, cascading its
+	// deletion. The property is added back to them when it is restored.
+	Sets []string
+
+	DeletedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Expired checks whether the entry is expired at the given moment.
+func (entry *TrashEntry) Expired(at time.Time) bool {
+	return !entry.ExpiresAt.After(at)
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// Webhook is a subscription of an external endpoint to the changes made to the
+// properties and sets of a namespace. Each change is delivered as a POST
+// request to the URL of the webhook.
+type Webhook struct {
+	ID        string
+	Namespace string
+	URL       string
+
+	// Events restricts the notified changes to the given event types (e.g.
+	// "property.updated"). Empty if all changes must be notified.
+	Events []string
+
+	// Set restricts the notified changes to the given set and to the properties
+	// it contains. Empty if the changes of all properties must be notified.
+	Set string
+
+	// Secret is the key used to sign the deliveries. Empty if the deliveries
+	// must not be signed.
+	Secret string
+}
+
+// Accepts checks whether the webhook must be notified of the given event type.
+func (webhook *Webhook) Accepts(event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+
+	for _, accepted := range webhook.Events {
+		if accepted == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeadLetter is a delivery of a webhook that failed all its attempts. It holds
+// the delivered payload, so that the delivery can be replayed.
+type DeadLetter struct {
+	ID        string
+	Namespace string
+	WebhookID string
+	Event     string
+	Payload   []byte
+	Attempts  int
+
+	// Error describes the failure of the last attempt.
+	Error    string
+	FailedAt time.Time
+}
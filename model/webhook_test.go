@@ -0,0 +1,16 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookAccepts(t *testing.T) {
+	all := &Webhook{}
+	some := &Webhook{Events: []string{"property.created", "set.deleted"}}
+
+	assert.Equal(t, true, all.Accepts("property.updated"))
+	assert.Equal(t, true, some.Accepts("set.deleted"))
+	assert.Equal(t, false, some.Accepts("property.updated"))
+}
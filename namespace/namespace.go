@@ -0,0 +1,62 @@
+/*
+Package namespace implements the namespace dimension shared by all models.
+
+A namespace isolates entities belonging to different tenants (e.g. teams) of
+the same deployment. The namespace of the current operation is carried along
+by means of the request context.
+*/
+package namespace
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default is the namespace of all entities that are not explicitly placed in
+// a namespace.
+const Default = ""
+
+// DefaultName is the name under which the Default namespace can be referenced
+// explicitly (e.g. in URLs).
+const DefaultName = "default"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+type contextKey struct{}
+
+// NewContext retrieves a copy of the given context carrying the given namespace.
+func NewContext(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, contextKey{}, namespace)
+}
+
+// FromContext retrieves the namespace carried by the given context. If the
+// context carries no namespace, the Default namespace is retrieved.
+func FromContext(ctx context.Context) string {
+	namespace, ok := ctx.Value(contextKey{}).(string)
+	if !ok {
+		return Default
+	}
+
+	return namespace
+}
+
+// Parse validates the given namespace name and retrieves the namespace it
+// references. The second result is false if the name is not valid.
+func Parse(name string) (string, bool) {
+	if name == DefaultName {
+		return Default, true
+	}
+
+	return name, validName.MatchString(name)
+}
+
+// Key retrieves a key that identifies the given name within the given namespace.
+// For the Default namespace the key is the name itself. The keys are unique as
+// long as the names do not contain '/', which namespaces cannot contain either.
+func Key(namespace string, name string) string {
+	if namespace == Default {
+		return name
+	}
+
+	return namespace + "/" + name
+}
@@ -0,0 +1,40 @@
+package namespace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, Default, FromContext(ctx))
+
+	ctx = NewContext(ctx, "payments")
+	assert.Equal(t, "payments", FromContext(ctx))
+}
+
+func TestParse(t *testing.T) {
+	ns, ok := Parse("payments")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "payments", ns)
+
+	ns, ok = Parse(DefaultName)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, Default, ns)
+
+	_, ok = Parse("")
+	assert.Equal(t, false, ok)
+
+	_, ok = Parse("team payments")
+	assert.Equal(t, false, ok)
+
+	_, ok = Parse(".hidden")
+	assert.Equal(t, false, ok)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "common", Key(Default, "common"))
+	assert.Equal(t, "payments/common", Key("payments", "common"))
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// ConstraintDto defines how the constraint of a property must be exposed. The
+// enumerated values are natively typed, according to the property type, and the
+// schema is a JSON Schema document.
+type ConstraintDto struct {
+	Min     *float64        `json:"min,omitempty"`
+	Max     *float64        `json:"max,omitempty"`
+	Enum    []interface{}   `json:"enum,omitempty"`
+	Pattern string          `json:"pattern,omitempty"`
+	Schema  json.RawMessage `json:"schema,omitempty"`
+}
+
+// ToConstraint converts the given constraint, if any, to its model form.
+func ToConstraint(typ model.PropertyType, dto *ConstraintDto) (*model.Constraint, error) {
+	if dto == nil {
+		return nil, nil
+	}
+
+	constraint := &model.Constraint{
+		Min:     dto.Min,
+		Max:     dto.Max,
+		Pattern: dto.Pattern,
+	}
+
+	if len(dto.Schema) > 0 {
+		schema := new(bytes.Buffer)
+		if err := json.Compact(schema, dto.Schema); err != nil {
+			return nil, err
+		}
+
+		constraint.Schema = schema.String()
+	}
+
+	for _, allowed := range dto.Enum {
+		value, err := model.FormatValue(enumType(typ), allowed)
+		if err != nil {
+			return nil, err
+		}
+
+		constraint.Enum = append(constraint.Enum, value)
+	}
+
+	return constraint, nil
+}
+
+// fromConstraint reverses ToConstraint.
+func fromConstraint(typ model.PropertyType, constraint *model.Constraint) *ConstraintDto {
+	if constraint == nil {
+		return nil
+	}
+
+	dto := &ConstraintDto{
+		Min:     constraint.Min,
+		Max:     constraint.Max,
+		Pattern: constraint.Pattern,
+	}
+
+	if constraint.Schema != "" {
+		dto.Schema = json.RawMessage(constraint.Schema)
+	}
+
+	for _, allowed := range constraint.Enum {
+		dto.Enum = append(dto.Enum, toTypedValue(enumType(typ), allowed))
+	}
+
+	return dto
+}
+
+// enumType retrieves the type of the enumerated values: the items of list
+// values are strings.
+func enumType(typ model.PropertyType) model.PropertyType {
+	if typ == model.TypeList {
+		return model.TypeString
+	}
+
+	return typ
+}
@@ -1,12 +1,21 @@
 package http
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/caller"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/schedule"
 	"github.com/rghiorghisor/basic-go-rest-api/server"
 )
 
@@ -14,30 +23,65 @@ import (
 type Controller struct {
 	formatters formatters
 	service    property.Service
+	scheduler  schedule.Service
 }
 
-// PropertyDto defines how a property must be exposed.
+// PropertyDto defines how a property must be exposed. The value is exposed
+// natively typed, according to the property type.
 type PropertyDto struct {
-	ID          string `json:"id,omitempty"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	Value       string `json:"value"`
+	ID          string                 `json:"id,omitempty"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Value       interface{}            `json:"value"`
+	Secret      bool                   `json:"secret,omitempty"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	Overrides   map[string]interface{} `json:"overrides,omitempty"`
+	Constraint  *ConstraintDto         `json:"constraint,omitempty"`
+	Targeting   *TargetingDto          `json:"targeting,omitempty"`
+	ExpiresAt   *time.Time             `json:"expires_at,omitempty"`
+	Revision    int                    `json:"revision,omitempty"`
 }
 
-// New retrieves a brand new contoller wrapping around the given service.
-func New(service property.Service) server.ControllerWrapper {
+// PropertyRevisionDto defines how a property revision must be exposed.
+type PropertyRevisionDto struct {
+	Revision    int                    `json:"revision"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Value       interface{}            `json:"value"`
+	Secret      bool                   `json:"secret,omitempty"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	Overrides   map[string]interface{} `json:"overrides,omitempty"`
+	Constraint  *ConstraintDto         `json:"constraint,omitempty"`
+	Targeting   *TargetingDto          `json:"targeting,omitempty"`
+	ExpiresAt   *time.Time             `json:"expires_at,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
+}
+
+// New retrieves a brand new contoller wrapping around the given service. The
+// changes of the property values are scheduled through the given scheduler.
+func New(service property.Service, scheduler schedule.Service) server.ControllerWrapper {
 	return server.ControllerWrapper{
 		Controller: &Controller{
 			formatters: newFormatters(),
 			service:    service,
+			scheduler:  scheduler,
 		},
 	}
 }
 
 type createDto struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Value       string `json:"value"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        string                 `json:"type"`
+	Value       interface{}            `json:"value"`
+	Secret      bool                   `json:"secret"`
+	Labels      map[string]string      `json:"labels"`
+	Overrides   map[string]interface{} `json:"overrides"`
+	Constraint  *ConstraintDto         `json:"constraint"`
+	Targeting   *TargetingDto          `json:"targeting"`
+	ExpiresAt   *time.Time             `json:"expires_at"`
 }
 
 // Create retrieves creates (if possible) a brand new property.
@@ -49,14 +93,14 @@ func (ctrl *Controller) Create(ctx *gin.Context) {
 		return
 	}
 
-	prop := &model.Property{
-		Name:        dto.Name,
-		Description: dto.Description,
-		Value:       dto.Value,
+	prop, err := toProperty(dto)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
 	}
 
 	// Call service (business logic).
-	err := ctrl.service.Create(ctx.Request.Context(), prop)
+	err = ctrl.service.Create(ctx.Request.Context(), prop)
 
 	// Respond with either error either success.
 	if err != nil {
@@ -69,10 +113,24 @@ func (ctrl *Controller) Create(ctx *gin.Context) {
 }
 
 type readAllResponseDto struct {
-	PropertyDto []PropertyDto `json:"properties"`
+	PropertyDto   []PropertyDto `json:"properties"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+	Total         int           `json:"total"`
 }
 
+// Read retrieves a single property. As the router cannot register static routes
+// next to the ':id' parameter, the reserved 'tree' and 'watch' ids are handled
+// by ReadTree and Watch.
 func (ctrl *Controller) Read(ctx *gin.Context) {
+	switch ctx.Param("id") {
+	case treeID:
+		ctrl.ReadTree(ctx)
+		return
+	case watchID:
+		ctrl.Watch(ctx)
+		return
+	}
+
 	ctrl.readOne(ctx, toPropertyFiltered)
 }
 
@@ -82,39 +140,196 @@ func (ctrl *Controller) ReadBasic(ctx *gin.Context) {
 }
 
 func (ctrl *Controller) readOne(ctx *gin.Context, f func(*model.Property, property.Query) interface{}) {
-	query := parse(ctx)
+	query, err := parse(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
-	foundProp, err := ctrl.service.FindByID(ctx.Request.Context(), query.ID)
+	requestCtx := ctx.Request.Context()
+	if query.Raw {
+		requestCtx = property.NewRawContext(requestCtx)
+	}
+
+	var foundProp *model.Property
+	if query.HasAsOf() {
+		foundProp, err = ctrl.service.FindByIDAt(requestCtx, query.ID, query.GetAsOf())
+	} else {
+		foundProp, err = ctrl.service.FindByID(requestCtx, query.ID)
+	}
 
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, f(foundProp, query))
+	server.SetETag(ctx, foundProp.Revision)
+	ctx.JSON(http.StatusOK, f(protect(ctx, foundProp.Resolve(query.Profiles), query), query))
 }
 
 // ReadAll retrieves a list of all available properties.
 func (ctrl *Controller) ReadAll(ctx *gin.Context) {
-	query := parse(ctx)
-	properties, err := ctrl.service.ReadAll(ctx.Request.Context(), query)
+	query, err := parse(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	properties, info, err := ctrl.readAll(ctx, query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctrl.formatters.process(ctx, http.StatusOK, properties, info)
+}
+
+// ReadTree retrieves the properties as nested objects built from their dotted
+// names. It accepts the same query parameters as ReadAll, except for the
+// pagination ones, as the whole tree is always retrieved.
+func (ctrl *Controller) ReadTree(ctx *gin.Context) {
+	query, err := parse(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	query.ID = ""
+	query.Page = model.Page{}
+
+	properties, _, err := ctrl.readAll(ctx, query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toTree(properties))
+}
+
+// readAll retrieves the properties matching the query, resolved for the queried
+// profiles and protected.
+func (ctrl *Controller) readAll(ctx *gin.Context, query property.Query) ([]*model.Property, model.PageInfo, error) {
+	properties, info, err := ctrl.service.ReadAll(ctx.Request.Context(), query)
+	if err != nil {
+		return nil, info, err
+	}
+
+	protected := make([]*model.Property, len(properties))
+	for i, prop := range properties {
+		protected[i] = protect(ctx, prop.Resolve(query.Profiles), query)
+	}
+
+	return protected, info, nil
+}
 
+type historyResponseDto struct {
+	Revisions []PropertyRevisionDto `json:"revisions"`
+}
+
+// ReadHistory retrieves all recorded revisions of a single property.
+func (ctrl *Controller) ReadHistory(ctx *gin.Context) {
+	query, err := parse(ctx)
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctrl.formatters.process(ctx, http.StatusOK, properties)
+	revisions, err := ctrl.service.History(ctx.Request.Context(), query.ID)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &historyResponseDto{
+		Revisions: toPropertyRevisions(ctx, revisions, query),
+	})
+}
+
+// Rollback restores a single property to the revision given as query parameter.
+func (ctrl *Controller) Rollback(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	rawRevision := ctx.Query("revision")
+	revision, err := strconv.Atoi(rawRevision)
+	if err != nil {
+		ctx.Error(errors.NewInvalidParameter("revision", rawRevision))
+		return
+	}
+
+	prop, err := ctrl.service.Rollback(ctx.Request.Context(), id, revision)
+
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	server.SetETag(ctx, prop.Revision)
+	ctx.JSON(http.StatusOK, toPropertyDTO(protect(ctx, prop, property.EmptyQuery)))
+}
+
+// DecodeProperty retrieves the property held by the given JSON data, in the
+// same form as accepted when creating a single property.
+func DecodeProperty(data []byte) (*model.Property, error) {
+	dto := new(createDto)
+	if err := json.Unmarshal(data, dto); err != nil {
+		return nil, fmt.Errorf("invalid property value")
+	}
+
+	return toProperty(dto)
+}
+
+func toProperty(dto *createDto) (*model.Property, error) {
+	typ := model.PropertyType(dto.Type)
+	value, err := model.FormatValue(typ, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := toRawOverrides(typ, dto.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	constraint, err := ToConstraint(typ, dto.Constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	targeting, err := ToTargeting(typ, dto.Targeting)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Property{
+		Name:        dto.Name,
+		Description: dto.Description,
+		Type:        typ,
+		Value:       value,
+		Secret:      dto.Secret,
+		Labels:      dto.Labels,
+		Overrides:   overrides,
+		Constraint:  constraint,
+		Targeting:   targeting,
+		ExpiresAt:   toExpiresAt(dto.ExpiresAt),
+	}, nil
 }
 
 type updateDto struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Value       string `json:"value"`
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Type        string                 `json:"type"`
+	Value       interface{}            `json:"value"`
+	Secret      bool                   `json:"secret"`
+	Labels      map[string]string      `json:"labels"`
+	Overrides   map[string]interface{} `json:"overrides"`
+	Constraint  *ConstraintDto         `json:"constraint"`
+	Targeting   *TargetingDto          `json:"targeting"`
+	ExpiresAt   *time.Time             `json:"expires_at"`
 }
 
-// Update a single property.
+// Update a single property. The update is conditional if the request has an
+// 'If-Match' header holding the ETag of the property.
 func (ctrl *Controller) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
@@ -124,28 +339,130 @@ func (ctrl *Controller) Update(ctx *gin.Context) {
 		return
 	}
 
+	revision, err := server.IfMatch(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctrl.update(ctx, id, inp, revision)
+}
+
+// Patch partially updates a single property, by applying either a JSON merge
+// patch (RFC 7396) or a JSON patch (RFC 6902) to the fields accepted by Update.
+// The patch is applied to the current state of the property, so the update is
+// always conditional, even if the request has no 'If-Match' header.
+func (ctrl *Controller) Patch(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	revision, err := server.IfMatch(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	foundProp, err := ctrl.service.FindByID(property.NewRawContext(ctx.Request.Context()), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if revision == 0 {
+		revision = foundProp.Revision
+	}
+
+	inp := toUpdateDto(foundProp)
+	if err := server.Patch(ctx, model.Property{}, inp); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctrl.update(ctx, id, inp, revision)
+}
+
+func (ctrl *Controller) update(ctx *gin.Context, id string, inp *updateDto, revision int) {
+	typ := model.PropertyType(inp.Type)
+	value, err := model.FormatValue(typ, inp.Value)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	overrides, err := toRawOverrides(typ, inp.Overrides)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	constraint, err := ToConstraint(typ, inp.Constraint)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	targeting, err := ToTargeting(typ, inp.Targeting)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
 	prop := &model.Property{
 		ID:          id,
 		Name:        inp.Name,
 		Description: inp.Description,
-		Value:       inp.Value,
+		Type:        typ,
+		Value:       value,
+		Secret:      inp.Secret,
+		Labels:      inp.Labels,
+		Overrides:   overrides,
+		Constraint:  constraint,
+		Targeting:   targeting,
+		ExpiresAt:   toExpiresAt(inp.ExpiresAt),
+		Revision:    revision,
 	}
 
-	err := ctrl.service.Update(ctx.Request.Context(), prop)
+	err = ctrl.service.Update(ctx.Request.Context(), prop)
 
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, toPropertyDTO(prop))
+	server.SetETag(ctx, prop.Revision)
+	ctx.JSON(http.StatusOK, toPropertyDTO(protect(ctx, prop, property.EmptyQuery)))
+}
+
+// toUpdateDto retrieves the editable fields of the given property, as accepted
+// by Update.
+func toUpdateDto(b *model.Property) *updateDto {
+	return &updateDto{
+		ID:          b.ID,
+		Name:        b.Name,
+		Description: b.Description,
+		Type:        string(b.Type),
+		Value:       toTypedValue(b.Type, b.Value),
+		Secret:      b.Secret,
+		Labels:      b.Labels,
+		Overrides:   toTypedOverrides(b.Type, b.Overrides),
+		Constraint:  fromConstraint(b.Type, b.Constraint),
+		Targeting:   fromTargeting(b.Type, b.Targeting),
+		ExpiresAt:   fromExpiresAt(b.ExpiresAt),
+	}
 }
 
-// Delete a single property, specified by means of its identifier.
+// Delete a single property, specified by means of its identifier. The deletion
+// is conditional if the request has an 'If-Match' header holding the ETag of the
+// property.
 func (ctrl *Controller) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := ctrl.service.Delete(ctx.Request.Context(), id)
+	revision, err := server.IfMatch(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = ctrl.service.Delete(ctx.Request.Context(), id, revision)
 
 	if err != nil {
 		ctx.Error(err)
@@ -168,7 +485,7 @@ func toProperties(bs []*model.Property) []PropertyDto {
 func toBasicProperty(b *model.Property, query property.Query) interface{} {
 	return PropertyDto{
 		Name:  b.Name,
-		Value: b.Value,
+		Value: toTypedValue(b.Type, b.Value),
 	}
 }
 
@@ -176,9 +493,133 @@ func toPropertyDTO(b *model.Property) PropertyDto {
 	return PropertyDto{
 		ID:          b.ID,
 		Name:        b.Name,
-		Value:       b.Value,
+		Type:        string(b.Type),
+		Value:       toTypedValue(b.Type, b.Value),
 		Description: b.Description,
+		Secret:      b.Secret,
+		Labels:      b.Labels,
+		Overrides:   toTypedOverrides(b.Type, b.Overrides),
+		Constraint:  fromConstraint(b.Type, b.Constraint),
+		Targeting:   fromTargeting(b.Type, b.Targeting),
+		ExpiresAt:   fromExpiresAt(b.ExpiresAt),
+		Revision:    b.Revision,
+	}
+}
+
+// toExpiresAt converts the given expiry time, if any, to the form used by the
+// model, where the zero time means that the property never expires.
+func toExpiresAt(expiresAt *time.Time) time.Time {
+	if expiresAt == nil {
+		return time.Time{}
+	}
+
+	return expiresAt.UTC()
+}
+
+// fromExpiresAt reverses toExpiresAt.
+func fromExpiresAt(expiresAt time.Time) *time.Time {
+	if expiresAt.IsZero() {
+		return nil
+	}
+
+	return &expiresAt
+}
+
+// toTypedValue converts the raw value according to the given type. If the value
+// cannot be converted (e.g. stored before the type was declared) the raw value
+// is used instead.
+func toTypedValue(typ model.PropertyType, value string) interface{} {
+	typed, err := model.ParseValue(typ, value)
+	if err != nil {
+		return value
+	}
+
+	return typed
+}
+
+// toTypedOverrides applies toTypedValue to each of the given profile overrides.
+func toTypedOverrides(typ model.PropertyType, overrides map[string]string) map[string]interface{} {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(overrides))
+	for profile, value := range overrides {
+		out[profile] = toTypedValue(typ, value)
+	}
+
+	return out
+}
+
+// toRawOverrides converts the given profile overrides into their raw form,
+// according to the given type.
+func toRawOverrides(typ model.PropertyType, overrides map[string]interface{}) (map[string]string, error) {
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+
+	out := make(map[string]string, len(overrides))
+	for profile, value := range overrides {
+		raw, err := model.FormatValue(typ, value)
+		if err != nil {
+			return nil, err
+		}
+
+		out[profile] = raw
+	}
+
+	return out, nil
+}
+
+func toPropertyRevisions(ctx *gin.Context, rs []*model.PropertyRevision, query property.Query) []PropertyRevisionDto {
+	out := make([]PropertyRevisionDto, len(rs))
+
+	for i, r := range rs {
+		p := protect(ctx, r.Property(), query)
+
+		out[i] = PropertyRevisionDto{
+			Revision:    r.Revision,
+			Name:        r.Name,
+			Description: r.Description,
+			Type:        string(r.Type),
+			Value:       toTypedValue(p.Type, p.Value),
+			Secret:      r.Secret,
+			Labels:      r.Labels,
+			Overrides:   toTypedOverrides(p.Type, p.Overrides),
+			Constraint:  fromConstraint(r.Type, r.Constraint),
+			Targeting:   fromTargeting(r.Type, r.Targeting),
+			ExpiresAt:   fromExpiresAt(r.ExpiresAt),
+			Timestamp:   r.Timestamp,
+		}
+	}
+
+	return out
+}
+
+// protect retrieves the given property with its value and overrides masked if
+// the property is a secret, unless the query explicitly asks for the value to be
+// revealed. Each reveal is logged.
+func protect(ctx *gin.Context, prop *model.Property, query property.Query) *model.Property {
+	if !prop.Secret {
+		return prop
+	}
+
+	if query.Reveal {
+		logger.Main.Infof("Revealed secret property '%s' (id='%s', revision=%d) to %s.", prop.Name, prop.ID, prop.Revision, caller.FromContext(ctx.Request.Context()).Actor)
+
+		return prop
+	}
+
+	masked := *prop
+	masked.Value = model.MaskedValue
+	if len(prop.Overrides) > 0 {
+		masked.Overrides = make(map[string]string, len(prop.Overrides))
+		for profile := range prop.Overrides {
+			masked.Overrides[profile] = model.MaskedValue
+		}
 	}
+
+	return &masked
 }
 
 func toPropertyFiltered(b *model.Property, query property.Query) interface{} {
@@ -195,7 +636,7 @@ func toPropertyFiltered(b *model.Property, query property.Query) interface{} {
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
 
-		jsonKey := field.Tag.Get("json")
+		jsonKey := strings.Split(field.Tag.Get("json"), ",")[0]
 
 		if query.Fields.Contains(jsonKey) {
 			out[jsonKey] = rv.Field(i).Interface()
@@ -205,14 +646,22 @@ func toPropertyFiltered(b *model.Property, query property.Query) interface{} {
 	return out
 }
 
-// Register this controller to the provided group.
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
 func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
-	api := routerGroup.Group("/property")
-
-	api.POST("", ctrl.Create)
-	api.GET("", ctrl.ReadAll)
-	api.GET("/:id", ctrl.Read)
-	api.GET("/:id/basic", ctrl.ReadBasic)
-	api.PUT("/:id", ctrl.Update)
-	api.DELETE("/:id", ctrl.Delete)
+	for _, api := range server.NamespacedGroups(routerGroup, "/property") {
+		api.POST("", ctrl.Create)
+		api.POST("/:id", ctrl.Import)
+		api.GET("", ctrl.ReadAll)
+		api.GET("/:id", ctrl.Read)
+		api.GET("/:id/basic", ctrl.ReadBasic)
+		api.GET("/:id/history", ctrl.ReadHistory)
+		api.POST("/:id/rollback", ctrl.Rollback)
+		api.POST("/:id/schedule", ctrl.Schedule)
+		api.GET("/:id/schedule", ctrl.ReadSchedule)
+		api.DELETE("/:id/schedule/:change", ctrl.CancelSchedule)
+		api.PUT("/:id", ctrl.Update)
+		api.PATCH("/:id", ctrl.Patch)
+		api.DELETE("/:id", ctrl.Delete)
+	}
 }
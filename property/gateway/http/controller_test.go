@@ -1,20 +1,27 @@
 package http
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/caller"
 	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/property"
+	schedule_service "github.com/rghiorghisor/basic-go-rest-api/schedule/service"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -41,6 +48,152 @@ func TestCreate(t *testing.T) {
 	assert.Equal(t, 201, w.Code)
 }
 
+func TestCreateTyped(t *testing.T) {
+	router, service := setup()
+
+	prop := &model.Property{Name: "test.list", Type: model.TypeList, Value: "a,b,c"}
+
+	service.On("Create", prop).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(0).(*model.Property)
+		arg.ID = "testid"
+	})
+
+	body := []byte(`{"name": "test.list", "type": "list", "value": ["a", "b", "c"]}`)
+
+	// Perform action.
+	w := perform("POST", "/api/property", body, router)
+
+	// Test result.
+	assert.Equal(t, "/api/property/testid", w.Header().Get("Location"))
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestCreateExpiring(t *testing.T) {
+	router, service := setup()
+
+	expiresAt := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	prop := &model.Property{Name: "test.timeout", Value: "30s", ExpiresAt: expiresAt}
+
+	service.On("Create", prop).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(0).(*model.Property)
+		arg.ID = "testid"
+	})
+
+	body := []byte(`{"name": "test.timeout", "value": "30s", "expires_at": "2030-01-01T14:00:00+02:00"}`)
+
+	// Perform action.
+	w := perform("POST", "/api/property", body, router)
+
+	// Test result.
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestCreateConstraint(t *testing.T) {
+	router, service := setup()
+
+	max := 65535.0
+	prop := &model.Property{
+		Name:       "db.port",
+		Type:       model.TypeInt,
+		Value:      "5432",
+		Constraint: &model.Constraint{Max: &max, Enum: []string{"5432", "5433"}}}
+
+	service.On("Create", prop).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(0).(*model.Property)
+		arg.ID = "testid"
+	})
+
+	body := []byte(`{"name": "db.port", "type": "int", "value": 5432, "constraint": {"max": 65535, "enum": [5432, 5433]}}`)
+
+	// Perform action.
+	w := perform("POST", "/api/property", body, router)
+
+	// Test result.
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestCreateConstraintViolations(t *testing.T) {
+	router, service := setup()
+
+	prop := &model.Property{
+		Name:       "db",
+		Type:       model.TypeJSON,
+		Value:      `{"host":"localhost"}`,
+		Constraint: &model.Constraint{Schema: `{"required":["port"]}`}}
+
+	service.On("Create", prop).Return(apperrors.NewViolations(reflect.TypeOf(model.Property{}), []apperrors.Violation{{Field: "value", Path: "/port", Message: "is required"}}))
+
+	body := []byte(`{"name": "db", "type": "json", "value": {"host": "localhost"}, "constraint": {"schema": {"required": ["port"]}}}`)
+
+	// Perform action.
+	w := perform("POST", "/api/property", body, router)
+
+	// Test result.
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestReadConstraint(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "db", Type: model.TypeJSON, Value: `{"port":1}`, Constraint: &model.Constraint{Schema: `{"required":["port"]}`}}
+
+	service.On("FindByID", "TestId").Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"db","type":"json","value":{"port":1},"constraint":{"schema":{"required":["port"]}}}`, w.Body.String())
+}
+
+func TestCreateTargeting(t *testing.T) {
+	router, service := setup()
+
+	prop := &model.Property{
+		Name:  "checkout.limit",
+		Type:  model.TypeInt,
+		Value: "10",
+		Targeting: &model.Targeting{Key: "account", Rules: []model.TargetingRule{
+			{Conditions: []model.TargetingCondition{{Attribute: "country", Operator: model.ConditionIn, Values: []string{"RO"}}}, Variant: "50"},
+			{Rollout: []model.RolloutShare{{Variant: "20", Weight: 50}, {Variant: "30", Weight: 50}}},
+		}}}
+
+	service.On("Create", prop).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(0).(*model.Property)
+		arg.ID = "testid"
+	})
+
+	body := []byte(`{"name": "checkout.limit", "type": "int", "value": 10, "targeting": {"key": "account", "rules": [` +
+		`{"conditions": [{"attribute": "country", "operator": "in", "values": ["RO"]}], "variant": 50},` +
+		`{"rollout": [{"variant": 20, "weight": 50}, {"variant": 30, "weight": 50}]}]}}`)
+
+	// Perform action.
+	w := perform("POST", "/api/property", body, router)
+
+	// Test result.
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestReadTargeting(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "checkout.enabled", Type: model.TypeBool, Value: "false", Targeting: &model.Targeting{Rules: []model.TargetingRule{
+		{Conditions: []model.TargetingCondition{{Attribute: "user_id", Operator: model.ConditionIn, Values: []string{"u1"}}}, Variant: "true"},
+	}}}
+
+	service.On("FindByID", "TestId").Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"id":"TestId","name":"checkout.enabled","type":"bool","value":false,"targeting":{"rules":[{"conditions":[{"attribute":"user_id","operator":"in","values":["u1"]}],"variant":true}]}}`, w.Body.String())
+}
+
 func TestCreateConflict(t *testing.T) {
 	router, service := setup()
 
@@ -103,7 +256,7 @@ func TestReadAll(t *testing.T) {
 		properties[i] = &model.Property{ID: "Id" + is, Name: "Name test " + is, Description: "Description test " + is, Value: is}
 	}
 
-	service.On("ReadAll", property.EmptyQuery).Return(properties, nil)
+	service.On("ReadAll", property.EmptyQuery).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
 	w := perform("GET", "/api/property", nil, router)
@@ -122,7 +275,7 @@ func TestReadAllProperties(t *testing.T) {
 		properties[i] = &model.Property{ID: "Id" + is, Name: "name.test" + is, Description: "Description test" + is, Value: "Value test" + is}
 	}
 
-	service.On("ReadAll", property.EmptyQuery).Return(properties, nil)
+	service.On("ReadAll", property.EmptyQuery).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
 	headers := map[string]string{
@@ -151,7 +304,7 @@ func TestReadAllDefaultFormat(t *testing.T) {
 		properties[i] = &model.Property{ID: "Id" + is, Name: "name.test" + is, Description: "Description test" + is, Value: "Value test" + is}
 	}
 
-	service.On("ReadAll", property.EmptyQuery).Return(properties, nil)
+	service.On("ReadAll", property.EmptyQuery).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
 	headers := map[string]string{
@@ -173,7 +326,7 @@ func TestReadAllUnexpected(t *testing.T) {
 		properties[i] = &model.Property{ID: "Id", Name: "Name", Description: "Description", Value: "Value"}
 	}
 	query := property.Query{}
-	service.On("ReadAll", query).Return(properties, errors.New("unexpected"))
+	service.On("ReadAll", query).Return(properties, model.PageInfo{}, errors.New("unexpected"))
 
 	// Perform action.
 	w := perform("GET", "/api/property", nil, router)
@@ -198,174 +351,992 @@ func TestRead(t *testing.T) {
 	assert.Equal(t, `{"id":"TestId","name":"Name test","description":"Description test","value":"Value test"}`, w.Body.String())
 }
 
-func TestReadFields(t *testing.T) {
+func TestReadExpiring(t *testing.T) {
 	router, service := setup()
 
 	// Mock service return.
-	property := &model.Property{ID: "TestId", Name: "Name test", Description: "Description test", Value: "Value test"}
+	property := &model.Property{ID: "TestId", Name: "test.timeout", Value: "30s", ExpiresAt: time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)}
 
 	service.On("FindByID", "TestId").Return(property, nil)
 
 	// Perform action.
-	w := perform("GET", "/api/property/TestId?fields=name&fields=value", nil, router)
+	w := perform("GET", "/api/property/TestId", nil, router)
 
 	// Test result
 	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, `{"name":"Name test","value":"Value test"}`, w.Body.String())
+	assert.Equal(t, `{"id":"TestId","name":"test.timeout","value":"30s","expires_at":"2030-01-01T12:00:00Z"}`, w.Body.String())
 }
 
-func TestReadNotFound(t *testing.T) {
+func TestReadTyped(t *testing.T) {
 	router, service := setup()
 
 	// Mock service return.
-	service.On("FindByID", "TestId").Return(&model.Property{}, apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+	property := &model.Property{ID: "TestId", Name: "test.json", Type: model.TypeJSON, Value: `{"a":[1,2]}`}
+
+	service.On("FindByID", "TestId").Return(property, nil)
 
 	// Perform action.
 	w := perform("GET", "/api/property/TestId", nil, router)
 
-	// Test result.
-	assert.Equal(t, 404, w.Code)
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"test.json","type":"json","value":{"a":[1,2]}}`, w.Body.String())
 }
 
-func TestReadUnexpected(t *testing.T) {
+func TestReadAllTyped(t *testing.T) {
 	router, service := setup()
 
-	// Mock service error.
-	service.On("FindByID", "TestId").Return(&model.Property{}, errors.New("unexpected"))
+	// Mock service return.
+	properties := []*model.Property{
+		{ID: "Id1", Name: "test.int", Type: model.TypeInt, Value: "42"},
+		{ID: "Id2", Name: "test.bool", Type: model.TypeBool, Value: "true"},
+		{ID: "Id3", Name: "test.duration", Type: model.TypeDuration, Value: "90s"},
+		{ID: "Id4", Name: "test.untyped", Value: "42"},
+	}
+
+	service.On("ReadAll", property.EmptyQuery).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
-	w := perform("GET", "/api/property/TestId", nil, router)
+	w := perform("GET", "/api/property", nil, router)
 
 	// Test result.
-	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"properties":[`+
+		`{"id":"Id1","name":"test.int","type":"int","value":42},`+
+		`{"id":"Id2","name":"test.bool","type":"bool","value":true},`+
+		`{"id":"Id3","name":"test.duration","type":"duration","value":"1m30s"},`+
+		`{"id":"Id4","name":"test.untyped","value":"42"}],"total":4}`, w.Body.String())
 }
 
-func TestReadBasic(t *testing.T) {
+func TestReadSecret(t *testing.T) {
 	router, service := setup()
 
 	// Mock service return.
-	property := &model.Property{ID: "TestId", Name: "Name test", Description: "Description test", Value: "Value test"}
+	property := &model.Property{ID: "TestId", Name: "db.password", Value: "Value test", Secret: true}
 
 	service.On("FindByID", "TestId").Return(property, nil)
 
 	// Perform action.
-	w := perform("GET", "/api/property/TestId/basic", nil, router)
+	w := perform("GET", "/api/property/TestId", nil, router)
 
 	// Test result
 	assert.Equal(t, 200, w.Code)
-	assert.Equal(t, `{"name":"Name test","value":"Value test"}`, w.Body.String())
+	assert.Equal(t, `{"id":"TestId","name":"db.password","value":"******","secret":true}`, w.Body.String())
+	assert.Equal(t, "Value test", property.Value)
 }
 
-func TestUpdate(t *testing.T) {
-	router, service := setup()
+func TestReadSecretReveal(t *testing.T) {
+	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(caller.NewContext(ctx.Request.Context(), caller.Caller{Actor: "jane"}))
+	})
 
-	dto := &updateDto{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
-	prop := &model.Property{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
+	service := new(PropertyServiceMock)
+	New(service, new(schedule_service.ScheduleServiceMock)).Controller.Register(router.Group("/api"))
 
-	service.On("Update", prop).Return(nil)
+	buf := new(bytes.Buffer)
+	logger.Main = logger.NewDummyLogger(buf)
 
-	body, err := json.Marshal(dto)
-	assert.NoError(t, err)
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "db.password", Value: "Value test", Secret: true}
+
+	service.On("FindByID", "TestId").Return(property, nil)
 
 	// Perform action.
-	w := perform("PUT", "/api/property/testid", body, router)
+	w := perform("GET", "/api/property/TestId?reveal=true", nil, router)
 
-	// Test result.
+	// Test result
 	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"db.password","value":"Value test","secret":true}`, w.Body.String())
+	assert.Contains(t, buf.String(), "Revealed secret property 'db.password' (id='TestId', revision=0) to jane.")
 }
 
-func TestUpdateSyntacticInvalidRequestJSON(t *testing.T) {
+func TestReadAllSecretProperties(t *testing.T) {
 	router, service := setup()
 
-	prop := &model.Property{
-		ID:          "testid",
-		Name:        "TestCreateDto",
-		Description: "Test Description Dto",
-		Value:       "/uri/go/rest/api"}
+	// Mock service return.
+	properties := []*model.Property{
+		{ID: "Id1", Name: "db.url", Value: "localhost"},
+		{ID: "Id2", Name: "db.password", Value: "Value test", Secret: true},
+	}
 
-	service.On("Update", prop).Return(nil)
+	service.On("ReadAll", property.EmptyQuery).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
-	body := []byte(`{"id: "testid", ""name": "TestCreateDto" "description": "Test Description Dto" "value": "122345"}`)
+	// Perform action.
+	headers := map[string]string{
+		"Accept": "application/java.properties",
+	}
+	w := performWithHeaders("GET", "/api/property", nil, router, headers)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, ""+
+		"db.url = localhost\n"+
+		"db.password = ******\n", w.Body.String())
+}
+
+func TestReadAllRaw(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{
+		{ID: "Id1", Name: "db.url", Value: "jdbc:postgresql://${db.host}/app"},
+	}
+
+	service.On("ReadAll", property.Query{Raw: true}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
-	w := perform("PUT", "/api/property/testid", body, router)
+	headers := map[string]string{
+		"Accept": "application/java.properties",
+	}
+	w := performWithHeaders("GET", "/api/property?raw=true", nil, router, headers)
 
 	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "db.url = jdbc:postgresql://${db.host}/app\n", w.Body.String())
+}
+
+func TestReadAllInvalidRaw(t *testing.T) {
+	router, _ := setup()
+
+	w := perform("GET", "/api/property?raw=maybe", nil, router)
+
 	assert.Equal(t, 400, w.Code)
 }
 
-func TestUpdateUnexpected(t *testing.T) {
+func TestReadAllRelease(t *testing.T) {
 	router, service := setup()
 
-	dto := &updateDto{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
-	prop := &model.Property{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
-
-	service.On("Update", prop).Return(errors.New("unexpected"))
+	// Mock service return.
+	properties := []*model.Property{
+		{Name: "db.password", Value: "s3cr3t", Secret: true},
+		{Name: "db.url", Value: "jdbc:postgresql://localhost/app"},
+	}
 
-	body, err := json.Marshal(dto)
-	assert.NoError(t, err)
+	service.On("ReadAll", property.Query{Set: "db", Release: 3}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
-	w := perform("PUT", "/api/property/testid", body, router)
+	headers := map[string]string{
+		"Accept": "application/java.properties",
+	}
+	w := performWithHeaders("GET", "/api/property?set=db&release=3", nil, router, headers)
 
 	// Test result.
-	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "db.password = ******\ndb.url = jdbc:postgresql://localhost/app\n", w.Body.String())
 }
 
-func TestDelete(t *testing.T) {
+func TestReadAllInvalidRelease(t *testing.T) {
+	router, _ := setup()
+
+	for _, uri := range []string{"/api/property?release=3", "/api/property?set=db&release=0", "/api/property?set=db&release=latest"} {
+		w := perform("GET", uri, nil, router)
+
+		assert.Equal(t, 400, w.Code)
+	}
+}
+
+func TestReadFields(t *testing.T) {
 	router, service := setup()
 
-	// Mock service action.
-	service.On("Delete", "TestId").Return(nil)
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "Name test", Description: "Description test", Value: "Value test"}
+
+	service.On("FindByID", "TestId").Return(property, nil)
 
 	// Perform action.
-	w := perform("DELETE", "/api/property/TestId", nil, router)
+	w := perform("GET", "/api/property/TestId?fields=name&fields=value", nil, router)
 
-	// Test result.
-	assert.Equal(t, 204, w.Code)
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"name":"Name test","value":"Value test"}`, w.Body.String())
 }
 
-func TestDeleteNotFound(t *testing.T) {
+func TestReadFieldsOmitEmpty(t *testing.T) {
 	router, service := setup()
 
-	// Mock service action.
-	service.On("Delete", "TestId").Return(apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "Name test", Type: model.TypeInt, Value: "10"}
+
+	service.On("FindByID", "TestId").Return(property, nil)
 
 	// Perform action.
-	w := perform("DELETE", "/api/property/TestId", nil, router)
+	w := perform("GET", "/api/property/TestId?fields=id&fields=type", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","type":"int"}`, w.Body.String())
+}
+
+func TestReadNotFound(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	service.On("FindByID", "TestId").Return(&model.Property{}, apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId", nil, router)
 
 	// Test result.
 	assert.Equal(t, 404, w.Code)
 }
 
-func TestDeleteUnexpected(t *testing.T) {
+func TestReadUnexpected(t *testing.T) {
 	router, service := setup()
 
 	// Mock service error.
-	service.On("Delete", "TestId").Return(errors.New("unexpected"))
+	service.On("FindByID", "TestId").Return(&model.Property{}, errors.New("unexpected"))
 
 	// Perform action.
-	w := perform("DELETE", "/api/property/TestId", nil, router)
+	w := perform("GET", "/api/property/TestId", nil, router)
 
 	// Test result.
 	assert.Equal(t, 500, w.Code)
 }
 
-func setup() (r *gin.Engine, serviceMock *PropertyServiceMock) {
-	router := gin.Default()
-	router.Use(
-		jsonAppErrorHandler(),
-	)
-	api := router.Group("/api")
+func TestReadBasic(t *testing.T) {
+	router, service := setup()
 
-	service := new(PropertyServiceMock)
-	//setService := new(set_service.PropertySetServiceMock)
-	controller := New(service).Controller
-	controller.Register(api)
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "Name test", Description: "Description test", Value: "Value test"}
+
+	service.On("FindByID", "TestId").Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId/basic", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"name":"Name test","value":"Value test"}`, w.Body.String())
+}
+
+func TestReadAsOf(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	at := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	property := &model.Property{ID: "TestId", Name: "Name test", Value: "Old value", Revision: 1}
+
+	service.On("FindByIDAt", "TestId", at).Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId?asOf=2020-09-01T10:00:00Z", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"Name test","value":"Old value","revision":1}`, w.Body.String())
+}
+
+func TestReadAsOfInvalid(t *testing.T) {
+	router, _ := setup()
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId?asOf=yesterday", nil, router)
+
+	// Test result
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestReadHistory(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	at := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	revisions := []*model.PropertyRevision{
+		{PropertyID: "TestId", Revision: 1, Name: "Name test", Value: "Old value", Timestamp: at},
+		{PropertyID: "TestId", Revision: 2, Name: "Name test", Value: "New value", Timestamp: at.Add(time.Hour)},
+	}
+
+	service.On("History", "TestId").Return(revisions, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId/history", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"revisions":[`+
+		`{"revision":1,"name":"Name test","value":"Old value","timestamp":"2020-09-01T10:00:00Z"},`+
+		`{"revision":2,"name":"Name test","value":"New value","timestamp":"2020-09-01T11:00:00Z"}]}`, w.Body.String())
+}
+
+func TestReadHistoryNotFound(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	service.On("History", "TestId").Return([]*model.PropertyRevision{}, apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId/history", nil, router)
+
+	// Test result.
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestRollback(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "Name test", Value: "Old value", Revision: 3}
+
+	service.On("Rollback", "TestId", 1).Return(property, nil)
+
+	// Perform action.
+	w := perform("POST", "/api/property/TestId/rollback?revision=1", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"Name test","value":"Old value","revision":3}`, w.Body.String())
+}
+
+func TestRollbackInvalidRevision(t *testing.T) {
+	router, _ := setup()
+
+	// Perform action.
+	w := perform("POST", "/api/property/TestId/rollback?revision=last", nil, router)
+
+	// Test result.
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestUpdate(t *testing.T) {
+	router, service := setup()
+
+	dto := &updateDto{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
+	prop := &model.Property{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
+
+	service.On("Update", prop).Return(nil)
+
+	body, err := json.Marshal(dto)
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := perform("PUT", "/api/property/testid", body, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestUpdateSyntacticInvalidRequestJSON(t *testing.T) {
+	router, service := setup()
+
+	prop := &model.Property{
+		ID:          "testid",
+		Name:        "TestCreateDto",
+		Description: "Test Description Dto",
+		Value:       "/uri/go/rest/api"}
+
+	service.On("Update", prop).Return(nil)
+
+	body := []byte(`{"id: "testid", ""name": "TestCreateDto" "description": "Test Description Dto" "value": "122345"}`)
+
+	// Perform action.
+	w := perform("PUT", "/api/property/testid", body, router)
+
+	// Test result.
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestUpdateUnexpected(t *testing.T) {
+	router, service := setup()
+
+	dto := &updateDto{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
+	prop := &model.Property{ID: "testid", Name: "TestCreateDto", Description: "Test Description Dto", Value: "/uri/go/rest/api"}
+
+	service.On("Update", prop).Return(errors.New("unexpected"))
+
+	body, err := json.Marshal(dto)
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := perform("PUT", "/api/property/testid", body, router)
+
+	// Test result.
+	assert.Equal(t, 500, w.Code)
+}
+
+func TestDelete(t *testing.T) {
+	router, service := setup()
+
+	// Mock service action.
+	service.On("Delete", "TestId", 0).Return(nil)
+
+	// Perform action.
+	w := perform("DELETE", "/api/property/TestId", nil, router)
+
+	// Test result.
+	assert.Equal(t, 204, w.Code)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	router, service := setup()
+
+	// Mock service action.
+	service.On("Delete", "TestId", 0).Return(apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+
+	// Perform action.
+	w := perform("DELETE", "/api/property/TestId", nil, router)
+
+	// Test result.
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestDeleteUnexpected(t *testing.T) {
+	router, service := setup()
+
+	// Mock service error.
+	service.On("Delete", "TestId", 0).Return(errors.New("unexpected"))
+
+	// Perform action.
+	w := perform("DELETE", "/api/property/TestId", nil, router)
+
+	// Test result.
+	assert.Equal(t, 500, w.Code)
+}
+
+func TestReadETag(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Name: "Name test", Value: "Value test", Revision: 3}
+	service.On("FindByID", "TestId").Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	router, service := setup()
+
+	dto := &updateDto{Name: "TestCreateDto", Value: "/uri/go/rest/api"}
+	prop := &model.Property{ID: "testid", Name: "TestCreateDto", Value: "/uri/go/rest/api", Revision: 3}
+
+	service.On("Update", prop).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Property).Revision = 4
+	}).Return(nil)
+
+	body, err := json.Marshal(dto)
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := performWithHeaders("PUT", "/api/property/testid", body, router, map[string]string{"If-Match": `"3"`})
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+}
+
+func TestUpdatePreconditionFailed(t *testing.T) {
+	router, service := setup()
+
+	dto := &updateDto{Name: "TestCreateDto", Value: "/uri/go/rest/api"}
+	prop := &model.Property{ID: "testid", Name: "TestCreateDto", Value: "/uri/go/rest/api", Revision: 2}
+
+	service.On("Update", prop).Return(apperrors.NewPreconditionFailed(model.Property{}, "testid", 2))
+
+	body, err := json.Marshal(dto)
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := performWithHeaders("PUT", "/api/property/testid", body, router, map[string]string{"If-Match": `"2"`})
+
+	// Test result.
+	assert.Equal(t, 412, w.Code)
+}
+
+func TestUpdateInvalidIfMatch(t *testing.T) {
+	router, _ := setup()
+
+	body, err := json.Marshal(&updateDto{Name: "TestCreateDto", Value: "/uri/go/rest/api"})
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := performWithHeaders("PUT", "/api/property/testid", body, router, map[string]string{"If-Match": `W/"2"`})
+
+	// Test result.
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestDeletePreconditionFailed(t *testing.T) {
+	router, service := setup()
+
+	// Mock service action.
+	service.On("Delete", "TestId", 2).Return(apperrors.NewPreconditionFailed(model.Property{}, "TestId", 2))
+
+	// Perform action.
+	w := performWithHeaders("DELETE", "/api/property/TestId", nil, router, map[string]string{"If-Match": `"2"`})
+
+	// Test result.
+	assert.Equal(t, 412, w.Code)
+}
+
+func TestPatchMerge(t *testing.T) {
+	router, service := setup()
+
+	found := &model.Property{ID: "testid", Name: "test.int", Description: "Old", Type: model.TypeInt, Value: "42", Labels: map[string]string{"team": "payments"}, Revision: 3}
+	prop := &model.Property{ID: "testid", Name: "test.int", Description: "New", Type: model.TypeInt, Value: "42", Revision: 3}
+
+	service.On("FindByID", "testid").Return(found, nil)
+	service.On("Update", prop).Return(nil)
+
+	// Perform action.
+	w := performWithHeaders("PATCH", "/api/property/testid", []byte(`{"description":"New","labels":null}`), router, map[string]string{"Content-Type": "application/merge-patch+json"})
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"testid","name":"test.int","description":"New","type":"int","value":42,"revision":3}`, w.Body.String())
+}
+
+func TestPatchJSONPatch(t *testing.T) {
+	router, service := setup()
+
+	found := &model.Property{ID: "testid", Name: "test.name", Value: "old", Revision: 3}
+	prop := &model.Property{ID: "testid", Name: "test.name", Value: "new", Revision: 2}
+
+	service.On("FindByID", "testid").Return(found, nil)
+	service.On("Update", prop).Return(apperrors.NewPreconditionFailed(model.Property{}, "testid", 2))
+
+	// Perform action.
+	w := performWithHeaders("PATCH", "/api/property/testid", []byte(`[{"op":"replace","path":"/value","value":"new"}]`), router, map[string]string{"Content-Type": "application/json-patch+json", "If-Match": `"2"`})
+
+	// Test result.
+	assert.Equal(t, 412, w.Code)
+}
+
+func TestPatchInvalid(t *testing.T) {
+	router, service := setup()
+
+	found := &model.Property{ID: "testid", Name: "test.name", Value: "old", Revision: 3}
+	service.On("FindByID", "testid").Return(found, nil)
+
+	// Perform action.
+	w := performWithHeaders("PATCH", "/api/property/testid", []byte(`[{"op":"remove","path":"/missing"}]`), router, map[string]string{"Content-Type": "application/json-patch+json"})
+	assert.Equal(t, 400, w.Code)
+
+	w = performWithHeaders("PATCH", "/api/property/testid", []byte(`{"value":"new"}`), router, map[string]string{"Content-Type": "application/json"})
+	assert.Equal(t, 415, w.Code)
+
+	service.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestImport(t *testing.T) {
+	router, service := setup()
+
+	props := []*model.Property{
+		{Name: "test.name", Description: "Test description", Value: "test value"},
+		{Name: "test.other", Value: "${test.name}"},
+	}
+	options := property.ImportOptions{Conflict: property.ConflictOverwrite, Set: "common"}
+
+	report := &property.ImportReport{}
+	report.Created("test.name")
+	report.Failed("test.other", apperrors.NewInvalidEntityCustom(model.Property{}, "'value' is not valid."))
+	service.On("Import", props, options).Return(report, nil)
+
+	// Perform action.
+	body, contentType := multipartFile("java.properties", "# Test description\ntest.name=test value\ntest.other=${test.name}\n")
+	w := performWithHeaders("POST", "/api/property/import?conflict=overwrite&set=common", body, router, map[string]string{"Content-Type": contentType})
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"created":1,"updated":0,"skipped":0,"failed":1,"entries":[{"name":"test.name","status":"created"},{"name":"test.other","status":"failed","error":"Invalid model.Property entity. 'value' is not valid."}]}`, w.Body.String())
+}
+
+func TestImportFormat(t *testing.T) {
+	router, service := setup()
+
+	props := []*model.Property{{Name: "DB_HOST", Value: "localhost"}}
+	options := property.ImportOptions{Conflict: property.ConflictFail}
+
+	service.On("Import", props, options).Return(nil, apperrors.NewConflict(reflect.TypeOf(model.Property{}), "name", "DB_HOST"))
+
+	// Perform action.
+	body, contentType := multipartFile("settings.txt", "DB_HOST=localhost\n")
+	w := performWithHeaders("POST", "/api/property/import?format=env", body, router, map[string]string{"Content-Type": contentType})
+
+	// Test result.
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestImportInvalid(t *testing.T) {
+	router, service := setup()
+
+	body, contentType := multipartFile("settings.txt", "DB_HOST=localhost\n")
+	w := performWithHeaders("POST", "/api/property/import", body, router, map[string]string{"Content-Type": contentType})
+	assert.Equal(t, 400, w.Code)
+
+	body, contentType = multipartFile(".env", "DB_HOST=localhost\n")
+	w = performWithHeaders("POST", "/api/property/import?conflict=merge", body, router, map[string]string{"Content-Type": contentType})
+	assert.Equal(t, 400, w.Code)
+
+	body, contentType = multipartFile("settings.json", "[1, 2]")
+	w = performWithHeaders("POST", "/api/property/import", body, router, map[string]string{"Content-Type": contentType})
+	assert.Equal(t, 400, w.Code)
+
+	w = perform("POST", "/api/property/import", nil, router)
+	assert.Equal(t, 400, w.Code)
+
+	w = perform("POST", "/api/property/testid", nil, router)
+	assert.Equal(t, 404, w.Code)
+
+	service.AssertNotCalled(t, "Import", mock.Anything, mock.Anything)
+}
+
+func TestWatchLongPoll(t *testing.T) {
+	router, service := setup()
+
+	hub := watch.NewHub()
+	hub.Publish(context.Background(), watch.PropertyEvent(watch.Created, &model.Property{Name: "a"}))
+
+	properties := []*model.Property{{ID: "Id", Name: "a", Value: "1"}}
+	service.On("Watch", property.Query{Set: "common"}).Return(hub.Subscribe(nil), nil)
+	service.On("ReadAll", property.Query{Set: "common"}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
+
+	w := perform("GET", "/api/property/watch?set=common&index=0", nil, router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Index"))
+	assert.Contains(t, w.Body.String(), `"name":"a"`)
+}
+
+func TestWatchLongPollWait(t *testing.T) {
+	router, service := setup()
+
+	hub := watch.NewHub()
+	hub.Publish(context.Background(), watch.PropertyEvent(watch.Created, &model.Property{Name: "a"}))
+
+	service.On("Watch", property.EmptyQuery).Return(hub.Subscribe(nil), nil)
+	service.On("ReadAll", property.EmptyQuery).Return([]*model.Property{}, model.PageInfo{}, nil)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		hub.Publish(context.Background(), watch.PropertyEvent(watch.Updated, &model.Property{Name: "a"}))
+	}()
+
+	w := perform("GET", "/api/property/watch?index=1&wait=5s", nil, router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Index"))
+}
+
+func TestWatchLongPollTimeout(t *testing.T) {
+	router, service := setup()
+
+	service.On("Watch", property.EmptyQuery).Return(watch.NewHub().Subscribe(nil), nil)
+	service.On("ReadAll", property.EmptyQuery).Return([]*model.Property{}, model.PageInfo{}, nil)
+
+	w := perform("GET", "/api/property/watch?index=0&wait=10ms", nil, router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Index"))
+}
+
+func TestWatchLongPollInvalid(t *testing.T) {
+	router, service := setup()
+
+	for _, params := range []string{"index=x", "index=-1", "index=0&wait=x", "index=0&wait=1h"} {
+		w := perform("GET", "/api/property/watch?"+params, nil, router)
+
+		assert.Equal(t, 400, w.Code, params)
+	}
+
+	service.AssertNotCalled(t, "Watch", mock.Anything)
+}
+
+func TestWatchStream(t *testing.T) {
+	router, service := setup()
+
+	hub := watch.NewHub()
+	service.On("Watch", property.EmptyQuery).Return(hub.Subscribe(nil), nil)
+	hub.Publish(context.Background(), watch.PropertyEvent(watch.Created, &model.Property{ID: "Id", Name: "a", Value: "1"}))
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	response, err := http.Get(srv.URL + "/api/property/watch")
+	assert.Nil(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Equal(t, "0", response.Header.Get("X-Index"))
+
+	reader := bufio.NewReader(response.Body)
+	lines := make([]string, 3)
+	for i := range lines {
+		lines[i], err = reader.ReadString('\n')
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, "id: 1\n", lines[0])
+	assert.Equal(t, "event: created\n", lines[1])
+	assert.Contains(t, lines[2], `"type":"created"`)
+	assert.Contains(t, lines[2], `"name":"a"`)
+}
+
+func TestCreateNamespaced(t *testing.T) {
+	router, service := setup()
+
+	dto := &createDto{Name: "TestCreateDto", Value: "TestValue"}
+	prop := &model.Property{Name: "TestCreateDto", Value: "TestValue"}
+
+	service.On("Create", prop).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(0).(*model.Property)
+		arg.ID = "testid"
+	})
+
+	body, err := json.Marshal(dto)
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := perform("POST", "/api/ns/payments/property", body, router)
+
+	// Test result.
+	assert.Equal(t, "/api/ns/payments/property/testid", w.Header().Get("Location"))
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestReadNamespaced(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.Property{ID: "TestId", Namespace: "payments", Name: "Name test", Value: "Value test"}
+
+	service.On("FindByID", "TestId").Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/ns/payments/property/TestId", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"Name test","value":"Value test"}`, w.Body.String())
+}
+
+func TestReadInvalidNamespace(t *testing.T) {
+	router, service := setup()
+
+	// Perform action.
+	w := perform("GET", "/api/ns/.payments/property/TestId", nil, router)
+
+	// Test result
+	assert.Equal(t, 400, w.Code)
+	service.AssertNotCalled(t, "FindByID", "TestId")
+}
+
+func TestReadAllSelector(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{{ID: "Id", Name: "Name", Value: "Value", Labels: map[string]string{"team": "payments"}}}
+	selector, _ := model.ParseLabelSelector("team=payments,tier!=dev")
+	service.On("ReadAll", property.Query{Selector: selector}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property?selector=team%3Dpayments%2Ctier%21%3Ddev", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"properties":[{"id":"Id","name":"Name","value":"Value","labels":{"team":"payments"}}],"total":1}`, w.Body.String())
+}
+
+func TestReadAllSelectorInvalid(t *testing.T) {
+	router, _ := setup()
+
+	// Perform action.
+	w := perform("GET", "/api/property?selector=team%3D%3D%3D", nil, router)
+
+	// Test result.
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestReadAllPrefix(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{{ID: "Id", Name: "payments.db.url", Value: "Value"}}
+	service.On("ReadAll", property.Query{Prefix: "payments.db"}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property?prefix=payments.db", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"properties":[{"id":"Id","name":"payments.db.url","value":"Value"}],"total":1}`, w.Body.String())
+}
+
+func TestReadAllPrefixInvalid(t *testing.T) {
+	router, _ := setup()
+
+	w := perform("GET", "/api/property?prefix=payments..db", nil, router)
+
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestReadTree(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{
+		{ID: "Id1", Name: "payments.db.pool.size", Type: model.TypeInt, Value: "10"},
+		{ID: "Id2", Name: "payments.db", Value: "primary"},
+		{ID: "Id3", Name: "payments.db.url", Value: "localhost"},
+		{ID: "Id4", Name: "payments.token", Value: "Value", Secret: true},
+	}
+	service.On("ReadAll", property.Query{Prefix: "payments"}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/tree?prefix=payments", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"payments":{"db":{"_value":"primary","pool":{"size":10},"url":"localhost"},"token":"******"}}`, w.Body.String())
+}
+
+func TestReadTreeUnknownSet(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	service.On("ReadAll", property.Query{Set: "missing"}).Return([]*model.Property(nil), model.PageInfo{}, apperrors.NewEntityNotFound(model.PropertySet{}, "missing"))
+
+	// Perform action.
+	w := perform("GET", "/api/property/tree?set=missing", nil, router)
+
+	// Test result.
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestReadAllPage(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{{ID: "Id", Name: "b", Value: "Value"}}
+	page := model.Page{Limit: 1, After: "c", Descending: true}
+	service.On("ReadAll", property.Query{Page: page}).Return(properties, model.PageInfo{Total: 3, Next: "b"}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property?limit=1&sort=-name&page_token="+model.EncodePageToken("c"), nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"properties":[{"id":"Id","name":"b","value":"Value"}],"next_page_token":"`+model.EncodePageToken("b")+`","total":3}`, w.Body.String())
+}
+
+func TestReadAllPageInvalid(t *testing.T) {
+	router, _ := setup()
+
+	for _, query := range []string{"limit=0", "limit=ten", "sort=value", "page_token=%21%21"} {
+		w := perform("GET", "/api/property?"+query, nil, router)
+
+		assert.Equal(t, 400, w.Code, query)
+	}
+}
+
+func TestCreateOverrides(t *testing.T) {
+	router, service := setup()
+
+	prop := &model.Property{Name: "db.pool", Type: model.TypeInt, Value: "5", Overrides: map[string]string{"prod": "50"}}
+	service.On("Create", prop).Return(nil)
+
+	// Perform action.
+	w := perform("POST", "/api/property", []byte(`{"name":"db.pool","type":"int","value":5,"overrides":{"prod":50}}`), router)
+
+	// Test result.
+	assert.Equal(t, 201, w.Code)
+	service.AssertCalled(t, "Create", prop)
+}
+
+func TestReadProfile(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	prop := &model.Property{ID: "TestId", Name: "db.url", Value: "dev-url", Overrides: map[string]string{"prod": "prod-url"}}
+	service.On("FindByID", "TestId").Return(prop, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property/TestId?profile=prod-eu,prod", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"id":"TestId","name":"db.url","value":"prod-url","overrides":{"prod":"prod-url"}}`, w.Body.String())
+}
+
+func TestReadAllProfileProperties(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{
+		{ID: "Id0", Name: "db.url", Value: "dev-url", Overrides: map[string]string{"prod": "prod-url"}},
+		{ID: "Id1", Name: "db.user", Value: "admin"},
+	}
+	service.On("ReadAll", property.Query{Profiles: []string{"prod"}}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
+
+	// Perform action.
+	headers := map[string]string{
+		"Accept": "application/java.properties",
+	}
+	w := performWithHeaders("GET", "/api/property?profile=prod", nil, router, headers)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, ""+
+		"db.url = prod-url\n"+
+		"db.user = admin\n", w.Body.String())
+}
+
+func TestReadAllProfileSecret(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.Property{
+		{ID: "Id0", Name: "db.password", Value: "dev-secret", Secret: true, Overrides: map[string]string{"prod": "prod-secret"}},
+	}
+	service.On("ReadAll", property.Query{Profiles: []string{"prod"}}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/property?profile=prod", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"properties":[{"id":"Id0","name":"db.password","value":"******","secret":true,"overrides":{"prod":"******"}}],"total":1}`, w.Body.String())
+}
+
+func TestReadAllProfileInvalid(t *testing.T) {
+	router, _ := setup()
+
+	// Perform action.
+	w := perform("GET", "/api/property?profile=prod.eu", nil, router)
+
+	// Test result.
+	assert.Equal(t, 400, w.Code)
+}
+
+func setup() (r *gin.Engine, serviceMock *PropertyServiceMock) {
+	router, service, _ := setupScheduler()
 
 	return router, service
 }
 
+func setupScheduler() (r *gin.Engine, serviceMock *PropertyServiceMock, schedulerMock *schedule_service.ScheduleServiceMock) {
+	logger.Main = logger.NewDummyLogger(new(bytes.Buffer))
+
+	router := gin.Default()
+	router.Use(
+		jsonAppErrorHandler(),
+	)
+	api := router.Group("/api")
+
+	service := new(PropertyServiceMock)
+	scheduler := new(schedule_service.ScheduleServiceMock)
+	//setService := new(set_service.PropertySetServiceMock)
+	controller := New(service, scheduler).Controller
+	controller.Register(api)
+
+	return router, service, scheduler
+}
+
 func perform(method string, uri string, body []byte, router *gin.Engine) (rr *httptest.ResponseRecorder) {
 	return performWithHeaders(method, uri, body, router, nil)
 }
@@ -390,6 +1361,17 @@ func performWithHeaders(method string, uri string, body []byte, router *gin.Engi
 	return w
 }
 
+func multipartFile(filename string, content string) ([]byte, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	part, _ := writer.CreateFormFile("file", filename)
+	part.Write([]byte(content))
+	writer.Close()
+
+	return body.Bytes(), writer.FormDataContentType()
+}
+
 type PropertyServiceMock struct {
 	mock.Mock
 }
@@ -400,10 +1382,10 @@ func (m *PropertyServiceMock) Create(ctx context.Context, property *model.Proper
 	return args.Error(0)
 }
 
-func (m *PropertyServiceMock) ReadAll(ctx context.Context, q property.Query) ([]*model.Property, error) {
+func (m *PropertyServiceMock) ReadAll(ctx context.Context, q property.Query) ([]*model.Property, model.PageInfo, error) {
 	args := m.Called(q)
 
-	return args.Get(0).([]*model.Property), args.Error(1)
+	return args.Get(0).([]*model.Property), args.Get(1).(model.PageInfo), args.Error(2)
 }
 
 func (m *PropertyServiceMock) FindByID(ctx context.Context, id string) (*model.Property, error) {
@@ -412,12 +1394,56 @@ func (m *PropertyServiceMock) FindByID(ctx context.Context, id string) (*model.P
 	return args.Get(0).(*model.Property), args.Error(1)
 }
 
-func (m *PropertyServiceMock) Delete(ctx context.Context, id string) error {
+func (m *PropertyServiceMock) FindByIDAt(ctx context.Context, id string, at time.Time) (*model.Property, error) {
+	args := m.Called(id, at)
+
+	return args.Get(0).(*model.Property), args.Error(1)
+}
+
+func (m *PropertyServiceMock) FindByName(ctx context.Context, name string) (*model.Property, error) {
+	args := m.Called(name)
+
+	return args.Get(0).(*model.Property), args.Error(1)
+}
+
+func (m *PropertyServiceMock) History(ctx context.Context, id string) ([]*model.PropertyRevision, error) {
 	args := m.Called(id)
 
+	return args.Get(0).([]*model.PropertyRevision), args.Error(1)
+}
+
+func (m *PropertyServiceMock) Rollback(ctx context.Context, id string, revision int) (*model.Property, error) {
+	args := m.Called(id, revision)
+
+	return args.Get(0).(*model.Property), args.Error(1)
+}
+
+func (m *PropertyServiceMock) Delete(ctx context.Context, id string, revision int) error {
+	args := m.Called(id, revision)
+
 	return args.Error(0)
 }
 
+func (m *PropertyServiceMock) Import(ctx context.Context, properties []*model.Property, options property.ImportOptions) (*property.ImportReport, error) {
+	args := m.Called(properties, options)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*property.ImportReport), args.Error(1)
+}
+
+func (m *PropertyServiceMock) Watch(ctx context.Context, q property.Query) (*watch.Subscription, error) {
+	args := m.Called(q)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*watch.Subscription), args.Error(1)
+}
+
 func (m *PropertyServiceMock) Update(ctx context.Context, property *model.Property) error {
 	args := m.Called(property)
 
@@ -0,0 +1,537 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/magiconair/properties"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"gopkg.in/yaml.v2"
+)
+
+// importID is the reserved id under which the import is available, as the
+// router cannot register static routes next to the ':id' parameter.
+const importID = "import"
+
+// importFileField is the name of the multipart form field holding the file.
+const importFileField = "file"
+
+type importEntryDto struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type importResponseDto struct {
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	Failed  int              `json:"failed"`
+	Entries []importEntryDto `json:"entries"`
+}
+
+// Import adds the properties of an uploaded file, sent as the 'file' field of a
+// multipart form. The format of the file is given by the 'format' parameter or,
+// if missing, by the file extension. The 'conflict' parameter defines how the
+// properties that already exist are handled ('fail' by default) and the 'set'
+// parameter names a property set that must contain all imported names.
+func (ctrl *Controller) Import(ctx *gin.Context) {
+	if ctx.Param("id") != importID {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	options, err := parseImportOptions(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	file, err := ctx.FormFile(importFileField)
+	if err != nil {
+		ctx.Error(errors.NewInvalidParameter(importFileField, ""))
+		return
+	}
+
+	format := ctx.Query("format")
+	if format == "" {
+		format = fileFormat(file.Filename)
+	}
+
+	parser := newParsers().find(format)
+	if parser == nil {
+		ctx.Error(errors.NewInvalidParameter("format", format))
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	props, err := parser.parse(data)
+	if err != nil {
+		ctx.Error(errors.NewInvalidParameter(importFileField, err.Error()))
+		return
+	}
+
+	report, err := ctrl.service.Import(ctx.Request.Context(), props, options)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toImportResponse(report))
+}
+
+func parseImportOptions(ctx *gin.Context) (property.ImportOptions, error) {
+	options := property.ImportOptions{
+		Conflict: property.ConflictStrategy(ctx.DefaultQuery("conflict", string(property.ConflictFail))),
+		Set:      ctx.Query("set"),
+	}
+
+	if !property.IsValidConflictStrategy(options.Conflict) {
+		return options, errors.NewInvalidParameter("conflict", string(options.Conflict))
+	}
+
+	return options, nil
+}
+
+// fileFormat retrieves the format of the file with the given name, based on its
+// extension (e.g. 'yaml' for 'app.yaml' and 'env' for '.env').
+func fileFormat(filename string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+}
+
+func toImportResponse(report *property.ImportReport) *importResponseDto {
+	entries := make([]importEntryDto, len(report.Entries))
+	for i, entry := range report.Entries {
+		entries[i] = importEntryDto{Name: entry.Name, Status: string(entry.Status)}
+		if entry.Error != nil {
+			entries[i].Error = errorMessage(entry.Error)
+		}
+	}
+
+	return &importResponseDto{
+		Created: report.Count(property.ImportCreated),
+		Updated: report.Count(property.ImportUpdated),
+		Skipped: report.Count(property.ImportSkipped),
+		Failed:  report.Count(property.ImportFailed),
+		Entries: entries,
+	}
+}
+
+func errorMessage(err error) string {
+	if castError, ok := err.(*errors.Error); ok {
+		return castError.Message
+	}
+
+	return err.Error()
+}
+
+type parsers struct {
+	values []parser
+}
+
+func newParsers() parsers {
+	return parsers{
+		values: []parser{
+			&javaPropertiesParser{},
+			&yamlParser{},
+			&jsonParser{},
+			&dotenvParser{},
+		},
+	}
+}
+
+func (p parsers) find(format string) parser {
+	for _, parser := range p.values {
+		if parser.supports(strings.ToLower(format)) {
+			return parser
+		}
+	}
+
+	return nil
+}
+
+// parser reads the properties of a file. Parsers of formats without types
+// retrieve untyped properties, so that overwriting keeps the existing types.
+type parser interface {
+	supports(format string) bool
+	parse(data []byte) ([]*model.Property, error)
+}
+
+type javaPropertiesParser struct {
+}
+
+func (p javaPropertiesParser) supports(format string) bool {
+	return format == "properties" || format == "java.properties"
+}
+
+// parse reads a java.properties file. The comments preceding a key are used as
+// the description, mirroring the javaPropertiesFormatter.
+func (p javaPropertiesParser) parse(data []byte) ([]*model.Property, error) {
+	loader := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+	props, err := loader.LoadBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Property, 0, props.Len())
+	for _, key := range props.Keys() {
+		value, _ := props.Get(key)
+		result = append(result, &model.Property{
+			Name:        key,
+			Description: strings.Join(props.GetComments(key), "\n"),
+			Value:       value,
+		})
+	}
+
+	return result, nil
+}
+
+type yamlParser struct {
+}
+
+func (p yamlParser) supports(format string) bool {
+	return format == "yaml" || format == "yml"
+}
+
+// parse reads a YAML document, whose nested keys are joined into dotted names.
+func (p yamlParser) parse(data []byte) ([]*model.Property, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return flatten(document)
+}
+
+type jsonParser struct {
+}
+
+func (p jsonParser) supports(format string) bool {
+	return format == "json"
+}
+
+// parse reads a JSON document. Both the list retrieved by the jsonFormatter and
+// nested objects (e.g. the tree view), whose keys are joined into dotted names,
+// are accepted. Lists holding masked secrets are rejected, as their values are
+// unknown.
+func (p jsonParser) parse(data []byte) ([]*model.Property, error) {
+	list := new(readAllResponseDto)
+	if err := decodeJSON(data, list); err == nil && list.PropertyDto != nil {
+		return fromProperties(list.PropertyDto)
+	}
+
+	var document map[string]interface{}
+	if err := decodeJSON(data, &document); err != nil {
+		return nil, err
+	}
+
+	return flatten(document)
+}
+
+// decodeJSON decodes the given data keeping numbers as json.Number, so that
+// large integers are not altered.
+func decodeJSON(data []byte, target interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(target)
+}
+
+// fromProperties converts an exported list of properties. A name may only be
+// listed once and masked secret values (or overrides) cannot be imported.
+func fromProperties(dtos []PropertyDto) ([]*model.Property, error) {
+	result := make([]*model.Property, len(dtos))
+	names := make(map[string]bool, len(dtos))
+	for i, dto := range dtos {
+		if names[dto.Name] {
+			return nil, fmt.Errorf("the property '%s' is listed more than once", dto.Name)
+		}
+		names[dto.Name] = true
+
+		if dto.Secret && isMasked(dto) {
+			return nil, fmt.Errorf("the value of the secret property '%s' is masked", dto.Name)
+		}
+
+		typ := model.PropertyType(dto.Type)
+		value, err := model.FormatValue(typ, dto.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides, err := toRawOverrides(typ, dto.Overrides)
+		if err != nil {
+			return nil, err
+		}
+
+		constraint, err := ToConstraint(typ, dto.Constraint)
+		if err != nil {
+			return nil, err
+		}
+
+		targeting, err := ToTargeting(typ, dto.Targeting)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = &model.Property{
+			Name:        dto.Name,
+			Description: dto.Description,
+			Type:        typ,
+			Value:       value,
+			Secret:      dto.Secret,
+			Labels:      dto.Labels,
+			Overrides:   overrides,
+			Constraint:  constraint,
+			Targeting:   targeting,
+			ExpiresAt:   toExpiresAt(dto.ExpiresAt),
+		}
+	}
+
+	return result, nil
+}
+
+func isMasked(dto PropertyDto) bool {
+	if dto.Value == model.MaskedValue {
+		return true
+	}
+
+	for _, value := range dto.Overrides {
+		if value == model.MaskedValue {
+			return true
+		}
+	}
+
+	return false
+}
+
+type dotenvParser struct {
+}
+
+func (p dotenvParser) supports(format string) bool {
+	return format == "env" || format == "dotenv"
+}
+
+// parse reads a .env file: one 'KEY=value' assignment per line, optionally
+// preceded by 'export'. Values may be single quoted (taken literally) or double
+// quoted (supporting the usual escapes); unquoted values end at a ' #' comment.
+// A key may only be assigned once.
+func (p dotenvParser) parse(data []byte) ([]*model.Property, error) {
+	result := make([]*model.Property, 0)
+	names := make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+		separator := strings.Index(text, "=")
+		if separator <= 0 {
+			return nil, fmt.Errorf("line %d is not an assignment", line)
+		}
+
+		name := strings.TrimSpace(text[:separator])
+		value, err := dotenvValue(strings.TrimSpace(text[separator+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+
+		if names[name] {
+			return nil, fmt.Errorf("line %d: the property '%s' is defined more than once", line, name)
+		}
+		names[name] = true
+
+		result = append(result, &model.Property{Name: name, Value: value})
+	}
+
+	return result, scanner.Err()
+}
+
+func dotenvValue(raw string) (string, error) {
+	if strings.HasPrefix(raw, "'") {
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+
+		return raw[1 : end+1], nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		for end := 1; end < len(raw); end++ {
+			if raw[end] == '\\' {
+				end++
+				continue
+			}
+
+			if raw[end] == '"' {
+				return strconv.Unquote(raw[:end+1])
+			}
+		}
+
+		return "", fmt.Errorf("unterminated quoted value")
+	}
+
+	if comment := strings.Index(raw, " #"); comment >= 0 {
+		raw = raw[:comment]
+	}
+
+	return strings.TrimSpace(raw), nil
+}
+
+// flatten retrieves the properties of a nested document, sorted by name. Nested
+// keys are joined into dotted names and the treeValueKey holds the value of a
+// name that is also a parent, mirroring the tree view. Strings are untyped,
+// numbers and booleans are typed accordingly and lists are kept as JSON.
+func flatten(document map[string]interface{}) ([]*model.Property, error) {
+	values := make(map[string]interface{})
+	if err := flattenInto(values, "", document); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*model.Property, len(names))
+	for i, name := range names {
+		typ, value, err := toRawValue(values[name])
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = &model.Property{Name: name, Type: typ, Value: value}
+	}
+
+	return result, nil
+}
+
+func flattenInto(values map[string]interface{}, prefix string, document interface{}) error {
+	children, isParent := toStringMap(document)
+	if !isParent {
+		if prefix == "" {
+			return fmt.Errorf("the document is not an object")
+		}
+
+		if _, found := values[prefix]; found {
+			return fmt.Errorf("the property '%s' is defined more than once", prefix)
+		}
+
+		values[prefix] = document
+		return nil
+	}
+
+	for key, child := range children {
+		name := key
+		if key == treeValueKey {
+			name = prefix
+		} else if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if err := flattenInto(values, name, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toStringMap retrieves the given value as a map with string keys, if it is a
+// JSON object or a YAML mapping.
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[fmt.Sprint(key)] = child
+		}
+
+		return result, true
+	}
+
+	return nil, false
+}
+
+func toRawValue(value interface{}) (model.PropertyType, string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", "", nil
+	case string:
+		return "", v, nil
+	case bool:
+		return model.TypeBool, strconv.FormatBool(v), nil
+	case int:
+		return model.TypeInt, strconv.Itoa(v), nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return model.TypeInt, v.String(), nil
+		}
+
+		return model.TypeFloat, v.String(), nil
+	case float64:
+		return model.TypeFloat, strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		raw, err := json.Marshal(toJSONCompatible(v))
+		if err != nil {
+			return "", "", err
+		}
+
+		return model.TypeJSON, string(raw), nil
+	}
+
+	return "", fmt.Sprint(value), nil
+}
+
+// toJSONCompatible converts the YAML mappings within the given value to JSON
+// objects, so that the value can be encoded as JSON.
+func toJSONCompatible(value interface{}) interface{} {
+	if children, isMap := toStringMap(value); isMap {
+		result := make(map[string]interface{}, len(children))
+		for key, child := range children {
+			result[key] = toJSONCompatible(child)
+		}
+
+		return result
+	}
+
+	if items, isList := value.([]interface{}); isList {
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = toJSONCompatible(item)
+		}
+
+		return result
+	}
+
+	return value
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJavaProperties(t *testing.T) {
+	actual, err := newParsers().find("properties").parse([]byte("# Test description\ntest.name=test value\ntest.url=${test.host}/api\n"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Property{
+		{Name: "test.name", Description: "Test description", Value: "test value"},
+		{Name: "test.url", Value: "${test.host}/api"},
+	}, actual)
+}
+
+func TestParseYAML(t *testing.T) {
+	data := `
+payments:
+  db:
+    _value: primary
+    url: postgres://db
+    port: 5432
+  enabled: true
+  ratio: 0.5
+  hosts: [a, b]
+`
+	actual, err := newParsers().find("yml").parse([]byte(data))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Property{
+		{Name: "payments.db", Value: "primary"},
+		{Name: "payments.db.port", Type: model.TypeInt, Value: "5432"},
+		{Name: "payments.db.url", Value: "postgres://db"},
+		{Name: "payments.enabled", Type: model.TypeBool, Value: "true"},
+		{Name: "payments.hosts", Type: model.TypeJSON, Value: `["a","b"]`},
+		{Name: "payments.ratio", Type: model.TypeFloat, Value: "0.5"},
+	}, actual)
+}
+
+func TestParseJSON(t *testing.T) {
+	actual, err := newParsers().find("json").parse([]byte(`{"payments":{"db":{"port":5432,"url":"postgres://db"}}}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Property{
+		{Name: "payments.db.port", Type: model.TypeInt, Value: "5432"},
+		{Name: "payments.db.url", Value: "postgres://db"},
+	}, actual)
+}
+
+func TestParseJSONList(t *testing.T) {
+	data := `{"properties":[{"id":"1","name":"test.int","type":"int","value":9007199254740993,"labels":{"team":"payments"}},{"name":"test.list","type":"list","value":["a","b"]}],"total":2}`
+	actual, err := newParsers().find("json").parse([]byte(data))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Property{
+		{Name: "test.int", Type: model.TypeInt, Value: "9007199254740993", Labels: map[string]string{"team": "payments"}},
+		{Name: "test.list", Type: model.TypeList, Value: "a,b"},
+	}, actual)
+
+	_, err = newParsers().find("json").parse([]byte(`{"properties":[{"name":"test.secret","value":"******","secret":true}]}`))
+	assert.NotNil(t, err)
+
+	_, err = newParsers().find("json").parse([]byte(`{"properties":[{"name":"test.secret","value":"x","secret":true,"overrides":{"prod":"******"}}]}`))
+	assert.NotNil(t, err)
+
+	_, err = newParsers().find("json").parse([]byte(`{"properties":[{"name":"test.name","value":"a"},{"name":"test.name","value":"b"}]}`))
+	assert.NotNil(t, err)
+}
+
+func TestParseJSONListDetails(t *testing.T) {
+	data := `{"properties":[{"name":"test.port","type":"int","value":8080,"overrides":{"prod":443},"constraint":{"min":1,"max":65535},"targeting":{"rules":[{"conditions":[{"attribute":"region","operator":"in","values":["eu"]}],"variant":8443}]}}]}`
+	actual, err := newParsers().find("json").parse([]byte(data))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(actual))
+	assert.Equal(t, map[string]string{"prod": "443"}, actual[0].Overrides)
+	assert.Equal(t, 65535.0, *actual[0].Constraint.Max)
+	assert.Equal(t, "8443", actual[0].Targeting.Rules[0].Variant)
+}
+
+func TestParseJSONRepeated(t *testing.T) {
+	_, err := newParsers().find("json").parse([]byte(`{"payments.db":{"url":"a"},"payments":{"db":{"url":"b"}}}`))
+
+	assert.NotNil(t, err)
+}
+
+func TestParseDotenv(t *testing.T) {
+	data := `
+# Database
+export DB_HOST=localhost # local only
+DB_PASSWORD='p@ss #1'
+DB_OPTIONS="ssl=true\nretries=3"
+`
+	actual, err := newParsers().find("env").parse([]byte(data))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*model.Property{
+		{Name: "DB_HOST", Value: "localhost"},
+		{Name: "DB_PASSWORD", Value: "p@ss #1"},
+		{Name: "DB_OPTIONS", Value: "ssl=true\nretries=3"},
+	}, actual)
+
+	invalid := []string{"DB_HOST", "=localhost", `DB_HOST="localhost`, "DB_HOST='localhost", "DB_HOST=a\nexport DB_HOST=b"}
+	for _, data := range invalid {
+		_, err := newParsers().find("env").parse([]byte(data))
+
+		assert.NotNil(t, err, data)
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	assert.Equal(t, "env", fileFormat(".env"))
+	assert.Equal(t, "yaml", fileFormat("config/App.YAML"))
+	assert.Equal(t, "properties", fileFormat("java.properties"))
+	assert.Equal(t, "", fileFormat("settings"))
+}
@@ -1,16 +1,109 @@
 package http
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
 )
 
-func parse(ctx *gin.Context) property.Query {
+func parse(ctx *gin.Context) (property.Query, error) {
 	q := property.Query{}
 
 	q.ID = ctx.Param("id")
 	q.Set = ctx.Query("set")
 	q.Fields = property.NewFields(ctx.QueryArray("fields"))
 
-	return q
+	if prefix := ctx.Query("prefix"); prefix != "" {
+		if !isValidPrefix(prefix) {
+			return q, errors.NewInvalidParameter("prefix", prefix)
+		}
+
+		q.Prefix = prefix
+	}
+
+	if selector := ctx.Query("selector"); selector != "" {
+		s, err := model.ParseLabelSelector(selector)
+		if err != nil {
+			return q, errors.NewInvalidParameter("selector", selector)
+		}
+
+		q.Selector = s
+	}
+
+	if profile := ctx.Query("profile"); profile != "" {
+		for _, p := range strings.Split(profile, ",") {
+			p = strings.TrimSpace(p)
+			if !model.IsValidProfile(p) {
+				return q, errors.NewInvalidParameter("profile", profile)
+			}
+
+			q.Profiles = append(q.Profiles, p)
+		}
+	}
+
+	if release := ctx.Query("release"); release != "" {
+		number, err := strconv.Atoi(release)
+		if err != nil || number <= 0 || !q.HasSet() {
+			return q, errors.NewInvalidParameter("release", release)
+		}
+
+		q.Release = number
+	}
+
+	if asOf := ctx.Query("asOf"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			return q, errors.NewInvalidParameter("asOf", asOf)
+		}
+
+		q.AsOf = at
+	}
+
+	page, err := server.ParsePage(ctx)
+	if err != nil {
+		return q, err
+	}
+	q.Page = page
+
+	if reveal := ctx.Query("reveal"); reveal != "" {
+		r, err := strconv.ParseBool(reveal)
+		if err != nil {
+			return q, errors.NewInvalidParameter("reveal", reveal)
+		}
+
+		q.Reveal = r
+	}
+
+	if raw := ctx.Query("raw"); raw != "" {
+		r, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, errors.NewInvalidParameter("raw", raw)
+		}
+
+		q.Raw = r
+	}
+
+	return q, nil
+}
+
+// isValidPrefix checks whether the given prefix is a dotted name without empty
+// segments or spaces.
+func isValidPrefix(prefix string) bool {
+	if strings.ContainsAny(prefix, " ") {
+		return false
+	}
+
+	for _, segment := range strings.Split(prefix, ".") {
+		if segment == "" {
+			return false
+		}
+	}
+
+	return true
 }
@@ -22,7 +22,7 @@ func newFormatters() formatters {
 	}
 }
 
-func (f formatters) process(ctx *gin.Context, code int, bs []*model.Property) {
+func (f formatters) process(ctx *gin.Context, code int, bs []*model.Property, info model.PageInfo) {
 	acceptHeader := ctx.Request.Header.Get("Accept")
 	found := false
 	for _, f := range f.values {
@@ -30,19 +30,19 @@ func (f formatters) process(ctx *gin.Context, code int, bs []*model.Property) {
 			continue
 		}
 
-		f.process(ctx, code, bs)
+		f.process(ctx, code, bs, info)
 		found = true
 		break
 	}
 
 	if !found {
-		f.values[0].process(ctx, code, bs)
+		f.values[0].process(ctx, code, bs, info)
 	}
 }
 
 type formatter interface {
 	supports(acceptHeader string) bool
-	process(ctx *gin.Context, code int, bs []*model.Property)
+	process(ctx *gin.Context, code int, bs []*model.Property, info model.PageInfo)
 }
 
 type jsonFormatter struct {
@@ -54,9 +54,11 @@ func (f jsonFormatter) supports(acceptHeader string) bool {
 		strings.EqualFold(acceptHeader, "application/json")
 }
 
-func (f jsonFormatter) process(ctx *gin.Context, code int, bs []*model.Property) {
+func (f jsonFormatter) process(ctx *gin.Context, code int, bs []*model.Property, info model.PageInfo) {
 	ctx.JSON(code, &readAllResponseDto{
-		PropertyDto: toProperties(bs),
+		PropertyDto:   toProperties(bs),
+		NextPageToken: model.EncodePageToken(info.Next),
+		Total:         info.Total,
 	})
 }
 
@@ -67,8 +69,10 @@ func (f javaPropertiesFormatter) supports(acceptHeader string) bool {
 	return strings.EqualFold(acceptHeader, "application/java.properties")
 }
 
-func (f javaPropertiesFormatter) process(ctx *gin.Context, code int, bs []*model.Property) {
-
+// process writes the given properties as a java.properties file. The file has
+// no room for the page information, so only the properties of the page are
+// written.
+func (f javaPropertiesFormatter) process(ctx *gin.Context, code int, bs []*model.Property, info model.PageInfo) {
 	buf := new(bytes.Buffer)
 	props := properties.NewProperties()
 	for _, prop := range bs {
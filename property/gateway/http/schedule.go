@@ -0,0 +1,114 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// ScheduledChangeDto defines how a scheduled change must be exposed. The value
+// is exposed natively typed, according to the property type, and is masked for
+// secret properties.
+type ScheduledChangeDto struct {
+	ID         string      `json:"id"`
+	PropertyID string      `json:"property_id"`
+	Value      interface{} `json:"value"`
+	ActivateAt time.Time   `json:"activate_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type scheduleDto struct {
+	Value      interface{} `json:"value"`
+	ActivateAt time.Time   `json:"activate_at"`
+}
+
+type scheduleResponseDto struct {
+	Changes []*ScheduledChangeDto `json:"changes"`
+}
+
+// Schedule stages a new value for a single property, which becomes the value of
+// the property at the given activation time.
+func (ctrl *Controller) Schedule(ctx *gin.Context) {
+	dto := new(scheduleDto)
+	if err := ctx.BindJSON(dto); err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	prop, err := ctrl.service.FindByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	value, err := model.FormatValue(prop.Type, dto.Value)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	change := &model.ScheduledChange{
+		PropertyID: prop.ID,
+		Value:      value,
+		ActivateAt: dto.ActivateAt.UTC(),
+	}
+
+	if err := ctrl.scheduler.Create(ctx.Request.Context(), change); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Writer.Header().Set("Location", ctx.Request.URL.Path+"/"+change.ID)
+	ctx.JSON(http.StatusCreated, toScheduledChange(prop.Type, change))
+}
+
+// ReadSchedule retrieves the pending changes of a single property, in the order
+// in which they are applied.
+func (ctrl *Controller) ReadSchedule(ctx *gin.Context) {
+	prop, err := ctrl.service.FindByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	changes, err := ctrl.scheduler.ReadAll(ctx.Request.Context(), prop.ID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	dtos := make([]*ScheduledChangeDto, len(changes))
+	for i, change := range changes {
+		dtos[i] = toScheduledChange(prop.Type, change)
+	}
+
+	ctx.JSON(http.StatusOK, &scheduleResponseDto{Changes: dtos})
+}
+
+// CancelSchedule cancels a single pending change of a single property.
+func (ctrl *Controller) CancelSchedule(ctx *gin.Context) {
+	if err := ctrl.scheduler.Cancel(ctx.Request.Context(), ctx.Param("id"), ctx.Param("change")); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func toScheduledChange(typ model.PropertyType, change *model.ScheduledChange) *ScheduledChangeDto {
+	dto := &ScheduledChangeDto{
+		ID:         change.ID,
+		PropertyID: change.PropertyID,
+		Value:      toTypedValue(typ, change.Value),
+		ActivateAt: change.ActivateAt,
+		CreatedAt:  change.CreatedAt,
+	}
+
+	if change.Secret {
+		dto.Value = model.MaskedValue
+	}
+
+	return dto
+}
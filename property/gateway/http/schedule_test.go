@@ -0,0 +1,69 @@
+package http
+
+import (
+	"testing"
+	"time"
+
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSchedule(t *testing.T) {
+	router, service, scheduler := setupScheduler()
+
+	prop := &model.Property{ID: "TestId", Name: "test.hosts", Type: model.TypeList, Value: "a"}
+	service.On("FindByID", "TestId").Return(prop, nil)
+
+	change := &model.ScheduledChange{PropertyID: "TestId", Value: "a,b", ActivateAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	scheduler.On("Create", change).Return(nil).Run(func(args mock.Arguments) {
+		arg := args.Get(0).(*model.ScheduledChange)
+		arg.ID = "change-1"
+		arg.CreatedAt = time.Date(2029, 12, 31, 12, 0, 0, 0, time.UTC)
+	})
+
+	w := perform("POST", "/api/property/TestId/schedule", []byte(`{"value": ["a", "b"], "activate_at": "2030-01-01T02:00:00+02:00"}`), router)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "/api/property/TestId/schedule/change-1", w.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":"change-1","property_id":"TestId","value":["a","b"],"activate_at":"2030-01-01T00:00:00Z","created_at":"2029-12-31T12:00:00Z"}`, w.Body.String())
+}
+
+func TestScheduleNotFound(t *testing.T) {
+	router, service, scheduler := setupScheduler()
+
+	service.On("FindByID", "TestId").Return(&model.Property{}, apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+
+	w := perform("POST", "/api/property/TestId/schedule", []byte(`{"value": "b", "activate_at": "2030-01-01T00:00:00Z"}`), router)
+
+	assert.Equal(t, 404, w.Code)
+	scheduler.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestReadScheduleSecret(t *testing.T) {
+	router, service, scheduler := setupScheduler()
+
+	service.On("FindByID", "TestId").Return(&model.Property{ID: "TestId", Name: "test.password", Value: "a", Secret: true}, nil)
+	scheduler.On("ReadAll", "TestId").Return([]*model.ScheduledChange{
+		{ID: "change-1", PropertyID: "TestId", Value: "b", Secret: true, ActivateAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	w := perform("GET", "/api/property/TestId/schedule", nil, router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"changes":[{"id":"change-1","property_id":"TestId","value":"******","activate_at":"2030-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z"}]}`, w.Body.String())
+}
+
+func TestCancelSchedule(t *testing.T) {
+	router, _, scheduler := setupScheduler()
+
+	scheduler.On("Cancel", "TestId", "change-1").Return(nil)
+	scheduler.On("Cancel", "TestId", "missing").Return(apperrors.NewEntityNotFound(model.ScheduledChange{}, "missing"))
+
+	w := perform("DELETE", "/api/property/TestId/schedule/change-1", nil, router)
+	assert.Equal(t, 204, w.Code)
+
+	w = perform("DELETE", "/api/property/TestId/schedule/missing", nil, router)
+	assert.Equal(t, 404, w.Code)
+}
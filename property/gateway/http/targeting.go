@@ -0,0 +1,98 @@
+package http
+
+import (
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// TargetingDto defines how the targeting rules of a property must be exposed.
+// The variants are natively typed, according to the property type.
+type TargetingDto struct {
+	Key   string             `json:"key,omitempty"`
+	Rules []TargetingRuleDto `json:"rules"`
+}
+
+// TargetingRuleDto defines how a single targeting rule must be exposed.
+type TargetingRuleDto struct {
+	Conditions []TargetingConditionDto `json:"conditions,omitempty"`
+	Variant    interface{}             `json:"variant,omitempty"`
+	Rollout    []RolloutShareDto       `json:"rollout,omitempty"`
+}
+
+// TargetingConditionDto defines how a targeting condition must be exposed.
+type TargetingConditionDto struct {
+	Attribute string   `json:"attribute"`
+	Operator  string   `json:"operator"`
+	Values    []string `json:"values"`
+}
+
+// RolloutShareDto defines how a share of a percentage rollout must be exposed.
+type RolloutShareDto struct {
+	Variant interface{} `json:"variant"`
+	Weight  int         `json:"weight"`
+}
+
+// ToTargeting converts the given targeting rules, if any, to their model form.
+func ToTargeting(typ model.PropertyType, dto *TargetingDto) (*model.Targeting, error) {
+	if dto == nil {
+		return nil, nil
+	}
+
+	targeting := &model.Targeting{Key: dto.Key, Rules: make([]model.TargetingRule, len(dto.Rules))}
+	for i, ruleDto := range dto.Rules {
+		rule := &targeting.Rules[i]
+
+		variant, err := model.FormatValue(typ, ruleDto.Variant)
+		if err != nil {
+			return nil, err
+		}
+		rule.Variant = variant
+
+		for _, condition := range ruleDto.Conditions {
+			rule.Conditions = append(rule.Conditions, model.TargetingCondition{
+				Attribute: condition.Attribute,
+				Operator:  model.ConditionOperator(condition.Operator),
+				Values:    condition.Values,
+			})
+		}
+
+		for _, share := range ruleDto.Rollout {
+			variant, err := model.FormatValue(typ, share.Variant)
+			if err != nil {
+				return nil, err
+			}
+
+			rule.Rollout = append(rule.Rollout, model.RolloutShare{Variant: variant, Weight: share.Weight})
+		}
+	}
+
+	return targeting, nil
+}
+
+// fromTargeting reverses ToTargeting.
+func fromTargeting(typ model.PropertyType, targeting *model.Targeting) *TargetingDto {
+	if targeting == nil {
+		return nil
+	}
+
+	dto := &TargetingDto{Key: targeting.Key, Rules: make([]TargetingRuleDto, len(targeting.Rules))}
+	for i, rule := range targeting.Rules {
+		ruleDto := &dto.Rules[i]
+		if rule.Variant != "" {
+			ruleDto.Variant = toTypedValue(typ, rule.Variant)
+		}
+
+		for _, condition := range rule.Conditions {
+			ruleDto.Conditions = append(ruleDto.Conditions, TargetingConditionDto{
+				Attribute: condition.Attribute,
+				Operator:  string(condition.Operator),
+				Values:    condition.Values,
+			})
+		}
+
+		for _, share := range rule.Rollout {
+			ruleDto.Rollout = append(ruleDto.Rollout, RolloutShareDto{Variant: toTypedValue(typ, share.Variant), Weight: share.Weight})
+		}
+	}
+
+	return dto
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// treeID is the reserved id under which the property tree is served.
+const treeID = "tree"
+
+// treeValueKey holds the value of a property whose name is also the parent of
+// other properties, e.g. the value of 'a.b' when 'a.b.c' also exists.
+const treeValueKey = "_value"
+
+// toTree builds nested objects from the dotted names of the given properties,
+// e.g. 'payments.db.pool.size' becomes {"payments":{"db":{"pool":{"size":...}}}}.
+func toTree(bs []*model.Property) map[string]interface{} {
+	sorted := make([]*model.Property, len(bs))
+	copy(sorted, bs)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	root := make(map[string]interface{})
+	for _, b := range sorted {
+		segments := strings.Split(b.Name, ".")
+
+		node := root
+		for _, segment := range segments[:len(segments)-1] {
+			node = child(node, segment)
+		}
+
+		value := toTypedValue(b.Type, b.Value)
+		last := segments[len(segments)-1]
+		if existing, ok := node[last].(map[string]interface{}); ok {
+			existing[treeValueKey] = value
+			continue
+		}
+
+		node[last] = value
+	}
+
+	return root
+}
+
+// child retrieves the nested object stored under the given key, creating it if
+// needed. A value already stored under the key is moved into the new object.
+func child(node map[string]interface{}, key string) map[string]interface{} {
+	existing, found := node[key]
+	if nested, ok := existing.(map[string]interface{}); ok {
+		return nested
+	}
+
+	nested := make(map[string]interface{})
+	if found {
+		nested[treeValueKey] = existing
+	}
+	node[key] = nested
+
+	return nested
+}
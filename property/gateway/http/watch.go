@@ -0,0 +1,214 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+)
+
+// watchID is the reserved id under which the changes can be watched, as the
+// router cannot register static routes next to the ':id' parameter.
+const watchID = "watch"
+
+// indexHeader holds the index of the store that a watch response reflects.
+const indexHeader = "X-Index"
+
+// heartbeatInterval is the time after which an idle event stream is sent a
+// comment, so that proxies do not close it.
+const heartbeatInterval = 15 * time.Second
+
+// defaultWait and maxWait bound the time a long poll waits for a change.
+const (
+	defaultWait = 30 * time.Second
+	maxWait     = 5 * time.Minute
+)
+
+type eventDto struct {
+	Index    uint64       `json:"index"`
+	Type     string       `json:"type"`
+	Property *PropertyDto `json:"property,omitempty"`
+	Set      *eventSetDto `json:"set,omitempty"`
+}
+
+type eventSetDto struct {
+	Name     string   `json:"name"`
+	Values   []string `json:"values"`
+	Includes []string `json:"includes,omitempty"`
+	Version  int      `json:"version,omitempty"`
+}
+
+// Watch notifies the changes of the properties, optionally restricted to the
+// properties of a set by the 'set' parameter.
+//
+// Without the 'index' parameter, the changes are streamed as Server-Sent Events
+// for as long as the client stays connected. Otherwise the request is a long
+// poll: it waits until the index of the store exceeds the given one, for at most
+// the duration of the 'wait' parameter, then retrieves the same response as
+// ReadAll. In both cases the index is given by the X-Index header.
+func (ctrl *Controller) Watch(ctx *gin.Context) {
+	query, err := parse(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	query.ID = ""
+
+	index, wait, isLongPoll, err := parseLongPoll(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	subscription, err := ctrl.service.Watch(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	defer subscription.Close()
+
+	server.DisableWriteTimeout(ctx)
+	if isLongPoll {
+		ctrl.longPoll(ctx, subscription, query, index, wait)
+		return
+	}
+
+	stream(ctx, subscription, query)
+}
+
+func parseLongPoll(ctx *gin.Context) (uint64, time.Duration, bool, error) {
+	rawIndex := ctx.Query("index")
+	if rawIndex == "" {
+		return 0, 0, false, nil
+	}
+
+	index, err := strconv.ParseUint(rawIndex, 10, 64)
+	if err != nil {
+		return 0, 0, false, errors.NewInvalidParameter("index", rawIndex)
+	}
+
+	wait := defaultWait
+	if rawWait := ctx.Query("wait"); rawWait != "" {
+		wait, err = time.ParseDuration(rawWait)
+		if err != nil || wait <= 0 || wait > maxWait {
+			return 0, 0, false, errors.NewInvalidParameter("wait", rawWait)
+		}
+	}
+
+	return index, wait, true, nil
+}
+
+// longPoll waits for a change only if the given index is the current one. An
+// index ahead of the current one was issued before the application restarted,
+// so it is answered right away, just like an index that is behind.
+func (ctrl *Controller) longPoll(ctx *gin.Context, subscription *watch.Subscription, query property.Query, index uint64, wait time.Duration) {
+	current := subscription.Index()
+	if current == index {
+		event, done := next(ctx, subscription, wait)
+		if done {
+			return
+		}
+
+		if event != nil {
+			current = event.Index
+		}
+	}
+
+	properties, info, err := ctrl.readAll(ctx, query)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header(indexHeader, strconv.FormatUint(current, 10))
+	ctrl.formatters.process(ctx, http.StatusOK, properties, info)
+}
+
+// next waits for the next event accepted by the subscription, for at most the
+// given duration. No event is retrieved if the time is up or the subscription
+// is closed, and done tells whether the client went away.
+func next(ctx *gin.Context, subscription *watch.Subscription, wait time.Duration) (*watch.Event, bool) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case event, open := <-subscription.Events():
+			if !open {
+				return nil, false
+			}
+
+			if subscription.Accepts(event) {
+				return &event, false
+			}
+		case <-timer.C:
+			return nil, false
+		case <-ctx.Request.Context().Done():
+			return nil, true
+		}
+	}
+}
+
+// stream writes the events of the subscription until either the client goes
+// away or the subscription is closed for falling behind, in which case the
+// client is expected to reconnect.
+func stream(ctx *gin.Context, subscription *watch.Subscription, query property.Query) {
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header(indexHeader, strconv.FormatUint(subscription.Index(), 10))
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event, open := <-subscription.Events():
+			if !open {
+				return
+			}
+
+			if !subscription.Accepts(event) {
+				continue
+			}
+
+			data, err := json.Marshal(toEventDto(ctx, event, query))
+			if err != nil {
+				return
+			}
+
+			fmt.Fprintf(ctx.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Index, event.Type, data)
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": heartbeat\n\n")
+		}
+
+		ctx.Writer.Flush()
+	}
+}
+
+func toEventDto(ctx *gin.Context, event watch.Event, query property.Query) *eventDto {
+	dto := &eventDto{Index: event.Index, Type: string(event.Type)}
+
+	if event.Property != nil {
+		prop := toPropertyDTO(protect(ctx, event.Property.Resolve(query.Profiles), query))
+		dto.Property = &prop
+	}
+
+	if event.Set != nil {
+		dto.Set = &eventSetDto{Name: event.Set.Name, Values: event.Set.Values, Includes: event.Set.Includes, Version: event.Set.Version}
+	}
+
+	return dto
+}
@@ -2,83 +2,170 @@ package bolt
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
 
 	"github.com/asdine/storm/v3/q"
 
 	"github.com/asdine/storm/v3"
 	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
 )
 
 // PropertyRepository is a representation of the property repository for Bolt DBs.
 type PropertyRepository struct {
-	db *storm.DB
+	db     *storm.DB
+	cipher *encryption.Cipher
 }
 
 type propertyDto struct {
 	ID          string `storm:"id"`
-	Name        string `storm:"unique"`
+	Namespace   string
+	Name        string
+	Path        string `storm:"unique"`
 	Description string `bson:"description"`
+	Type        string
 	Value       string `bson:"value"`
+	Secret      bool
+	Labels      map[string]string
+	Overrides   map[string]string
+	Constraint  *model.Constraint
+	Targeting   *model.Targeting
+	ExpiresAt   time.Time
+	Revision    int
 }
 
-// New retrieves a new repository object ready to be used.
-func New(db *storm.DB) storage.Repository {
+type propertyRevisionDto struct {
+	ID          int    `storm:"id,increment"`
+	PropertyID  string `storm:"index"`
+	Revision    int
+	Name        string
+	Description string
+	Type        string
+	Value       string
+	Secret      bool
+	Labels      map[string]string
+	Overrides   map[string]string
+	Constraint  *model.Constraint
+	Targeting   *model.Targeting
+	ExpiresAt   time.Time
+	Timestamp   time.Time
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values of secret properties and it can be nil, in which
+// case secret properties cannot be stored.
+func New(db *storm.DB, cipher *encryption.Cipher) storage.Repository {
 	repo := &PropertyRepository{
-		db: db,
+		db:     db,
+		cipher: cipher,
 	}
 	db.Init(&propertyDto{})
+	db.Init(&propertyRevisionDto{})
+
+	// The path index used to allow duplicates, so it is rebuilt as unique.
+	if err := db.ReIndex(&propertyDto{}); err != nil {
+		logger.Main.Error("Cannot rebuild the properties path index.", err)
+	}
+
+	repo.indexPaths()
+	repo.versionProperties()
 
 	return repo
 }
 
-// Create a new entry based on the provided property.
+// indexPaths fills in the path of the properties stored before the path index
+// was introduced.
+func (repository PropertyRepository) indexPaths() {
+	var dtos []propertyDto
+	err := repository.db.Select(q.Eq("Path", "")).Find(&dtos)
+	if err != nil && err != storm.ErrNotFound {
+		logger.Main.Error("Cannot read the properties to index by path.", err)
+		return
+	}
+
+	for _, dto := range dtos {
+		dto.Path = path(dto.Namespace, dto.Name)
+		if err := repository.db.Save(&dto); err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot index the property '%s' by path.", dto.ID), err)
+		}
+	}
+}
+
+// versionProperties gives the first revision to the properties stored before the
+// properties were revised, so that their entity tags can be used by conditional
+// requests.
+func (repository PropertyRepository) versionProperties() {
+	var dtos []propertyDto
+	err := repository.db.Select(q.Eq("Revision", 0)).Find(&dtos)
+	if err != nil && err != storm.ErrNotFound {
+		logger.Main.Error("Cannot read the properties to revise.", err)
+		return
+	}
+
+	for _, dto := range dtos {
+		dto.Revision = 1
+		if err := repository.db.Save(&dto); err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot revise the property '%s'.", dto.ID), err)
+		}
+	}
+}
+
+// Create a new entry based on the provided property. The first revision of the
+// property is recorded along with it.
 func (repository PropertyRepository) Create(ctx context.Context, property *model.Property) error {
 	id := uuid.New().String()
 	property.ID = id
+	property.Revision = 1
 
-	dto := convertToDto(property)
-
-	err := repository.db.Save(dto)
+	tx, err := transaction.BoltBegin(ctx, repository.db)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
-}
-
-// ReadAll retrieves all available properties.
-func (repository PropertyRepository) ReadAll(ctx context.Context) ([]*model.Property, error) {
-	var properties []propertyDto
-	if err := repository.db.All(&properties); err != nil {
-		return nil, err
+	if err := repository.save(tx, property); err != nil {
+		return err
 	}
 
-	return convertDtosToModel(properties), nil
+	return tx.Commit()
 }
 
-// ReadAllFiltered reads all available properties and filters them according to
-// the given names.
-func (repository PropertyRepository) ReadAllFiltered(ctx context.Context, names []string) ([]*model.Property, error) {
-	var properties []propertyDto
-	query := repository.db.Select(q.In("Name", names))
+// ReadAll retrieves the page of properties within the given namespace that
+// match the given filter.
+func (repository PropertyRepository) ReadAll(ctx context.Context, namespace string, filter storage.Filter) ([]*model.Property, model.PageInfo, error) {
+	return repository.find(ctx, namespace, filter)
+}
 
-	if err := query.Find(&properties); err != nil {
-		return nil, err
+// ReadAllFiltered reads the page of properties within the given namespace that
+// match the given names and filter. The names may also be name patterns.
+func (repository PropertyRepository) ReadAllFiltered(ctx context.Context, namespace string, names []string, filter storage.Filter) ([]*model.Property, model.PageInfo, error) {
+	membership := model.NewMembership(names)
+	if len(membership.Patterns()) == 0 {
+		return repository.find(ctx, namespace, filter, q.In("Name", membership.Names()))
 	}
 
-	return convertDtosToModel(properties), nil
+	matcher := q.Or(
+		q.In("Name", membership.Names()),
+		q.NewFieldMatcher("Name", patternMatcher{patterns: membership.Patterns()}))
+
+	return repository.find(ctx, namespace, filter, matcher)
 }
 
 // FindByID retrieves the property matching the given id if such a property
 // exists; otherwise will return a not found error.
 func (repository PropertyRepository) FindByID(context context.Context, id string) (*model.Property, error) {
 	var dto propertyDto
-	err := repository.db.One("ID", id, &dto)
+	err := repository.node(context).One("ID", id, &dto)
 
-	if storm.ErrNotFound == err {
+	if storm.ErrNotFound == err || (err == nil && expired(dto.ExpiresAt, time.Now())) {
 		return nil, errors.NewEntityNotFound(model.Property{}, id)
 	}
 
@@ -86,14 +173,14 @@ func (repository PropertyRepository) FindByID(context context.Context, id string
 		return nil, err
 	}
 
-	return convertToModel(&dto), nil
+	return repository.convertToModel(&dto)
 }
 
-// FindByName retrieves the property matching the given name if such a property
-// exists; otherwise will return a not found error.
-func (repository PropertyRepository) FindByName(context context.Context, name string) (*model.Property, error) {
+// FindByName retrieves the property matching the given name within the given
+// namespace if such a property exists; otherwise will return a not found error.
+func (repository PropertyRepository) FindByName(context context.Context, namespace string, name string) (*model.Property, error) {
 	var dto propertyDto
-	err := repository.db.One("Name", name, &dto)
+	err := repository.node(context).Select(q.Eq("Namespace", namespace), q.Eq("Name", name), unexpired(time.Now())).First(&dto)
 
 	if storm.ErrNotFound == err {
 		return nil, errors.NewEntityNotFound(model.Property{}, name)
@@ -103,13 +190,21 @@ func (repository PropertyRepository) FindByName(context context.Context, name st
 		return nil, err
 	}
 
-	return convertToModel(&dto), nil
+	return repository.convertToModel(&dto)
 }
 
-// Delete the property with the given id.
-func (repository PropertyRepository) Delete(context context.Context, id string) error {
+// Delete the property with the given id. Unless the given revision is zero, the
+// property must have that revision. Its revisions are kept, so that it can be
+// restored, until DeleteHistory removes them.
+func (repository PropertyRepository) Delete(context context.Context, id string, revision int) error {
+	tx, err := transaction.BoltBegin(context, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	var dto propertyDto
-	err := repository.db.One("ID", id, &dto)
+	err = tx.One("ID", id, &dto)
 
 	if storm.ErrNotFound == err {
 		return errors.NewEntityNotFound(model.Property{}, id)
@@ -119,17 +214,77 @@ func (repository PropertyRepository) Delete(context context.Context, id string)
 		return err
 	}
 
-	if err := repository.db.DeleteStruct(&dto); err != nil {
+	if revision != 0 && revision != dto.Revision {
+		return errors.NewPreconditionFailed(model.Property{}, id, revision)
+	}
+
+	if err := tx.DeleteStruct(&dto); err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// Restore recreates the given deleted property under its id. The property gets
+// the revision following its recorded ones, which is recorded along with it.
+func (repository PropertyRepository) Restore(ctx context.Context, property *model.Property) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var found propertyDto
+	err = tx.One("ID", property.ID, &found)
+
+	if err == nil {
+		return errors.NewConflict(reflect.TypeOf(property), "id", property.ID)
+	}
+
+	if storm.ErrNotFound != err {
+		return err
+	}
+
+	var last propertyRevisionDto
+	err = tx.Select(q.Eq("PropertyID", property.ID)).OrderBy("Revision").Reverse().First(&last)
+
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	if last.Revision > property.Revision {
+		property.Revision = last.Revision
+	}
+	property.Revision++
+	if err := repository.save(tx, property); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Update all fields of the given property.
+// DeleteHistory removes all revisions of the deleted property with the given id.
+func (repository PropertyRepository) DeleteHistory(ctx context.Context, id string) error {
+	err := repository.node(ctx).Select(q.Eq("PropertyID", id)).Delete(new(propertyRevisionDto))
+	if err == storm.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
+
+// Update all fields of the given property. Unless the revision of the given
+// property is zero, the stored property must have that revision. The property
+// revision is incremented and the new state is recorded as a new revision.
 func (repository PropertyRepository) Update(ctx context.Context, property *model.Property) error {
-	dto := convertToDto(property)
-	err := repository.db.Update(dto)
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var found propertyDto
+	err = tx.One("ID", property.ID, &found)
 
 	if storm.ErrNotFound == err {
 		return errors.NewEntityNotFound(model.Property{}, property.ID)
@@ -139,33 +294,441 @@ func (repository PropertyRepository) Update(ctx context.Context, property *model
 		return err
 	}
 
+	if property.Revision != 0 && property.Revision != found.Revision {
+		return errors.NewPreconditionFailed(model.Property{}, property.ID, property.Revision)
+	}
+
+	property.Revision = found.Revision + 1
+	if err := repository.save(tx, property); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReadHistory retrieves all recorded revisions of the property with the given
+// id, ordered from the oldest to the newest.
+func (repository PropertyRepository) ReadHistory(ctx context.Context, id string) ([]*model.PropertyRevision, error) {
+	var dtos []propertyRevisionDto
+	err := repository.node(ctx).Select(q.Eq("PropertyID", id)).OrderBy("Revision").Find(&dtos)
+
+	if storm.ErrNotFound == err {
+		return []*model.PropertyRevision{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertRevisionDtosToModel(dtos)
+}
+
+// FindRevision retrieves a single revision of the property with the given id.
+func (repository PropertyRepository) FindRevision(ctx context.Context, id string, revision int) (*model.PropertyRevision, error) {
+	var dto propertyRevisionDto
+	err := repository.node(ctx).Select(q.Eq("PropertyID", id), q.Eq("Revision", revision)).First(&dto)
+
+	if storm.ErrNotFound == err {
+		return nil, errors.NewEntityNotFound(model.PropertyRevision{}, fmt.Sprintf("%s@%d", id, revision))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertRevisionToModel(&dto)
+}
+
+// FindRevisionAt retrieves the revision of the property with the given id that
+// was active at the given moment in time.
+func (repository PropertyRepository) FindRevisionAt(ctx context.Context, id string, at time.Time) (*model.PropertyRevision, error) {
+	revisions, err := repository.ReadHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *model.PropertyRevision
+	for _, revision := range revisions {
+		if revision.Timestamp.After(at) {
+			break
+		}
+
+		found = revision
+	}
+
+	if found == nil {
+		return nil, errors.NewEntityNotFound(model.PropertyRevision{}, fmt.Sprintf("%s@%s", id, at.Format(time.RFC3339)))
+	}
+
+	return found, nil
+}
+
+// DeleteExpired removes the properties that have expired at the given moment in
+// time, along with all their revisions, and retrieves the removed properties.
+func (repository PropertyRepository) DeleteExpired(ctx context.Context, at time.Time) ([]*model.Property, error) {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var dtos []propertyDto
+	err = tx.Select(q.NewFieldMatcher("ExpiresAt", expiryMatcher{at: at, expired: true})).Find(&dtos)
+
+	if storm.ErrNotFound == err {
+		return []*model.Property{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dto := range dtos {
+		if err := tx.DeleteStruct(&dto); err != nil {
+			return nil, err
+		}
+
+		err = tx.Select(q.Eq("PropertyID", dto.ID)).Delete(new(propertyRevisionDto))
+		if err != nil && err != storm.ErrNotFound {
+			return nil, err
+		}
+	}
+
+	properties, err := repository.convertDtosToModel(dtos)
+	if err != nil {
+		return nil, err
+	}
+
+	return properties, tx.Commit()
+}
+
+// find retrieves the page of properties of the given namespace matching the
+// filter and all given matchers.
+func (repository PropertyRepository) find(ctx context.Context, namespace string, filter storage.Filter, matchers ...q.Matcher) ([]*model.Property, model.PageInfo, error) {
+	matchers = append(matchers, q.Eq("Namespace", namespace), unexpired(time.Now()))
+	if !filter.Selector.IsEmpty() {
+		matchers = append(matchers, q.NewFieldMatcher("Labels", labelsMatcher{selector: filter.Selector}))
+	}
+
+	var dtos []propertyDto
+	var err error
+	if filter.Prefix == "" {
+		dtos, err = repository.findAll(ctx, matchers...)
+	} else {
+		dtos, err = repository.findByPrefix(ctx, namespace, filter, matchers...)
+	}
+
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	return repository.paginate(dtos, filter.Page)
+}
+
+func (repository PropertyRepository) findAll(ctx context.Context, matchers ...q.Matcher) ([]propertyDto, error) {
+	var dtos []propertyDto
+	err := repository.node(ctx).Select(matchers...).Find(&dtos)
+
+	if storm.ErrNotFound == err {
+		return []propertyDto{}, nil
+	}
+
+	return dtos, err
+}
+
+// findByPrefix looks the candidates up through the path index instead of
+// scanning all properties, then applies the matchers to the candidates.
+func (repository PropertyRepository) findByPrefix(ctx context.Context, namespace string, filter storage.Filter, matchers ...q.Matcher) ([]propertyDto, error) {
+	var candidates []propertyDto
+	err := repository.node(ctx).Prefix("Path", path(namespace, filter.Prefix), &candidates)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	matcher := q.And(matchers...)
+	dtos := make([]propertyDto, 0, len(candidates))
+	for _, candidate := range candidates {
+		if !filter.MatchesPrefix(candidate.Name) {
+			continue
+		}
+
+		matches, err := matcher.Match(&candidate)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			dtos = append(dtos, candidate)
+		}
+	}
+
+	return dtos, nil
+}
+
+// paginate sorts the given properties by name and converts only the ones that
+// belong to the given page.
+func (repository PropertyRepository) paginate(dtos []propertyDto, page model.Page) ([]*model.Property, model.PageInfo, error) {
+	sort.Slice(dtos, func(i, j int) bool {
+		return page.Less(dtos[i].Name, dtos[j].Name)
+	})
+
+	names := make([]string, len(dtos))
+	for i, dto := range dtos {
+		names[i] = dto.Name
+	}
+
+	from, to, info := page.Window(names)
+	properties, err := repository.convertDtosToModel(dtos[from:to])
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	return properties, info, nil
+}
+
+// path builds the value of the path index, which keeps the properties of each
+// namespace together. Namespaces cannot contain ':', so the separator is safe.
+func path(namespace string, name string) string {
+	return namespace + ":" + name
+}
+
+// labelsMatcher matches the labels of the stored properties against a selector.
+type labelsMatcher struct {
+	selector model.LabelSelector
+}
+
+func (matcher labelsMatcher) MatchField(v interface{}) (bool, error) {
+	labels, _ := v.(map[string]string)
+
+	return matcher.selector.Matches(labels), nil
+}
+
+// patternMatcher matches the names of the stored properties against a list of
+// name patterns.
+type patternMatcher struct {
+	patterns []*model.NamePattern
+}
+
+func (matcher patternMatcher) MatchField(v interface{}) (bool, error) {
+	name, _ := v.(string)
+	for _, pattern := range matcher.patterns {
+		if pattern.Matches(name) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// expiryMatcher matches the expiry time of the stored properties against the
+// given moment in time, retrieving either the expired or the unexpired ones.
+type expiryMatcher struct {
+	at      time.Time
+	expired bool
+}
+
+func (matcher expiryMatcher) MatchField(v interface{}) (bool, error) {
+	expiresAt, _ := v.(time.Time)
+
+	return expired(expiresAt, matcher.at) == matcher.expired, nil
+}
+
+// expired checks whether the given expiry time, if any, has passed at the given
+// moment in time.
+func expired(expiresAt time.Time, at time.Time) bool {
+	return !expiresAt.IsZero() && !expiresAt.After(at)
+}
+
+// unexpired retrieves the matcher of the properties that have not expired at
+// the given moment in time.
+func unexpired(at time.Time) q.Matcher {
+	return q.NewFieldMatcher("ExpiresAt", expiryMatcher{at: at})
+}
+
+// claimPath checks that no other property of the namespace uses the name of the
+// given one. An expired property still using the name is removed, along with
+// its revisions, as it is never retrieved anyway.
+func claimPath(tx storm.Node, dto *propertyDto) error {
+	var holder propertyDto
+	err := tx.One("Path", dto.Path, &holder)
+	if storm.ErrNotFound == err || (err == nil && holder.ID == dto.ID) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !expired(holder.ExpiresAt, time.Now()) {
+		return errors.NewConflict(reflect.TypeOf(&model.Property{}), "name", dto.Name)
+	}
+
+	if err := tx.DeleteStruct(&holder); err != nil {
+		return err
+	}
+
+	err = tx.Select(q.Eq("PropertyID", holder.ID)).Delete(new(propertyRevisionDto))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
 	return nil
 }
 
+// save stores the given property, along with a new revision capturing its state.
+func (repository PropertyRepository) save(tx storm.Node, property *model.Property) error {
+	value, err := storage.SealValue(repository.cipher, property.Secret, property.Value)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := storage.SealOverrides(repository.cipher, property.Secret, property.Overrides)
+	if err != nil {
+		return err
+	}
+
+	dto := convertToDto(property)
+	dto.Value = value
+	dto.Overrides = overrides
+	if err := claimPath(tx, dto); err != nil {
+		return err
+	}
+
+	if err := tx.Save(dto); err != nil {
+		return err
+	}
+
+	revisionDto := convertRevisionToDto(model.NewPropertyRevision(property, time.Now()))
+	revisionDto.Value = value
+	revisionDto.Overrides = overrides
+
+	return tx.Save(revisionDto)
+}
+
 func convertToDto(property *model.Property) *propertyDto {
 	return &propertyDto{
 		ID:          property.ID,
+		Namespace:   property.Namespace,
 		Name:        property.Name,
+		Path:        path(property.Namespace, property.Name),
 		Description: property.Description,
+		Type:        string(property.Type),
 		Value:       property.Value,
+		Secret:      property.Secret,
+		Labels:      property.Labels,
+		Constraint:  property.Constraint,
+		Targeting:   property.Targeting,
+		ExpiresAt:   property.ExpiresAt,
+		Revision:    property.Revision,
 	}
 }
 
-func convertDtosToModel(dtos []propertyDto) []*model.Property {
+func (repository PropertyRepository) convertDtosToModel(dtos []propertyDto) ([]*model.Property, error) {
 	result := make([]*model.Property, len(dtos))
 
 	for index, dto := range dtos {
-		result[index] = convertToModel(&dto)
+		property, err := repository.convertToModel(&dto)
+		if err != nil {
+			return nil, err
+		}
+
+		result[index] = property
 	}
 
-	return result
+	return result, nil
 }
 
-func convertToModel(dto *propertyDto) *model.Property {
+func (repository PropertyRepository) convertToModel(dto *propertyDto) (*model.Property, error) {
+	value, err := storage.OpenValue(repository.cipher, dto.Secret, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := storage.OpenOverrides(repository.cipher, dto.Secret, dto.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.Property{
 		ID:          dto.ID,
+		Namespace:   dto.Namespace,
 		Name:        dto.Name,
 		Description: dto.Description,
-		Value:       dto.Value,
+		Type:        model.PropertyType(dto.Type),
+		Value:       value,
+		Secret:      dto.Secret,
+		Labels:      dto.Labels,
+		Overrides:   overrides,
+		Constraint:  dto.Constraint,
+		Targeting:   dto.Targeting,
+		ExpiresAt:   dto.ExpiresAt,
+		Revision:    dto.Revision,
+	}, nil
+}
+
+func convertRevisionToDto(revision *model.PropertyRevision) *propertyRevisionDto {
+	return &propertyRevisionDto{
+		PropertyID:  revision.PropertyID,
+		Revision:    revision.Revision,
+		Name:        revision.Name,
+		Description: revision.Description,
+		Type:        string(revision.Type),
+		Value:       revision.Value,
+		Secret:      revision.Secret,
+		Labels:      revision.Labels,
+		Constraint:  revision.Constraint,
+		Targeting:   revision.Targeting,
+		ExpiresAt:   revision.ExpiresAt,
+		Timestamp:   revision.Timestamp,
 	}
 }
+
+func (repository PropertyRepository) convertRevisionDtosToModel(dtos []propertyRevisionDto) ([]*model.PropertyRevision, error) {
+	result := make([]*model.PropertyRevision, len(dtos))
+
+	for index, dto := range dtos {
+		revision, err := repository.convertRevisionToModel(&dto)
+		if err != nil {
+			return nil, err
+		}
+
+		result[index] = revision
+	}
+
+	return result, nil
+}
+
+func (repository PropertyRepository) convertRevisionToModel(dto *propertyRevisionDto) (*model.PropertyRevision, error) {
+	value, err := storage.OpenValue(repository.cipher, dto.Secret, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := storage.OpenOverrides(repository.cipher, dto.Secret, dto.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PropertyRevision{
+		PropertyID:  dto.PropertyID,
+		Revision:    dto.Revision,
+		Name:        dto.Name,
+		Description: dto.Description,
+		Type:        model.PropertyType(dto.Type),
+		Value:       value,
+		Secret:      dto.Secret,
+		Labels:      dto.Labels,
+		Overrides:   overrides,
+		Constraint:  dto.Constraint,
+		Targeting:   dto.Targeting,
+		ExpiresAt:   dto.ExpiresAt,
+		Timestamp:   dto.Timestamp,
+	}, nil
+}
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository PropertyRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	g_errors "errors"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
 	"github.com/rghiorghisor/basic-go-rest-api/util"
 	"gopkg.in/go-playground/assert.v1"
 )
@@ -42,7 +47,7 @@ func TestReadAll(t *testing.T) {
 	repo.Create(context.Background(), prop1)
 	repo.Create(context.Background(), prop2)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
 
 	if readProps[0].ID == prop2.ID {
 		tmp := prop1
@@ -72,7 +77,7 @@ func TestReadAllFiltered(t *testing.T) {
 	repo.Create(context.Background(), prop2)
 	repo.Create(context.Background(), prop3)
 
-	readProps, _ := repo.ReadAllFiltered(context.Background(), []string{prop1.Name, prop2.Name})
+	readProps, _, _ := repo.ReadAllFiltered(context.Background(), namespace.Default, []string{prop1.Name, prop2.Name}, storage.Filter{})
 
 	if readProps[0].ID == prop2.ID {
 		tmp := prop1
@@ -90,13 +95,59 @@ func TestReadAllFiltered(t *testing.T) {
 	assert.Equal(t, prop2.Value, readProps[1].Value)
 }
 
+func TestReadAllFilteredPatterns(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	repo.Create(context.Background(), &model.Property{Name: "db.host", Value: "localhost"})
+	repo.Create(context.Background(), &model.Property{Name: "payments.api.url", Value: "http://api"})
+	repo.Create(context.Background(), &model.Property{Name: "payments.db.url", Value: "http://db"})
+	repo.Create(context.Background(), &model.Property{Name: "payments.web.url", Value: "http://web"})
+	repo.Create(context.Background(), &model.Property{Name: "log.level", Value: "info"})
+
+	readProps, info, err := repo.ReadAllFiltered(context.Background(), namespace.Default, []string{"db.host", `/^payments\.(api|db)\./`, "log.*"}, storage.Filter{})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, info.Total)
+	assert.Equal(t, "db.host", readProps[0].Name)
+	assert.Equal(t, "log.level", readProps[1].Name)
+	assert.Equal(t, "payments.api.url", readProps[2].Name)
+	assert.Equal(t, "payments.db.url", readProps[3].Name)
+}
+
+func TestReadAllSelector(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1", Labels: map[string]string{"team": "payments", "tier": "critical"}}
+	prop2 := &model.Property{Name: "test.name.2", Value: "test.value.2", Labels: map[string]string{"team": "payments", "tier": "dev"}}
+	prop3 := &model.Property{Name: "test.name.3", Value: "test.value.3"}
+
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+	repo.Create(context.Background(), prop3)
+
+	selector, _ := model.ParseLabelSelector("team=payments,tier!=dev")
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Selector: selector})
+
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop1.ID, readProps[0].ID)
+	assert.Equal(t, prop1.Labels, readProps[0].Labels)
+
+	selector, _ = model.ParseLabelSelector("!team")
+	readProps, _, _ = repo.ReadAllFiltered(context.Background(), namespace.Default, []string{prop2.Name, prop3.Name}, storage.Filter{Selector: selector})
+
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop3.ID, readProps[0].ID)
+}
+
 func TestReadAllUnexpected(t *testing.T) {
 	repo := setup()
 	repo.db.Close()
 
 	defer tearDown(repo)
 
-	_, err := repo.ReadAll(context.Background())
+	_, _, err := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
 
@@ -179,10 +230,10 @@ func TestFindByName(t *testing.T) {
 	var readProps []*model.Property
 	readProps = make([]*model.Property, 0, 2)
 
-	prop, _ := repo.FindByName(context.Background(), prop1.Name)
+	prop, _ := repo.FindByName(context.Background(), namespace.Default, prop1.Name)
 	readProps = append(readProps, prop)
 
-	prop, _ = repo.FindByName(context.Background(), prop2.Name)
+	prop, _ = repo.FindByName(context.Background(), namespace.Default, prop2.Name)
 	readProps = append(readProps, prop)
 
 	assert.Equal(t, true, (readProps[0].ID != ""))
@@ -200,7 +251,7 @@ func TestFindByNameNotFound(t *testing.T) {
 	defer tearDown(repo)
 
 	name := "test.notfound.name"
-	_, err := repo.FindByName(context.Background(), name)
+	_, err := repo.FindByName(context.Background(), namespace.Default, name)
 
 	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, name), err)
 }
@@ -211,7 +262,7 @@ func TestFindByNameUnexpected(t *testing.T) {
 	defer tearDown(repo)
 
 	name := "test.notfound.name"
-	_, err := repo.FindByName(context.Background(), name)
+	_, err := repo.FindByName(context.Background(), namespace.Default, name)
 
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
@@ -228,14 +279,14 @@ func TestDelete(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
 
 	assert.Equal(t, true, (readProps[0].ID != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Description, readProps[0].Description)
 	assert.Equal(t, prop1.Value, readProps[0].Value)
 
-	repo.Delete(context.Background(), readProps[0].ID)
+	repo.Delete(context.Background(), readProps[0].ID, 0)
 	id := readProps[0].ID
 	_, err := repo.FindByID(context.Background(), id)
 
@@ -247,7 +298,7 @@ func TestDeleteNotFound(t *testing.T) {
 	defer tearDown(repo)
 
 	id := "test.notfound.id"
-	err := repo.Delete(context.Background(), id)
+	err := repo.Delete(context.Background(), id, 0)
 	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, id), err)
 }
 
@@ -257,7 +308,7 @@ func TestDeleteUnexpectedFind(t *testing.T) {
 	defer tearDown(repo)
 
 	id := "test.notfound.id"
-	err := repo.Delete(context.Background(), id)
+	err := repo.Delete(context.Background(), id, 0)
 
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
@@ -274,7 +325,7 @@ func TestUpdate(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
 	assert.Equal(t, true, (readProps[0].ID != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Description, readProps[0].Description)
@@ -303,14 +354,14 @@ func TestUpdateNotFound(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
 	assert.Equal(t, true, (readProps[0].ID != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Description, readProps[0].Description)
 	assert.Equal(t, prop1.Value, readProps[0].Value)
 
 	prop1.Description = "test.description.1.2"
-	repo.Delete(context.Background(), prop1.ID)
+	repo.Delete(context.Background(), prop1.ID, 0)
 	err := repo.Update(context.Background(), prop1)
 	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, prop1.ID), err)
 }
@@ -328,7 +379,7 @@ func TestUpdateUnExpected(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
 	assert.Equal(t, true, (readProps[0].ID != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Description, readProps[0].Description)
@@ -341,6 +392,477 @@ func TestUpdateUnExpected(t *testing.T) {
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
 
+func TestReadHistory(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Description: "test.description.1", Value: "test.value.1"}
+
+	repo.Create(context.Background(), prop1)
+	assert.Equal(t, 1, prop1.Revision)
+
+	prop1.Value = "test.value.1.2"
+	repo.Update(context.Background(), prop1)
+	assert.Equal(t, 2, prop1.Revision)
+
+	prop1.Value = "test.value.1.3"
+	repo.Update(context.Background(), prop1)
+	assert.Equal(t, 3, prop1.Revision)
+
+	revisions, err := repo.ReadHistory(context.Background(), prop1.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(revisions))
+	assert.Equal(t, 1, revisions[0].Revision)
+	assert.Equal(t, "test.value.1", revisions[0].Value)
+	assert.Equal(t, 2, revisions[1].Revision)
+	assert.Equal(t, "test.value.1.2", revisions[1].Value)
+	assert.Equal(t, 3, revisions[2].Revision)
+	assert.Equal(t, "test.value.1.3", revisions[2].Value)
+
+	found, _ := repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, 3, found.Revision)
+}
+
+func TestReadHistoryEmpty(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	revisions, err := repo.ReadHistory(context.Background(), "test.notfound.id")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(revisions))
+}
+
+func TestFindRevision(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Description: "test.description.1", Value: "test.value.1"}
+
+	repo.Create(context.Background(), prop1)
+	prop1.Value = "test.value.1.2"
+	repo.Update(context.Background(), prop1)
+
+	revision, err := repo.FindRevision(context.Background(), prop1.ID, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, prop1.ID, revision.PropertyID)
+	assert.Equal(t, "test.value.1", revision.Value)
+
+	_, err = repo.FindRevision(context.Background(), prop1.ID, 3)
+	assert.Equal(t, errors.NewEntityNotFound(model.PropertyRevision{}, prop1.ID+"@3"), err)
+}
+
+func TestFindRevisionAt(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Description: "test.description.1", Value: "test.value.1"}
+
+	before := time.Now().Add(-time.Hour)
+	repo.Create(context.Background(), prop1)
+	prop1.Value = "test.value.1.2"
+	repo.Update(context.Background(), prop1)
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop1.ID)
+
+	revision, err := repo.FindRevisionAt(context.Background(), prop1.ID, revisions[0].Timestamp)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, revision.Revision)
+
+	revision, err = repo.FindRevisionAt(context.Background(), prop1.ID, time.Now())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, revision.Revision)
+
+	_, err = repo.FindRevisionAt(context.Background(), prop1.ID, before)
+	assert.Equal(t, errors.NewEntityNotFound(model.PropertyRevision{}, prop1.ID+"@"+before.Format(time.RFC3339)), err)
+}
+
+func TestDeleteHistory(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Description: "test.description.1", Value: "test.value.1"}
+
+	repo.Create(context.Background(), prop1)
+	repo.Delete(context.Background(), prop1.ID, 0)
+
+	revisions, err := repo.ReadHistory(context.Background(), prop1.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(revisions))
+
+	err = repo.DeleteHistory(context.Background(), prop1.ID)
+	assert.Equal(t, nil, err)
+
+	revisions, err = repo.ReadHistory(context.Background(), prop1.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(revisions))
+}
+
+func TestRestore(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1"}
+	repo.Create(context.Background(), prop1)
+	prop1.Value = "test.value.2"
+	repo.Update(context.Background(), prop1)
+	repo.Delete(context.Background(), prop1.ID, 0)
+
+	restored := &model.Property{ID: prop1.ID, Name: "test.name.1", Value: "test.value.2", Revision: 2}
+	err := repo.Restore(context.Background(), restored)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, restored.Revision)
+
+	actual, _ := repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, restored, actual)
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop1.ID)
+	assert.Equal(t, 3, len(revisions))
+
+	err = repo.Restore(context.Background(), restored)
+	assert.Equal(t, errors.NewConflict(reflect.TypeOf(restored), "id", prop1.ID), err)
+}
+
+func TestCreateSecret(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.secret.1", Secret: true}
+
+	err := repo.Create(context.Background(), prop1)
+	assert.Equal(t, nil, err)
+
+	var stored propertyDto
+	repo.db.One("ID", prop1.ID, &stored)
+	assert.NotEqual(t, "test.secret.1", stored.Value)
+	assert.Equal(t, true, stored.Secret)
+
+	var storedRevision propertyRevisionDto
+	repo.db.One("PropertyID", prop1.ID, &storedRevision)
+	assert.NotEqual(t, "test.secret.1", storedRevision.Value)
+
+	found, _ := repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, "test.secret.1", found.Value)
+	assert.Equal(t, true, found.Secret)
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop1.ID)
+	assert.Equal(t, "test.secret.1", revisions[0].Value)
+}
+
+func TestCreateOverrides(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1", Overrides: map[string]string{"prod": "test.value.prod"}}
+	prop2 := &model.Property{Name: "test.name.2", Value: "test.secret.2", Secret: true, Overrides: map[string]string{"prod": "test.secret.prod"}}
+
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+
+	var stored propertyDto
+	repo.db.One("ID", prop2.ID, &stored)
+	assert.NotEqual(t, "test.secret.prod", stored.Overrides["prod"])
+
+	found, _ := repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, prop1.Overrides, found.Overrides)
+
+	found, _ = repo.FindByID(context.Background(), prop2.ID)
+	assert.Equal(t, prop2.Overrides, found.Overrides)
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop2.ID)
+	assert.Equal(t, prop2.Overrides, revisions[0].Overrides)
+}
+
+func TestCreateConstraint(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	max := 65535.0
+	prop := &model.Property{Name: "test.port", Type: model.TypeInt, Value: "8080", Constraint: &model.Constraint{Max: &max, Enum: []string{"80", "8080"}}}
+
+	repo.Create(context.Background(), prop)
+
+	found, _ := repo.FindByID(context.Background(), prop.ID)
+	assert.Equal(t, prop.Constraint, found.Constraint)
+
+	found.Constraint = &model.Constraint{Pattern: "^80"}
+	repo.Update(context.Background(), found)
+
+	found, _ = repo.FindByID(context.Background(), prop.ID)
+	assert.Equal(t, &model.Constraint{Pattern: "^80"}, found.Constraint)
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop.ID)
+	assert.Equal(t, prop.Constraint, revisions[0].Constraint)
+	assert.Equal(t, found.Constraint, revisions[1].Constraint)
+}
+
+func TestCreateTargeting(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	targeting := &model.Targeting{Key: "account", Rules: []model.TargetingRule{
+		{Conditions: []model.TargetingCondition{{Attribute: "country", Operator: model.ConditionIn, Values: []string{"RO"}}}, Variant: "true"},
+		{Rollout: []model.RolloutShare{{Variant: "true", Weight: 10}, {Variant: "false", Weight: 90}}},
+	}}
+	prop := &model.Property{Name: "test.enabled", Type: model.TypeBool, Value: "false", Targeting: targeting}
+
+	repo.Create(context.Background(), prop)
+
+	found, _ := repo.FindByID(context.Background(), prop.ID)
+	assert.Equal(t, targeting, found.Targeting)
+
+	found.Targeting = nil
+	repo.Update(context.Background(), found)
+
+	found, _ = repo.FindByID(context.Background(), prop.ID)
+	assert.Equal(t, (*model.Targeting)(nil), found.Targeting)
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop.ID)
+	assert.Equal(t, targeting, revisions[0].Targeting)
+}
+
+func TestCreateSecretNoKey(t *testing.T) {
+	repo := setup()
+	repo.cipher = nil
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.secret.1", Secret: true}
+
+	err := repo.Create(context.Background(), prop1)
+	assert.Equal(t, errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "'secret' properties require an encryption key to be configured."), err)
+
+	_, err = repo.FindByName(context.Background(), namespace.Default, prop1.Name)
+	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, prop1.Name), err)
+}
+
+func TestNamespaces(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1"}
+	prop2 := &model.Property{Namespace: "payments", Name: "test.name.1", Value: "test.value.2"}
+
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop1.ID, readProps[0].ID)
+
+	readProps, _, _ = repo.ReadAllFiltered(context.Background(), "payments", []string{prop1.Name}, storage.Filter{})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop2.ID, readProps[0].ID)
+	assert.Equal(t, "payments", readProps[0].Namespace)
+
+	found, _ := repo.FindByName(context.Background(), "payments", prop1.Name)
+	assert.Equal(t, prop2.Value, found.Value)
+
+	_, err := repo.FindByName(context.Background(), "other", prop1.Name)
+	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, prop1.Name), err)
+}
+
+func TestReadAllPrefix(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "payments.db", Value: "test.value.1"}
+	prop2 := &model.Property{Name: "payments.db.pool.size", Value: "test.value.2", Labels: map[string]string{"team": "payments"}}
+	prop3 := &model.Property{Name: "payments.dbx", Value: "test.value.3"}
+	prop4 := &model.Property{Namespace: "payments", Name: "payments.db.url", Value: "test.value.4"}
+
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+	repo.Create(context.Background(), prop3)
+	repo.Create(context.Background(), prop4)
+
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Prefix: "payments.db"})
+	assert.Equal(t, map[string]bool{prop1.ID: true, prop2.ID: true}, ids(readProps))
+
+	selector, _ := model.ParseLabelSelector("team=payments")
+	readProps, _, _ = repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Prefix: "payments", Selector: selector})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop2.ID, readProps[0].ID)
+
+	readProps, _, _ = repo.ReadAllFiltered(context.Background(), namespace.Default, []string{prop1.Name, prop3.Name}, storage.Filter{Prefix: "payments.db"})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop1.ID, readProps[0].ID)
+
+	readProps, _, _ = repo.ReadAll(context.Background(), "payments", storage.Filter{Prefix: "payments.db"})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, prop4.ID, readProps[0].ID)
+
+	readProps, _, err := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Prefix: "billing"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(readProps))
+}
+
+func TestUpdateRevision(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1"}
+	repo.Create(context.Background(), prop1)
+
+	prop1.Value = "test.value.2"
+	assert.Equal(t, nil, repo.Update(context.Background(), prop1))
+	assert.Equal(t, 2, prop1.Revision)
+
+	stale := &model.Property{ID: prop1.ID, Name: "test.name.1", Value: "test.value.3", Revision: 1}
+	err := repo.Update(context.Background(), stale)
+	assert.Equal(t, errors.NewPreconditionFailed(model.Property{}, prop1.ID, 1), err)
+
+	found, _ := repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, "test.value.2", found.Value)
+	assert.Equal(t, 2, found.Revision)
+}
+
+func TestDeleteRevision(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1"}
+	repo.Create(context.Background(), prop1)
+	repo.Update(context.Background(), prop1)
+
+	err := repo.Delete(context.Background(), prop1.ID, 1)
+	assert.Equal(t, errors.NewPreconditionFailed(model.Property{}, prop1.ID, 1), err)
+
+	assert.Equal(t, nil, repo.Delete(context.Background(), prop1.ID, 2))
+
+	_, err = repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, prop1.ID), err)
+}
+
+func TestReadAllPage(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	for _, name := range []string{"c", "a", "e", "b", "d"} {
+		repo.Create(context.Background(), &model.Property{Name: name, Value: "test.value"})
+	}
+
+	readProps, info, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Page: model.Page{Limit: 2}})
+	assert.Equal(t, []string{"a", "b"}, names(readProps))
+	assert.Equal(t, model.PageInfo{Total: 5, Next: "b"}, info)
+
+	readProps, info, _ = repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Page: model.Page{Limit: 2, After: "d"}})
+	assert.Equal(t, []string{"e"}, names(readProps))
+	assert.Equal(t, model.PageInfo{Total: 5}, info)
+
+	readProps, info, _ = repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Page: model.Page{Limit: 2, After: "d", Descending: true}})
+	assert.Equal(t, []string{"c", "b"}, names(readProps))
+	assert.Equal(t, model.PageInfo{Total: 5, Next: "b"}, info)
+
+	readProps, info, _ = repo.ReadAllFiltered(context.Background(), namespace.Default, []string{"a", "c", "e"}, storage.Filter{Page: model.Page{Limit: 2, After: "a"}})
+	assert.Equal(t, []string{"c", "e"}, names(readProps))
+	assert.Equal(t, model.PageInfo{Total: 3}, info)
+}
+
+func TestIndexPaths(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	repo.db.Save(&propertyDto{ID: "test.id.1", Name: "payments.db.url", Value: "test.value.1"})
+	repo.indexPaths()
+
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Prefix: "payments"})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, "test.id.1", readProps[0].ID)
+}
+
+func TestExpired(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1", ExpiresAt: time.Now().Add(-time.Minute)}
+	prop2 := &model.Property{Name: "test.name.2", Value: "test.value.2", ExpiresAt: time.Now().Add(time.Hour)}
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+
+	readProps, info, _ := repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
+	assert.Equal(t, []string{"test.name.2"}, names(readProps))
+	assert.Equal(t, 1, info.Total)
+
+	readProps, _, _ = repo.ReadAll(context.Background(), namespace.Default, storage.Filter{Prefix: "test"})
+	assert.Equal(t, []string{"test.name.2"}, names(readProps))
+
+	readProps, _, _ = repo.ReadAllFiltered(context.Background(), namespace.Default, []string{"test.name.1", "test.name.2"}, storage.Filter{})
+	assert.Equal(t, []string{"test.name.2"}, names(readProps))
+
+	_, err := repo.FindByID(context.Background(), prop1.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, prop1.ID), err)
+
+	_, err = repo.FindByName(context.Background(), namespace.Default, prop1.Name)
+	assert.Equal(t, errors.NewEntityNotFound(model.Property{}, prop1.Name), err)
+
+	found, _ := repo.FindByID(context.Background(), prop2.ID)
+	assert.Equal(t, true, prop2.ExpiresAt.Equal(found.ExpiresAt))
+}
+
+func TestUniqueName(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1"}
+	prop2 := &model.Property{Name: "test.name.2", Value: "test.value.2"}
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+
+	err := repo.Create(context.Background(), &model.Property{Name: "test.name.1", Value: "other"})
+	assert.Equal(t, errors.NewConflict(reflect.TypeOf(&model.Property{}), "name", "test.name.1"), err)
+
+	prop2.Name = prop1.Name
+	err = repo.Update(context.Background(), prop2)
+	assert.Equal(t, errors.NewConflict(reflect.TypeOf(&model.Property{}), "name", "test.name.1"), err)
+
+	other := &model.Property{Namespace: "payments", Name: "test.name.1", Value: "other"}
+	assert.Equal(t, nil, repo.Create(context.Background(), other))
+}
+
+func TestUniqueNameExpired(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	expiredProp := &model.Property{Name: "test.name", Value: "old", ExpiresAt: time.Now().Add(-time.Minute)}
+	repo.Create(context.Background(), expiredProp)
+
+	prop := &model.Property{Name: "test.name", Value: "new"}
+	assert.Equal(t, nil, repo.Create(context.Background(), prop))
+
+	found, _ := repo.FindByName(context.Background(), namespace.Default, "test.name")
+	assert.Equal(t, prop.ID, found.ID)
+
+	history, _ := repo.ReadHistory(context.Background(), expiredProp.ID)
+	assert.Equal(t, 0, len(history))
+}
+
+func TestDeleteExpired(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.Property{Name: "test.name.1", Value: "test.value.1", Secret: true, ExpiresAt: time.Now().Add(time.Minute)}
+	prop2 := &model.Property{Name: "test.name.2", Value: "test.value.2", ExpiresAt: time.Now().Add(time.Hour)}
+	prop3 := &model.Property{Name: "test.name.3", Value: "test.value.3"}
+	repo.Create(context.Background(), prop1)
+	repo.Create(context.Background(), prop2)
+	repo.Create(context.Background(), prop3)
+
+	removed, err := repo.DeleteExpired(context.Background(), time.Now())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(removed))
+
+	removed, err = repo.DeleteExpired(context.Background(), time.Now().Add(10*time.Minute))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"test.name.1"}, names(removed))
+	assert.Equal(t, "test.value.1", removed[0].Value)
+
+	var dtos []propertyDto
+	repo.db.All(&dtos)
+	assert.Equal(t, 2, len(dtos))
+
+	revisions, _ := repo.ReadHistory(context.Background(), prop1.ID)
+	assert.Equal(t, 0, len(revisions))
+}
+
 func BenchmarkReadAll(b *testing.B) {
 	repo := setup()
 	defer tearDown(repo)
@@ -360,18 +882,38 @@ func BenchmarkReadAll(b *testing.B) {
 	repo.Create(context.Background(), prop2)
 
 	for n := 0; n < b.N; n++ {
-		repo.ReadAll(context.Background())
+		repo.ReadAll(context.Background(), namespace.Default, storage.Filter{})
+	}
+
+}
+
+func ids(props []*model.Property) map[string]bool {
+	result := make(map[string]bool, len(props))
+	for _, prop := range props {
+		result[prop.ID] = true
+	}
+
+	return result
+}
+
+func names(props []*model.Property) []string {
+	result := make([]string, len(props))
+	for i, prop := range props {
+		result[i] = prop.Name
 	}
 
+	return result
 }
 
 func setup() *PropertyRepository {
 	util.CreateParentFolder(defaultDB)
 
 	db, _ := storm.Open(defaultDB)
+	cipher, _ := encryption.New([]byte("0123456789abcdef0123456789abcdef"))
 
 	return &PropertyRepository{
-		db: db,
+		db:     db,
+		cipher: cipher,
 	}
 }
 
@@ -2,90 +2,276 @@ package mongo
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	ns "github.com/rghiorghisor/basic-go-rest-api/namespace"
 	"github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const propertiesCollectionName = "properties_collection"
+const propertiesHistoryCollectionName = "properties_history_collection"
 
 type propertyDto struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Namespace   string             `bson:"namespace,omitempty"`
 	Name        string             `bson:"name"`
 	Description string             `bson:"description"`
+	Type        string             `bson:"type,omitempty"`
 	Value       string             `bson:"value"`
+	Secret      bool               `bson:"secret,omitempty"`
+	Labels      []labelDto         `bson:"labels,omitempty"`
+	Overrides   map[string]string  `bson:"overrides,omitempty"`
+	Constraint  *constraintDto     `bson:"constraint,omitempty"`
+	Targeting   *targetingDto      `bson:"targeting,omitempty"`
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty"`
+	Revision    int                `bson:"revision"`
+}
+
+// labelDto stores a single label. Labels are stored as key/value pairs, rather
+// than as a document, as label keys can contain characters that have a special
+// meaning in field paths.
+type labelDto struct {
+	Key   string `bson:"key"`
+	Value string `bson:"value"`
+}
+
+type propertyRevisionDto struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	PropertyID  string             `bson:"property_id"`
+	Revision    int                `bson:"revision"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description"`
+	Type        string             `bson:"type,omitempty"`
+	Value       string             `bson:"value"`
+	Secret      bool               `bson:"secret,omitempty"`
+	Labels      []labelDto         `bson:"labels,omitempty"`
+	Overrides   map[string]string  `bson:"overrides,omitempty"`
+	Constraint  *constraintDto     `bson:"constraint,omitempty"`
+	Targeting   *targetingDto      `bson:"targeting,omitempty"`
+	ExpiresAt   *time.Time         `bson:"expires_at,omitempty"`
+	Timestamp   time.Time          `bson:"timestamp"`
+
+	// PurgeAt is the expiry time of the property the revision belongs to, so
+	// that the revisions expire along with the property.
+	PurgeAt *time.Time `bson:"purge_at,omitempty"`
+}
+
+// constraintDto stores the constraint of a property. The JSON Schema is stored
+// as a string, as its keywords can start with '$'.
+type constraintDto struct {
+	Min     *float64 `bson:"min,omitempty"`
+	Max     *float64 `bson:"max,omitempty"`
+	Enum    []string `bson:"enum,omitempty"`
+	Pattern string   `bson:"pattern,omitempty"`
+	Schema  string   `bson:"schema,omitempty"`
+}
+
+// targetingDto stores the targeting rules of a property.
+type targetingDto struct {
+	Key   string             `bson:"key,omitempty"`
+	Rules []targetingRuleDto `bson:"rules"`
+}
+
+type targetingRuleDto struct {
+	Conditions []targetingConditionDto `bson:"conditions,omitempty"`
+	Variant    string                  `bson:"variant,omitempty"`
+	Rollout    []rolloutShareDto       `bson:"rollout,omitempty"`
+}
+
+type targetingConditionDto struct {
+	Attribute string   `bson:"attribute"`
+	Operator  string   `bson:"operator"`
+	Values    []string `bson:"values"`
+}
+
+type rolloutShareDto struct {
+	Variant string `bson:"variant"`
+	Weight  int    `bson:"weight"`
 }
 
 // PropertyRepository is a representation of the property repository for
 // a mongo DBs.
 type PropertyRepository struct {
-	dbCollection *mongo.Collection
+	dbCollection      *mongo.Collection
+	historyCollection *mongo.Collection
+	cipher            *encryption.Cipher
 }
 
-// New retrieves a new repository object ready to be used.
-func New(db *mongo.Database) storage.Repository {
-	return &PropertyRepository{
-		dbCollection: db.Collection(propertiesCollectionName),
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values of secret properties and it can be nil, in which
+// case secret properties cannot be stored.
+func New(db *mongo.Database, cipher *encryption.Cipher) storage.Repository {
+	repo := &PropertyRepository{
+		dbCollection:      db.Collection(propertiesCollectionName),
+		historyCollection: db.Collection(propertiesHistoryCollectionName),
+		cipher:            cipher,
 	}
+	repo.createIndexes()
+	repo.versionProperties()
+
+	return repo
 }
 
-// Create a new entry based on the provided property.
+// versionProperties gives the first revision to the properties stored before the
+// properties were revised, so that their entity tags can be used by conditional
+// requests.
+func (repository PropertyRepository) versionProperties() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := repository.dbCollection.UpdateMany(ctx,
+		bson.M{"revision": bson.M{"$in": bson.A{nil, 0}}},
+		bson.M{"$set": bson.M{"revision": 1}})
+	if err != nil {
+		logger.Main.Error("Cannot revise the properties.", err)
+	}
+}
+
+// createIndexes ensures the unique index backing the lookups by name, including
+// the prefix queries, which are anchored and can therefore use it. The expired
+// properties and their revisions are removed by TTL indexes.
+func (repository PropertyRepository) createIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The name index used to allow duplicates, so it is replaced by a unique one.
+	repository.dbCollection.Indexes().DropOne(ctx, "namespace_1_name_1")
+
+	_, err := repository.dbCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "namespace", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetName("namespace_name_unique").SetUnique(true),
+	})
+	if err != nil {
+		logger.Main.Error("Cannot create the properties name index.", err)
+	}
+
+	_, err = repository.dbCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.Main.Error("Cannot create the properties expiry index.", err)
+	}
+
+	_, err = repository.historyCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "purge_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.Main.Error("Cannot create the properties history expiry index.", err)
+	}
+}
+
+// Create a new entry based on the provided property. The first revision of the
+// property is recorded along with it.
 func (repository PropertyRepository) Create(ctx context.Context, property *model.Property) error {
+	property.Revision = 1
+	value, err := storage.SealValue(repository.cipher, property.Secret, property.Value)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := storage.SealOverrides(repository.cipher, property.Secret, property.Overrides)
+	if err != nil {
+		return err
+	}
+
 	dto := convertToDto(property)
+	dto.Value = value
+	dto.Overrides = overrides
 
-	foundProp, _ := repository.FindByName(ctx, property.Name)
+	foundProp, _ := repository.FindByName(ctx, property.Namespace, property.Name)
 	if foundProp != nil {
 		return errors.NewConflict(reflect.TypeOf(foundProp), "name", property.Name)
 	}
 
+	if err := repository.releaseName(ctx, property); err != nil {
+		return err
+	}
+
 	result, err := repository.dbCollection.InsertOne(ctx, dto)
+	if isDuplicateKey(err) {
+		return errors.NewConflict(reflect.TypeOf(property), "name", property.Name)
+	}
+
 	if err != nil {
 		return err
 	}
 
 	property.ID = result.InsertedID.(primitive.ObjectID).Hex()
 
-	return nil
+	return repository.saveRevision(ctx, property, value, overrides)
 }
 
-// ReadAll retrieves all available properties.
-func (repository PropertyRepository) ReadAll(ctx context.Context) ([]*model.Property, error) {
-	cursor, error := repository.dbCollection.Find(ctx, bson.M{})
-	defer cursor.Close(ctx)
+// ReadAll retrieves the page of properties within the given namespace that
+// match the given filter.
+func (repository PropertyRepository) ReadAll(ctx context.Context, namespace string, filter storage.Filter) ([]*model.Property, model.PageInfo, error) {
+	query := withFilter(bson.M{"namespace": namespaceFilter(namespace)}, filter)
 
-	if error != nil {
-		return nil, error
+	return repository.find(ctx, query, filter.Page)
+}
+
+// ReadAllFiltered reads the page of properties within the given namespace that
+// match the given names and filter. The names may also be name patterns, which
+// are matched as regular expressions by the database.
+func (repository PropertyRepository) ReadAllFiltered(ctx context.Context, namespace string, names []string, filter storage.Filter) ([]*model.Property, model.PageInfo, error) {
+	membership := model.NewMembership(names)
+	members := bson.A{bson.M{"name": bson.M{"$in": membership.Names()}}}
+	for _, pattern := range membership.Patterns() {
+		members = append(members, bson.M{"name": primitive.Regex{Pattern: pattern.Expression()}})
 	}
 
-	result := make([]*propertyDto, 0)
+	query := withFilter(bson.M{
+		"namespace": namespaceFilter(namespace),
+		"$or":       members,
+	}, filter)
 
-	for cursor.Next(ctx) {
-		dto := new(propertyDto)
-		err := cursor.Decode(dto)
-		if err != nil {
-			return nil, err
-		}
+	return repository.find(ctx, query, filter.Page)
+}
 
-		result = append(result, dto)
+// find retrieves the page of properties matching the given query, along with
+// the total number of matching properties. One more property than the limit is
+// fetched to find out whether a next page exists.
+func (repository PropertyRepository) find(ctx context.Context, query bson.M, page model.Page) ([]*model.Property, model.PageInfo, error) {
+	total, err := repository.dbCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, model.PageInfo{}, err
 	}
 
-	return convertDtosToModel(result), nil
-}
+	order := 1
+	operator := "$gt"
+	if page.Descending {
+		order = -1
+		operator = "$lt"
+	}
 
-// ReadAllFiltered reads all available properties and filters them according to
-// the given names.
-func (repository PropertyRepository) ReadAllFiltered(ctx context.Context, names []string) ([]*model.Property, error) {
-	cursor, error := repository.dbCollection.Find(ctx, bson.M{"name": bson.M{"$in": names}})
-	defer cursor.Close(ctx)
+	if page.After != "" {
+		query = bson.M{"$and": bson.A{query, bson.M{"name": bson.M{operator: page.After}}}}
+	}
 
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: order}})
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit + 1))
+	}
+
+	cursor, error := repository.dbCollection.Find(ctx, query, opts)
 	if error != nil {
-		return nil, error
+		return nil, model.PageInfo{}, error
 	}
+	defer cursor.Close(ctx)
 
 	result := make([]*propertyDto, 0)
 
@@ -93,13 +279,24 @@ func (repository PropertyRepository) ReadAllFiltered(ctx context.Context, names
 		dto := new(propertyDto)
 		err := cursor.Decode(dto)
 		if err != nil {
-			return nil, err
+			return nil, model.PageInfo{}, err
 		}
 
 		result = append(result, dto)
 	}
 
-	return convertDtosToModel(result), nil
+	info := model.PageInfo{Total: int(total)}
+	if page.Limit > 0 && len(result) > page.Limit {
+		result = result[:page.Limit]
+		info.Next = result[page.Limit-1].Name
+	}
+
+	properties, err := repository.convertDtosToModel(result)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	return properties, info, nil
 }
 
 // FindByID retrieves the property matching the given id if such a property
@@ -109,7 +306,8 @@ func (repository PropertyRepository) FindByID(context context.Context, id string
 
 	result := new(propertyDto)
 	err := repository.dbCollection.FindOne(context, bson.M{
-		"_id": objID,
+		"_id":  objID,
+		"$and": bson.A{unexpired(time.Now())},
 	}).Decode(result)
 
 	if err != nil {
@@ -124,17 +322,28 @@ func (repository PropertyRepository) FindByID(context context.Context, id string
 		return nil, nil
 	}
 
-	return convertToModel(result), nil
+	return repository.convertToModel(result)
 }
 
-// FindByName retrieves the property matching the given name if such a property
-// exists; otherwise will return a not found error.
-func (repository PropertyRepository) FindByName(context context.Context, name string) (*model.Property, error) {
-	query := make(map[string]string)
-	query["name"] = name
+// FindByName retrieves the property matching the given name within the given
+// namespace if such a property exists; otherwise will return a not found error.
+func (repository PropertyRepository) FindByName(context context.Context, namespace string, name string) (*model.Property, error) {
+	result := new(propertyDto)
+	err := repository.dbCollection.FindOne(context, bson.M{
+		"namespace": namespaceFilter(namespace),
+		"name":      name,
+		"$and":      bson.A{unexpired(time.Now())},
+	}).Decode(result)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.Property{}, name)
+	}
 
-	return repository.findOneBy(context, &query)
+	if err != nil {
+		return nil, err
+	}
 
+	return repository.convertToModel(result)
 }
 
 func (repository PropertyRepository) findAllBy(ctx context.Context, queryValues *map[string]string) ([]*model.Property, error) {
@@ -163,7 +372,7 @@ func (repository PropertyRepository) findAllBy(ctx context.Context, queryValues
 		result = append(result, dto)
 	}
 
-	return convertDtosToModel(result), nil
+	return repository.convertDtosToModel(result)
 }
 
 func (repository PropertyRepository) findOneBy(context context.Context, queryValues *map[string]string) (*model.Property, error) {
@@ -184,57 +393,611 @@ func (repository PropertyRepository) findOneBy(context context.Context, queryVal
 		return nil, nil
 	}
 
-	return convertToModel(result), nil
+	return repository.convertToModel(result)
 }
 
-// Delete the property with the given id.
-func (repository PropertyRepository) Delete(context context.Context, id string) error {
+// Delete the property with the given id. Unless the given revision is zero, the
+// property must have that revision. Its revisions are kept, so that it can be
+// restored, until DeleteHistory removes them.
+func (repository PropertyRepository) Delete(context context.Context, id string, revision int) error {
 	objID, _ := primitive.ObjectIDFromHex(id)
 
-	_, err := repository.dbCollection.DeleteOne(context, bson.M{
-		"_id": objID})
+	result, err := repository.dbCollection.DeleteOne(context, withRevision(bson.M{"_id": objID}, revision))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return repository.notModified(context, id, revision)
+	}
+
+	return nil
+}
+
+// Restore recreates the given deleted property under its id. The property gets
+// the revision following its recorded ones, which is recorded along with it.
+func (repository PropertyRepository) Restore(ctx context.Context, property *model.Property) error {
+	objID, err := primitive.ObjectIDFromHex(property.ID)
+	if err != nil {
+		return err
+	}
+
+	last := new(propertyRevisionDto)
+	err = repository.historyCollection.FindOne(ctx,
+		bson.M{"property_id": property.ID},
+		options.FindOne().SetSort(bson.D{primitive.E{Key: "revision", Value: -1}})).Decode(last)
+
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	if last.Revision > property.Revision {
+		property.Revision = last.Revision
+	}
+	property.Revision++
+
+	value, err := storage.SealValue(repository.cipher, property.Secret, property.Value)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := storage.SealOverrides(repository.cipher, property.Secret, property.Overrides)
+	if err != nil {
+		return err
+	}
+
+	dto := convertToDto(property)
+	dto.ID = objID
+	dto.Value = value
+	dto.Overrides = overrides
+
+	if err := repository.releaseName(ctx, property); err != nil {
+		return err
+	}
+
+	_, err = repository.dbCollection.InsertOne(ctx, dto)
+	if isDuplicateKey(err) {
+		return errors.NewConflict(reflect.TypeOf(property), "name", property.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return repository.saveRevision(ctx, property, value, overrides)
+}
+
+// DeleteHistory removes all revisions of the deleted property with the given id.
+func (repository PropertyRepository) DeleteHistory(ctx context.Context, id string) error {
+	_, err := repository.historyCollection.DeleteMany(ctx, bson.M{"property_id": id})
 
 	return err
 }
 
-// Update all fields of the given property.
+// Update all fields of the given property. Unless the revision of the given
+// property is zero, the stored property must have that revision. The property
+// revision is incremented and the new state is recorded as a new revision.
 func (repository PropertyRepository) Update(ctx context.Context, property *model.Property) error {
 	objID, _ := primitive.ObjectIDFromHex(property.ID)
 
-	_, err := repository.dbCollection.UpdateOne(ctx,
-		bson.M{"_id": objID},
-		bson.D{primitive.E{Key: "$set", Value: bson.D{
-			primitive.E{Key: "name", Value: property.Name},
-			primitive.E{Key: "description", Value: property.Description},
-			primitive.E{Key: "value", Value: property.Value},
-		}}})
+	value, err := storage.SealValue(repository.cipher, property.Secret, property.Value)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := storage.SealOverrides(repository.cipher, property.Secret, property.Overrides)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.releaseName(ctx, property); err != nil {
+		return err
+	}
+
+	updated := new(propertyDto)
+	err = repository.dbCollection.FindOneAndUpdate(ctx,
+		withRevision(bson.M{"_id": objID}, property.Revision),
+		bson.D{
+			primitive.E{Key: "$set", Value: bson.D{
+				primitive.E{Key: "name", Value: property.Name},
+				primitive.E{Key: "description", Value: property.Description},
+				primitive.E{Key: "type", Value: string(property.Type)},
+				primitive.E{Key: "value", Value: value},
+				primitive.E{Key: "secret", Value: property.Secret},
+				primitive.E{Key: "labels", Value: convertLabelsToDto(property.Labels)},
+				primitive.E{Key: "overrides", Value: overrides},
+				primitive.E{Key: "constraint", Value: convertConstraintToDto(property.Constraint)},
+				primitive.E{Key: "targeting", Value: convertTargetingToDto(property.Targeting)},
+				primitive.E{Key: "expires_at", Value: convertTimeToDto(property.ExpiresAt)},
+			}},
+			primitive.E{Key: "$inc", Value: bson.D{
+				primitive.E{Key: "revision", Value: 1},
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(updated)
+
+	if err == mongo.ErrNoDocuments {
+		return repository.notModified(ctx, property.ID, property.Revision)
+	}
+
+	if isDuplicateKey(err) {
+		return errors.NewConflict(reflect.TypeOf(property), "name", property.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	property.Revision = updated.Revision
+
+	return repository.saveRevision(ctx, property, value, overrides)
+}
+
+// ReadHistory retrieves all recorded revisions of the property with the given
+// id, ordered from the oldest to the newest.
+func (repository PropertyRepository) ReadHistory(ctx context.Context, id string) ([]*model.PropertyRevision, error) {
+	cursor, err := repository.historyCollection.Find(ctx,
+		bson.M{"property_id": id},
+		options.Find().SetSort(bson.D{primitive.E{Key: "revision", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]*model.PropertyRevision, 0)
+
+	for cursor.Next(ctx) {
+		dto := new(propertyRevisionDto)
+		if err := cursor.Decode(dto); err != nil {
+			return nil, err
+		}
+
+		revision, err := repository.convertRevisionToModel(dto)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, revision)
+	}
+
+	return result, nil
+}
+
+// FindRevision retrieves a single revision of the property with the given id.
+func (repository PropertyRepository) FindRevision(ctx context.Context, id string, revision int) (*model.PropertyRevision, error) {
+	result := new(propertyRevisionDto)
+	err := repository.historyCollection.FindOne(ctx, bson.M{
+		"property_id": id,
+		"revision":    revision,
+	}).Decode(result)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.PropertyRevision{}, fmt.Sprintf("%s@%d", id, revision))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertRevisionToModel(result)
+}
+
+// FindRevisionAt retrieves the revision of the property with the given id that
+// was active at the given moment in time.
+func (repository PropertyRepository) FindRevisionAt(ctx context.Context, id string, at time.Time) (*model.PropertyRevision, error) {
+	result := new(propertyRevisionDto)
+	err := repository.historyCollection.FindOne(ctx,
+		bson.M{
+			"property_id": id,
+			"timestamp":   bson.M{"$lte": at},
+		},
+		options.FindOne().SetSort(bson.D{primitive.E{Key: "revision", Value: -1}})).Decode(result)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.PropertyRevision{}, fmt.Sprintf("%s@%s", id, at.Format(time.RFC3339)))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertRevisionToModel(result)
+}
+
+// DeleteExpired removes the properties that have expired at the given moment in
+// time, along with all their revisions, and retrieves the removed properties.
+// The properties already removed by the TTL indexes are not retrieved.
+func (repository PropertyRepository) DeleteExpired(ctx context.Context, at time.Time) ([]*model.Property, error) {
+	cursor, err := repository.dbCollection.Find(ctx, bson.M{"expires_at": bson.M{"$lte": at}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]*propertyDto, 0)
+	for cursor.Next(ctx) {
+		dto := new(propertyDto)
+		if err := cursor.Decode(dto); err != nil {
+			return nil, err
+		}
+
+		result = append(result, dto)
+	}
+
+	properties, err := repository.convertDtosToModel(result)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]*model.Property, 0, len(properties))
+	for _, property := range properties {
+		objID, _ := primitive.ObjectIDFromHex(property.ID)
+
+		deleted, err := repository.dbCollection.DeleteOne(ctx, bson.M{"_id": objID})
+		if err != nil {
+			return nil, err
+		}
+
+		if deleted.DeletedCount == 0 {
+			continue
+		}
+
+		if _, err := repository.historyCollection.DeleteMany(ctx, bson.M{"property_id": property.ID}); err != nil {
+			return nil, err
+		}
+
+		removed = append(removed, property)
+	}
+
+	return removed, nil
+}
+
+// saveRevision records the current state of the given property. The value and
+// the overrides are expected to be already sealed. All revisions of the
+// property are given its expiry time.
+func (repository PropertyRepository) saveRevision(ctx context.Context, property *model.Property, value string, overrides map[string]string) error {
+	dto := convertRevisionToDto(model.NewPropertyRevision(property, time.Now()))
+	dto.Value = value
+	dto.Overrides = overrides
+
+	if _, err := repository.historyCollection.InsertOne(ctx, dto); err != nil {
+		return err
+	}
+
+	_, err := repository.historyCollection.UpdateMany(ctx,
+		bson.M{"property_id": property.ID},
+		bson.M{"$set": bson.M{"purge_at": convertTimeToDto(property.ExpiresAt)}})
+
+	return err
+}
+
+// withRevision adds the required revision to the given filter, unless it is
+// zero.
+func withRevision(filter bson.M, revision int) bson.M {
+	if revision != 0 {
+		filter["revision"] = revision
+	}
+
+	return filter
+}
+
+// notModified retrieves the error explaining why the property with the given id
+// was not modified by a conditional operation: either it does not exist or it
+// does not have the required revision.
+func (repository PropertyRepository) notModified(ctx context.Context, id string, revision int) error {
+	if _, err := repository.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	return errors.NewPreconditionFailed(model.Property{}, id, revision)
+}
+
+// namespaceFilter retrieves the filter value matching the given namespace.
+// Properties of the default namespace are stored without a namespace.
+func namespaceFilter(namespace string) interface{} {
+	if namespace == ns.Default {
+		return nil
+	}
+
+	return namespace
+}
+
+// releaseName removes the expired property still using the name of the given
+// property, which the TTL index might not have removed yet, so that the name can
+// be used again.
+func (repository PropertyRepository) releaseName(ctx context.Context, property *model.Property) error {
+	_, err := repository.dbCollection.DeleteMany(ctx, bson.M{
+		"namespace":  namespaceFilter(property.Namespace),
+		"name":       property.Name,
+		"expires_at": bson.M{"$lte": time.Now()},
+	})
 
 	return err
 }
 
+func isDuplicateKey(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "duplicate key error collection")
+}
+
+// withFilter adds the conditions of the given filter to the query. The prefix is
+// matched by an anchored regular expression, so that the name index is used.
+// The expired properties, which might not have been removed yet, are excluded.
+func withFilter(query bson.M, filter storage.Filter) bson.M {
+	conditions := bson.A{unexpired(time.Now())}
+	if filter.Prefix != "" {
+		pattern := "^" + regexp.QuoteMeta(filter.Prefix) + `(\.|$)`
+		conditions = append(conditions, bson.M{"name": primitive.Regex{Pattern: pattern}})
+	}
+
+	conditions = append(conditions, selectorConditions(filter.Selector)...)
+	query["$and"] = conditions
+
+	return query
+}
+
+// unexpired retrieves the condition matching the properties that have not
+// expired at the given moment in time, including the ones without expiry time.
+func unexpired(at time.Time) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"expires_at": nil},
+		bson.M{"expires_at": bson.M{"$gt": at}},
+	}}
+}
+
+func selectorConditions(selector model.LabelSelector) bson.A {
+	conditions := bson.A{}
+	for _, requirement := range selector {
+		label := bson.M{"key": requirement.Key}
+
+		switch requirement.Operator {
+		case model.OperatorEquals, model.OperatorNotEquals:
+			label["value"] = requirement.Values[0]
+		case model.OperatorIn, model.OperatorNotIn:
+			label["value"] = bson.M{"$in": requirement.Values}
+		}
+
+		match := bson.M{"$elemMatch": label}
+
+		switch requirement.Operator {
+		case model.OperatorNotEquals, model.OperatorNotIn, model.OperatorDoesNotExist:
+			match = bson.M{"$not": match}
+		}
+
+		conditions = append(conditions, bson.M{"labels": match})
+	}
+
+	return conditions
+}
+
+func convertLabelsToDto(labels map[string]string) []labelDto {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]labelDto, len(keys))
+	for i, key := range keys {
+		result[i] = labelDto{Key: key, Value: labels[key]}
+	}
+
+	return result
+}
+
+func convertLabelsToModel(dtos []labelDto) map[string]string {
+	if len(dtos) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(dtos))
+	for _, dto := range dtos {
+		result[dto.Key] = dto.Value
+	}
+
+	return result
+}
+
+// convertTimeToDto retrieves the given time in the form in which it is stored;
+// the zero time is not stored at all.
+func convertTimeToDto(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// convertTimeToModel reverses convertTimeToDto.
+func convertTimeToModel(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
+
+func convertConstraintToDto(constraint *model.Constraint) *constraintDto {
+	if constraint == nil {
+		return nil
+	}
+
+	return &constraintDto{
+		Min:     constraint.Min,
+		Max:     constraint.Max,
+		Enum:    constraint.Enum,
+		Pattern: constraint.Pattern,
+		Schema:  constraint.Schema,
+	}
+}
+
+func convertConstraintToModel(dto *constraintDto) *model.Constraint {
+	if dto == nil {
+		return nil
+	}
+
+	return &model.Constraint{
+		Min:     dto.Min,
+		Max:     dto.Max,
+		Enum:    dto.Enum,
+		Pattern: dto.Pattern,
+		Schema:  dto.Schema,
+	}
+}
+
+func convertTargetingToDto(targeting *model.Targeting) *targetingDto {
+	if targeting == nil {
+		return nil
+	}
+
+	dto := &targetingDto{Key: targeting.Key, Rules: make([]targetingRuleDto, len(targeting.Rules))}
+	for i, rule := range targeting.Rules {
+		dto.Rules[i].Variant = rule.Variant
+
+		for _, condition := range rule.Conditions {
+			dto.Rules[i].Conditions = append(dto.Rules[i].Conditions, targetingConditionDto{
+				Attribute: condition.Attribute,
+				Operator:  string(condition.Operator),
+				Values:    condition.Values,
+			})
+		}
+
+		for _, share := range rule.Rollout {
+			dto.Rules[i].Rollout = append(dto.Rules[i].Rollout, rolloutShareDto{Variant: share.Variant, Weight: share.Weight})
+		}
+	}
+
+	return dto
+}
+
+func convertTargetingToModel(dto *targetingDto) *model.Targeting {
+	if dto == nil {
+		return nil
+	}
+
+	targeting := &model.Targeting{Key: dto.Key, Rules: make([]model.TargetingRule, len(dto.Rules))}
+	for i, rule := range dto.Rules {
+		targeting.Rules[i].Variant = rule.Variant
+
+		for _, condition := range rule.Conditions {
+			targeting.Rules[i].Conditions = append(targeting.Rules[i].Conditions, model.TargetingCondition{
+				Attribute: condition.Attribute,
+				Operator:  model.ConditionOperator(condition.Operator),
+				Values:    condition.Values,
+			})
+		}
+
+		for _, share := range rule.Rollout {
+			targeting.Rules[i].Rollout = append(targeting.Rules[i].Rollout, model.RolloutShare{Variant: share.Variant, Weight: share.Weight})
+		}
+	}
+
+	return targeting
+}
+
 func convertToDto(property *model.Property) *propertyDto {
 	return &propertyDto{
+		Namespace:   property.Namespace,
 		Name:        property.Name,
 		Description: property.Description,
+		Type:        string(property.Type),
 		Value:       property.Value,
+		Secret:      property.Secret,
+		Labels:      convertLabelsToDto(property.Labels),
+		Constraint:  convertConstraintToDto(property.Constraint),
+		Targeting:   convertTargetingToDto(property.Targeting),
+		ExpiresAt:   convertTimeToDto(property.ExpiresAt),
+		Revision:    property.Revision,
 	}
 }
 
-func convertDtosToModel(dtos []*propertyDto) []*model.Property {
+func (repository PropertyRepository) convertDtosToModel(dtos []*propertyDto) ([]*model.Property, error) {
 	result := make([]*model.Property, len(dtos))
 
 	for index, dto := range dtos {
-		result[index] = convertToModel(dto)
+		property, err := repository.convertToModel(dto)
+		if err != nil {
+			return nil, err
+		}
+
+		result[index] = property
 	}
 
-	return result
+	return result, nil
 }
 
-func convertToModel(dto *propertyDto) *model.Property {
+func (repository PropertyRepository) convertToModel(dto *propertyDto) (*model.Property, error) {
+	value, err := storage.OpenValue(repository.cipher, dto.Secret, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := storage.OpenOverrides(repository.cipher, dto.Secret, dto.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
 	return &model.Property{
 		ID:          dto.ID.Hex(),
+		Namespace:   dto.Namespace,
 		Name:        dto.Name,
 		Description: dto.Description,
-		Value:       dto.Value,
+		Type:        model.PropertyType(dto.Type),
+		Value:       value,
+		Secret:      dto.Secret,
+		Labels:      convertLabelsToModel(dto.Labels),
+		Overrides:   overrides,
+		Constraint:  convertConstraintToModel(dto.Constraint),
+		Targeting:   convertTargetingToModel(dto.Targeting),
+		ExpiresAt:   convertTimeToModel(dto.ExpiresAt),
+		Revision:    dto.Revision,
+	}, nil
+}
+
+func convertRevisionToDto(revision *model.PropertyRevision) *propertyRevisionDto {
+	return &propertyRevisionDto{
+		PropertyID:  revision.PropertyID,
+		Revision:    revision.Revision,
+		Name:        revision.Name,
+		Description: revision.Description,
+		Type:        string(revision.Type),
+		Value:       revision.Value,
+		Secret:      revision.Secret,
+		Labels:      convertLabelsToDto(revision.Labels),
+		Constraint:  convertConstraintToDto(revision.Constraint),
+		Targeting:   convertTargetingToDto(revision.Targeting),
+		ExpiresAt:   convertTimeToDto(revision.ExpiresAt),
+		Timestamp:   revision.Timestamp,
 	}
 }
+
+func (repository PropertyRepository) convertRevisionToModel(dto *propertyRevisionDto) (*model.PropertyRevision, error) {
+	value, err := storage.OpenValue(repository.cipher, dto.Secret, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := storage.OpenOverrides(repository.cipher, dto.Secret, dto.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PropertyRevision{
+		PropertyID:  dto.PropertyID,
+		Revision:    dto.Revision,
+		Name:        dto.Name,
+		Description: dto.Description,
+		Type:        model.PropertyType(dto.Type),
+		Value:       value,
+		Secret:      dto.Secret,
+		Labels:      convertLabelsToModel(dto.Labels),
+		Overrides:   overrides,
+		Constraint:  convertConstraintToModel(dto.Constraint),
+		Targeting:   convertTargetingToModel(dto.Targeting),
+		ExpiresAt:   convertTimeToModel(dto.ExpiresAt),
+		Timestamp:   dto.Timestamp,
+	}, nil
+}
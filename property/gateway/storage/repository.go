@@ -2,23 +2,75 @@ package storage
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/rghiorghisor/basic-go-rest-api/model"
 )
 
+// Filter further restricts the properties retrieved by the read operations of
+// a repository. The zero value does not restrict the results.
+type Filter struct {
+	// Prefix restricts the results to the subtree of the given dotted name, i.e.
+	// to the name itself and to the names starting with the name and a dot.
+	Prefix string
+
+	// Selector restricts the results to the properties whose labels match it.
+	Selector model.LabelSelector
+
+	// Page restricts the results, sorted by name, to a single page.
+	Page model.Page
+}
+
+// MatchesPrefix checks whether the given name belongs to the subtree defined by
+// the filter prefix.
+func (filter Filter) MatchesPrefix(name string) bool {
+	if filter.Prefix == "" {
+		return true
+	}
+
+	return name == filter.Prefix || strings.HasPrefix(name, filter.Prefix+".")
+}
+
 // Repository interface defining the functionality of a basic implementations.
+//
+// Update and Delete are conditional: unless the given revision is zero, they
+// fail with a precondition failed error if the stored property has another
+// revision. The check and the change are performed atomically.
+//
+// Delete keeps the revisions of the deleted property, so that Restore can bring
+// the property back under the same id and with its history, until
+// DeleteHistory removes them.
+//
+// The expired properties are never retrieved by the read operations, even
+// before DeleteExpired physically removes them.
+//
+// ReadAllFiltered accepts both plain names and name patterns (see
+// model.NamePattern) and matches the patterns within the storage.
 type Repository interface {
 	Create(ctx context.Context, property *model.Property) error
 
-	ReadAll(ctx context.Context) ([]*model.Property, error)
+	ReadAll(ctx context.Context, namespace string, filter Filter) ([]*model.Property, model.PageInfo, error)
 
-	ReadAllFiltered(ctx context.Context, names []string) ([]*model.Property, error)
+	ReadAllFiltered(ctx context.Context, namespace string, names []string, filter Filter) ([]*model.Property, model.PageInfo, error)
 
 	FindByID(context context.Context, id string) (*model.Property, error)
 
-	FindByName(context context.Context, name string) (*model.Property, error)
+	FindByName(context context.Context, namespace string, name string) (*model.Property, error)
 
-	Delete(context context.Context, id string) error
+	Delete(context context.Context, id string, revision int) error
 
 	Update(ctx context.Context, property *model.Property) error
+
+	Restore(ctx context.Context, property *model.Property) error
+
+	DeleteHistory(ctx context.Context, id string) error
+
+	ReadHistory(ctx context.Context, id string) ([]*model.PropertyRevision, error)
+
+	FindRevision(ctx context.Context, id string, revision int) (*model.PropertyRevision, error)
+
+	FindRevisionAt(ctx context.Context, id string, at time.Time) (*model.PropertyRevision, error)
+
+	DeleteExpired(ctx context.Context, at time.Time) ([]*model.Property, error)
 }
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"reflect"
+
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// SealValue retrieves the form in which a property value must be stored. Values
+// of secret properties are encrypted using the given cipher; any other values
+// are retrieved unchanged.
+func SealValue(cipher *encryption.Cipher, secret bool, value string) (string, error) {
+	if !secret {
+		return value, nil
+	}
+
+	sealed, err := cipher.Encrypt(value)
+	if err == encryption.ErrNoKey {
+		return "", errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "'secret' properties require an encryption key to be configured.")
+	}
+
+	return sealed, err
+}
+
+// OpenValue reverses SealValue, retrieving the actual value of a stored property.
+func OpenValue(cipher *encryption.Cipher, secret bool, value string) (string, error) {
+	if !secret {
+		return value, nil
+	}
+
+	return cipher.Decrypt(value)
+}
+
+// SealOverrides applies SealValue to each of the given profile overrides.
+func SealOverrides(cipher *encryption.Cipher, secret bool, overrides map[string]string) (map[string]string, error) {
+	return transformOverrides(overrides, func(value string) (string, error) {
+		return SealValue(cipher, secret, value)
+	})
+}
+
+// OpenOverrides reverses SealOverrides.
+func OpenOverrides(cipher *encryption.Cipher, secret bool, overrides map[string]string) (map[string]string, error) {
+	return transformOverrides(overrides, func(value string) (string, error) {
+		return OpenValue(cipher, secret, value)
+	})
+}
+
+func transformOverrides(overrides map[string]string, transform func(string) (string, error)) (map[string]string, error) {
+	if overrides == nil {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(overrides))
+	for profile, value := range overrides {
+		transformed, err := transform(value)
+		if err != nil {
+			return nil, err
+		}
+
+		result[profile] = transformed
+	}
+
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package property
+
+// ConflictStrategy defines how an import handles the entries whose names are
+// already used by existing properties.
+type ConflictStrategy string
+
+// All strategies that can be used to handle import conflicts.
+const (
+	// ConflictSkip leaves the existing properties unchanged.
+	ConflictSkip ConflictStrategy = "skip"
+
+	// ConflictOverwrite replaces the values of the existing properties.
+	ConflictOverwrite ConflictStrategy = "overwrite"
+
+	// ConflictFail rejects the whole import, before any change is made, if any
+	// of the entries conflicts with an existing property.
+	ConflictFail ConflictStrategy = "fail"
+)
+
+// IsValidConflictStrategy checks whether the given strategy is known.
+func IsValidConflictStrategy(strategy ConflictStrategy) bool {
+	return strategy == ConflictSkip || strategy == ConflictOverwrite || strategy == ConflictFail
+}
+
+// ImportOptions contains the parameters of an import.
+type ImportOptions struct {
+	// Conflict defines how the entries matching existing properties are handled.
+	Conflict ConflictStrategy
+
+	// Set is the id of a property set that must contain all imported names. The
+	// set is created if it does not exist. Empty if no set must be changed.
+	Set string
+}
+
+// ImportStatus defines the outcome of a single import entry.
+type ImportStatus string
+
+// All outcomes of an import entry.
+const (
+	ImportCreated ImportStatus = "created"
+	ImportUpdated ImportStatus = "updated"
+	ImportSkipped ImportStatus = "skipped"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// ImportEntry describes the outcome of importing a single property.
+type ImportEntry struct {
+	Name   string
+	Status ImportStatus
+	Error  error
+}
+
+// ImportReport describes the outcome of an import, entry by entry, in the order
+// in which the entries were imported.
+type ImportReport struct {
+	Entries []ImportEntry
+}
+
+// Count retrieves the number of entries having the given status.
+func (report *ImportReport) Count(status ImportStatus) int {
+	count := 0
+	for _, entry := range report.Entries {
+		if entry.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Names retrieves the names of all entries that did not fail, i.e. of all
+// properties that exist after the import.
+func (report *ImportReport) Names() []string {
+	names := make([]string, 0, len(report.Entries))
+	for _, entry := range report.Entries {
+		if entry.Status != ImportFailed {
+			names = append(names, entry.Name)
+		}
+	}
+
+	return names
+}
+
+func (report *ImportReport) add(name string, status ImportStatus, err error) {
+	report.Entries = append(report.Entries, ImportEntry{Name: name, Status: status, Error: err})
+}
+
+// Created records an entry that was imported as a new property.
+func (report *ImportReport) Created(name string) {
+	report.add(name, ImportCreated, nil)
+}
+
+// Updated records an entry that overwrote an existing property.
+func (report *ImportReport) Updated(name string) {
+	report.add(name, ImportUpdated, nil)
+}
+
+// Skipped records an entry that was ignored in favor of an existing property.
+func (report *ImportReport) Skipped(name string) {
+	report.add(name, ImportSkipped, nil)
+}
+
+// Failed records an entry that could not be imported.
+func (report *ImportReport) Failed(name string, err error) {
+	report.add(name, ImportFailed, err)
+}
@@ -2,7 +2,9 @@ package property
 
 import (
 	"strings"
+	"time"
 
+	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/util"
 )
 
@@ -11,9 +13,17 @@ var EmptyQuery = Query{}
 
 // Query contains parameters that can be used to filter or search certain results.
 type Query struct {
-	ID     string
-	Set    string
-	Fields Fields
+	ID       string
+	Set      string
+	Release  int
+	Prefix   string
+	Selector model.LabelSelector
+	Profiles []string
+	AsOf     time.Time
+	Reveal   bool
+	Raw      bool
+	Fields   Fields
+	Page     model.Page
 }
 
 // Fields contains any field names that must be returned.
@@ -64,3 +74,38 @@ func (q Query) HasSet() bool {
 func (q Query) GetSet() string {
 	return q.Set
 }
+
+// HasRelease retrieves true if the Query targets a release of the defined set,
+// false otherwise.
+func (q Query) HasRelease() bool {
+	return q.HasSet() && q.Release > 0
+}
+
+// GetRelease retrieves the number of the targeted release. The call to this func
+// should be preceded by a call to the Query.HasRelease method.
+func (q Query) GetRelease() int {
+	return q.Release
+}
+
+// HasAsOf retrieves true if the Query targets a past moment in time, false otherwise.
+func (q Query) HasAsOf() bool {
+	return !q.AsOf.IsZero()
+}
+
+// GetAsOf retrieves the moment in time targeted by the Query. The call to this
+// func should be preceded by a call to the Query.HasAsOf method.
+func (q Query) GetAsOf() time.Time {
+	return q.AsOf
+}
+
+// GetSelector retrieves the label selector the results must match. An empty
+// selector matches all results.
+func (q Query) GetSelector() model.LabelSelector {
+	return q.Selector
+}
+
+// GetPrefix retrieves the dotted name whose subtree the results must belong to.
+// An empty prefix matches all results.
+func (q Query) GetPrefix() string {
+	return q.Prefix
+}
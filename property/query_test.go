@@ -2,6 +2,7 @@ package property
 
 import (
 	"testing"
+	"time"
 
 	"gopkg.in/go-playground/assert.v1"
 )
@@ -36,3 +37,22 @@ func TestHasSet(t *testing.T) {
 	assert.Equal(t, true, query.HasSet())
 	assert.Equal(t, "set-test", query.GetSet())
 }
+
+func TestHasRelease(t *testing.T) {
+	query := &Query{Release: 3}
+
+	assert.Equal(t, false, query.HasRelease())
+	query.Set = "set-test"
+	assert.Equal(t, true, query.HasRelease())
+	assert.Equal(t, 3, query.GetRelease())
+}
+
+func TestHasAsOf(t *testing.T) {
+	query := &Query{}
+
+	assert.Equal(t, false, query.HasAsOf())
+	at := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	query.AsOf = at
+	assert.Equal(t, true, query.HasAsOf())
+	assert.Equal(t, at, query.GetAsOf())
+}
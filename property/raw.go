@@ -0,0 +1,21 @@
+package property
+
+import "context"
+
+type rawContextKey struct{}
+
+// NewRawContext retrieves a copy of the given context in which the properties
+// are retrieved as they are stored, i.e. without interpolating the references
+// to other properties. It must be used when a retrieved property is changed and
+// written back.
+func NewRawContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rawContextKey{}, true)
+}
+
+// IsRaw checks whether the properties must be retrieved without interpolation
+// within the given context.
+func IsRaw(ctx context.Context) bool {
+	raw, _ := ctx.Value(rawContextKey{}).(bool)
+
+	return raw
+}
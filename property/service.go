@@ -2,19 +2,33 @@ package property
 
 import (
 	"context"
+	"time"
 
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
 )
 
 // Service defines the use case available for properties
 type Service interface {
 	Create(ctx context.Context, property *model.Property) error
 
-	ReadAll(ctx context.Context, query Query) ([]*model.Property, error)
+	ReadAll(ctx context.Context, query Query) ([]*model.Property, model.PageInfo, error)
 
 	FindByID(ctx context.Context, id string) (*model.Property, error)
 
-	Delete(ctx context.Context, id string) error
+	FindByIDAt(ctx context.Context, id string, at time.Time) (*model.Property, error)
+
+	FindByName(ctx context.Context, name string) (*model.Property, error)
+
+	History(ctx context.Context, id string) ([]*model.PropertyRevision, error)
+
+	Rollback(ctx context.Context, id string, revision int) (*model.Property, error)
+
+	Delete(ctx context.Context, id string, revision int) error
 
 	Update(ctx context.Context, property *model.Property) error
+
+	Import(ctx context.Context, properties []*model.Property, options ImportOptions) (*ImportReport, error)
+
+	Watch(ctx context.Context, query Query) (*watch.Subscription, error)
 }
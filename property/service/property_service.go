@@ -2,96 +2,846 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/config"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
 	"github.com/rghiorghisor/basic-go-rest-api/property"
 	"github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	releasestorage "github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage"
 	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
 )
 
 // PropertyService defines the service handling property operations.
 type PropertyService struct {
 	validators validators
 	repository storage.Repository
+	releases   releasestorage.Repository
+	transactor transaction.Transactor
 
 	setService propertyset.Service
+	hub        *watch.Hub
+	auditor    audit.Service
+	trash      trash.Service
 }
 
-// New creates a PropertyService.
+// New creates a PropertyService and starts removing the expired properties at
+// the configured interval, unless the interval is not positive.
 //
 // As this service needs access to a repository to perform action, it is the
 // responsibility of the service to get the correct repo from the storage parameter.
-func New(storage *serverstorage.Storage, setService propertyset.Service) property.Service {
-	return PropertyService{
+// All changes are recorded by the given auditor and published to the given hub.
+// The deleted properties are moved to the given trash, within the same
+// transaction as their deletion.
+func New(storage *serverstorage.Storage, setService propertyset.Service, hub *watch.Hub, auditor audit.Service, trashService trash.Service, configuration *config.ExpiryConfiguration) property.Service {
+	service := PropertyService{
 		validators: newValidators(),
 		repository: storage.PropertyRepository,
+		releases:   storage.ReleaseRepository,
+		transactor: storage.Transactor,
 		setService: setService,
+		hub:        hub,
+		auditor:    auditor,
+		trash:      trashService,
 	}
+
+	if configuration.SweepInterval > 0 {
+		go service.sweep(configuration.SweepInterval)
+	}
+
+	return service
 }
 
-// Create processes a new property and adds it to the repository.
+// Create processes a new property and adds it to the repository. The property
+// is placed in the namespace of the given context and its name must be unique
+// within that namespace.
 func (service PropertyService) Create(ctx context.Context, prop *model.Property) error {
+	prop.Namespace = namespace.FromContext(ctx)
 	if err := service.validators.check(prop); err != nil {
 		return err
 	}
 
-	foundProp, _ := service.repository.FindByName(ctx, prop.Name)
+	foundProp, _ := service.repository.FindByName(ctx, prop.Namespace, prop.Name)
 	if foundProp != nil {
 		return errors.NewConflict(reflect.TypeOf(foundProp), "name", prop.Name)
 	}
 
-	return service.repository.Create(ctx, prop)
+	if err := service.checkReferences(ctx, prop); err != nil {
+		return err
+	}
+
+	return service.insert(ctx, prop)
 }
 
-// ReadAll retrieves all available properties, based on the provided query.
+// ReadAll retrieves a page of the available properties of the namespace of the
+// given context, based on the provided query.
 //
 // The Query.Set defines the set of properties to be used. In case such a set
 // is defined, the names from the set will be used to filter the results;
-// otherwise, all properties are retrieved.
-func (service PropertyService) ReadAll(ctx context.Context, query property.Query) ([]*model.Property, error) {
-	var filterValues []string
-	var err error
+// otherwise, all properties are retrieved. The set is resolved within the same
+// namespace. The Query.Prefix restricts the results to the subtree of the given
+// dotted name and the Query.Selector to the properties whose labels match it.
+// The Query.Page defines the order of the results and the page to retrieve.
+//
+// The Query.Release pins the results to the given release of the set, whose
+// properties are retrieved as they were frozen, regardless of any later change.
+//
+// The references to other properties are interpolated, unless the Query.Raw is
+// set or the context is a raw one. See FindByID for details. The properties of
+// a release are already interpolated.
+func (service PropertyService) ReadAll(ctx context.Context, query property.Query) ([]*model.Property, model.PageInfo, error) {
+	props, info, err := service.readAll(ctx, query)
+	if err != nil || query.Raw || query.HasRelease() || property.IsRaw(ctx) {
+		return props, info, err
+	}
+
+	props, err = service.interpolate(ctx, props...)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	return props, info, nil
+}
+
+func (service PropertyService) readAll(ctx context.Context, query property.Query) ([]*model.Property, model.PageInfo, error) {
+	ns := namespace.FromContext(ctx)
+	filter := storage.Filter{Prefix: query.GetPrefix(), Selector: query.GetSelector(), Page: query.Page}
+
+	if query.HasRelease() {
+		return service.readRelease(ctx, ns, query.GetSet(), query.GetRelease(), filter)
+	}
+
 	if query.HasSet() {
-		filterValues, err = service.setService.FindValuesByID(ctx, query.GetSet())
+		filterValues, err := service.setService.FindValuesByID(ctx, query.GetSet())
 		if err != nil {
-			return nil, err
+			return nil, model.PageInfo{}, err
 		}
 
-		return service.repository.ReadAllFiltered(ctx, filterValues)
+		return service.repository.ReadAllFiltered(ctx, ns, filterValues, filter)
 	}
 
-	return service.repository.ReadAll(ctx)
+	return service.repository.ReadAll(ctx, ns, filter)
+}
+
+// readRelease retrieves the page of the properties of the given release that
+// match the given filter.
+func (service PropertyService) readRelease(ctx context.Context, ns string, set string, number int, filter storage.Filter) ([]*model.Property, model.PageInfo, error) {
+	release, err := service.releases.Find(ctx, ns, set, number)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	props := make([]*model.Property, 0, len(release.Properties))
+	for _, prop := range release.Properties {
+		if filter.MatchesPrefix(prop.Name) && filter.Selector.Matches(prop.Labels) {
+			props = append(props, prop)
+		}
+	}
+
+	sort.Slice(props, func(i, j int) bool {
+		return filter.Page.Less(props[i].Name, props[j].Name)
+	})
+
+	names := make([]string, len(props))
+	for i, prop := range props {
+		names[i] = prop.Name
+	}
+
+	from, to, info := filter.Page.Window(names)
+
+	return props[from:to], info, nil
 }
 
 // FindByID retrieves the property matching the given id if such a property
-// exists; otherwise will return a not found error.
+// exists within the namespace of the given context; otherwise will return a not
+// found error.
+//
+// Unless the context is a raw one, the '${name}' references of the value and
+// overrides of the property are replaced by the values of the referenced
+// properties of the same namespace. An invalid entity error is returned if the
+// references form a cycle.
 func (service PropertyService) FindByID(ctx context.Context, id string) (*model.Property, error) {
+	foundProp, err := service.find(ctx, id)
+	if err != nil || property.IsRaw(ctx) {
+		return foundProp, err
+	}
+
+	props, err := service.interpolate(ctx, foundProp)
+	if err != nil {
+		return nil, err
+	}
+
+	return props[0], nil
+}
+
+// find retrieves the property matching the given id, as it is stored.
+func (service PropertyService) find(ctx context.Context, id string) (*model.Property, error) {
 	foundProp, err := service.repository.FindByID(ctx, id)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if foundProp == nil {
+	if foundProp == nil || foundProp.Namespace != namespace.FromContext(ctx) {
 		return nil, errors.NewEntityNotFound(model.Property{}, id)
 	}
 
 	return foundProp, nil
 }
 
-// Delete the property with the given id.
-func (service PropertyService) Delete(ctx context.Context, id string) error {
-	return service.repository.Delete(ctx, id)
+// FindByIDAt retrieves the property matching the given id, as it was at the
+// given moment in time. Unless the context is a raw one, the references are
+// interpolated using the current values of the referenced properties.
+func (service PropertyService) FindByIDAt(ctx context.Context, id string, at time.Time) (*model.Property, error) {
+	foundProp, err := service.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := service.repository.FindRevisionAt(ctx, id, at)
+	if err != nil {
+		return nil, err
+	}
+
+	result := revision.Property()
+	result.Namespace = foundProp.Namespace
+
+	if property.IsRaw(ctx) {
+		return result, nil
+	}
+
+	props, err := service.interpolate(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return props[0], nil
+}
+
+// FindByName retrieves the property matching the given name if such a property
+// exists within the namespace of the given context; otherwise will return a not
+// found error. The references are interpolated just like FindByID does.
+func (service PropertyService) FindByName(ctx context.Context, name string) (*model.Property, error) {
+	foundProp, err := service.repository.FindByName(ctx, namespace.FromContext(ctx), name)
+	if err != nil {
+		return nil, err
+	}
+
+	if foundProp == nil {
+		return nil, errors.NewEntityNotFound(model.Property{}, name)
+	}
+
+	if property.IsRaw(ctx) {
+		return foundProp, nil
+	}
+
+	props, err := service.interpolate(ctx, foundProp)
+	if err != nil {
+		return nil, err
+	}
+
+	return props[0], nil
+}
+
+// History retrieves all recorded revisions of the property matching the given
+// id, ordered from the oldest to the newest.
+func (service PropertyService) History(ctx context.Context, id string) ([]*model.PropertyRevision, error) {
+	if _, err := service.find(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return service.repository.ReadHistory(ctx, id)
+}
+
+// Rollback restores the property matching the given id to the state recorded
+// by the given revision. The rollback itself is recorded as a new revision.
+func (service PropertyService) Rollback(ctx context.Context, id string, revision int) (*model.Property, error) {
+	foundProp, err := service.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	foundRevision, err := service.repository.FindRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	foundProp.Name = foundRevision.Name
+	foundProp.Description = foundRevision.Description
+	foundProp.Type = foundRevision.Type
+	foundProp.Value = foundRevision.Value
+	foundProp.Labels = foundRevision.Labels
+	foundProp.Overrides = foundRevision.Overrides
+	foundProp.Constraint = foundRevision.Constraint
+	foundProp.Targeting = foundRevision.Targeting
+
+	if err := service.Update(ctx, foundProp); err != nil {
+		return nil, err
+	}
+
+	return foundProp, nil
+}
+
+// Delete the property with the given id, moving it to the trash. Unless the
+// given revision is zero, the property is deleted only if it still has that
+// revision. The property is also removed from the sets naming it, if the
+// integrity mode cascades the changes (see propertyset.Service.RenameMember);
+// the trash entry keeps these sets, so that the restored property is added back
+// to them. The deletion is written along with the changed sets, the trash entry
+// and the audit record, all-or-nothing.
+func (service PropertyService) Delete(ctx context.Context, id string, revision int) error {
+	foundProp, err := service.find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := service.repository.Delete(ctx, id, revision); err != nil {
+			return err
+		}
+
+		sets, formerSets, err := service.cascade(ctx, foundProp.Name, "")
+		if err != nil {
+			return err
+		}
+
+		entry := trash.PropertyEntry(foundProp)
+		entry.Sets = sets
+		if err := service.trash.Put(ctx, entry); err != nil {
+			return err
+		}
+
+		if err := service.auditor.Record(ctx, audit.PropertyChange(audit.ActionDelete, foundProp, nil)); err != nil {
+			return err
+		}
+
+		event := watch.PropertyEvent(watch.Deleted, foundProp)
+		event.FormerSets = formerSets
+		service.hub.Publish(ctx, event)
+
+		return nil
+	})
 }
 
-// Update all fields of the given property.
+// Update all fields of the given property. The property cannot be moved to
+// another namespace. Unless the revision of the given property is zero, the
+// property is updated only if it still has that revision. The new name of a
+// renamed property must not be used within the namespace. A renamed property is
+// also renamed within the sets naming it, if the integrity mode cascades the
+// changes (see propertyset.Service.RenameMember), all-or-nothing.
 func (service PropertyService) Update(ctx context.Context, prop *model.Property) error {
 	if err := service.validators.check(prop); err != nil {
 		return err
 	}
 
-	return service.repository.Update(ctx, prop)
+	foundProp, err := service.find(ctx, prop.ID)
+	if err != nil {
+		return err
+	}
+
+	prop.Namespace = namespace.FromContext(ctx)
+	if foundProp.Name != prop.Name {
+		namesake, _ := service.repository.FindByName(ctx, prop.Namespace, prop.Name)
+		if namesake != nil {
+			return errors.NewConflict(reflect.TypeOf(namesake), "name", prop.Name)
+		}
+	}
+
+	if err := service.checkReferences(ctx, prop); err != nil {
+		return err
+	}
+
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		var formerSets []string
+		if foundProp.Name != prop.Name {
+			var err error
+			if _, formerSets, err = service.cascade(ctx, foundProp.Name, prop.Name); err != nil {
+				return err
+			}
+		}
+
+		return service.save(ctx, foundProp, prop, formerSets)
+	})
+}
+
+// Import adds the given properties to the namespace of the given context. The
+// properties whose names are already used are handled according to the conflict
+// strategy of the given options; when overwritten, they keep their labels and
+// secret flag, as well as their type, description, overrides, constraint and
+// targeting unless the imported ones are set. A name may only be imported once.
+// The masked values of the secret properties are rejected, as their actual
+// values are unknown.
+// Each property is validated and written on its own, so an invalid property is
+// reported as failed without stopping the import. Its references must resolve
+// to stored or imported properties and must not form cycles.
+//
+// If the options define a set, all imported names are added to that set, which
+// is created when missing.
+func (service PropertyService) Import(ctx context.Context, props []*model.Property, options property.ImportOptions) (*property.ImportReport, error) {
+	ns := namespace.FromContext(ctx)
+
+	listed := make(map[string]bool, len(props))
+	for _, prop := range props {
+		if listed[prop.Name] {
+			return nil, errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'name' has value '%s' more than once.", prop.Name))
+		}
+		listed[prop.Name] = true
+	}
+
+	found := make(map[string]*model.Property)
+	for _, prop := range props {
+		foundProp, err := service.repository.FindByName(ctx, ns, prop.Name)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+
+		if foundProp == nil {
+			continue
+		}
+
+		if options.Conflict == property.ConflictFail {
+			return nil, errors.NewConflict(reflect.TypeOf(foundProp), "name", prop.Name)
+		}
+
+		found[prop.Name] = foundProp
+	}
+
+	// The references are resolved against the imported properties first, so
+	// that they may reference each other regardless of their order.
+	imported := make([]*model.Property, 0, len(props))
+	for _, prop := range props {
+		if found[prop.Name] == nil || options.Conflict != property.ConflictSkip {
+			imported = append(imported, prop)
+		}
+	}
+
+	report := &property.ImportReport{}
+	for _, prop := range props {
+		foundProp := found[prop.Name]
+		if foundProp != nil && options.Conflict == property.ConflictSkip {
+			report.Skipped(prop.Name)
+			continue
+		}
+
+		if foundProp == nil {
+			prop.Namespace = ns
+			if err := service.create(ctx, prop, imported); err != nil {
+				report.Failed(prop.Name, err)
+				continue
+			}
+
+			report.Created(prop.Name)
+			continue
+		}
+
+		if err := service.overwrite(ctx, foundProp, prop, imported); err != nil {
+			report.Failed(prop.Name, err)
+			continue
+		}
+
+		report.Updated(prop.Name)
+	}
+
+	if options.Set != "" {
+		if err := service.addToSet(ctx, options.Set, report.Names()); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func (service PropertyService) create(ctx context.Context, prop *model.Property, imported []*model.Property) error {
+	if err := checkMasked(prop.Secret, prop); err != nil {
+		return err
+	}
+
+	if err := service.validators.check(prop); err != nil {
+		return err
+	}
+
+	if err := service.checkReferences(ctx, prop, imported...); err != nil {
+		return err
+	}
+
+	return service.insert(ctx, prop)
+}
+
+// overwrite replaces the found property with the imported one. The found
+// revision is expected, so that concurrent changes are not lost.
+func (service PropertyService) overwrite(ctx context.Context, foundProp *model.Property, prop *model.Property, imported []*model.Property) error {
+	if err := checkMasked(foundProp.Secret || prop.Secret, prop); err != nil {
+		return err
+	}
+
+	before := *foundProp
+
+	foundProp.Value = prop.Value
+	if prop.Type != "" {
+		foundProp.Type = prop.Type
+	}
+
+	if prop.Description != "" {
+		foundProp.Description = prop.Description
+	}
+
+	if prop.Overrides != nil {
+		foundProp.Overrides = prop.Overrides
+	}
+
+	if prop.Constraint != nil {
+		foundProp.Constraint = prop.Constraint
+	}
+
+	if prop.Targeting != nil {
+		foundProp.Targeting = prop.Targeting
+	}
+
+	if err := service.validators.check(foundProp); err != nil {
+		return err
+	}
+
+	if err := service.checkReferences(ctx, foundProp, imported...); err != nil {
+		return err
+	}
+
+	return service.save(ctx, &before, foundProp, nil)
+}
+
+// checkMasked rejects the imported property if it is secret and holds masked
+// values, as exported without revealing them: whatever the format, importing
+// them would replace the secret values with the placeholder.
+func checkMasked(secret bool, prop *model.Property) error {
+	if secret && prop.Masked() {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "'value' of a secret property is masked.")
+	}
+
+	return nil
+}
+
+// addToSet adds the given names to the set with the given id, keeping the names
+// that the set already contains. The set is created if it does not exist.
+func (service PropertyService) addToSet(ctx context.Context, id string, names []string) error {
+	foundSet, err := service.setService.FindByID(ctx, id)
+	if errors.IsNotFound(err) {
+		return service.setService.Create(ctx, &model.PropertySet{Name: id, Values: names})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]bool, len(foundSet.Values))
+	for _, value := range foundSet.Values {
+		values[value] = true
+	}
+
+	for _, name := range names {
+		if !values[name] {
+			foundSet.Values = append(foundSet.Values, name)
+			values[name] = true
+		}
+	}
+
+	return service.setService.Update(ctx, foundSet)
+}
+
+// Watch retrieves a subscription to the changes of the properties within the
+// namespace of the given context. If the query defines a set, only the changes
+// of the properties of that set, including the ones matching its name patterns,
+// are notified, along with the changes of the set itself. The members are kept
+// up to date by expanding the set again on each change of a set of the
+// namespace, as the set may include the changed one. The set is expanded by the
+// receiver, when it gets to the change (see watch.Subscription.Accepts), rather
+// than by the hub.
+func (service PropertyService) Watch(ctx context.Context, query property.Query) (*watch.Subscription, error) {
+	filter := &watchFilter{namespace: namespace.FromContext(ctx)}
+
+	if query.HasSet() {
+		names, err := service.setService.FindValuesByID(ctx, query.GetSet())
+		if err != nil {
+			return nil, err
+		}
+
+		// The subscription outlives the request, so the set is expanded within
+		// a context of its own.
+		setCtx := namespace.NewContext(context.Background(), filter.namespace)
+		filter.set = query.GetSet()
+		filter.members = model.NewMembership(names)
+		filter.expand = func() ([]string, error) {
+			return service.setService.FindValuesByID(setCtx, filter.set)
+		}
+	}
+
+	return service.hub.SubscribeChecked(filter.matches, filter.accepts), nil
+}
+
+// insert adds the given property to the repository, then records and publishes
+// its creation, all within a single transaction.
+func (service PropertyService) insert(ctx context.Context, prop *model.Property) error {
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := service.repository.Create(ctx, prop); err != nil {
+			return err
+		}
+
+		if err := service.auditor.Record(ctx, audit.PropertyChange(audit.ActionCreate, nil, prop)); err != nil {
+			return err
+		}
+
+		service.hub.Publish(ctx, watch.PropertyEvent(watch.Created, prop))
+
+		return nil
+	})
+}
+
+// save updates the given property in the repository, then records and publishes
+// its update, all within a single transaction, given the state of the property
+// before the update and the sets it was removed from by the update (see
+// watch.Event.FormerSets).
+func (service PropertyService) save(ctx context.Context, before *model.Property, prop *model.Property, formerSets []string) error {
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := service.repository.Update(ctx, prop); err != nil {
+			return err
+		}
+
+		if err := service.auditor.Record(ctx, audit.PropertyChange(audit.ActionUpdate, before, prop)); err != nil {
+			return err
+		}
+
+		event := watch.PropertyEvent(watch.Updated, prop)
+		event.FormerSets = formerSets
+		service.hub.Publish(ctx, event)
+
+		return nil
+	})
+}
+
+// cascade propagates the renaming or the deletion of the property with the given
+// name to the sets naming it (see propertyset.Service.RenameMember). It retrieves
+// the changed sets, along with the sets the property was removed from through
+// them, which the events of the change must carry for the filters by set.
+func (service PropertyService) cascade(ctx context.Context, name string, newName string) ([]string, []string, error) {
+	sets, err := service.setService.RenameMember(ctx, name, newName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	formerSets, err := service.setService.Including(ctx, sets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sets, formerSets, nil
+}
+
+// checkReferences validates that the properties referenced by the given property
+// exist within its namespace and that they do not reference it back. The value
+// and overrides of the property must also be valid once interpolated. The given
+// pending properties, about to be written as well, are used instead of the
+// stored ones with the same names.
+func (service PropertyService) checkReferences(ctx context.Context, prop *model.Property, pending ...*model.Property) error {
+	lookup := service.lookup(ctx, append([]*model.Property{prop}, pending...)...)
+
+	values := []string{prop.Value}
+	for _, value := range prop.Overrides {
+		values = append(values, value)
+	}
+
+	for _, value := range values {
+		for _, name := range model.References(value) {
+			found, err := lookup(name)
+			if err != nil {
+				return err
+			}
+
+			if found == nil {
+				return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'value' references unknown property '%s'.", name))
+			}
+		}
+	}
+
+	interpolated, err := model.NewInterpolator(lookup).Interpolate(prop)
+	if err != nil {
+		return toReferenceError(err)
+	}
+
+	return service.validators.check(interpolated)
+}
+
+// interpolate retrieves the given properties with their references replaced by
+// the values of the referenced properties of the namespace of the given context.
+func (service PropertyService) interpolate(ctx context.Context, props ...*model.Property) ([]*model.Property, error) {
+	interpolator := model.NewInterpolator(service.lookup(ctx))
+
+	result := make([]*model.Property, len(props))
+	for i, prop := range props {
+		interpolated, err := interpolator.Interpolate(prop)
+		if err != nil {
+			return nil, toReferenceError(err)
+		}
+
+		result[i] = interpolated
+	}
+
+	return result, nil
+}
+
+// lookup retrieves a model.Lookup finding the properties by name within the
+// namespace of the given context. The given properties are used instead of the
+// stored ones with the same names.
+func (service PropertyService) lookup(ctx context.Context, props ...*model.Property) model.Lookup {
+	ns := namespace.FromContext(ctx)
+
+	return func(name string) (*model.Property, error) {
+		for _, prop := range props {
+			if prop.Name == name {
+				return prop, nil
+			}
+		}
+
+		foundProp, err := service.repository.FindByName(ctx, ns, name)
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return foundProp, err
+	}
+}
+
+func toReferenceError(err error) error {
+	if cycle, ok := err.(*model.ReferenceCycleError); ok {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'value' has a reference cycle: %s.", strings.Join(cycle.Names, " -> ")))
+	}
+
+	return err
+}
+
+// sweep permanently removes the expired properties, once every given interval.
+func (service PropertyService) sweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		service.sweepExpired(time.Now())
+	}
+}
+
+// sweepExpired removes the properties that have expired at the given moment in
+// time. The removals are recorded, published and cascaded to the sets like the
+// deletions, all-or-nothing, but the removed properties are not moved to the
+// trash. If any of them fails, the properties are removed by the next sweep.
+func (service PropertyService) sweepExpired(at time.Time) {
+	var props []*model.Property
+	err := service.transactor.Run(context.Background(), func(ctx context.Context) error {
+		var err error
+		if props, err = service.repository.DeleteExpired(ctx, at); err != nil {
+			return err
+		}
+
+		for _, prop := range props {
+			ctx := namespace.NewContext(ctx, prop.Namespace)
+			if err := service.auditor.Record(ctx, audit.PropertyChange(audit.ActionExpire, prop, nil)); err != nil {
+				return err
+			}
+
+			_, formerSets, err := service.cascade(ctx, prop.Name, "")
+			if err != nil {
+				return err
+			}
+
+			event := watch.PropertyEvent(watch.Deleted, prop)
+			event.FormerSets = formerSets
+			service.hub.Publish(ctx, event)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logger.Main.Error("Cannot remove the expired properties", err)
+		return
+	}
+
+	if len(props) > 0 {
+		logger.Main.Infof("Removed %d expired properties.", len(props))
+	}
+}
+
+type watchFilter struct {
+	namespace string
+	set       string
+	expand    func() ([]string, error)
+
+	// mutex guards the fields below, as the members are matched by the hub
+	// but expanded by the receiver.
+	mutex   sync.Mutex
+	members *model.Membership
+
+	// changed counts the changes of the sets matched by the hub, expanded
+	// the ones the receiver expanded the set for.
+	changed  int
+	expanded int
+}
+
+// matches is called by the hub, so it only counts the changes of the sets: until
+// the receiver expands the set again, the changes of all properties are let
+// through.
+func (filter *watchFilter) matches(event watch.Event) bool {
+	if event.Namespace != filter.namespace {
+		return false
+	}
+
+	if filter.set == "" {
+		return event.Set == nil
+	}
+
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	if event.Set != nil {
+		filter.changed++
+		return true
+	}
+
+	return filter.changed != filter.expanded || filter.members.Contains(event.Property.Name) || event.WasMember(filter.set)
+}
+
+// accepts is called by the receiver, which expands the set again on each change
+// of a set.
+func (filter *watchFilter) accepts(event watch.Event) bool {
+	if filter.set == "" {
+		return true
+	}
+
+	if event.Set != nil {
+		names, err := filter.expand()
+		if err != nil {
+			names = nil
+		}
+
+		filter.mutex.Lock()
+		defer filter.mutex.Unlock()
+
+		filter.members = model.NewMembership(names)
+		filter.expanded++
+		return event.Set.Name == filter.set
+	}
+
+	filter.mutex.Lock()
+	defer filter.mutex.Unlock()
+
+	return filter.members.Contains(event.Property.Name) || event.WasMember(filter.set)
 }
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
@@ -18,7 +21,7 @@ type validators struct {
 
 func newValidators() validators {
 	return validators{
-		values: []validator{nameValidator{}},
+		values: []validator{nameValidator{}, typeValidator{}, labelsValidator{}, overridesValidator{}, constraintValidator{}, targetingValidator{}, expiryValidator{}},
 	}
 }
 
@@ -47,3 +50,160 @@ func (v nameValidator) check(prop *model.Property) error {
 
 	return nil
 }
+
+type typeValidator struct {
+}
+
+func (v typeValidator) check(prop *model.Property) error {
+	if !model.IsValidPropertyType(prop.Type) {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'type' has unknown value '%s'.", prop.Type))
+	}
+
+	if model.HasReferences(prop.Value) {
+		return nil
+	}
+
+	if _, err := prop.TypedValue(); err != nil {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'value' is not a valid %s.", prop.Type))
+	}
+
+	return nil
+}
+
+type labelsValidator struct {
+}
+
+func (v labelsValidator) check(prop *model.Property) error {
+	for key, value := range prop.Labels {
+		if !model.IsValidLabelKey(key) {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'labels' has invalid key '%s'.", key))
+		}
+
+		if !model.IsValidLabelValue(value) {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'labels' has invalid value '%s' for key '%s'.", value, key))
+		}
+	}
+
+	return nil
+}
+
+type overridesValidator struct {
+}
+
+func (v overridesValidator) check(prop *model.Property) error {
+	for profile, value := range prop.Overrides {
+		if !model.IsValidProfile(profile) {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'overrides' has invalid profile '%s'.", profile))
+		}
+
+		if model.HasReferences(value) {
+			continue
+		}
+
+		if _, err := model.ParseValue(prop.Type, value); err != nil {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'overrides' value for profile '%s' is not a valid %s.", profile, prop.Type))
+		}
+	}
+
+	return nil
+}
+
+type constraintValidator struct {
+}
+
+// check validates the constraint of the property, then its value and overrides
+// against the constraint. The values referencing other properties are checked
+// once interpolated.
+func (v constraintValidator) check(prop *model.Property) error {
+	if prop.Constraint == nil {
+		return nil
+	}
+
+	if err := prop.Constraint.Check(prop.Type); err != nil {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'constraint' is not valid: %s.", err))
+	}
+
+	var violations []errors.Violation
+	if !model.HasReferences(prop.Value) {
+		violations = appendViolations(violations, "value", prop.Constraint.Validate(prop.Type, prop.Value))
+	}
+
+	profiles := make([]string, 0, len(prop.Overrides))
+	for profile := range prop.Overrides {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+
+	for _, profile := range profiles {
+		if value := prop.Overrides[profile]; !model.HasReferences(value) {
+			violations = appendViolations(violations, "overrides."+profile, prop.Constraint.Validate(prop.Type, value))
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewViolations(reflect.TypeOf(model.Property{}), violations)
+	}
+
+	return nil
+}
+
+func appendViolations(violations []errors.Violation, field string, found []model.ConstraintViolation) []errors.Violation {
+	for _, violation := range found {
+		violations = append(violations, errors.Violation{Field: field, Path: violation.Path, Message: violation.Message})
+	}
+
+	return violations
+}
+
+type targetingValidator struct {
+}
+
+// check validates the targeting rules of the property. As the variants are
+// served to any caller, secret properties cannot have targeting rules. The
+// variants must satisfy the constraint of the property, if any.
+func (v targetingValidator) check(prop *model.Property) error {
+	if prop.Targeting == nil {
+		return nil
+	}
+
+	if prop.Secret {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "'targeting' cannot be used by secret properties.")
+	}
+
+	if err := prop.Targeting.Check(prop.Type); err != nil {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), fmt.Sprintf("'targeting' is not valid: %s.", err))
+	}
+
+	if prop.Constraint == nil {
+		return nil
+	}
+
+	var violations []errors.Violation
+	for index, rule := range prop.Targeting.Rules {
+		field := fmt.Sprintf("targeting.rules.%d", index)
+		if rule.Variant != "" {
+			violations = appendViolations(violations, field, prop.Constraint.Validate(prop.Type, rule.Variant))
+		}
+
+		for _, share := range rule.Rollout {
+			violations = appendViolations(violations, field, prop.Constraint.Validate(prop.Type, share.Variant))
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.NewViolations(reflect.TypeOf(model.Property{}), violations)
+	}
+
+	return nil
+}
+
+type expiryValidator struct {
+}
+
+func (v expiryValidator) check(prop *model.Property) error {
+	if prop.Expired(time.Now()) {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Property{}), "'expires_at' must be in the future.")
+	}
+
+	return nil
+}
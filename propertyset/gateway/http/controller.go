@@ -1,37 +1,76 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	"github.com/rghiorghisor/basic-go-rest-api/release"
 	"github.com/rghiorghisor/basic-go-rest-api/server"
 )
 
 // Controller that handles the relation between the server and the service.
 type Controller struct {
-	service propertyset.Service
+	service  propertyset.Service
+	releases release.Service
 }
 
-// PropertySetDto defines how a property set must be exposed.
+// PropertySetDto defines how a property set must be exposed. The members are
+// exposed only if the set is expanded.
 type PropertySetDto struct {
-	Name   string   `json:"name"`
-	Values []string `json:"values"`
+	Name     string      `json:"name"`
+	Values   []string    `json:"values"`
+	Includes []string    `json:"includes,omitempty"`
+	Version  int         `json:"version,omitempty"`
+	Members  []MemberDto `json:"members,omitempty"`
 }
 
-// New retrieves a brand new contoller wrapping around the given service.
-func New(service propertyset.Service) server.ControllerWrapper {
+// MemberDto defines how a member of the effective membership of a set must be
+// exposed, along with the set providing it.
+type MemberDto struct {
+	Name string `json:"name"`
+	Set  string `json:"set"`
+}
+
+// ReleaseDto defines how a release of a property set must be exposed. The
+// properties of the release are read through the property endpoints, pinned to
+// the release.
+type ReleaseDto struct {
+	Set       string    `json:"set"`
+	Release   int       `json:"release"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrphansDto defines how the names of a property set that no longer resolve to
+// properties must be exposed.
+type OrphansDto struct {
+	Set     string   `json:"set"`
+	Orphans []string `json:"orphans"`
+}
+
+// New retrieves a brand new contoller wrapping around the given service. The
+// releases of the sets are created through the given release service.
+func New(service propertyset.Service, releases release.Service) server.ControllerWrapper {
 	return server.ControllerWrapper{
 		Controller: &Controller{
-			service: service,
+			service:  service,
+			releases: releases,
 		},
 	}
 }
 
 type createDto struct {
-	Name   string   `json:"name"`
-	Values []string `json:"values"`
+	Name     string   `json:"name"`
+	Values   []string `json:"values"`
+	Includes []string `json:"includes"`
 }
 
 // Create retrieves creates (if possible) a brand new property set.
@@ -44,8 +83,9 @@ func (ctrl *Controller) Create(ctx *gin.Context) {
 	}
 
 	prop := &model.PropertySet{
-		Name:   dto.Name,
-		Values: dto.Values,
+		Name:     dto.Name,
+		Values:   dto.Values,
+		Includes: dto.Includes,
 	}
 
 	// Call service (business logic).
@@ -63,12 +103,27 @@ func (ctrl *Controller) Create(ctx *gin.Context) {
 
 type readAllResponseDto struct {
 	PropertySetDto []*PropertySetDto `json:"sets"`
+	NextPageToken  string            `json:"next_page_token,omitempty"`
+	Total          int               `json:"total"`
 }
 
-// Read reads a single property set based on the provided identifier.
+// Read reads a single property set based on the provided identifier. If the
+// 'expand' query parameter is true, the effective membership of the set, i.e.
+// including the members of the included sets, is retrieved as well.
 func (ctrl *Controller) Read(ctx *gin.Context) {
 	id := ctx.Param("id")
 
+	expand := false
+	if value := ctx.Query("expand"); value != "" {
+		e, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.Error(errors.NewInvalidParameter("expand", value))
+			return
+		}
+
+		expand = e
+	}
+
 	foundProp, err := ctrl.service.FindByID(ctx.Request.Context(), id)
 
 	if err != nil {
@@ -76,12 +131,30 @@ func (ctrl *Controller) Read(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, toProperty(foundProp))
+	dto := toProperty(foundProp)
+	if expand {
+		members, err := ctrl.service.Expand(ctx.Request.Context(), id)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+
+		dto.Members = toMembers(members)
+	}
+
+	server.SetETag(ctx, foundProp.Version)
+	ctx.JSON(http.StatusOK, dto)
 }
 
-// ReadAll retrieves a list of all available properties.
+// ReadAll retrieves a page of the available property sets.
 func (ctrl *Controller) ReadAll(ctx *gin.Context) {
-	properties, err := ctrl.service.ReadAll(ctx.Request.Context())
+	page, err := server.ParsePage(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	properties, info, err := ctrl.service.ReadAll(ctx.Request.Context(), page)
 
 	if err != nil {
 		ctx.Error(err)
@@ -90,14 +163,18 @@ func (ctrl *Controller) ReadAll(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, &readAllResponseDto{
 		PropertySetDto: toProperties(properties),
+		NextPageToken:  model.EncodePageToken(info.Next),
+		Total:          info.Total,
 	})
 }
 
 type updateDto struct {
-	Values []string `json:"values"`
+	Values   []string `json:"values"`
+	Includes []string `json:"includes"`
 }
 
-// Update a single property set.
+// Update a single property set. The update is conditional if the request has
+// an 'If-Match' header holding the ETag of the set.
 func (ctrl *Controller) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
@@ -107,9 +184,54 @@ func (ctrl *Controller) Update(ctx *gin.Context) {
 		return
 	}
 
+	version, err := server.IfMatch(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctrl.update(ctx, id, inp, version)
+}
+
+// Patch partially updates a single property set, by applying either a JSON
+// merge patch (RFC 7396) or a JSON patch (RFC 6902) to the fields accepted by
+// Update, e.g. '[{"op":"add","path":"/values/-","value":"name"}]' adds a single
+// member. The patch is applied to the current state of the set, so the update
+// is always conditional, even if the request has no 'If-Match' header.
+func (ctrl *Controller) Patch(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	version, err := server.IfMatch(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	foundSet, err := ctrl.service.FindByID(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if version == 0 {
+		version = foundSet.Version
+	}
+
+	inp := &updateDto{Values: foundSet.Values, Includes: foundSet.Includes}
+	if err := server.Patch(ctx, model.PropertySet{}, inp); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctrl.update(ctx, id, inp, version)
+}
+
+func (ctrl *Controller) update(ctx *gin.Context, id string, inp *updateDto, version int) {
 	prop := &model.PropertySet{
-		Name:   id,
-		Values: inp.Values,
+		Name:     id,
+		Values:   inp.Values,
+		Includes: inp.Includes,
+		Version:  version,
 	}
 
 	if err := ctrl.service.Update(ctx.Request.Context(), prop); err != nil {
@@ -117,14 +239,23 @@ func (ctrl *Controller) Update(ctx *gin.Context) {
 		return
 	}
 
+	server.SetETag(ctx, prop.Version)
 	ctx.JSON(http.StatusOK, toProperty(prop))
 }
 
-// Delete a single property set, specified by means of its identifier.
+// Delete a single property set, specified by means of its identifier. The
+// deletion is conditional if the request has an 'If-Match' header holding the
+// ETag of the set.
 func (ctrl *Controller) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := ctrl.service.Delete(ctx.Request.Context(), id); err != nil {
+	version, err := server.IfMatch(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := ctrl.service.Delete(ctx.Request.Context(), id, version); err != nil {
 		ctx.Error(err)
 		return
 	}
@@ -132,6 +263,42 @@ func (ctrl *Controller) Delete(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// Snapshot creates a new release of a single property set, freezing the current
+// values of its properties. The 'Location' header of the response points to the
+// properties of the release.
+func (ctrl *Controller) Snapshot(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	release, err := ctrl.releases.Create(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	base := strings.TrimSuffix(ctx.Request.URL.Path, "/set/"+id+"/snapshot")
+	ctx.Writer.Header().Set("Location", fmt.Sprintf("%s/property?set=%s&release=%d", base, url.QueryEscape(id), release.Number))
+	ctx.JSON(http.StatusCreated, &ReleaseDto{
+		Set:       release.Set,
+		Release:   release.Number,
+		Size:      len(release.Properties),
+		CreatedAt: release.CreatedAt,
+	})
+}
+
+// Orphans lists the names within the values of a single property set that do
+// not resolve to any property of its namespace.
+func (ctrl *Controller) Orphans(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	orphans, err := ctrl.service.Orphans(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &OrphansDto{Set: id, Orphans: orphans})
+}
+
 func toProperties(bs []*model.PropertySet) []*PropertySetDto {
 	out := make([]*PropertySetDto, len(bs))
 
@@ -144,18 +311,34 @@ func toProperties(bs []*model.PropertySet) []*PropertySetDto {
 
 func toProperty(b *model.PropertySet) *PropertySetDto {
 	return &PropertySetDto{
-		Name:   b.Name,
-		Values: b.Values,
+		Name:     b.Name,
+		Values:   b.Values,
+		Includes: b.Includes,
+		Version:  b.Version,
 	}
 }
 
-// Register this controller to the provided group.
-func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
-	api := routerGroup.Group("/set")
+func toMembers(members []model.SetMember) []MemberDto {
+	out := make([]MemberDto, len(members))
 
-	api.POST("", ctrl.Create)
-	api.GET("", ctrl.ReadAll)
-	api.GET("/:id", ctrl.Read)
-	api.PUT("/:id", ctrl.Update)
-	api.DELETE("/:id", ctrl.Delete)
+	for i, member := range members {
+		out[i] = MemberDto{Name: member.Name, Set: member.Set}
+	}
+
+	return out
+}
+
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
+func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
+	for _, api := range server.NamespacedGroups(routerGroup, "/set") {
+		api.POST("", ctrl.Create)
+		api.GET("", ctrl.ReadAll)
+		api.GET("/:id", ctrl.Read)
+		api.PUT("/:id", ctrl.Update)
+		api.PATCH("/:id", ctrl.Patch)
+		api.DELETE("/:id", ctrl.Delete)
+		api.POST("/:id/snapshot", ctrl.Snapshot)
+		api.GET("/:id/orphans", ctrl.Orphans)
+	}
 }
@@ -9,11 +9,13 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset/service"
+	release_service "github.com/rghiorghisor/basic-go-rest-api/release/service"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -98,7 +100,7 @@ func TestReadAll(t *testing.T) {
 		properties[i] = &model.PropertySet{Name: "test.name." + strconv.Itoa(i), Values: []string{"test.value." + strconv.Itoa(i) + ".1", "test.value." + strconv.Itoa(i) + ".2"}}
 	}
 
-	service.On("ReadAll").Return(properties, nil)
+	service.On("ReadAll", model.Page{}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	// Perform action.
 	w := perform("GET", "/api/set", nil, router)
@@ -107,6 +109,21 @@ func TestReadAll(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 }
 
+func TestReadAllPage(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	properties := []*model.PropertySet{{Name: "test.name.1", Values: []string{"test.value.1.1"}}}
+	service.On("ReadAll", model.Page{Limit: 1}).Return(properties, model.PageInfo{Total: 2, Next: "test.name.1"}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/set?limit=1", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"sets":[{"name":"test.name.1","values":["test.value.1.1"]}],"next_page_token":"`+model.EncodePageToken("test.name.1")+`","total":2}`, w.Body.String())
+}
+
 func TestReadAllUnexpected(t *testing.T) {
 	router, service := setup()
 
@@ -116,7 +133,7 @@ func TestReadAllUnexpected(t *testing.T) {
 		properties[i] = &model.PropertySet{Name: "test.name." + strconv.Itoa(i), Values: []string{"test.value." + strconv.Itoa(i) + ".1", "test.value." + strconv.Itoa(i) + ".2"}}
 	}
 
-	service.On("ReadAll").Return(properties, errors.New("unexpected"))
+	service.On("ReadAll", model.Page{}).Return(properties, model.PageInfo{}, errors.New("unexpected"))
 
 	// Perform action.
 	w := perform("GET", "/api/set", nil, router)
@@ -140,6 +157,51 @@ func TestRead(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 }
 
+func TestReadExpanded(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.PropertySet{Name: "payments", Values: []string{"payments.url"}, Includes: []string{"common"}, Version: 2}
+
+	service.On("FindByID", "payments").Return(property, nil)
+	service.On("Expand", "payments").Return([]model.SetMember{{Name: "db.host", Set: "common"}, {Name: "payments.url", Set: "payments"}}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/set/payments", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"name":"payments","values":["payments.url"],"includes":["common"],"version":2}`, w.Body.String())
+
+	// Perform action.
+	w = perform("GET", "/api/set/payments?expand=true", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"name":"payments","values":["payments.url"],"includes":["common"],"version":2,`+
+		`"members":[{"name":"db.host","set":"common"},{"name":"payments.url","set":"payments"}]}`, w.Body.String())
+
+	// Perform action.
+	w = perform("GET", "/api/set/payments?expand=all", nil, router)
+
+	// Test result
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestCreateIncludes(t *testing.T) {
+	router, service := setup()
+
+	prop := &model.PropertySet{Name: "payments", Values: []string{"payments.url"}, Includes: []string{"common"}}
+
+	service.On("Create", prop).Return(nil)
+
+	// Perform action.
+	w := perform("POST", "/api/set", []byte(`{"name":"payments","values":["payments.url"],"includes":["common"]}`), router)
+
+	// Test result.
+	assert.Equal(t, 201, w.Code)
+}
+
 func TestReadNotFound(t *testing.T) {
 	router, service := setup()
 
@@ -224,7 +286,7 @@ func TestDelete(t *testing.T) {
 	router, service := setup()
 
 	// Mock service action.
-	service.On("Delete", "TestId").Return(nil)
+	service.On("Delete", "TestId", 0).Return(nil)
 
 	// Perform action.
 	w := perform("DELETE", "/api/set/TestId", nil, router)
@@ -237,7 +299,7 @@ func TestDeleteNotFound(t *testing.T) {
 	router, service := setup()
 
 	// Mock service action.
-	service.On("Delete", "TestId").Return(apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
+	service.On("Delete", "TestId", 0).Return(apperrors.NewEntityNotFound(&model.Property{}, "TestId"))
 
 	// Perform action.
 	w := perform("DELETE", "/api/set/TestId", nil, router)
@@ -250,7 +312,7 @@ func TestDeleteUnexpected(t *testing.T) {
 	router, service := setup()
 
 	// Mock service error.
-	service.On("Delete", "TestId").Return(errors.New("unexpected"))
+	service.On("Delete", "TestId", 0).Return(errors.New("unexpected"))
 
 	// Perform action.
 	w := perform("DELETE", "/api/set/TestId", nil, router)
@@ -259,7 +321,139 @@ func TestDeleteUnexpected(t *testing.T) {
 	assert.Equal(t, 500, w.Code)
 }
 
+func TestReadNamespaced(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	property := &model.PropertySet{Namespace: "payments", Name: "test.name.1", Values: []string{"test.value.1.1"}}
+
+	service.On("FindByID", "test.name.1").Return(property, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/ns/payments/set/test.name.1", nil, router)
+
+	// Test result
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"name":"test.name.1","values":["test.value.1.1"]}`, w.Body.String())
+}
+
+func TestReadETag(t *testing.T) {
+	router, service := setup()
+
+	// Mock service return.
+	service.On("FindByID", "test.name.1").Return(&model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}, Version: 2}, nil)
+
+	// Perform action.
+	w := perform("GET", "/api/set/test.name.1", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	assert.Equal(t, `{"name":"test.name.1","values":["test.value.1.1"],"version":2}`, w.Body.String())
+}
+
+func TestUpdatePreconditionFailed(t *testing.T) {
+	router, service := setup()
+
+	dto := &updateDto{Values: []string{"test.value.1.1"}}
+	prop := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}, Version: 2}
+
+	service.On("Update", prop).Return(apperrors.NewPreconditionFailed(model.PropertySet{}, "test.name.1", 2))
+
+	body, err := json.Marshal(dto)
+	assert.NoError(t, err)
+
+	// Perform action.
+	w := performWithHeaders("PUT", "/api/set/test.name.1", body, router, map[string]string{"If-Match": `"2"`})
+
+	// Test result.
+	assert.Equal(t, 412, w.Code)
+}
+
+func TestDeleteIfMatch(t *testing.T) {
+	router, service := setup()
+
+	// Mock service action.
+	service.On("Delete", "TestId", 3).Return(nil)
+
+	// Perform action.
+	w := performWithHeaders("DELETE", "/api/set/TestId", nil, router, map[string]string{"If-Match": `"3"`})
+
+	// Test result.
+	assert.Equal(t, 204, w.Code)
+}
+
+func TestPatch(t *testing.T) {
+	router, service := setup()
+
+	found := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}, Version: 2}
+	prop := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1", "test.value.1.2"}, Version: 2}
+
+	service.On("FindByID", "test.name.1").Return(found, nil)
+	service.On("Update", prop).Return(nil)
+
+	// Perform action.
+	w := performWithHeaders("PATCH", "/api/set/test.name.1", []byte(`[{"op":"add","path":"/values/-","value":"test.value.1.2"}]`), router, map[string]string{"Content-Type": "application/json-patch+json"})
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"name":"test.name.1","values":["test.value.1.1","test.value.1.2"],"version":2}`, w.Body.String())
+}
+
+func TestOrphans(t *testing.T) {
+	router, service := setup()
+
+	service.On("Orphans", "payments").Return([]string{"db.port"}, nil)
+	service.On("Orphans", "missing").Return(nil, apperrors.NewEntityNotFound(model.PropertySet{}, "missing"))
+
+	// Perform action.
+	w := perform("GET", "/api/ns/payments/set/payments/orphans", nil, router)
+
+	// Test result.
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"set":"payments","orphans":["db.port"]}`, w.Body.String())
+
+	// Perform action.
+	w = perform("GET", "/api/set/missing/orphans", nil, router)
+
+	// Test result.
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestSnapshot(t *testing.T) {
+	router, _, releases := setupReleases()
+
+	createdAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	releases.On("Create", "common").Return(&model.Release{Namespace: "payments", Set: "common", Number: 3, Properties: []*model.Property{{Name: "a"}, {Name: "b"}}, CreatedAt: createdAt}, nil)
+
+	// Perform action.
+	w := perform("POST", "/api/ns/payments/set/common/snapshot", nil, router)
+
+	// Test result.
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "/api/ns/payments/property?set=common&release=3", w.Header().Get("Location"))
+	assert.JSONEq(t, `{"set":"common","release":3,"size":2,"created_at":"2020-10-01T12:00:00Z"}`, w.Body.String())
+}
+
+func TestSnapshotNotFound(t *testing.T) {
+	router, _, releases := setupReleases()
+
+	releases.On("Create", "missing").Return(nil, apperrors.NewEntityNotFound(model.PropertySet{}, "missing"))
+
+	// Perform action.
+	w := perform("POST", "/api/set/missing/snapshot", nil, router)
+
+	// Test result.
+	assert.Equal(t, 404, w.Code)
+}
+
 func setup() (r *gin.Engine, serviceMock *service.PropertySetServiceMock) {
+	router, service, _ := setupReleases()
+
+	return router, service
+}
+
+func setupReleases() (r *gin.Engine, serviceMock *service.PropertySetServiceMock, releasesMock *release_service.ReleaseServiceMock) {
 	router := gin.Default()
 	router.Use(
 		jsonAppErrorHandler(),
@@ -267,10 +461,11 @@ func setup() (r *gin.Engine, serviceMock *service.PropertySetServiceMock) {
 	api := router.Group("/api")
 
 	service := new(service.PropertySetServiceMock)
-	controller := New(service).Controller
+	releases := new(release_service.ReleaseServiceMock)
+	controller := New(service, releases).Controller
 	controller.Register(api)
 
-	return router, service
+	return router, service, releases
 }
 
 func perform(method string, uri string, body []byte, router *gin.Engine) (rr *httptest.ResponseRecorder) {
@@ -2,12 +2,18 @@ package bolt
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	ns "github.com/rghiorghisor/basic-go-rest-api/namespace"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
 )
 
 // PropertySetRepository is a representation of the property repository for Bolt DBs.
@@ -15,9 +21,15 @@ type PropertySetRepository struct {
 	db *storm.DB
 }
 
+// propertySetDto is identified by the namespace.Key of the set, so that names
+// remain unique only within a namespace.
 type propertySetDto struct {
-	Name   string   `storm:"id,unique"`
-	Values []string `bson:"values"`
+	ID        string `storm:"id"`
+	Namespace string
+	Name      string
+	Values    []string `bson:"values"`
+	Includes  []string
+	Version   int
 }
 
 // New retrieves a new repository object ready to be used.
@@ -26,15 +38,35 @@ func New(db *storm.DB) storage.Repository {
 		db: db,
 	}
 	db.Init(&propertySetDto{})
+	repo.versionSets()
 
 	return repo
 }
 
+// versionSets gives the first version to the sets stored before the sets were
+// versioned, so that their entity tags can be used by conditional requests.
+func (repository PropertySetRepository) versionSets() {
+	var dtos []propertySetDto
+	err := repository.db.Select(q.Eq("Version", 0)).Find(&dtos)
+	if err != nil && err != storm.ErrNotFound {
+		logger.Main.Error("Cannot read the sets to version.", err)
+		return
+	}
+
+	for _, dto := range dtos {
+		dto.Version = 1
+		if err := repository.db.Save(&dto); err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot version the set '%s'.", dto.ID), err)
+		}
+	}
+}
+
 // Create a new entry based on the provided property.
 func (repository PropertySetRepository) Create(ctx context.Context, propSet *model.PropertySet) error {
+	propSet.Version = 1
 	dto := convertToDto(propSet)
 
-	tx, err := repository.db.Begin(true)
+	tx, err := transaction.BoltBegin(ctx, repository.db)
 	if err != nil {
 		return err
 	}
@@ -42,7 +74,7 @@ func (repository PropertySetRepository) Create(ctx context.Context, propSet *mod
 
 	var found propertySetDto
 
-	e := tx.One("Name", propSet.Name, &found)
+	e := tx.One("ID", dto.ID, &found)
 	if e == nil {
 		return errors.NewConflict(reflect.TypeOf((*model.PropertySet)(nil)).Elem(), "name", propSet.Name)
 	}
@@ -58,21 +90,36 @@ func (repository PropertySetRepository) Create(ctx context.Context, propSet *mod
 	return tx.Commit()
 }
 
-// ReadAll retrieves all available properties.
-func (repository PropertySetRepository) ReadAll(ctx context.Context) ([]*model.PropertySet, error) {
+// ReadAll retrieves the given page of the property sets within the given
+// namespace, sorted by name.
+func (repository PropertySetRepository) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.PropertySet, model.PageInfo, error) {
 	var propSets []propertySetDto
-	if err := repository.db.All(&propSets); err != nil {
-		return nil, err
+	err := repository.node(ctx).Select(q.Eq("Namespace", namespace)).Find(&propSets)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, model.PageInfo{}, err
+	}
+
+	sort.Slice(propSets, func(i, j int) bool {
+		return page.Less(propSets[i].Name, propSets[j].Name)
+	})
+
+	names := make([]string, len(propSets))
+	for i, dto := range propSets {
+		names[i] = dto.Name
 	}
 
-	return convertDtosToModel(propSets), nil
+	from, to, info := page.Window(names)
+
+	return convertDtosToModel(propSets[from:to]), info, nil
 }
 
-// FindByID retrieves the property matching the given id if such a property
-// exists; otherwise will return a not found error.
-func (repository PropertySetRepository) FindByID(context context.Context, id string) (*model.PropertySet, error) {
+// FindByID retrieves the property set matching the given id within the given
+// namespace if such a property set exists; otherwise will return a not found
+// error.
+func (repository PropertySetRepository) FindByID(context context.Context, namespace string, id string) (*model.PropertySet, error) {
 	var dto propertySetDto
-	err := repository.db.One("Name", id, &dto)
+	err := repository.node(context).One("ID", ns.Key(namespace, id), &dto)
 
 	if storm.ErrNotFound == err {
 		return nil, errors.NewEntityNotFound(model.PropertySet{}, id)
@@ -85,27 +132,25 @@ func (repository PropertySetRepository) FindByID(context context.Context, id str
 	return convertToModel(&dto), nil
 }
 
-// FindByName retrieves the property matching the given name if such a property
-// exists; otherwise will return a not found error.
-func (repository PropertySetRepository) FindByName(context context.Context, name string) (*model.PropertySet, error) {
-	var dto propertySetDto
-	err := repository.db.One("Name", name, &dto)
-
-	if storm.ErrNotFound == err {
-		return nil, errors.NewEntityNotFound(model.PropertySet{}, name)
-	}
+// FindByName retrieves the property set matching the given name within the
+// given namespace if such a property set exists; otherwise will return a not
+// found error.
+func (repository PropertySetRepository) FindByName(context context.Context, namespace string, name string) (*model.PropertySet, error) {
+	return repository.FindByID(context, namespace, name)
+}
 
+// Delete the property set with the given id within the given namespace. Unless
+// the given version is zero, the set must have that version.
+func (repository PropertySetRepository) Delete(context context.Context, namespace string, id string, version int) error {
+	tx, err := transaction.BoltBegin(context, repository.db)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer tx.Rollback()
 
-	return convertToModel(&dto), nil
-}
-
-// Delete the property with the given id.
-func (repository PropertySetRepository) Delete(context context.Context, id string) error {
 	var dto propertySetDto
-	err := repository.db.One("Name", id, &dto)
+	key := ns.Key(namespace, id)
+	err = tx.One("ID", key, &dto)
 
 	if storm.ErrNotFound == err {
 		return errors.NewEntityNotFound(model.PropertySet{}, id)
@@ -115,17 +160,30 @@ func (repository PropertySetRepository) Delete(context context.Context, id strin
 		return err
 	}
 
-	if err := repository.db.DeleteStruct(&dto); err != nil {
+	if version != 0 && version != dto.Version {
+		return errors.NewPreconditionFailed(model.PropertySet{}, id, version)
+	}
+
+	// Sets stored before namespaces were introduced do not hold their key.
+	dto.ID = key
+	if err := tx.DeleteStruct(&dto); err != nil {
 		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-// Update all fields of the given property.
+// Update all fields of the given property. Unless the version of the given set
+// is zero, the stored set must have that version. The version is incremented.
 func (repository PropertySetRepository) Update(ctx context.Context, property *model.PropertySet) error {
-	dto := convertToDto(property)
-	err := repository.db.Update(dto)
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var found propertySetDto
+	err = tx.One("ID", ns.Key(property.Namespace, property.Name), &found)
 
 	if storm.ErrNotFound == err {
 		return errors.NewEntityNotFound(model.PropertySet{}, property.Name)
@@ -135,13 +193,26 @@ func (repository PropertySetRepository) Update(ctx context.Context, property *mo
 		return err
 	}
 
-	return nil
+	if property.Version != 0 && property.Version != found.Version {
+		return errors.NewPreconditionFailed(model.PropertySet{}, property.Name, property.Version)
+	}
+
+	property.Version = found.Version + 1
+	if err := tx.Save(convertToDto(property)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func convertToDto(property *model.PropertySet) *propertySetDto {
 	return &propertySetDto{
-		Name:   property.Name,
-		Values: property.Values,
+		ID:        ns.Key(property.Namespace, property.Name),
+		Namespace: property.Namespace,
+		Name:      property.Name,
+		Values:    property.Values,
+		Includes:  property.Includes,
+		Version:   property.Version,
 	}
 }
 
@@ -157,7 +228,16 @@ func convertDtosToModel(dtos []propertySetDto) []*model.PropertySet {
 
 func convertToModel(dto *propertySetDto) *model.PropertySet {
 	return &model.PropertySet{
-		Name:   dto.Name,
-		Values: dto.Values,
+		Namespace: dto.Namespace,
+		Name:      dto.Name,
+		Values:    dto.Values,
+		Includes:  dto.Includes,
+		Version:   dto.Version,
 	}
 }
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository PropertySetRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
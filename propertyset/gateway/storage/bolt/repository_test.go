@@ -9,6 +9,7 @@ import (
 	"github.com/asdine/storm/v3"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
 	"github.com/rghiorghisor/basic-go-rest-api/util"
 	"gopkg.in/go-playground/assert.v1"
 )
@@ -38,7 +39,7 @@ func TestReadAll(t *testing.T) {
 	repo.Create(context.Background(), prop1)
 	repo.Create(context.Background(), prop2)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 
 	if readProps[0].Name == prop2.Name {
 		tmp := prop1
@@ -60,7 +61,7 @@ func TestReadAllUnexpected(t *testing.T) {
 
 	defer tearDown(repo)
 
-	_, err := repo.ReadAll(context.Background())
+	_, _, err := repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
 
@@ -77,10 +78,10 @@ func TestFindById(t *testing.T) {
 	var readProps []*model.PropertySet
 	readProps = make([]*model.PropertySet, 0, 2)
 
-	prop, _ := repo.FindByID(context.Background(), prop1.Name)
+	prop, _ := repo.FindByID(context.Background(), namespace.Default, prop1.Name)
 	readProps = append(readProps, prop)
 
-	prop, _ = repo.FindByID(context.Background(), prop2.Name)
+	prop, _ = repo.FindByID(context.Background(), namespace.Default, prop2.Name)
 	readProps = append(readProps, prop)
 
 	assert.Equal(t, true, (readProps[0].Name != ""))
@@ -96,7 +97,7 @@ func TestFindByIdNotFound(t *testing.T) {
 	defer tearDown(repo)
 
 	id := "test.notfound.id"
-	_, err := repo.FindByID(context.Background(), id)
+	_, err := repo.FindByID(context.Background(), namespace.Default, id)
 
 	assert.Equal(t, errors.NewEntityNotFound(model.PropertySet{}, id), err)
 }
@@ -107,7 +108,7 @@ func TestFindByIdUnexpected(t *testing.T) {
 	defer tearDown(repo)
 
 	id := "test.notfound.id"
-	_, err := repo.FindByID(context.Background(), id)
+	_, err := repo.FindByID(context.Background(), namespace.Default, id)
 
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
@@ -125,10 +126,10 @@ func TestFindByName(t *testing.T) {
 	var readProps []*model.PropertySet
 	readProps = make([]*model.PropertySet, 0, 2)
 
-	prop, _ := repo.FindByName(context.Background(), prop1.Name)
+	prop, _ := repo.FindByName(context.Background(), namespace.Default, prop1.Name)
 	readProps = append(readProps, prop)
 
-	prop, _ = repo.FindByName(context.Background(), prop2.Name)
+	prop, _ = repo.FindByName(context.Background(), namespace.Default, prop2.Name)
 	readProps = append(readProps, prop)
 
 	assert.Equal(t, true, (readProps[0].Name != ""))
@@ -144,7 +145,7 @@ func TestFindByNameNotFound(t *testing.T) {
 	defer tearDown(repo)
 
 	name := "test.notfound.name"
-	_, err := repo.FindByName(context.Background(), name)
+	_, err := repo.FindByName(context.Background(), namespace.Default, name)
 
 	assert.Equal(t, errors.NewEntityNotFound(model.PropertySet{}, name), err)
 }
@@ -155,7 +156,7 @@ func TestFindByNameUnexpected(t *testing.T) {
 	defer tearDown(repo)
 
 	name := "test.notfound.name"
-	_, err := repo.FindByName(context.Background(), name)
+	_, err := repo.FindByName(context.Background(), namespace.Default, name)
 
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
@@ -168,15 +169,15 @@ func TestDelete(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 
 	assert.Equal(t, true, (readProps[0].Name != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Values, readProps[0].Values)
 
-	repo.Delete(context.Background(), readProps[0].Name)
+	repo.Delete(context.Background(), namespace.Default, readProps[0].Name, 0)
 	id := readProps[0].Name
-	_, err := repo.FindByID(context.Background(), id)
+	_, err := repo.FindByID(context.Background(), namespace.Default, id)
 
 	assert.Equal(t, errors.NewEntityNotFound(model.PropertySet{}, id), err)
 }
@@ -186,7 +187,7 @@ func TestDeleteNotFound(t *testing.T) {
 	defer tearDown(repo)
 
 	id := "test.notfound.id"
-	err := repo.Delete(context.Background(), id)
+	err := repo.Delete(context.Background(), namespace.Default, id, 0)
 	assert.Equal(t, errors.NewEntityNotFound(model.PropertySet{}, id), err)
 }
 
@@ -196,11 +197,28 @@ func TestDeleteUnexpectedFind(t *testing.T) {
 	defer tearDown(repo)
 
 	id := "test.notfound.id"
-	err := repo.Delete(context.Background(), id)
+	err := repo.Delete(context.Background(), namespace.Default, id, 0)
 
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
 
+func TestUpdateIncludes(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	set := &model.PropertySet{Name: "payments", Values: []string{"payments.url"}, Includes: []string{"common"}}
+	repo.Create(context.Background(), set)
+
+	found, _ := repo.FindByID(context.Background(), namespace.Default, set.Name)
+	assert.Equal(t, []string{"common"}, found.Includes)
+
+	set.Includes = []string{"common", "logging"}
+	repo.Update(context.Background(), set)
+
+	found, _ = repo.FindByID(context.Background(), namespace.Default, set.Name)
+	assert.Equal(t, []string{"common", "logging"}, found.Includes)
+}
+
 func TestUpdate(t *testing.T) {
 	repo := setup()
 	defer tearDown(repo)
@@ -209,7 +227,7 @@ func TestUpdate(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 	assert.Equal(t, true, (readProps[0].Name != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Values, readProps[0].Values)
@@ -219,7 +237,7 @@ func TestUpdate(t *testing.T) {
 
 	repo.Update(context.Background(), prop1)
 
-	found, _ := repo.FindByID(context.Background(), prop1.Name)
+	found, _ := repo.FindByID(context.Background(), namespace.Default, prop1.Name)
 	assert.Equal(t, found.Name, prop1.Name)
 	assert.Equal(t, found.Values, prop1.Values)
 }
@@ -232,7 +250,7 @@ func TestUpdateNotFound(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 	assert.Equal(t, true, (readProps[0].Name != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Values, readProps[0].Values)
@@ -240,7 +258,7 @@ func TestUpdateNotFound(t *testing.T) {
 	expectedValues := append(prop1.Values, "test.value.1.3")
 	prop1.Values = expectedValues
 
-	repo.Delete(context.Background(), prop1.Name)
+	repo.Delete(context.Background(), namespace.Default, prop1.Name, 0)
 	err := repo.Update(context.Background(), prop1)
 	assert.Equal(t, errors.NewEntityNotFound(model.PropertySet{}, prop1.Name), err)
 }
@@ -254,7 +272,7 @@ func TestUpdateUnExpected(t *testing.T) {
 
 	repo.Create(context.Background(), prop1)
 
-	readProps, _ := repo.ReadAll(context.Background())
+	readProps, _, _ := repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 	assert.Equal(t, true, (readProps[0].Name != ""))
 	assert.Equal(t, prop1.Name, readProps[0].Name)
 	assert.Equal(t, prop1.Values, readProps[0].Values)
@@ -267,6 +285,106 @@ func TestUpdateUnExpected(t *testing.T) {
 	assert.Equal(t, g_errors.New("database not open"), err)
 }
 
+func TestNamespaces(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}}
+	prop2 := &model.PropertySet{Namespace: "payments", Name: "test.name.1", Values: []string{"test.value.2.1"}}
+
+	assert.Equal(t, nil, repo.Create(context.Background(), prop1))
+	assert.Equal(t, nil, repo.Create(context.Background(), prop2))
+
+	readProps, _, _ := repo.ReadAll(context.Background(), "payments", model.Page{})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, "payments", readProps[0].Namespace)
+	assert.Equal(t, prop2.Values, readProps[0].Values)
+
+	found, _ := repo.FindByID(context.Background(), namespace.Default, prop1.Name)
+	assert.Equal(t, prop1.Values, found.Values)
+
+	repo.Delete(context.Background(), "payments", prop2.Name, 0)
+
+	_, err := repo.FindByID(context.Background(), "payments", prop2.Name)
+	assert.Equal(t, errors.NewEntityNotFound(model.PropertySet{}, prop2.Name), err)
+
+	found, _ = repo.FindByID(context.Background(), namespace.Default, prop1.Name)
+	assert.Equal(t, prop1.Values, found.Values)
+}
+
+func TestUpdateVersion(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}}
+	repo.Create(context.Background(), prop1)
+	assert.Equal(t, 1, prop1.Version)
+
+	prop1.Values = []string{"test.value.1.2"}
+	assert.Equal(t, nil, repo.Update(context.Background(), prop1))
+	assert.Equal(t, 2, prop1.Version)
+
+	stale := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.3"}, Version: 1}
+	err := repo.Update(context.Background(), stale)
+	assert.Equal(t, errors.NewPreconditionFailed(model.PropertySet{}, prop1.Name, 1), err)
+
+	found, _ := repo.FindByID(context.Background(), namespace.Default, prop1.Name)
+	assert.Equal(t, []string{"test.value.1.2"}, found.Values)
+	assert.Equal(t, 2, found.Version)
+}
+
+func TestDeleteVersion(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	prop1 := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}}
+	repo.Create(context.Background(), prop1)
+
+	err := repo.Delete(context.Background(), namespace.Default, prop1.Name, 2)
+	assert.Equal(t, errors.NewPreconditionFailed(model.PropertySet{}, prop1.Name, 2), err)
+
+	assert.Equal(t, nil, repo.Delete(context.Background(), namespace.Default, prop1.Name, 1))
+}
+
+func TestVersionSets(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	repo.db.Save(&propertySetDto{ID: "common", Name: "common", Values: []string{"db.host"}})
+	repo.versionSets()
+
+	found, _ := repo.FindByID(context.Background(), namespace.Default, "common")
+	assert.Equal(t, 1, found.Version)
+
+	found.Values = []string{"db.port"}
+	assert.Equal(t, nil, repo.Update(context.Background(), found))
+	assert.Equal(t, 2, found.Version)
+}
+
+func TestReadAllPage(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	for _, name := range []string{"c", "a", "b"} {
+		repo.Create(context.Background(), &model.PropertySet{Name: name, Values: []string{"test.value"}})
+	}
+
+	readProps, info, _ := repo.ReadAll(context.Background(), namespace.Default, model.Page{Limit: 2})
+	assert.Equal(t, 2, len(readProps))
+	assert.Equal(t, "a", readProps[0].Name)
+	assert.Equal(t, "b", readProps[1].Name)
+	assert.Equal(t, model.PageInfo{Total: 3, Next: "b"}, info)
+
+	readProps, info, _ = repo.ReadAll(context.Background(), namespace.Default, model.Page{Limit: 2, After: "b"})
+	assert.Equal(t, 1, len(readProps))
+	assert.Equal(t, "c", readProps[0].Name)
+	assert.Equal(t, model.PageInfo{Total: 3}, info)
+
+	readProps, _, _ = repo.ReadAll(context.Background(), namespace.Default, model.Page{Descending: true})
+	assert.Equal(t, "c", readProps[0].Name)
+	assert.Equal(t, "a", readProps[2].Name)
+}
+
 func BenchmarkReadAll(b *testing.B) {
 	repo := setup()
 	defer tearDown(repo)
@@ -278,7 +396,7 @@ func BenchmarkReadAll(b *testing.B) {
 	repo.Create(context.Background(), prop2)
 
 	for n := 0; n < b.N; n++ {
-		repo.ReadAll(context.Background())
+		repo.ReadAll(context.Background(), namespace.Default, model.Page{})
 	}
 
 }
@@ -4,20 +4,31 @@ import (
 	"context"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	ns "github.com/rghiorghisor/basic-go-rest-api/namespace"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const setCollection = "set_collection"
 
+// propertySetDto is identified by the namespace.Key of the set, so that names
+// remain unique only within a namespace. Sets stored before namespaces were
+// introduced hold only the identifier, which is also their name.
 type propertySetDto struct {
-	Name   string   `bson:"_id"`
-	Values []string `bson:"values"`
+	ID        string   `bson:"_id"`
+	Namespace string   `bson:"namespace,omitempty"`
+	Name      string   `bson:"name,omitempty"`
+	Values    []string `bson:"values"`
+	Includes  []string `bson:"includes,omitempty"`
+	Version   int      `bson:"version,omitempty"`
 }
 
 // PropertySetRepository is a representation of the property repository for
@@ -28,13 +39,31 @@ type PropertySetRepository struct {
 
 // New retrieves a new repository object ready to be used.
 func New(db *mongo.Database) storage.Repository {
-	return &PropertySetRepository{
+	repo := &PropertySetRepository{
 		dbCollection: db.Collection(setCollection),
 	}
+	repo.versionSets()
+
+	return repo
+}
+
+// versionSets gives the first version to the sets stored before the sets were
+// versioned, so that their entity tags can be used by conditional requests.
+func (repository PropertySetRepository) versionSets() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := repository.dbCollection.UpdateMany(ctx,
+		bson.M{"version": bson.M{"$in": bson.A{nil, 0}}},
+		bson.M{"$set": bson.M{"version": 1}})
+	if err != nil {
+		logger.Main.Error("Cannot version the sets.", err)
+	}
 }
 
 // Create a new entry based on the provided property.
 func (repository PropertySetRepository) Create(ctx context.Context, property *model.PropertySet) error {
+	property.Version = 1
 	dto := convertToDto(property)
 
 	_, err := repository.dbCollection.InsertOne(ctx, dto)
@@ -49,14 +78,38 @@ func (repository PropertySetRepository) Create(ctx context.Context, property *mo
 	return nil
 }
 
-// ReadAll retrieves all available properties.
-func (repository PropertySetRepository) ReadAll(ctx context.Context) ([]*model.PropertySet, error) {
-	cursor, error := repository.dbCollection.Find(ctx, bson.M{})
-	defer cursor.Close(ctx)
+// ReadAll retrieves the given page of the property sets within the given
+// namespace, sorted by name. Within a namespace, the identifiers sort as the
+// names do, which holds for the sets stored without a name as well.
+func (repository PropertySetRepository) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.PropertySet, model.PageInfo, error) {
+	filter := bson.M{"namespace": namespaceFilter(namespace)}
 
+	total, err := repository.dbCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	order := 1
+	operator := "$gt"
+	if page.Descending {
+		order = -1
+		operator = "$lt"
+	}
+
+	if page.After != "" {
+		filter["_id"] = bson.M{operator: ns.Key(namespace, page.After)}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: order}})
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit + 1))
+	}
+
+	cursor, error := repository.dbCollection.Find(ctx, filter, opts)
 	if error != nil {
-		return nil, error
+		return nil, model.PageInfo{}, error
 	}
+	defer cursor.Close(ctx)
 
 	result := make([]*propertySetDto, 0)
 
@@ -64,19 +117,27 @@ func (repository PropertySetRepository) ReadAll(ctx context.Context) ([]*model.P
 		dto := new(propertySetDto)
 		err := cursor.Decode(dto)
 		if err != nil {
-			return nil, err
+			return nil, model.PageInfo{}, err
 		}
 
 		result = append(result, dto)
 	}
 
-	return convertDtosToModel(result), nil
+	sets := convertDtosToModel(result)
+	info := model.PageInfo{Total: int(total)}
+	if page.Limit > 0 && len(sets) > page.Limit {
+		sets = sets[:page.Limit]
+		info.Next = sets[page.Limit-1].Name
+	}
+
+	return sets, info, nil
 }
 
-// FindByID retrieves the property matching the given id if such a property
-// exists; otherwise will return a not found error.
-func (repository PropertySetRepository) FindByID(context context.Context, id string) (*model.PropertySet, error) {
-	objID := id
+// FindByID retrieves the property set matching the given id within the given
+// namespace if such a property set exists; otherwise will return a not found
+// error.
+func (repository PropertySetRepository) FindByID(context context.Context, namespace string, id string) (*model.PropertySet, error) {
+	objID := ns.Key(namespace, id)
 
 	result := new(propertySetDto)
 	err := repository.dbCollection.FindOne(context, bson.M{
@@ -148,31 +209,93 @@ func (repository PropertySetRepository) findOneBy(context context.Context, query
 	return convertToModel(result), nil
 }
 
-// Delete the property with the given id.
-func (repository PropertySetRepository) Delete(context context.Context, id string) error {
-	objID := id
+// Delete the property set with the given id within the given namespace. Unless
+// the given version is zero, the set must have that version.
+func (repository PropertySetRepository) Delete(context context.Context, namespace string, id string, version int) error {
+	objID := ns.Key(namespace, id)
+
+	result, err := repository.dbCollection.DeleteOne(context, withVersion(bson.M{"_id": objID}, version))
+	if err != nil {
+		return err
+	}
 
-	_, err := repository.dbCollection.DeleteOne(context, bson.M{
-		"_id": objID})
+	if result.DeletedCount == 0 {
+		return repository.notModified(context, namespace, id, version)
+	}
 
-	return err
+	return nil
 }
 
-// Update all fields of the given property.
+// Update all fields of the given property. Unless the version of the given set
+// is zero, the stored set must have that version. The version is incremented.
 func (repository PropertySetRepository) Update(ctx context.Context, property *model.PropertySet) error {
-	_, err := repository.dbCollection.UpdateOne(ctx,
-		bson.M{"_id": property.Name},
-		bson.D{primitive.E{Key: "$set", Value: bson.D{
-			primitive.E{Key: "values", Value: property.Values},
-		}}})
+	filter := withVersion(bson.M{"_id": ns.Key(property.Namespace, property.Name)}, property.Version)
+
+	updated := new(propertySetDto)
+	err := repository.dbCollection.FindOneAndUpdate(ctx,
+		filter,
+		bson.D{
+			primitive.E{Key: "$set", Value: bson.D{
+				primitive.E{Key: "values", Value: property.Values},
+				primitive.E{Key: "includes", Value: property.Includes},
+			}},
+			primitive.E{Key: "$inc", Value: bson.D{
+				primitive.E{Key: "version", Value: 1},
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(updated)
+
+	if err == mongo.ErrNoDocuments {
+		return repository.notModified(ctx, property.Namespace, property.Name, property.Version)
+	}
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	property.Version = updated.Version
+
+	return nil
+}
+
+// withVersion adds the required version to the given filter, unless it is zero.
+func withVersion(filter bson.M, version int) bson.M {
+	if version != 0 {
+		filter["version"] = version
+	}
+
+	return filter
+}
+
+// notModified retrieves the error explaining why the set with the given id was
+// not modified by a conditional operation: either it does not exist or it does
+// not have the required version.
+func (repository PropertySetRepository) notModified(ctx context.Context, namespace string, id string, version int) error {
+	if _, err := repository.FindByID(ctx, namespace, id); err != nil {
+		return err
+	}
+
+	return errors.NewPreconditionFailed(model.PropertySet{}, id, version)
+}
+
+// namespaceFilter retrieves the filter value matching the given namespace.
+// Sets of the default namespace are stored without a namespace.
+func namespaceFilter(namespace string) interface{} {
+	if namespace == ns.Default {
+		return nil
+	}
+
+	return namespace
 }
 
 func convertToDto(property *model.PropertySet) *propertySetDto {
 	return &propertySetDto{
-		Name:   property.Name,
-		Values: property.Values,
+		ID:        ns.Key(property.Namespace, property.Name),
+		Namespace: property.Namespace,
+		Name:      property.Name,
+		Values:    property.Values,
+		Includes:  property.Includes,
+		Version:   property.Version,
 	}
 }
 
@@ -187,8 +310,16 @@ func convertDtosToModel(dtos []*propertySetDto) []*model.PropertySet {
 }
 
 func convertToModel(dto *propertySetDto) *model.PropertySet {
+	name := dto.Name
+	if name == "" {
+		name = dto.ID
+	}
+
 	return &model.PropertySet{
-		Name:   dto.Name,
-		Values: dto.Values,
+		Namespace: dto.Namespace,
+		Name:      name,
+		Values:    dto.Values,
+		Includes:  dto.Includes,
+		Version:   dto.Version,
 	}
 }
@@ -7,14 +7,21 @@ import (
 )
 
 // Repository interface defining the functionality of a basic implementations.
+//
+// Property sets are identified by their name, which is unique only within the
+// namespace of the set.
+//
+// Update and Delete are conditional: unless the given version is zero, they
+// fail with a precondition failed error if the stored set has another version.
+// The check and the change are performed atomically.
 type Repository interface {
 	Create(ctx context.Context, property *model.PropertySet) error
 
-	ReadAll(ctx context.Context) ([]*model.PropertySet, error)
+	ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.PropertySet, model.PageInfo, error)
 
-	FindByID(context context.Context, id string) (*model.PropertySet, error)
+	FindByID(context context.Context, namespace string, id string) (*model.PropertySet, error)
 
-	Delete(context context.Context, id string) error
+	Delete(context context.Context, namespace string, id string, version int) error
 
 	Update(ctx context.Context, property *model.PropertySet) error
 }
@@ -10,13 +10,21 @@ import (
 type Service interface {
 	Create(ctx context.Context, property *model.PropertySet) error
 
-	ReadAll(ctx context.Context) ([]*model.PropertySet, error)
+	ReadAll(ctx context.Context, page model.Page) ([]*model.PropertySet, model.PageInfo, error)
 
 	FindByID(ctx context.Context, id string) (*model.PropertySet, error)
 
 	FindValuesByID(ctx context.Context, id string) ([]string, error)
 
-	Delete(ctx context.Context, id string) error
+	Expand(ctx context.Context, id string) ([]model.SetMember, error)
+
+	Orphans(ctx context.Context, id string) ([]string, error)
+
+	RenameMember(ctx context.Context, name string, newName string) ([]string, error)
+
+	Including(ctx context.Context, names []string) ([]string, error)
+
+	Delete(ctx context.Context, id string, version int) error
 
 	Update(ctx context.Context, property *model.PropertySet) error
 }
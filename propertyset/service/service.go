@@ -2,43 +2,121 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/config"
 	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	propertystorage "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage"
 	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
 )
 
 // PropertySetService defines the service handling property sets operations.
 type PropertySetService struct {
 	repository storage.Repository
+	properties propertystorage.Repository
+	transactor transaction.Transactor
+	hub        *watch.Hub
+	auditor    audit.Service
+	trash      trash.Service
+	integrity  string
 }
 
-// New creates a PropertySetService.
+// New creates a PropertySetService, keeping the names of the sets consistent
+// with the properties according to the configured integrity mode. An unknown
+// mode is handled as config.IntegrityReport.
 //
 // As this service needs access to a repository to perform action, it is the
 // responsibility of the service to get the correct repo from the storage parameter.
-func New(storage *serverstorage.Storage) propertyset.Service {
+// All changes are recorded by the given auditor and published to the given hub.
+// The deleted sets are moved to the given trash, within the same transaction as
+// their deletion.
+func New(storage *serverstorage.Storage, hub *watch.Hub, auditor audit.Service, trashService trash.Service, configuration *config.IntegrityConfiguration) propertyset.Service {
+	integrity := configuration.Mode
+	switch integrity {
+	case config.IntegrityReport, config.IntegrityReject, config.IntegrityCascade:
+	case "":
+		integrity = config.IntegrityReport
+	default:
+		logger.Main.Warn(fmt.Sprintf("Unknown integrity mode '%s'. Using '%s'.", integrity, config.IntegrityReport))
+		integrity = config.IntegrityReport
+	}
+
 	return PropertySetService{
 		repository: storage.PropertySetRepository,
+		properties: storage.PropertyRepository,
+		transactor: storage.Transactor,
+		hub:        hub,
+		auditor:    auditor,
+		trash:      trashService,
+		integrity:  integrity,
 	}
 }
 
-// Create processes a new property set and adds it to the repository.
+// Create processes a new property set and adds it to the repository. The set
+// is placed in the namespace of the given context and its name must be unique
+// within that namespace. The name cannot contain '/', which separates the
+// namespace from the name within the keys of the sets (see namespace.Key). The
+// values may be property names as well as glob or regular expression name
+// patterns, which must be valid. The included sets must exist within the same
+// namespace and they must not include the new set back. When the integrity mode
+// is config.IntegrityReject, the named properties must exist within the same
+// namespace as well. The set is written along with the audit record,
+// all-or-nothing.
 func (service PropertySetService) Create(ctx context.Context, prop *model.PropertySet) error {
-	return service.repository.Create(ctx, prop)
+	prop.Namespace = namespace.FromContext(ctx)
+	if strings.Contains(prop.Name, "/") {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), "'name' cannot contain '/'.")
+	}
+
+	if err := checkPatterns(prop); err != nil {
+		return err
+	}
+
+	if err := service.checkIncludes(ctx, prop); err != nil {
+		return err
+	}
+
+	if err := service.checkMembers(ctx, prop); err != nil {
+		return err
+	}
+
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := service.repository.Create(ctx, prop); err != nil {
+			return err
+		}
+
+		if err := service.auditor.Record(ctx, audit.SetChange(audit.ActionCreate, nil, prop)); err != nil {
+			return err
+		}
+
+		service.hub.Publish(ctx, watch.SetEvent(watch.Created, prop))
+
+		return nil
+	})
 }
 
-// ReadAll retrieves all available property sets.
-func (service PropertySetService) ReadAll(ctx context.Context) ([]*model.PropertySet, error) {
-	return service.repository.ReadAll(ctx)
+// ReadAll retrieves the given page of the property sets of the namespace of the
+// given context.
+func (service PropertySetService) ReadAll(ctx context.Context, page model.Page) ([]*model.PropertySet, model.PageInfo, error) {
+	return service.repository.ReadAll(ctx, namespace.FromContext(ctx), page)
 }
 
 // FindByID retrieves the property set matching the given id if such a property set
-// exists; otherwise will return a not found error.
+// exists within the namespace of the given context; otherwise will return a not
+// found error.
 func (service PropertySetService) FindByID(ctx context.Context, id string) (*model.PropertySet, error) {
-	foundProp, err := service.repository.FindByID(ctx, id)
+	foundProp, err := service.repository.FindByID(ctx, namespace.FromContext(ctx), id)
 
 	if err != nil {
 		return nil, err
@@ -52,22 +130,338 @@ func (service PropertySetService) FindByID(ctx context.Context, id string) (*mod
 }
 
 // FindValuesByID retrieves all values associated with the set identified by the
-// given parameter.
+// given parameter, i.e. the names of its effective members. See Expand.
 func (service PropertySetService) FindValuesByID(ctx context.Context, id string) ([]string, error) {
+	members, err := service.Expand(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.MemberNames(members), nil
+}
+
+// Expand retrieves the effective membership of the set identified by the given
+// parameter, by recursively expanding the sets it includes. The included sets
+// that no longer exist are ignored. An invalid entity error is returned if the
+// includes form a cycle.
+func (service PropertySetService) Expand(ctx context.Context, id string) ([]model.SetMember, error) {
 	foundSet, err := service.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return foundSet.Values, nil
+	members, err := foundSet.Expand(service.lookup(ctx))
+	if err != nil {
+		return nil, toIncludeError(err)
+	}
+
+	return members, nil
 }
 
-// Delete the property set with the given id.
-func (service PropertySetService) Delete(ctx context.Context, id string) error {
-	return service.repository.Delete(ctx, id)
+// Orphans retrieves the names within the values of the set identified by the
+// given parameter that do not resolve to a property of its namespace. Neither
+// the name patterns nor the values of the included sets are considered.
+func (service PropertySetService) Orphans(ctx context.Context, id string) ([]string, error) {
+	foundSet, err := service.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.orphans(ctx, foundSet)
 }
 
-// Update all fields of the given property set.
+// RenameMember replaces the given property name with the new one within the
+// values of the sets of the namespace of the given context, or removes it if
+// the new name is empty, and retrieves the names of the changed sets. The sets
+// are changed only if the integrity mode is config.IntegrityCascade. The sets
+// are written within the given context, i.e. within the transaction of the
+// property change, if any.
+func (service PropertySetService) RenameMember(ctx context.Context, name string, newName string) ([]string, error) {
+	if service.integrity != config.IntegrityCascade {
+		return nil, nil
+	}
+
+	sets, _, err := service.repository.ReadAll(ctx, namespace.FromContext(ctx), model.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, set := range sets {
+		values, changed := renameValue(set.Values, name, newName)
+		if !changed {
+			continue
+		}
+
+		set.Values = values
+		if err := service.Update(ctx, set); err != nil {
+			return nil, err
+		}
+
+		names = append(names, set.Name)
+	}
+
+	return names, nil
+}
+
+// Including retrieves the given set names along with the names of the sets of
+// the namespace of the given context including any of them, directly or through
+// other sets. The sets that do not exist are ignored.
+func (service PropertySetService) Including(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	sets, _, err := service.repository.ReadAll(ctx, namespace.FromContext(ctx), model.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	including := make(map[string]bool)
+	for _, name := range names {
+		including[name] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, set := range sets {
+			if including[set.Name] {
+				continue
+			}
+
+			for _, name := range set.Includes {
+				if including[name] {
+					including[set.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	result := append([]string(nil), names...)
+	for _, set := range sets {
+		if including[set.Name] && !contains(names, set.Name) {
+			result = append(result, set.Name)
+		}
+	}
+
+	return result, nil
+}
+
+// Delete the property set with the given id, moving it to the trash. Unless
+// the given version is zero, the set is deleted only if it still has that
+// version. The deletion is written along with the trash entry and the audit
+// record, all-or-nothing.
+func (service PropertySetService) Delete(ctx context.Context, id string, version int) error {
+	foundSet, err := service.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := service.repository.Delete(ctx, foundSet.Namespace, id, version); err != nil {
+			return err
+		}
+
+		if err := service.trash.Put(ctx, trash.SetEntry(foundSet)); err != nil {
+			return err
+		}
+
+		if err := service.auditor.Record(ctx, audit.SetChange(audit.ActionDelete, foundSet, nil)); err != nil {
+			return err
+		}
+
+		service.hub.Publish(ctx, watch.SetEvent(watch.Deleted, &model.PropertySet{Namespace: foundSet.Namespace, Name: id}))
+
+		return nil
+	})
+}
+
+// Update all fields of the given property set. Unless the version of the given
+// set is zero, the set is updated only if it still has that version. As for
+// Create, the name patterns must be valid, the included sets must exist and they
+// must not include the set back, while the named properties must exist when the
+// integrity mode is config.IntegrityReject. The set is written along with the
+// audit record, all-or-nothing.
 func (service PropertySetService) Update(ctx context.Context, prop *model.PropertySet) error {
-	return service.repository.Update(ctx, prop)
+	foundSet, err := service.FindByID(ctx, prop.Name)
+	if err != nil {
+		return err
+	}
+
+	prop.Namespace = foundSet.Namespace
+	if err := checkPatterns(prop); err != nil {
+		return err
+	}
+
+	if err := service.checkIncludes(ctx, prop); err != nil {
+		return err
+	}
+
+	if err := service.checkMembers(ctx, prop); err != nil {
+		return err
+	}
+
+	return service.transactor.Run(ctx, func(ctx context.Context) error {
+		if err := service.repository.Update(ctx, prop); err != nil {
+			return err
+		}
+
+		if err := service.auditor.Record(ctx, audit.SetChange(audit.ActionUpdate, foundSet, prop)); err != nil {
+			return err
+		}
+
+		service.hub.Publish(ctx, watch.SetEvent(watch.Updated, prop))
+
+		return nil
+	})
+}
+
+// checkPatterns validates the name patterns among the values of the given set.
+func checkPatterns(set *model.PropertySet) error {
+	for _, value := range set.Values {
+		if !model.IsNamePattern(value) {
+			continue
+		}
+
+		if _, err := model.ParseNamePattern(value); err != nil {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), fmt.Sprintf("'values' has invalid pattern '%s'.", value))
+		}
+	}
+
+	return nil
+}
+
+// checkMembers validates that the properties named by the given set exist
+// within its namespace, unless the integrity mode is other than
+// config.IntegrityReject.
+func (service PropertySetService) checkMembers(ctx context.Context, set *model.PropertySet) error {
+	if service.integrity != config.IntegrityReject {
+		return nil
+	}
+
+	orphans, err := service.orphans(ctx, set)
+	if err != nil {
+		return err
+	}
+
+	if len(orphans) > 0 {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), fmt.Sprintf("'values' references unknown property '%s'.", orphans[0]))
+	}
+
+	return nil
+}
+
+// orphans retrieves the names within the values of the given set that do not
+// resolve to a property of its namespace, in the order of the values.
+func (service PropertySetService) orphans(ctx context.Context, set *model.PropertySet) ([]string, error) {
+	names := model.NewMembership(set.Values).Names()
+	orphans := make([]string, 0)
+	if len(names) == 0 {
+		return orphans, nil
+	}
+
+	props, _, err := service.properties.ReadAllFiltered(ctx, set.Namespace, names, propertystorage.Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(props))
+	for _, prop := range props {
+		found[prop.Name] = true
+	}
+
+	for _, name := range names {
+		if !found[name] {
+			orphans = append(orphans, name)
+		}
+	}
+
+	return orphans, nil
+}
+
+// renameValue replaces the given name with the new one within the given values,
+// or removes it if the new name is empty. The new name is not repeated if the
+// values already contain it.
+func renameValue(values []string, name string, newName string) ([]string, bool) {
+	renamed := make([]string, 0, len(values))
+	changed := false
+	for _, value := range values {
+		if value == name {
+			value = newName
+			changed = true
+		}
+
+		if value == "" || (value == newName && contains(renamed, newName)) {
+			continue
+		}
+
+		renamed = append(renamed, value)
+	}
+
+	return renamed, changed
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkIncludes validates that the sets included by the given set exist within
+// its namespace and that they do not include it back.
+func (service PropertySetService) checkIncludes(ctx context.Context, set *model.PropertySet) error {
+	lookup := service.lookup(ctx, set)
+
+	for _, name := range set.Includes {
+		found, err := lookup(name)
+		if err != nil {
+			return err
+		}
+
+		if found == nil {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), fmt.Sprintf("'includes' references unknown set '%s'.", name))
+		}
+	}
+
+	if _, err := set.Expand(lookup); err != nil {
+		return toIncludeError(err)
+	}
+
+	return nil
+}
+
+// lookup retrieves a model.SetLookup finding the sets by name within the
+// namespace of the given context. The given sets are used instead of the stored
+// ones with the same names.
+func (service PropertySetService) lookup(ctx context.Context, sets ...*model.PropertySet) model.SetLookup {
+	ns := namespace.FromContext(ctx)
+
+	return func(name string) (*model.PropertySet, error) {
+		for _, set := range sets {
+			if set.Name == name {
+				return set, nil
+			}
+		}
+
+		foundSet, err := service.repository.FindByID(ctx, ns, name)
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return foundSet, err
+	}
+}
+
+func toIncludeError(err error) error {
+	if cycle, ok := err.(*model.SetCycleError); ok {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), fmt.Sprintf("'includes' has a cycle: %s.", strings.Join(cycle.Names, " -> ")))
+	}
+
+	return err
 }
@@ -20,16 +20,20 @@ func (m *PropertySetServiceMock) Create(ctx context.Context, property *model.Pro
 }
 
 // ReadAll mock function.
-func (m *PropertySetServiceMock) ReadAll(ctx context.Context) ([]*model.PropertySet, error) {
-	args := m.Called()
+func (m *PropertySetServiceMock) ReadAll(ctx context.Context, page model.Page) ([]*model.PropertySet, model.PageInfo, error) {
+	args := m.Called(page)
 
-	return args.Get(0).([]*model.PropertySet), args.Error(1)
+	return args.Get(0).([]*model.PropertySet), args.Get(1).(model.PageInfo), args.Error(2)
 }
 
 // FindByID mock function.
 func (m *PropertySetServiceMock) FindByID(ctx context.Context, id string) (*model.PropertySet, error) {
 	args := m.Called(id)
 
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
 	return args.Get(0).(*model.PropertySet), args.Error(1)
 }
 
@@ -40,10 +44,54 @@ func (m *PropertySetServiceMock) FindValuesByID(ctx context.Context, id string)
 	return args.Get(0).([]string), args.Error(1)
 }
 
-// Delete mock function.
-func (m *PropertySetServiceMock) Delete(ctx context.Context, id string) error {
+// Expand mock function.
+func (m *PropertySetServiceMock) Expand(ctx context.Context, id string) ([]model.SetMember, error) {
+	args := m.Called(id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.SetMember), args.Error(1)
+}
+
+// Orphans mock function.
+func (m *PropertySetServiceMock) Orphans(ctx context.Context, id string) ([]string, error) {
 	args := m.Called(id)
 
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// RenameMember mock function.
+func (m *PropertySetServiceMock) RenameMember(ctx context.Context, name string, newName string) ([]string, error) {
+	args := m.Called(name, newName)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// Including mock function.
+func (m *PropertySetServiceMock) Including(ctx context.Context, names []string) ([]string, error) {
+	args := m.Called(names)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// Delete mock function.
+func (m *PropertySetServiceMock) Delete(ctx context.Context, id string, version int) error {
+	args := m.Called(id, version)
+
 	return args.Error(0)
 }
 
@@ -7,10 +7,17 @@ import (
 	"strconv"
 	"testing"
 
+	audit_service "github.com/rghiorghisor/basic-go-rest-api/audit/service"
+	"github.com/rghiorghisor/basic-go-rest-api/config"
 	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
 	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	propertystorage "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
 	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
 	"github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -49,10 +56,10 @@ func TestReadAll(t *testing.T) {
 		properties[i] = &model.PropertySet{Name: "test.name." + strconv.Itoa(i), Values: []string{"test.value." + strconv.Itoa(i) + ".1", "test.value." + strconv.Itoa(i) + ".2"}}
 	}
 
-	repo.On("ReadAll").Return(properties, nil)
+	repo.On("ReadAll", namespace.Default, model.Page{}).Return(properties, model.PageInfo{Total: len(properties)}, nil)
 
 	ctx := context.Background()
-	actual, err := srv.ReadAll(ctx)
+	actual, _, err := srv.ReadAll(ctx, model.Page{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, properties, actual)
@@ -62,7 +69,7 @@ func TestFindByID(t *testing.T) {
 	srv, repo := setup()
 
 	found := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1", "test.value.1.2"}}
-	repo.On("FindByID", found.Name).Return(found, nil)
+	repo.On("FindByID", namespace.Default, found.Name).Return(found, nil)
 
 	ctx := context.Background()
 	actual, err := srv.FindByID(ctx, found.Name)
@@ -75,7 +82,7 @@ func TestFindByIDNotFound(t *testing.T) {
 	srv, repo := setup()
 
 	notFoundID := "testid"
-	repo.On("FindByID", notFoundID).Return(nil, nil)
+	repo.On("FindByID", namespace.Default, notFoundID).Return(nil, nil)
 
 	ctx := context.Background()
 	actual, err := srv.FindByID(ctx, notFoundID)
@@ -89,7 +96,7 @@ func TestFindByIDUnexpected(t *testing.T) {
 
 	notFoundID := "TestId"
 	expectedError := errors.New("unexpected")
-	repo.On("FindByID", notFoundID).Return(nil, expectedError)
+	repo.On("FindByID", namespace.Default, notFoundID).Return(nil, expectedError)
 
 	ctx := context.Background()
 	actual, err := srv.FindByID(ctx, notFoundID)
@@ -103,6 +110,7 @@ func TestUpdate(t *testing.T) {
 
 	toUpdate := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1", "test.value.1.2"}}
 
+	repo.On("FindByID", namespace.Default, toUpdate.Name).Return(&model.PropertySet{Name: toUpdate.Name}, nil)
 	repo.On("Update", toUpdate).Return(nil)
 
 	ctx := context.Background()
@@ -116,40 +124,267 @@ func TestDelete(t *testing.T) {
 
 	toDeleteID := "TestID"
 
-	repo.On("Delete", toDeleteID).Return(nil)
+	repo.On("FindByID", namespace.Default, toDeleteID).Return(&model.PropertySet{Name: toDeleteID}, nil)
+	repo.On("Delete", namespace.Default, toDeleteID, 0).Return(nil)
 
 	ctx := context.Background()
-	err := srv.Delete(ctx, toDeleteID)
+	err := srv.Delete(ctx, toDeleteID, 0)
 
 	assert.Nil(t, err)
 }
 
+func TestCreateNamespaced(t *testing.T) {
+	srv, repo := setup()
+
+	toCreate := &model.PropertySet{Name: "test.name.1", Values: []string{"test.value.1.1"}}
+
+	repo.On("Create", toCreate).Return(nil)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	actualErr := srv.Create(ctx, toCreate)
+
+	assert.Nil(t, actualErr)
+	assert.Equal(t, "payments", toCreate.Namespace)
+}
+
+func TestFindValuesByIDNamespaced(t *testing.T) {
+	srv, repo := setup()
+
+	found := &model.PropertySet{Namespace: "payments", Name: "test.name.1", Values: []string{"test.value.1.1"}}
+	repo.On("FindByID", "payments", found.Name).Return(found, nil)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	actual, err := srv.FindValuesByID(ctx, found.Name)
+
+	assert.Nil(t, err)
+	assert.Equal(t, found.Values, actual)
+}
+
+func TestCreateIncludes(t *testing.T) {
+	srv, repo := setup()
+
+	toCreate := &model.PropertySet{Name: "payments", Values: []string{"payments.url"}, Includes: []string{"common"}}
+	repo.On("FindByID", namespace.Default, "common").Return(&model.PropertySet{Name: "common", Values: []string{"db.host"}}, nil)
+	repo.On("Create", toCreate).Return(nil)
+
+	actualErr := srv.Create(context.Background(), toCreate)
+
+	assert.Nil(t, actualErr)
+}
+
+func TestCreateUnknownInclude(t *testing.T) {
+	srv, repo := setup()
+
+	toCreate := &model.PropertySet{Name: "payments", Includes: []string{"missing"}}
+	repo.On("FindByID", namespace.Default, "missing").Return(nil, apperrors.NewEntityNotFound(model.PropertySet{}, "missing"))
+
+	actualErr := srv.Create(context.Background(), toCreate)
+
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), "'includes' references unknown set 'missing'."), actualErr)
+	repo.AssertNotCalled(t, "Create", toCreate)
+}
+
+func TestCreateInvalidName(t *testing.T) {
+	srv, repo := setup()
+
+	toCreate := &model.PropertySet{Name: "payments/common", Values: []string{"db.host"}}
+
+	actualErr := srv.Create(context.Background(), toCreate)
+
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), "'name' cannot contain '/'."), actualErr)
+	repo.AssertNotCalled(t, "Create", toCreate)
+}
+
+func TestCreateInvalidPattern(t *testing.T) {
+	srv, repo := setup()
+
+	toCreate := &model.PropertySet{Name: "payments", Values: []string{"payments.*", "/payments.(/"}}
+
+	actualErr := srv.Create(context.Background(), toCreate)
+
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), "'values' has invalid pattern '/payments.(/'."), actualErr)
+	repo.AssertNotCalled(t, "Create", toCreate)
+}
+
+func TestUpdateIncludeCycle(t *testing.T) {
+	srv, repo := setup()
+
+	toUpdate := &model.PropertySet{Name: "common", Values: []string{"db.host"}, Includes: []string{"payments"}}
+	repo.On("FindByID", namespace.Default, "common").Return(&model.PropertySet{Name: "common", Values: []string{"db.host"}}, nil)
+	repo.On("FindByID", namespace.Default, "payments").Return(&model.PropertySet{Name: "payments", Includes: []string{"common"}}, nil)
+
+	actualErr := srv.Update(context.Background(), toUpdate)
+
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), "'includes' has a cycle: common -> payments -> common."), actualErr)
+	repo.AssertNotCalled(t, "Update", toUpdate)
+}
+
+func TestFindValuesByIDIncludes(t *testing.T) {
+	srv, repo := setup()
+
+	repo.On("FindByID", "payments", "payments").Return(&model.PropertySet{Namespace: "payments", Name: "payments", Values: []string{"payments.url", "db.host"}, Includes: []string{"common", "removed"}}, nil)
+	repo.On("FindByID", "payments", "common").Return(&model.PropertySet{Namespace: "payments", Name: "common", Values: []string{"db.host", "db.port"}}, nil)
+	repo.On("FindByID", "payments", "removed").Return(nil, apperrors.NewEntityNotFound(model.PropertySet{}, "removed"))
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	actual, err := srv.FindValuesByID(ctx, "payments")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"db.host", "db.port", "payments.url"}, actual)
+
+	members, err := srv.Expand(ctx, "payments")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.SetMember{{Name: "db.host", Set: "payments"}, {Name: "db.port", Set: "common"}, {Name: "payments.url", Set: "payments"}}, members)
+}
+
+func TestCreateRejectUnknownMember(t *testing.T) {
+	srv, repo, properties := setupIntegrity(config.IntegrityReject)
+
+	toCreate := &model.PropertySet{Name: "payments", Values: []string{"db.host", "payments.*", "db.port"}}
+	properties.On("ReadAllFiltered", namespace.Default, []string{"db.host", "db.port"}).Return([]*model.Property{{Name: "db.host"}}, nil)
+
+	actualErr := srv.Create(context.Background(), toCreate)
+
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.PropertySet{}), "'values' references unknown property 'db.port'."), actualErr)
+	repo.AssertNotCalled(t, "Create", toCreate)
+}
+
+func TestCreateReportUnknownMember(t *testing.T) {
+	srv, repo, properties := setupIntegrity(config.IntegrityReport)
+
+	toCreate := &model.PropertySet{Name: "payments", Values: []string{"db.port"}}
+	repo.On("Create", toCreate).Return(nil)
+
+	actualErr := srv.Create(context.Background(), toCreate)
+
+	assert.Nil(t, actualErr)
+	properties.AssertNotCalled(t, "ReadAllFiltered", mock.Anything, mock.Anything)
+}
+
+func TestOrphans(t *testing.T) {
+	srv, repo, properties := setupIntegrity(config.IntegrityReport)
+
+	repo.On("FindByID", namespace.Default, "payments").Return(&model.PropertySet{Name: "payments", Values: []string{"db.port", "payments.*", "db.host", "log.level"}}, nil)
+	properties.On("ReadAllFiltered", namespace.Default, []string{"db.port", "db.host", "log.level"}).Return([]*model.Property{{Name: "db.host"}}, nil)
+
+	orphans, err := srv.Orphans(context.Background(), "payments")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"db.port", "log.level"}, orphans)
+}
+
+func TestRenameMemberCascade(t *testing.T) {
+	srv, repo, _ := setupIntegrity(config.IntegrityCascade)
+
+	payments := &model.PropertySet{Name: "payments", Values: []string{"db.host", "payments.url"}, Version: 2}
+	common := &model.PropertySet{Name: "common", Values: []string{"db.host", "db.url"}, Version: 1}
+	logging := &model.PropertySet{Name: "logging", Values: []string{"log.level"}, Version: 1}
+	repo.On("ReadAll", namespace.Default, model.Page{}).Return([]*model.PropertySet{common, logging, payments}, model.PageInfo{Total: 3}, nil)
+	repo.On("FindByID", namespace.Default, "common").Return(&model.PropertySet{Name: "common", Values: []string{"db.host", "db.url"}, Version: 1}, nil)
+	repo.On("FindByID", namespace.Default, "payments").Return(&model.PropertySet{Name: "payments", Values: []string{"db.host", "payments.url"}, Version: 2}, nil)
+	repo.On("Update", mock.Anything).Return(nil)
+
+	names, err := srv.RenameMember(context.Background(), "db.host", "db.url")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"common", "payments"}, names)
+	repo.AssertCalled(t, "Update", &model.PropertySet{Name: "common", Values: []string{"db.url"}, Version: 1})
+	repo.AssertCalled(t, "Update", &model.PropertySet{Name: "payments", Values: []string{"db.url", "payments.url"}, Version: 2})
+	repo.AssertNumberOfCalls(t, "Update", 2)
+
+	names, err = srv.RenameMember(context.Background(), "payments.url", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"payments"}, names)
+	repo.AssertCalled(t, "Update", &model.PropertySet{Name: "payments", Values: []string{"db.url"}, Version: 2})
+}
+
+func TestIncluding(t *testing.T) {
+	srv, repo, _ := setupIntegrity(config.IntegrityCascade)
+
+	sets := []*model.PropertySet{
+		{Name: "all", Includes: []string{"payments"}},
+		{Name: "common"},
+		{Name: "logging", Includes: []string{"other"}},
+		{Name: "payments", Includes: []string{"common"}},
+	}
+	repo.On("ReadAll", namespace.Default, model.Page{}).Return(sets, model.PageInfo{Total: 4}, nil)
+
+	names, err := srv.Including(context.Background(), []string{"common"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"common", "all", "payments"}, names)
+
+	names, err = srv.Including(context.Background(), nil)
+
+	assert.Nil(t, err)
+	assert.Nil(t, names)
+	repo.AssertNumberOfCalls(t, "ReadAll", 1)
+}
+
+func TestRenameMemberReport(t *testing.T) {
+	srv, repo, _ := setupIntegrity(config.IntegrityReport)
+
+	names, err := srv.RenameMember(context.Background(), "db.host", "")
+
+	assert.Nil(t, err)
+	assert.Nil(t, names)
+	repo.AssertNotCalled(t, "ReadAll", mock.Anything, mock.Anything)
+}
+
 func setup() (service propertyset.Service, repo *PropertyRepositoryMock) {
+	service, repo, _ = setupIntegrity("")
+
+	return service, repo
+}
+
+func setupIntegrity(mode string) (service propertyset.Service, repo *PropertyRepositoryMock, properties *PropertiesMock) {
 	repoMock := new(PropertyRepositoryMock)
-	storage := &storage.Storage{PropertySetRepository: repoMock}
-	service = New(storage)
+	propertiesMock := new(PropertiesMock)
+	storage := &storage.Storage{PropertySetRepository: repoMock, PropertyRepository: propertiesMock, Transactor: &transaction.TransactorMock{}}
+	auditor := new(audit_service.AuditServiceMock)
+	auditor.On("Record", mock.Anything).Return(nil)
+	trash := new(trash_service.TrashServiceMock)
+	trash.On("Put", mock.Anything).Return(nil)
+	service = New(storage, watch.NewHub(), auditor, trash, &config.IntegrityConfiguration{Mode: mode})
+
+	return service, repoMock, propertiesMock
+}
+
+// PropertiesMock mocks the property repository, of which the set service only
+// reads the properties matching some names.
+type PropertiesMock struct {
+	mock.Mock
+	propertystorage.Repository
+}
+
+func (m *PropertiesMock) ReadAllFiltered(ctx context.Context, namespace string, names []string, filter propertystorage.Filter) ([]*model.Property, model.PageInfo, error) {
+	args := m.Called(namespace, names)
+
+	props := args.Get(0).([]*model.Property)
 
-	return service, repoMock
+	return props, model.PageInfo{Total: len(props)}, args.Error(1)
 }
 
 type PropertyRepositoryMock struct {
 	mock.Mock
 }
 
-func (m PropertyRepositoryMock) Create(ctx context.Context, property *model.PropertySet) error {
+func (m *PropertyRepositoryMock) Create(ctx context.Context, property *model.PropertySet) error {
 	args := m.Called(property)
 
 	return args.Error(0)
 }
 
-func (m PropertyRepositoryMock) ReadAll(ctx context.Context) ([]*model.PropertySet, error) {
-	args := m.Called()
+func (m *PropertyRepositoryMock) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.PropertySet, model.PageInfo, error) {
+	args := m.Called(namespace, page)
 
-	return args.Get(0).([]*model.PropertySet), args.Error(1)
+	return args.Get(0).([]*model.PropertySet), args.Get(1).(model.PageInfo), args.Error(2)
 }
 
-func (m PropertyRepositoryMock) FindByID(context context.Context, id string) (*model.PropertySet, error) {
-	args := m.Called(id)
+func (m *PropertyRepositoryMock) FindByID(context context.Context, namespace string, id string) (*model.PropertySet, error) {
+	args := m.Called(namespace, id)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -158,8 +393,8 @@ func (m PropertyRepositoryMock) FindByID(context context.Context, id string) (*m
 	return args.Get(0).(*model.PropertySet), args.Error(1)
 }
 
-func (m PropertyRepositoryMock) FindByName(context context.Context, name string) (*model.PropertySet, error) {
-	args := m.Called(name)
+func (m *PropertyRepositoryMock) FindByName(context context.Context, namespace string, name string) (*model.PropertySet, error) {
+	args := m.Called(namespace, name)
 
 	var q *model.PropertySet
 	if args.Get(0) == nil {
@@ -171,14 +406,14 @@ func (m PropertyRepositoryMock) FindByName(context context.Context, name string)
 	return q, args.Error(1)
 }
 
-func (m PropertyRepositoryMock) Delete(context context.Context, id string) error {
-	args := m.Called(id)
+func (m *PropertyRepositoryMock) Delete(context context.Context, namespace string, id string, version int) error {
+	args := m.Called(namespace, id, version)
 
 	return args.Error(0)
 
 }
 
-func (m PropertyRepositoryMock) Update(ctx context.Context, property *model.PropertySet) error {
+func (m *PropertyRepositoryMock) Update(ctx context.Context, property *model.PropertySet) error {
 	args := m.Called(property)
 
 	return args.Error(0)
@@ -0,0 +1,174 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+)
+
+// ReleaseRepository is a representation of the releases repository for Bolt
+// DBs.
+type ReleaseRepository struct {
+	db     *storm.DB
+	cipher *encryption.Cipher
+}
+
+type releaseDto struct {
+	ID         string `storm:"id"`
+	Namespace  string
+	Set        string `storm:"index"`
+	Number     int
+	Properties []releasePropertyDto
+	CreatedAt  time.Time
+}
+
+type releasePropertyDto struct {
+	Name        string
+	Description string
+	Type        string
+	Value       string
+	Secret      bool
+	Labels      map[string]string
+	Overrides   map[string]string
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values of the secret properties of the releases.
+func New(db *storm.DB, cipher *encryption.Cipher) storage.Repository {
+	repo := &ReleaseRepository{
+		db:     db,
+		cipher: cipher,
+	}
+	db.Init(&releaseDto{})
+
+	return repo
+}
+
+// Create a new entry based on the provided release. The release is given the
+// number following the one of the latest release of the same set.
+func (repository ReleaseRepository) Create(ctx context.Context, release *model.Release) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var latest releaseDto
+	err = tx.Select(q.Eq("Namespace", release.Namespace), q.Eq("Set", release.Set)).OrderBy("Number").Reverse().First(&latest)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	release.Number = latest.Number + 1
+	dto, err := repository.convertToDto(release)
+	if err != nil {
+		return err
+	}
+
+	dto.ID = uuid.New().String()
+	if err := tx.Save(dto); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Find retrieves the release with the given number of the set with the given
+// name within the given namespace.
+func (repository ReleaseRepository) Find(ctx context.Context, namespace string, set string, number int) (*model.Release, error) {
+	var dto releaseDto
+	err := repository.node(ctx).Select(q.Eq("Namespace", namespace), q.Eq("Set", set), q.Eq("Number", number)).First(&dto)
+
+	if err == storm.ErrNotFound {
+		return nil, errors.NewEntityNotFound(model.Release{}, fmt.Sprintf("%s@%d", set, number))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertToModel(&dto)
+}
+
+func (repository ReleaseRepository) convertToDto(release *model.Release) (*releaseDto, error) {
+	properties := make([]releasePropertyDto, len(release.Properties))
+	for i, prop := range release.Properties {
+		value, err := property.SealValue(repository.cipher, prop.Secret, prop.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides, err := property.SealOverrides(repository.cipher, prop.Secret, prop.Overrides)
+		if err != nil {
+			return nil, err
+		}
+
+		properties[i] = releasePropertyDto{
+			Name:        prop.Name,
+			Description: prop.Description,
+			Type:        string(prop.Type),
+			Value:       value,
+			Secret:      prop.Secret,
+			Labels:      prop.Labels,
+			Overrides:   overrides,
+		}
+	}
+
+	return &releaseDto{
+		Namespace:  release.Namespace,
+		Set:        release.Set,
+		Number:     release.Number,
+		Properties: properties,
+		CreatedAt:  release.CreatedAt,
+	}, nil
+}
+
+func (repository ReleaseRepository) convertToModel(dto *releaseDto) (*model.Release, error) {
+	properties := make([]*model.Property, len(dto.Properties))
+	for i, prop := range dto.Properties {
+		value, err := property.OpenValue(repository.cipher, prop.Secret, prop.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides, err := property.OpenOverrides(repository.cipher, prop.Secret, prop.Overrides)
+		if err != nil {
+			return nil, err
+		}
+
+		properties[i] = &model.Property{
+			Namespace:   dto.Namespace,
+			Name:        prop.Name,
+			Description: prop.Description,
+			Type:        model.PropertyType(prop.Type),
+			Value:       value,
+			Secret:      prop.Secret,
+			Labels:      prop.Labels,
+			Overrides:   overrides,
+		}
+	}
+
+	return &model.Release{
+		Namespace:  dto.Namespace,
+		Set:        dto.Set,
+		Number:     dto.Number,
+		Properties: properties,
+		CreatedAt:  dto.CreatedAt,
+	}, nil
+}
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository ReleaseRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
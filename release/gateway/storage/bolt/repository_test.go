@@ -0,0 +1,71 @@
+package bolt
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var defaultDir = "../../../../tests/local-repo"
+var defaultDB = "../../../../tests/local-repo/releasedb"
+
+func TestCreateAndFind(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	createdAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	first := model.NewRelease("payments", "common", []*model.Property{
+		{Name: "db.host", Type: model.TypeString, Value: "localhost", Labels: map[string]string{"team": "payments"}},
+		{Name: "db.password", Value: "s3cr3t", Secret: true, Overrides: map[string]string{"prod": "other"}},
+	})
+	first.CreatedAt = createdAt
+	second := model.NewRelease("payments", "common", nil)
+	second.CreatedAt = createdAt
+	other := model.NewRelease("", "common", nil)
+	other.CreatedAt = createdAt
+
+	for _, release := range []*model.Release{first, second, other} {
+		assert.Equal(t, nil, repo.Create(context.Background(), release))
+	}
+
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, 2, second.Number)
+	assert.Equal(t, 1, other.Number)
+
+	var dto releaseDto
+	repo.db.Select(q.Eq("Namespace", "payments"), q.Eq("Number", 1)).First(&dto)
+	assert.NotEqual(t, "s3cr3t", dto.Properties[1].Value)
+	assert.NotEqual(t, "other", dto.Properties[1].Overrides["prod"])
+
+	found, err := repo.Find(context.Background(), "payments", "common", 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, first, found)
+
+	_, err = repo.Find(context.Background(), "payments", "common", 3)
+	assert.Equal(t, errors.NewEntityNotFound(model.Release{}, "common@3"), err)
+}
+
+func setup() *ReleaseRepository {
+	util.CreateParentFolder(defaultDB)
+
+	db, _ := storm.Open(defaultDB)
+	cipher, _ := encryption.New([]byte("0123456789abcdef0123456789abcdef"))
+
+	return New(db, cipher).(*ReleaseRepository)
+}
+
+func tearDown(repo *ReleaseRepository) {
+	repo.db.Close()
+
+	os.Remove(defaultDB)
+	os.Remove(defaultDir)
+}
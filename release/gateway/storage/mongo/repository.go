@@ -0,0 +1,189 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const releaseCollection = "release_collection"
+
+type releaseDto struct {
+	ID         string               `bson:"_id"`
+	Namespace  string               `bson:"namespace"`
+	Set        string               `bson:"set"`
+	Number     int                  `bson:"number"`
+	Properties []releasePropertyDto `bson:"properties"`
+	CreatedAt  time.Time            `bson:"created_at"`
+}
+
+type releasePropertyDto struct {
+	Name        string            `bson:"name"`
+	Description string            `bson:"description,omitempty"`
+	Type        string            `bson:"type,omitempty"`
+	Value       string            `bson:"value"`
+	Secret      bool              `bson:"secret,omitempty"`
+	Labels      map[string]string `bson:"labels,omitempty"`
+	Overrides   map[string]string `bson:"overrides,omitempty"`
+}
+
+// ReleaseRepository is a representation of the releases repository for a mongo
+// DBs.
+type ReleaseRepository struct {
+	dbCollection *mongo.Collection
+	cipher       *encryption.Cipher
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values of the secret properties of the releases.
+func New(db *mongo.Database, cipher *encryption.Cipher) storage.Repository {
+	repo := &ReleaseRepository{
+		dbCollection: db.Collection(releaseCollection),
+		cipher:       cipher,
+	}
+	repo.createIndexes()
+
+	return repo
+}
+
+// createIndexes ensures the unique index of the release numbers, which prevents
+// concurrent snapshots of a set from getting the same number.
+func (repository ReleaseRepository) createIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := repository.dbCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "namespace", Value: 1}, {Key: "set", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.Main.Error("Cannot create the releases number index.", err)
+	}
+}
+
+// Create a new entry based on the provided release. The release is given the
+// number following the one of the latest release of the same set.
+func (repository ReleaseRepository) Create(ctx context.Context, release *model.Release) error {
+	latest := new(releaseDto)
+	opts := options.FindOne().SetSort(bson.D{{Key: "number", Value: -1}})
+	err := repository.dbCollection.FindOne(ctx, bson.M{"namespace": release.Namespace, "set": release.Set}, opts).Decode(latest)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	release.Number = latest.Number + 1
+	dto, err := repository.convertToDto(release)
+	if err != nil {
+		return err
+	}
+
+	dto.ID = uuid.New().String()
+	_, err = repository.dbCollection.InsertOne(ctx, dto)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key error collection") {
+			return errors.NewConflict(reflect.TypeOf(release), "number", strconv.Itoa(release.Number))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Find retrieves the release with the given number of the set with the given
+// name within the given namespace.
+func (repository ReleaseRepository) Find(ctx context.Context, namespace string, set string, number int) (*model.Release, error) {
+	dto := new(releaseDto)
+	err := repository.dbCollection.FindOne(ctx, bson.M{"namespace": namespace, "set": set, "number": number}).Decode(dto)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.Release{}, fmt.Sprintf("%s@%d", set, number))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertToModel(dto)
+}
+
+func (repository ReleaseRepository) convertToDto(release *model.Release) (*releaseDto, error) {
+	properties := make([]releasePropertyDto, len(release.Properties))
+	for i, prop := range release.Properties {
+		value, err := property.SealValue(repository.cipher, prop.Secret, prop.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides, err := property.SealOverrides(repository.cipher, prop.Secret, prop.Overrides)
+		if err != nil {
+			return nil, err
+		}
+
+		properties[i] = releasePropertyDto{
+			Name:        prop.Name,
+			Description: prop.Description,
+			Type:        string(prop.Type),
+			Value:       value,
+			Secret:      prop.Secret,
+			Labels:      prop.Labels,
+			Overrides:   overrides,
+		}
+	}
+
+	return &releaseDto{
+		Namespace:  release.Namespace,
+		Set:        release.Set,
+		Number:     release.Number,
+		Properties: properties,
+		CreatedAt:  release.CreatedAt,
+	}, nil
+}
+
+func (repository ReleaseRepository) convertToModel(dto *releaseDto) (*model.Release, error) {
+	properties := make([]*model.Property, len(dto.Properties))
+	for i, prop := range dto.Properties {
+		value, err := property.OpenValue(repository.cipher, prop.Secret, prop.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides, err := property.OpenOverrides(repository.cipher, prop.Secret, prop.Overrides)
+		if err != nil {
+			return nil, err
+		}
+
+		properties[i] = &model.Property{
+			Namespace:   dto.Namespace,
+			Name:        prop.Name,
+			Description: prop.Description,
+			Type:        model.PropertyType(prop.Type),
+			Value:       value,
+			Secret:      prop.Secret,
+			Labels:      prop.Labels,
+			Overrides:   overrides,
+		}
+	}
+
+	return &model.Release{
+		Namespace:  dto.Namespace,
+		Set:        dto.Set,
+		Number:     dto.Number,
+		Properties: properties,
+		CreatedAt:  dto.CreatedAt,
+	}, nil
+}
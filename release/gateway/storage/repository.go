@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Repository interface defining the functionality of a basic implementations.
+//
+// Releases are identified by the namespace and name of their set along with
+// their number. Releases are immutable, so they can be neither updated nor
+// deleted.
+type Repository interface {
+	Create(ctx context.Context, release *model.Release) error
+
+	Find(ctx context.Context, namespace string, set string, number int) (*model.Release, error)
+}
@@ -0,0 +1,20 @@
+/*
+Package release implements the releases of the property sets.
+
+A release is an immutable snapshot of the properties of a set, taken with their
+resolved values. The releases of a set are numbered, so that their content can
+be pinned, e.g. by deployments, and read in any of the supported formats long
+after the properties themselves have changed.
+*/
+package release
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Service defines the use case available for the releases.
+type Service interface {
+	Create(ctx context.Context, set string) (*model.Release, error)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/release"
+	"github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+)
+
+// ReleaseService defines the service handling the releases of the property
+// sets.
+type ReleaseService struct {
+	repository storage.Repository
+	properties property.Service
+}
+
+// New creates a ReleaseService. The properties of the sets are read through the
+// given property service.
+//
+// As this service needs access to a repository to perform action, it is the
+// responsibility of the service to get the correct repo from the storage parameter.
+func New(storage *serverstorage.Storage, propertyService property.Service) release.Service {
+	return ReleaseService{
+		repository: storage.ReleaseRepository,
+		properties: propertyService,
+	}
+}
+
+// Create a new release of the set with the given name, within the namespace of
+// the given context. The release freezes the properties currently in the set,
+// with their references already interpolated; the names of the set that do not
+// match any property are left out.
+func (service ReleaseService) Create(ctx context.Context, set string) (*model.Release, error) {
+	props, _, err := service.properties.ReadAll(ctx, property.Query{Set: set})
+	if err != nil {
+		return nil, err
+	}
+
+	release := model.NewRelease(namespace.FromContext(ctx), set, props)
+	release.CreatedAt = time.Now().UTC()
+	if err := service.repository.Create(ctx, release); err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
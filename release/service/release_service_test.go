@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	"github.com/rghiorghisor/basic-go-rest-api/tests/fixture"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultDB = "../../tests/local-repo/releaseservicedb"
+
+type testContext struct {
+	services        *fixture.Services
+	service         ReleaseService
+	propertyService property.Service
+	setService      propertyset.Service
+}
+
+func TestCreateAndReadRelease(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	host := &model.Property{Name: "db.host", Value: "localhost"}
+	url := &model.Property{Name: "db.url", Value: "jdbc:postgresql://${db.host}/app", Labels: map[string]string{"tier": "backend"}}
+	password := &model.Property{Name: "db.password", Value: "s3cr3t", Secret: true}
+	for _, prop := range []*model.Property{host, url, password} {
+		assert.Nil(t, tc.propertyService.Create(ctx, prop))
+	}
+	assert.Nil(t, tc.setService.Create(ctx, &model.PropertySet{Name: "db", Values: []string{"db.url", "db.password", "db.missing"}}))
+
+	release, err := tc.service.Create(ctx, "db")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, release.Number)
+	assert.Equal(t, 2, len(release.Properties))
+
+	host.Value = "remote"
+	assert.Nil(t, tc.propertyService.Update(ctx, host))
+	assert.Nil(t, tc.propertyService.Delete(ctx, password.ID, 0))
+
+	props, info, err := tc.propertyService.ReadAll(ctx, property.Query{Set: "db", Release: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, info.Total)
+	assert.Equal(t, "db.password", props[0].Name)
+	assert.Equal(t, "s3cr3t", props[0].Value)
+	assert.True(t, props[0].Secret)
+	assert.Equal(t, "db.url", props[1].Name)
+	assert.Equal(t, "jdbc:postgresql://localhost/app", props[1].Value)
+
+	props, info, _ = tc.propertyService.ReadAll(ctx, property.Query{Set: "db", Release: 1, Selector: model.LabelSelector{{Key: "tier", Operator: model.OperatorExists}}})
+	assert.Equal(t, 1, info.Total)
+	assert.Equal(t, "db.url", props[0].Name)
+
+	props, info, _ = tc.propertyService.ReadAll(ctx, property.Query{Set: "db", Release: 1, Page: model.Page{Limit: 1, Descending: true}})
+	assert.Equal(t, model.PageInfo{Total: 2, Next: "db.url"}, info)
+	assert.Equal(t, "db.url", props[0].Name)
+
+	release, _ = tc.service.Create(ctx, "db")
+	assert.Equal(t, 2, release.Number)
+	props, _, _ = tc.propertyService.ReadAll(ctx, property.Query{Set: "db", Release: 2})
+	assert.Equal(t, "jdbc:postgresql://remote/app", props[0].Value)
+
+	_, _, err = tc.propertyService.ReadAll(context.Background(), property.Query{Set: "db", Release: 1})
+	assert.True(t, apperrors.IsNotFound(err))
+}
+
+func TestCreateMissingSet(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	_, err := tc.service.Create(context.Background(), "missing")
+	assert.True(t, apperrors.IsNotFound(err))
+}
+
+func setup() *testContext {
+	services := fixture.New(defaultDB, trash_service.New)
+
+	return &testContext{
+		services:        services,
+		service:         New(services.Storage, services.Properties).(ReleaseService),
+		propertyService: services.Properties,
+		setService:      services.Sets,
+	}
+}
+
+func tearDown(tc *testContext) {
+	tc.services.Close()
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// ReleaseServiceMock retrieves a new mock for ReleaseService.
+type ReleaseServiceMock struct {
+	mock.Mock
+}
+
+// Create mock function.
+func (m *ReleaseServiceMock) Create(ctx context.Context, set string) (*model.Release, error) {
+	args := m.Called(set)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Release), args.Error(1)
+}
@@ -0,0 +1,165 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+)
+
+// ScheduleRepository is a representation of the scheduled changes repository
+// for Bolt DBs.
+type ScheduleRepository struct {
+	db     *storm.DB
+	cipher *encryption.Cipher
+}
+
+type scheduledChangeDto struct {
+	ID         string `storm:"id"`
+	Namespace  string
+	PropertyID string `storm:"index"`
+	Value      string
+	Secret     bool
+	ActivateAt time.Time
+	CreatedAt  time.Time
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values scheduled for secret properties.
+func New(db *storm.DB, cipher *encryption.Cipher) storage.Repository {
+	repo := &ScheduleRepository{
+		db:     db,
+		cipher: cipher,
+	}
+	db.Init(&scheduledChangeDto{})
+
+	return repo
+}
+
+// Create a new entry based on the provided change. The change is given a brand
+// new id.
+func (repository ScheduleRepository) Create(ctx context.Context, change *model.ScheduledChange) error {
+	value, err := property.SealValue(repository.cipher, change.Secret, change.Value)
+	if err != nil {
+		return err
+	}
+
+	change.ID = uuid.New().String()
+	dto := convertToDto(change)
+	dto.Value = value
+
+	return repository.node(ctx).Save(dto)
+}
+
+// ReadAll retrieves the changes scheduled for the property with the given id
+// within the given namespace.
+func (repository ScheduleRepository) ReadAll(ctx context.Context, namespace string, propertyID string) ([]*model.ScheduledChange, error) {
+	return repository.find(ctx, q.Eq("PropertyID", propertyID), q.Eq("Namespace", namespace))
+}
+
+// ReadDue retrieves the changes of all namespaces that are due at the given
+// moment.
+func (repository ScheduleRepository) ReadDue(ctx context.Context, at time.Time) ([]*model.ScheduledChange, error) {
+	return repository.find(ctx, q.Lte("ActivateAt", at))
+}
+
+// Delete the change with the given id within the given namespace.
+func (repository ScheduleRepository) Delete(ctx context.Context, namespace string, id string) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var dto scheduledChangeDto
+	err = tx.One("ID", id, &dto)
+
+	if err == storm.ErrNotFound || (err == nil && dto.Namespace != namespace) {
+		return errors.NewEntityNotFound(model.ScheduledChange{}, id)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := tx.DeleteStruct(&dto); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// find retrieves the changes matching all given matchers, sorted by activation
+// time.
+func (repository ScheduleRepository) find(ctx context.Context, matchers ...q.Matcher) ([]*model.ScheduledChange, error) {
+	var dtos []scheduledChangeDto
+	err := repository.node(ctx).Select(matchers...).Find(&dtos)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	sort.Slice(dtos, func(i, j int) bool {
+		if dtos[i].ActivateAt.Equal(dtos[j].ActivateAt) {
+			return dtos[i].CreatedAt.Before(dtos[j].CreatedAt)
+		}
+
+		return dtos[i].ActivateAt.Before(dtos[j].ActivateAt)
+	})
+
+	result := make([]*model.ScheduledChange, len(dtos))
+	for i := range dtos {
+		change, err := repository.convertToModel(&dtos[i])
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = change
+	}
+
+	return result, nil
+}
+
+func convertToDto(change *model.ScheduledChange) *scheduledChangeDto {
+	return &scheduledChangeDto{
+		ID:         change.ID,
+		Namespace:  change.Namespace,
+		PropertyID: change.PropertyID,
+		Value:      change.Value,
+		Secret:     change.Secret,
+		ActivateAt: change.ActivateAt,
+		CreatedAt:  change.CreatedAt,
+	}
+}
+
+func (repository ScheduleRepository) convertToModel(dto *scheduledChangeDto) (*model.ScheduledChange, error) {
+	value, err := property.OpenValue(repository.cipher, dto.Secret, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ScheduledChange{
+		ID:         dto.ID,
+		Namespace:  dto.Namespace,
+		PropertyID: dto.PropertyID,
+		Value:      value,
+		Secret:     dto.Secret,
+		ActivateAt: dto.ActivateAt,
+		CreatedAt:  dto.CreatedAt,
+	}, nil
+}
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository ScheduleRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
@@ -0,0 +1,92 @@
+package bolt
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var defaultDir = "../../../../tests/local-repo"
+var defaultDB = "../../../../tests/local-repo/scheduledb"
+
+func TestCreateAndReadAll(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	activateAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := &model.ScheduledChange{Namespace: "payments", PropertyID: "1", Value: "b", ActivateAt: activateAt.Add(time.Hour)}
+	first := &model.ScheduledChange{Namespace: "payments", PropertyID: "1", Value: "s3cr3t", Secret: true, ActivateAt: activateAt}
+	other := &model.ScheduledChange{Namespace: "payments", PropertyID: "2", Value: "c", ActivateAt: activateAt}
+	for _, change := range []*model.ScheduledChange{later, first, other} {
+		assert.Equal(t, nil, repo.Create(context.Background(), change))
+	}
+
+	var dto scheduledChangeDto
+	repo.db.One("ID", first.ID, &dto)
+	assert.NotEqual(t, "s3cr3t", dto.Value)
+
+	changes, err := repo.ReadAll(context.Background(), "payments", "1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []*model.ScheduledChange{first, later}, changes)
+
+	changes, _ = repo.ReadAll(context.Background(), "", "1")
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestReadDue(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	now := time.Now().UTC()
+	due := &model.ScheduledChange{Namespace: "payments", PropertyID: "1", Value: "a", ActivateAt: now.Add(-time.Minute)}
+	pending := &model.ScheduledChange{PropertyID: "2", Value: "b", ActivateAt: now.Add(time.Minute)}
+	repo.Create(context.Background(), pending)
+	repo.Create(context.Background(), due)
+
+	changes, err := repo.ReadDue(context.Background(), now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []*model.ScheduledChange{due}, changes)
+
+	changes, _ = repo.ReadDue(context.Background(), now.Add(time.Hour))
+	assert.Equal(t, 2, len(changes))
+}
+
+func TestDelete(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	change := &model.ScheduledChange{Namespace: "payments", PropertyID: "1", Value: "a", ActivateAt: time.Now()}
+	repo.Create(context.Background(), change)
+
+	err := repo.Delete(context.Background(), "", change.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.ScheduledChange{}, change.ID), err)
+
+	assert.Equal(t, nil, repo.Delete(context.Background(), "payments", change.ID))
+
+	changes, _ := repo.ReadAll(context.Background(), "payments", "1")
+	assert.Equal(t, 0, len(changes))
+}
+
+func setup() *ScheduleRepository {
+	util.CreateParentFolder(defaultDB)
+
+	db, _ := storm.Open(defaultDB)
+	cipher, _ := encryption.New([]byte("0123456789abcdef0123456789abcdef"))
+
+	return New(db, cipher).(*ScheduleRepository)
+}
+
+func tearDown(repo *ScheduleRepository) {
+	repo.db.Close()
+
+	os.Remove(defaultDB)
+	os.Remove(defaultDir)
+}
@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const scheduleCollection = "schedule_collection"
+
+type scheduledChangeDto struct {
+	ID         string    `bson:"_id"`
+	Namespace  string    `bson:"namespace"`
+	PropertyID string    `bson:"property_id"`
+	Value      string    `bson:"value"`
+	Secret     bool      `bson:"secret,omitempty"`
+	ActivateAt time.Time `bson:"activate_at"`
+	CreatedAt  time.Time `bson:"created_at"`
+}
+
+// ScheduleRepository is a representation of the scheduled changes repository
+// for a mongo DBs.
+type ScheduleRepository struct {
+	dbCollection *mongo.Collection
+	cipher       *encryption.Cipher
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values scheduled for secret properties.
+func New(db *mongo.Database, cipher *encryption.Cipher) storage.Repository {
+	return &ScheduleRepository{
+		dbCollection: db.Collection(scheduleCollection),
+		cipher:       cipher,
+	}
+}
+
+// Create a new entry based on the provided change. The change is given a brand
+// new id.
+func (repository ScheduleRepository) Create(ctx context.Context, change *model.ScheduledChange) error {
+	value, err := property.SealValue(repository.cipher, change.Secret, change.Value)
+	if err != nil {
+		return err
+	}
+
+	change.ID = uuid.New().String()
+	dto := convertToDto(change)
+	dto.Value = value
+
+	_, err = repository.dbCollection.InsertOne(ctx, dto)
+
+	return err
+}
+
+// ReadAll retrieves the changes scheduled for the property with the given id
+// within the given namespace.
+func (repository ScheduleRepository) ReadAll(ctx context.Context, namespace string, propertyID string) ([]*model.ScheduledChange, error) {
+	return repository.find(ctx, bson.M{"namespace": namespace, "property_id": propertyID})
+}
+
+// ReadDue retrieves the changes of all namespaces that are due at the given
+// moment.
+func (repository ScheduleRepository) ReadDue(ctx context.Context, at time.Time) ([]*model.ScheduledChange, error) {
+	return repository.find(ctx, bson.M{"activate_at": bson.M{"$lte": at}})
+}
+
+// Delete the change with the given id within the given namespace.
+func (repository ScheduleRepository) Delete(ctx context.Context, namespace string, id string) error {
+	result, err := repository.dbCollection.DeleteOne(ctx, bson.M{"_id": id, "namespace": namespace})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.NewEntityNotFound(model.ScheduledChange{}, id)
+	}
+
+	return nil
+}
+
+// find retrieves the changes matching the given query, sorted by activation
+// time.
+func (repository ScheduleRepository) find(ctx context.Context, query bson.M) ([]*model.ScheduledChange, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "activate_at", Value: 1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := repository.dbCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	result := make([]*model.ScheduledChange, 0)
+	for cursor.Next(ctx) {
+		dto := new(scheduledChangeDto)
+		if err := cursor.Decode(dto); err != nil {
+			return nil, err
+		}
+
+		change, err := repository.convertToModel(dto)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, change)
+	}
+
+	return result, nil
+}
+
+func convertToDto(change *model.ScheduledChange) *scheduledChangeDto {
+	return &scheduledChangeDto{
+		ID:         change.ID,
+		Namespace:  change.Namespace,
+		PropertyID: change.PropertyID,
+		Value:      change.Value,
+		Secret:     change.Secret,
+		ActivateAt: change.ActivateAt,
+		CreatedAt:  change.CreatedAt,
+	}
+}
+
+func (repository ScheduleRepository) convertToModel(dto *scheduledChangeDto) (*model.ScheduledChange, error) {
+	value, err := property.OpenValue(repository.cipher, dto.Secret, dto.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ScheduledChange{
+		ID:         dto.ID,
+		Namespace:  dto.Namespace,
+		PropertyID: dto.PropertyID,
+		Value:      value,
+		Secret:     dto.Secret,
+		ActivateAt: dto.ActivateAt,
+		CreatedAt:  dto.CreatedAt,
+	}, nil
+}
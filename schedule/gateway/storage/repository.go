@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Repository interface defining the functionality of a basic implementations.
+//
+// The changes are always retrieved in the order in which they must be applied,
+// i.e. sorted by activation time.
+type Repository interface {
+	Create(ctx context.Context, change *model.ScheduledChange) error
+
+	ReadAll(ctx context.Context, namespace string, propertyID string) ([]*model.ScheduledChange, error)
+
+	ReadDue(ctx context.Context, at time.Time) ([]*model.ScheduledChange, error)
+
+	Delete(ctx context.Context, namespace string, id string) error
+}
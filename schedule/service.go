@@ -0,0 +1,24 @@
+/*
+Package schedule implements the scheduled changes of the property values.
+
+A scheduled change stages a value for a property, which becomes the value of
+the property at the activation time of the change. The changes are stored, so
+that the ones that became due while the application was stopped are applied
+as soon as it starts.
+*/
+package schedule
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Service defines the use case available for the scheduled changes.
+type Service interface {
+	Create(ctx context.Context, change *model.ScheduledChange) error
+
+	ReadAll(ctx context.Context, propertyID string) ([]*model.ScheduledChange, error)
+
+	Cancel(ctx context.Context, propertyID string, id string) error
+}
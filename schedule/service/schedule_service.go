@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/config"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/schedule"
+	"github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+)
+
+// ScheduleService defines the service handling the scheduled changes.
+type ScheduleService struct {
+	repository storage.Repository
+	properties property.Service
+}
+
+// New creates a ScheduleService and starts applying the due changes, right away
+// and then at the configured interval, unless the interval is not positive.
+//
+// As this service needs access to a repository to perform action, it is the
+// responsibility of the service to get the correct repo from the storage parameter.
+// The changes are applied through the given property service.
+func New(storage *serverstorage.Storage, properties property.Service, configuration *config.SchedulerConfiguration) schedule.Service {
+	service := ScheduleService{
+		repository: storage.ScheduleRepository,
+		properties: properties,
+	}
+
+	if configuration.Interval > 0 {
+		go service.run(configuration.Interval)
+	}
+
+	return service
+}
+
+// Create schedules the given change of the value of a property. The property
+// must exist within the namespace of the given context, the value must be valid
+// for the property type and the activation time must be in the future.
+func (service ScheduleService) Create(ctx context.Context, change *model.ScheduledChange) error {
+	prop, err := service.properties.FindByID(ctx, change.PropertyID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if change.Due(now) {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.ScheduledChange{}), "'activate_at' must be in the future.")
+	}
+
+	if err := checkValue(prop.Type, change.Value); err != nil {
+		return err
+	}
+
+	change.Namespace = prop.Namespace
+	change.Secret = prop.Secret
+	change.CreatedAt = now
+
+	return service.repository.Create(ctx, change)
+}
+
+// checkValue validates the given value against the given type. The values that
+// reference other properties are validated when the change is applied.
+func checkValue(typ model.PropertyType, value string) error {
+	if model.HasReferences(value) {
+		return nil
+	}
+
+	if _, err := model.ParseValue(typ, value); err != nil {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.ScheduledChange{}), fmt.Sprintf("'value' is not a valid %s.", typ))
+	}
+
+	return nil
+}
+
+// ReadAll retrieves the pending changes of the property with the given id, in
+// the order in which they are applied.
+func (service ScheduleService) ReadAll(ctx context.Context, propertyID string) ([]*model.ScheduledChange, error) {
+	if _, err := service.properties.FindByID(ctx, propertyID); err != nil {
+		return nil, err
+	}
+
+	return service.repository.ReadAll(ctx, namespace.FromContext(ctx), propertyID)
+}
+
+// Cancel the pending change with the given id of the property with the given
+// id.
+func (service ScheduleService) Cancel(ctx context.Context, propertyID string, id string) error {
+	changes, err := service.ReadAll(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.ID == id {
+			return service.repository.Delete(ctx, change.Namespace, change.ID)
+		}
+	}
+
+	return errors.NewEntityNotFound(model.ScheduledChange{}, id)
+}
+
+// run applies the due changes right away, so that the changes that became due
+// while the application was stopped are not delayed, then once every given
+// interval.
+func (service ScheduleService) run(interval time.Duration) {
+	service.applyDue(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		service.applyDue(time.Now())
+	}
+}
+
+// applyDue applies the changes that are due at the given moment, in order. A
+// change is removed only after being applied, so that it is retried if the
+// application stops in between; applying it twice has no further effect.
+func (service ScheduleService) applyDue(at time.Time) {
+	changes, err := service.repository.ReadDue(context.Background(), at)
+	if err != nil {
+		logger.Main.Error("Cannot read the due scheduled changes", err)
+		return
+	}
+
+	for _, change := range changes {
+		ctx := namespace.NewContext(context.Background(), change.Namespace)
+
+		if err := service.apply(ctx, change); err != nil {
+			if _, rejected := err.(*errors.Error); !rejected {
+				// Keep the change, so that it is retried.
+				logger.Main.Error("Cannot apply a scheduled change", err)
+				continue
+			}
+
+			logger.Main.Warn(fmt.Sprintf("Dropped scheduled change '%s' of property '%s': %s", change.ID, change.PropertyID, err))
+		}
+
+		if err := service.repository.Delete(ctx, change.Namespace, change.ID); err != nil && !errors.IsNotFound(err) {
+			logger.Main.Error("Cannot remove an applied scheduled change", err)
+		}
+	}
+}
+
+// apply sets the value of the given change as the value of its property,
+// regardless of the property revision.
+func (service ScheduleService) apply(ctx context.Context, change *model.ScheduledChange) error {
+	prop, err := service.properties.FindByID(property.NewRawContext(ctx), change.PropertyID)
+	if err != nil {
+		return err
+	}
+
+	prop.Value = change.Value
+	prop.Revision = 0
+
+	if err := service.properties.Update(ctx, prop); err != nil {
+		return err
+	}
+
+	logger.Main.Infof("Applied scheduled change '%s' to property '%s' (revision=%d).", change.ID, prop.Name, prop.Revision)
+
+	return nil
+}
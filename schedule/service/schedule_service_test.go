@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/config"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/tests/fixture"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultDB = "../../tests/local-repo/scheduleservicedb"
+
+type testContext struct {
+	services        *fixture.Services
+	service         ScheduleService
+	propertyService property.Service
+}
+
+func TestCreateAndApply(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	prop := &model.Property{Name: "test.limit", Type: model.TypeInt, Value: "10"}
+	tc.propertyService.Create(ctx, prop)
+
+	activateAt := time.Now().Add(time.Hour)
+	change := &model.ScheduledChange{PropertyID: prop.ID, Value: "20", ActivateAt: activateAt}
+	assert.Nil(t, tc.service.Create(ctx, change))
+	assert.Equal(t, "payments", change.Namespace)
+
+	changes, err := tc.service.ReadAll(ctx, prop.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(changes))
+
+	tc.service.applyDue(time.Now())
+	found, _ := tc.propertyService.FindByID(ctx, prop.ID)
+	assert.Equal(t, "10", found.Value)
+
+	tc.service.applyDue(activateAt)
+	found, _ = tc.propertyService.FindByID(ctx, prop.ID)
+	assert.Equal(t, "20", found.Value)
+	assert.Equal(t, 2, found.Revision)
+
+	changes, _ = tc.service.ReadAll(ctx, prop.ID)
+	assert.Equal(t, 0, len(changes))
+}
+
+func TestApplyInOrder(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "a"}
+	tc.propertyService.Create(ctx, prop)
+
+	now := time.Now()
+	tc.service.Create(ctx, &model.ScheduledChange{PropertyID: prop.ID, Value: "c", ActivateAt: now.Add(2 * time.Minute)})
+	tc.service.Create(ctx, &model.ScheduledChange{PropertyID: prop.ID, Value: "b", ActivateAt: now.Add(time.Minute)})
+
+	tc.service.applyDue(now.Add(time.Hour))
+	found, _ := tc.propertyService.FindByID(ctx, prop.ID)
+	assert.Equal(t, "c", found.Value)
+	assert.Equal(t, 3, found.Revision)
+}
+
+func TestCreateInvalid(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.limit", Type: model.TypeInt, Value: "10"}
+	tc.propertyService.Create(ctx, prop)
+
+	err := tc.service.Create(ctx, &model.ScheduledChange{PropertyID: prop.ID, Value: "20", ActivateAt: time.Now().Add(-time.Minute)})
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.ScheduledChange{}), "'activate_at' must be in the future."), err)
+
+	err = tc.service.Create(ctx, &model.ScheduledChange{PropertyID: prop.ID, Value: "twenty", ActivateAt: time.Now().Add(time.Minute)})
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.ScheduledChange{}), "'value' is not a valid int."), err)
+
+	err = tc.service.Create(namespace.NewContext(ctx, "payments"), &model.ScheduledChange{PropertyID: prop.ID, Value: "20", ActivateAt: time.Now().Add(time.Minute)})
+	assert.Equal(t, apperrors.NewEntityNotFound(model.Property{}, prop.ID), err)
+}
+
+func TestCancel(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "a"}
+	tc.propertyService.Create(ctx, prop)
+
+	change := &model.ScheduledChange{PropertyID: prop.ID, Value: "b", ActivateAt: time.Now().Add(time.Minute)}
+	tc.service.Create(ctx, change)
+
+	err := tc.service.Cancel(ctx, prop.ID, "missing")
+	assert.Equal(t, apperrors.NewEntityNotFound(model.ScheduledChange{}, "missing"), err)
+
+	assert.Nil(t, tc.service.Cancel(ctx, prop.ID, change.ID))
+
+	tc.service.applyDue(time.Now().Add(time.Hour))
+	found, _ := tc.propertyService.FindByID(ctx, prop.ID)
+	assert.Equal(t, "a", found.Value)
+}
+
+func TestApplyDeletedProperty(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "a"}
+	tc.propertyService.Create(ctx, prop)
+	tc.service.Create(ctx, &model.ScheduledChange{PropertyID: prop.ID, Value: "b", ActivateAt: time.Now().Add(time.Minute)})
+	tc.propertyService.Delete(ctx, prop.ID, 0)
+
+	tc.service.applyDue(time.Now().Add(time.Hour))
+
+	changes, _ := tc.service.repository.ReadDue(ctx, time.Now().Add(time.Hour))
+	assert.Equal(t, 0, len(changes))
+}
+
+func setup() *testContext {
+	services := fixture.New(defaultDB, trash_service.New)
+
+	return &testContext{
+		services:        services,
+		service:         New(services.Storage, services.Properties, &config.SchedulerConfiguration{}).(ScheduleService),
+		propertyService: services.Properties,
+	}
+}
+
+func tearDown(tc *testContext) {
+	tc.services.Close()
+}
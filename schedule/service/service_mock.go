@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// ScheduleServiceMock retrieves a new mock for ScheduleService.
+type ScheduleServiceMock struct {
+	mock.Mock
+}
+
+// Create mock function.
+func (m *ScheduleServiceMock) Create(ctx context.Context, change *model.ScheduledChange) error {
+	args := m.Called(change)
+
+	return args.Error(0)
+}
+
+// ReadAll mock function.
+func (m *ScheduleServiceMock) ReadAll(ctx context.Context, propertyID string) ([]*model.ScheduledChange, error) {
+	args := m.Called(propertyID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*model.ScheduledChange), args.Error(1)
+}
+
+// Cancel mock function.
+func (m *ScheduleServiceMock) Cancel(ctx context.Context, propertyID string, id string) error {
+	args := m.Called(propertyID, id)
+
+	return args.Error(0)
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type connContextKey struct{}
+
+// ConnContext retrieves a copy of the given context carrying the given
+// connection. It is meant to be used as the http.Server ConnContext, so that the
+// handlers can reach the connection serving their request.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// DisableWriteTimeout lifts the write timeout of the connection serving the
+// given request, for responses that are written over a long period of time
+// (e.g. event streams). The server sets the timeout anew for the next request
+// served by the connection.
+func DisableWriteTimeout(ctx *gin.Context) {
+	if conn, ok := ctx.Request.Context().Value(connContextKey{}).(net.Conn); ok {
+		conn.SetWriteDeadline(time.Time{})
+	}
+}
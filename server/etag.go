@@ -0,0 +1,36 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+)
+
+// SetETag exposes the given version of the retrieved entity as the entity tag
+// of the response.
+func SetETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// IfMatch retrieves the version required by the 'If-Match' header of the
+// request, as exposed by SetETag. Zero is retrieved if the header is missing or
+// is '*', meaning that any version matches.
+func IfMatch(ctx *gin.Context) (int, error) {
+	raw := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+
+	if len(raw) < 2 || !strings.HasPrefix(raw, `"`) || !strings.HasSuffix(raw, `"`) {
+		return 0, errors.NewInvalidParameter("If-Match", raw)
+	}
+
+	version, err := strconv.Atoi(raw[1 : len(raw)-1])
+	if err != nil || version < 1 {
+		return 0, errors.NewInvalidParameter("If-Match", raw)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected int
+		err      error
+	}{
+		{"", 0, nil},
+		{"*", 0, nil},
+		{`"3"`, 3, nil},
+		{`3`, 0, errors.NewInvalidParameter("If-Match", "3")},
+		{`"0"`, 0, errors.NewInvalidParameter("If-Match", `"0"`)},
+		{`W/"3"`, 0, errors.NewInvalidParameter("If-Match", `W/"3"`)},
+	}
+
+	for _, test := range tests {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request, _ = http.NewRequest("PUT", "/", nil)
+		ctx.Request.Header.Set("If-Match", test.header)
+
+		actual, err := IfMatch(ctx)
+
+		assert.Equal(t, test.expected, actual)
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestSetETag(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+
+	SetETag(ctx, 7)
+
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/caller"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+)
+
+// RequestIDHeader holds the id of a request. The id given by the client is kept;
+// otherwise a brand new id is generated. The response holds the id as well.
+const RequestIDHeader = "X-Request-ID"
+
+// ActorHeader holds the identity of the caller, as established by an upstream
+// authenticating proxy.
+const ActorHeader = "X-Actor"
+
+// CallerIdentifier retrieves a new middleware that stores the caller of each
+// request in the request context, where services will look for it.
+//
+// The actor is established by an upstream authenticating proxy, so it is taken
+// from the requests of the given trusted proxies (IP addresses or CIDR ranges)
+// only: it is given by the X-Actor header or else by the basic authentication
+// user name, while the client IP is given by the X-Forwarded-For header. All
+// other requests, as well as the ones lacking both, are identified by the client
+// IP, the address of the connection unless it comes from a trusted proxy. The
+// invalid proxies are ignored.
+func CallerIdentifier(trustedProxies []string) gin.HandlerFunc {
+	trusted := parseProxies(trustedProxies)
+
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		var actor string
+		if isTrusted(ctx, trusted) {
+			actor = ctx.GetHeader(ActorHeader)
+			if actor == "" {
+				actor, _, _ = ctx.Request.BasicAuth()
+			}
+
+			if actor == "" {
+				actor = ctx.ClientIP()
+			}
+		} else {
+			actor = remoteIP(ctx)
+		}
+
+		ctx.Header(RequestIDHeader, requestID)
+		ctx.Request = ctx.Request.WithContext(caller.NewContext(ctx.Request.Context(), caller.Caller{Actor: actor, RequestID: requestID}))
+		ctx.Next()
+	}
+}
+
+func parseProxies(proxies []string) []*net.IPNet {
+	parsed := []*net.IPNet{}
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		cidr := proxy
+		if !strings.Contains(cidr, "/") {
+			cidr += "/128"
+			if strings.Contains(proxy, ".") {
+				cidr = proxy + "/32"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logger.Main.Warn(fmt.Sprintf("Unknown trusted proxy '%s'. Ignoring it.", proxy))
+			continue
+		}
+
+		parsed = append(parsed, network)
+	}
+
+	return parsed
+}
+
+func isTrusted(ctx *gin.Context, trusted []*net.IPNet) bool {
+	ip := net.ParseIP(remoteIP(ctx))
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP retrieves the address of the connection, disregarding any forwarding
+// headers.
+func remoteIP(ctx *gin.Context) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(ctx.Request.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(ctx.Request.RemoteAddr)
+	}
+
+	return host
+}
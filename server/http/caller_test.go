@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/caller"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallerIdentifier(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger.Main = logger.NewDummyLogger(buf)
+
+	var identified caller.Caller
+
+	router := gin.New()
+	router.Use(CallerIdentifier([]string{"192.0.2.1", " 10.0.0.0/8", "unknown", ""}))
+	router.GET("/", func(ctx *gin.Context) {
+		identified = caller.FromContext(ctx.Request.Context())
+	})
+
+	w := performCaller(router, "192.0.2.1:1234", map[string]string{RequestIDHeader: "123", ActorHeader: "jane"})
+	assert.Equal(t, caller.Caller{Actor: "jane", RequestID: "123"}, identified)
+	assert.Equal(t, "123", w.Header().Get(RequestIDHeader))
+
+	w = performCaller(router, "10.1.2.3:1234", map[string]string{"Authorization": "Basic am9objpzZWNyZXQ="})
+	assert.Equal(t, "john", identified.Actor)
+	assert.NotEqual(t, "", identified.RequestID)
+	assert.Equal(t, identified.RequestID, w.Header().Get(RequestIDHeader))
+
+	performCaller(router, "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.1"})
+	assert.Equal(t, "10.0.0.1", identified.Actor)
+	assert.Contains(t, buf.String(), "Unknown trusted proxy 'unknown'")
+}
+
+func TestCallerIdentifierUntrusted(t *testing.T) {
+	var identified caller.Caller
+
+	router := gin.New()
+	router.Use(CallerIdentifier(nil))
+	router.GET("/", func(ctx *gin.Context) {
+		identified = caller.FromContext(ctx.Request.Context())
+	})
+
+	performCaller(router, "192.0.2.1:1234", map[string]string{ActorHeader: "jane", "X-Forwarded-For": "10.0.0.1"})
+	assert.Equal(t, "192.0.2.1", identified.Actor)
+
+	performCaller(router, "[2001:db8::1]:1234", map[string]string{"Authorization": "Basic am9objpzZWNyZXQ="})
+	assert.Equal(t, "2001:db8::1", identified.Actor)
+}
+
+func performCaller(router *gin.Engine, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	router.ServeHTTP(w, req)
+
+	return w
+}
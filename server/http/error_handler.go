@@ -11,9 +11,10 @@ import (
 
 // appError represents the formatted error to be returned as the response body, in case this is needed.
 type appError struct {
-	Code      int       `json:"code"`
-	Timestamp time.Time `json:"timestamp"`
-	Message   string    `json:"message"`
+	Code       int                `json:"code"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Message    string             `json:"message"`
+	Violations []errors.Violation `json:"violations,omitempty"`
 }
 
 // JSONAppErrorHandler is the middleware handling the overall error handling mechanism.
@@ -37,8 +38,9 @@ func handle(errType gin.ErrorType) gin.HandlerFunc {
 		case *errors.Error:
 			castError := err.(*errors.Error)
 			parsedError = &appError{
-				Code:    castError.Code,
-				Message: castError.Message,
+				Code:       castError.Code,
+				Message:    castError.Message,
+				Violations: castError.Violations,
 			}
 		default:
 			parsedError = &appError{
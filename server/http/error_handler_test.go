@@ -2,6 +2,7 @@ package http
 
 import (
 	eerrors "errors"
+	"strings"
 	"testing"
 
 	nhttp "net/http"
@@ -28,6 +29,20 @@ func TestHandle404(t *testing.T) {
 	assert.Equal(t, 404, w.Code)
 }
 
+func TestHandleViolations(t *testing.T) {
+	err := errors.NewViolations(TestStruct{}, []errors.Violation{{Field: "value", Path: "/port", Message: "is required"}})
+	router, mock := setup(err)
+
+	mock.On("Operation").Return(err)
+
+	w := httptest.NewRecorder()
+	req, _ := nhttp.NewRequest("GET", "/api", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Body.String(), `"violations":[{"field":"value","path":"/port","message":"is required"}]`))
+}
+
 func TestHandle500(t *testing.T) {
 	err := eerrors.New("unexpected")
 	router, mock := setup(err)
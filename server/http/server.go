@@ -21,6 +21,9 @@ type Server struct {
 	listener   net.Listener
 }
 
+// connContext lets the handlers reach the connection serving their request.
+var connContext = server.ConnContext
+
 // NewServer creates a new bare-boned application server.
 func NewServer() *Server {
 	return &Server{}
@@ -41,6 +44,7 @@ func (server *Server) Setup(config *config.AppConfiguration, controllers *server
 		AccessLogger(),
 		gin.Recovery(),
 		gin.Logger(),
+		CallerIdentifier(config.Server.HTTPServer.TrustedProxies),
 		JSONAppErrorHandler(),
 	)
 
@@ -109,5 +113,6 @@ func setupServer(server *Server, router *gin.Engine, serverConfiguration *config
 		ReadTimeout:    readTimeout,
 		WriteTimeout:   writeTimeout,
 		MaxHeaderBytes: 1 << 20,
+		ConnContext:    connContext,
 	}
 }
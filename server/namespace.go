@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+)
+
+// NamespacedGroups retrieves the router groups under which a namespaced resource
+// must be registered: the given path, which addresses the default namespace, and
+// the same path prefixed by '/ns/:namespace'.
+func NamespacedGroups(routerGroup *gin.RouterGroup, path string) []*gin.RouterGroup {
+	return []*gin.RouterGroup{
+		routerGroup.Group(path),
+		routerGroup.Group("/ns/:namespace"+path, namespaceHandler),
+	}
+}
+
+// namespaceHandler validates the namespace path parameter and stores the
+// namespace in the request context, where services will look for it.
+func namespaceHandler(ctx *gin.Context) {
+	name := ctx.Param("namespace")
+
+	ns, ok := namespace.Parse(name)
+	if !ok {
+		ctx.Error(errors.NewInvalidParameter("namespace", name))
+		ctx.Abort()
+		return
+	}
+
+	ctx.Request = ctx.Request.WithContext(namespace.NewContext(ctx.Request.Context(), ns))
+	ctx.Next()
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestNamespacedGroups(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var actual string
+	for _, group := range NamespacedGroups(router.Group("/api"), "/test") {
+		group.GET("", func(ctx *gin.Context) {
+			actual = namespace.FromContext(ctx.Request.Context())
+			ctx.Status(http.StatusOK)
+		})
+	}
+
+	w := perform(router, "/api/test")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, namespace.Default, actual)
+
+	w = perform(router, "/api/ns/payments/test")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "payments", actual)
+
+	w = perform(router, "/api/ns/default/test")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, namespace.Default, actual)
+}
+
+func TestNamespacedGroupsInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var actualErrors []*gin.Error
+	router.Use(func(ctx *gin.Context) {
+		ctx.Next()
+		actualErrors = ctx.Errors
+	})
+
+	called := false
+	for _, group := range NamespacedGroups(router.Group("/api"), "/test") {
+		group.GET("", func(ctx *gin.Context) {
+			called = true
+		})
+	}
+
+	perform(router, "/api/ns/.payments/test")
+	assert.Equal(t, false, called)
+	assert.Equal(t, 1, len(actualErrors))
+	assert.Equal(t, errors.NewInvalidParameter("namespace", ".payments"), actualErrors[0].Err)
+}
+
+func perform(router http.Handler, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
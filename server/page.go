@@ -0,0 +1,44 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// ParsePage retrieves the page requested by the 'limit', 'page_token' and
+// 'sort' query parameters of a list endpoint. The only supported sort values
+// are 'name' (default) and '-name'.
+func ParsePage(ctx *gin.Context) (model.Page, error) {
+	page := model.Page{}
+
+	if limit := ctx.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return page, errors.NewInvalidParameter("limit", limit)
+		}
+
+		page.Limit = l
+	}
+
+	if token := ctx.Query("page_token"); token != "" {
+		after, err := model.DecodePageToken(token)
+		if err != nil {
+			return page, errors.NewInvalidParameter("page_token", token)
+		}
+
+		page.After = after
+	}
+
+	switch sort := ctx.Query("sort"); sort {
+	case "", "name":
+	case "-name":
+		page.Descending = true
+	default:
+		return page, errors.NewInvalidParameter("sort", sort)
+	}
+
+	return page, nil
+}
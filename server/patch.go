@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+)
+
+// Content types of the supported patch documents.
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// Patch applies the patch document in the request body to the JSON form of the
+// given target, which must be a pointer to a struct, and stores the result back
+// into the target. The body is either a JSON merge patch (RFC 7396) or a JSON
+// patch (RFC 6902), as announced by the request content type. The given entity
+// describes the patched entity in the returned errors.
+func Patch(ctx *gin.Context, entity interface{}, target interface{}) error {
+	raw := ctx.GetHeader("Content-Type")
+	contentType, _, _ := mime.ParseMediaType(raw)
+	if contentType != MergePatchContentType && contentType != JSONPatchContentType {
+		return errors.NewUnsupportedMediaType(raw)
+	}
+
+	patch, err := ioutil.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	original, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	patched, err := apply(contentType, original, patch)
+	if err != nil {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(entity), fmt.Sprintf("Patch cannot be applied: %s.", err))
+	}
+
+	// Start from a blank target, so that the removed fields are not kept.
+	value := reflect.ValueOf(target).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	if err := json.Unmarshal(patched, target); err != nil {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(entity), fmt.Sprintf("Patch cannot be applied: %s.", err))
+	}
+
+	return nil
+}
+
+func apply(contentType string, original []byte, patch []byte) ([]byte, error) {
+	if contentType == MergePatchContentType {
+		return jsonpatch.MergePatch(original, patch)
+	}
+
+	operations, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return operations.Apply(original)
+}
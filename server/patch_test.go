@@ -0,0 +1,59 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+type patchTarget struct {
+	Name   string            `json:"name"`
+	Values []string          `json:"values"`
+	Labels map[string]string `json:"labels"`
+}
+
+func TestPatchMerge(t *testing.T) {
+	target := &patchTarget{Name: "test.name", Values: []string{"a"}, Labels: map[string]string{"team": "payments"}}
+
+	err := Patch(patchContext(MergePatchContentType, `{"name":"test.other","labels":null}`), patchTarget{}, target)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &patchTarget{Name: "test.other", Values: []string{"a"}}, target)
+}
+
+func TestPatchJSONPatch(t *testing.T) {
+	target := &patchTarget{Name: "test.name", Values: []string{"a"}}
+
+	err := Patch(patchContext(JSONPatchContentType+"; charset=utf-8", `[{"op":"add","path":"/values/-","value":"b"},{"op":"test","path":"/name","value":"test.name"}]`), patchTarget{}, target)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &patchTarget{Name: "test.name", Values: []string{"a", "b"}}, target)
+}
+
+func TestPatchFailedTest(t *testing.T) {
+	target := &patchTarget{Name: "test.name"}
+
+	err := Patch(patchContext(JSONPatchContentType, `[{"op":"test","path":"/name","value":"test.other"}]`), patchTarget{}, target)
+
+	assert.Equal(t, 400, err.(*errors.Error).Code)
+	assert.Equal(t, &patchTarget{Name: "test.name"}, target)
+}
+
+func TestPatchUnsupportedMediaType(t *testing.T) {
+	err := Patch(patchContext("application/json", `{}`), patchTarget{}, &patchTarget{})
+
+	assert.Equal(t, errors.NewUnsupportedMediaType("application/json"), err)
+}
+
+func patchContext(contentType string, body string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest("PATCH", "/", bytes.NewBufferString(body))
+	ctx.Request.Header.Set("Content-Type", contentType)
+
+	return ctx
+}
@@ -2,11 +2,17 @@ package storage
 
 import (
 	"github.com/asdine/storm/v3"
+	audit_bolt "github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage/bolt"
 	"github.com/rghiorghisor/basic-go-rest-api/config"
 	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	property_bolt "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage/bolt"
 	propertyset_bolt "github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage/bolt"
+	release_bolt "github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage/bolt"
+	schedule_bolt "github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage/bolt"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	trash_bolt "github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage/bolt"
 	"github.com/rghiorghisor/basic-go-rest-api/util"
+	webhook_bolt "github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage/bolt"
 )
 
 type boltFactory struct {
@@ -30,9 +36,20 @@ func (f *boltFactory) init(storage *Storage, config *config.StorageConfiguration
 		return err
 	}
 
+	cipher, err := loadCipher(config)
+	if err != nil {
+		return err
+	}
+
 	// Setup repositories.
-	storage.PropertyRepository = property_bolt.New(dbt)
+	storage.PropertyRepository = property_bolt.New(dbt, cipher)
 	storage.PropertySetRepository = propertyset_bolt.New(dbt)
+	storage.WebhookRepository = webhook_bolt.New(dbt, cipher)
+	storage.AuditRepository = audit_bolt.New(dbt)
+	storage.TrashRepository = trash_bolt.New(dbt, cipher)
+	storage.ScheduleRepository = schedule_bolt.New(dbt, cipher)
+	storage.ReleaseRepository = release_bolt.New(dbt, cipher)
+	storage.Transactor = transaction.NewBolt(dbt)
 
 	// Add here any new repository...
 
@@ -5,10 +5,16 @@ import (
 	"log"
 	"time"
 
+	audit_mongo "github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage/mongo"
 	"github.com/rghiorghisor/basic-go-rest-api/config"
 	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	property_mongo "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage/mongo"
 	propertyset_mongo "github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage/mongo"
+	release_mongo "github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage/mongo"
+	schedule_mongo "github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage/mongo"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	trash_mongo "github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage/mongo"
+	webhook_mongo "github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage/mongo"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -31,9 +37,20 @@ func (f *mongoFactory) init(storage *Storage, config *config.StorageConfiguratio
 	// Create db connection.
 	db := f.connect(mongoConfig)
 
+	cipher, err := loadCipher(config)
+	if err != nil {
+		return err
+	}
+
 	// Setup repositories.
-	storage.PropertyRepository = property_mongo.New(db)
+	storage.PropertyRepository = property_mongo.New(db, cipher)
 	storage.PropertySetRepository = propertyset_mongo.New(db)
+	storage.WebhookRepository = webhook_mongo.New(db, cipher)
+	storage.AuditRepository = audit_mongo.New(db)
+	storage.TrashRepository = trash_mongo.New(db, cipher)
+	storage.ScheduleRepository = schedule_mongo.New(db, cipher)
+	storage.ReleaseRepository = release_mongo.New(db, cipher)
+	storage.Transactor = transaction.NewMongo(db.Client())
 
 	// Add here any new repository...
 
@@ -3,10 +3,17 @@ package storage
 import (
 	"strings"
 
+	audit "github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage"
 	"github.com/rghiorghisor/basic-go-rest-api/config"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
 	"github.com/rghiorghisor/basic-go-rest-api/logger"
 	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
 	propertyset "github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage"
+	release "github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage"
+	schedule "github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	trash "github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage"
+	webhook "github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage"
 )
 
 // Storage structure contains all repositories.
@@ -15,6 +22,12 @@ type Storage struct {
 	defaultFactory        func() factory
 	PropertyRepository    property.Repository
 	PropertySetRepository propertyset.Repository
+	WebhookRepository     webhook.Repository
+	AuditRepository       audit.Repository
+	TrashRepository       trash.Repository
+	ScheduleRepository    schedule.Repository
+	ReleaseRepository     release.Repository
+	Transactor            transaction.Transactor
 }
 
 type factory interface {
@@ -60,3 +73,15 @@ func (storage *Storage) SetupStorage(config *config.StorageConfiguration) error
 func checkConfig(f factory, storageType string) bool {
 	return strings.EqualFold(f.id(), storageType)
 }
+
+// loadCipher creates the cipher used by repositories to encrypt sensitive values.
+// If no key file is configured no cipher is created.
+func loadCipher(config *config.StorageConfiguration) (*encryption.Cipher, error) {
+	if config.EncryptionKeyFile == "" {
+		logger.Main.Info("No encryption key file configured. Secret properties cannot be stored.")
+
+		return nil, nil
+	}
+
+	return encryption.NewFromKeyFile(config.EncryptionKeyFile)
+}
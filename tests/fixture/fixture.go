@@ -0,0 +1,109 @@
+/*
+Package fixture builds the services of the application on top of a local bolt
+database, wired as in the application, for the tests that exercise the services
+together.
+*/
+package fixture
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	audit_bolt "github.com/rghiorghisor/basic-go-rest-api/audit/gateway/storage/bolt"
+	audit_service "github.com/rghiorghisor/basic-go-rest-api/audit/service"
+	"github.com/rghiorghisor/basic-go-rest-api/config"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	property_bolt "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage/bolt"
+	property_service "github.com/rghiorghisor/basic-go-rest-api/property/service"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	propertyset_bolt "github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage/bolt"
+	propertyset_service "github.com/rghiorghisor/basic-go-rest-api/propertyset/service"
+	release_bolt "github.com/rghiorghisor/basic-go-rest-api/release/gateway/storage/bolt"
+	schedule_bolt "github.com/rghiorghisor/basic-go-rest-api/schedule/gateway/storage/bolt"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	trash_bolt "github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage/bolt"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+	webhook_bolt "github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage/bolt"
+)
+
+// Key is the encryption key of the repositories, so that secret properties can
+// be stored.
+var Key = []byte("0123456789abcdef0123456789abcdef")
+
+// TrashConstructor builds the trash service. It matches the constructor of the
+// trash service, which cannot be imported by this package as the tests of the
+// trash service use it as well.
+type TrashConstructor func(storage *serverstorage.Storage, hub *watch.Hub, auditor audit.Service, configuration *config.TrashConfiguration) trash.Service
+
+// Services holds the services built on top of a local bolt database.
+type Services struct {
+	DB         *storm.DB
+	Storage    *serverstorage.Storage
+	Hub        *watch.Hub
+	Auditor    audit.Service
+	Trash      trash.Service
+	Sets       propertyset.Service
+	Properties property.Service
+
+	path string
+}
+
+// New opens the bolt database at the given path and builds the services on top
+// of it. The trash service is built by the given constructor and keeps the
+// entries for an hour. The background jobs of the services are disabled.
+func New(path string, newTrash TrashConstructor) *Services {
+	return NewWithIntegrity(path, newTrash, config.IntegrityReport)
+}
+
+// NewWithIntegrity builds the services just like New, keeping the names of the
+// sets consistent with the properties according to the given integrity mode.
+func NewWithIntegrity(path string, newTrash TrashConstructor, integrity string) *Services {
+	logger.Main = logger.NewDummyLogger(new(bytes.Buffer))
+	util.CreateParentFolder(path)
+
+	db, _ := storm.Open(path)
+	cipher, _ := encryption.New(Key)
+	storage := &serverstorage.Storage{
+		PropertyRepository:    property_bolt.New(db, cipher),
+		PropertySetRepository: propertyset_bolt.New(db),
+		WebhookRepository:     webhook_bolt.New(db, cipher),
+		AuditRepository:       audit_bolt.New(db),
+		TrashRepository:       trash_bolt.New(db, cipher),
+		ScheduleRepository:    schedule_bolt.New(db, cipher),
+		ReleaseRepository:     release_bolt.New(db, cipher),
+		Transactor:            transaction.NewBolt(db),
+	}
+
+	hub := watch.NewHub()
+	auditor := audit_service.New(storage)
+	trashService := newTrash(storage, hub, auditor, &config.TrashConfiguration{Retention: time.Hour})
+	setService := propertyset_service.New(storage, hub, auditor, trashService, &config.IntegrityConfiguration{Mode: integrity})
+
+	return &Services{
+		DB:         db,
+		Storage:    storage,
+		Hub:        hub,
+		Auditor:    auditor,
+		Trash:      trashService,
+		Sets:       setService,
+		Properties: property_service.New(storage, setService, hub, auditor, trashService, &config.ExpiryConfiguration{}),
+		path:       path,
+	}
+}
+
+// Close closes the database and removes it, along with its folder if empty.
+func (services *Services) Close() {
+	services.DB.Close()
+
+	os.Remove(services.path)
+	os.Remove(filepath.Dir(services.path))
+}
@@ -0,0 +1,87 @@
+package transaction
+
+import (
+	"context"
+
+	"github.com/asdine/storm/v3"
+)
+
+type boltTransactor struct {
+	db *storm.DB
+}
+
+// NewBolt retrieves a Transactor running units of work in storm transactions.
+func NewBolt(db *storm.DB) Transactor {
+	return &boltTransactor{db: db}
+}
+
+func (t *boltTransactor) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if fromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txCtx, u := newContext(ctx, tx)
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	u.committed()
+
+	return nil
+}
+
+func boltTx(ctx context.Context) (storm.Node, bool) {
+	u := fromContext(ctx)
+	if u == nil {
+		return nil, false
+	}
+
+	tx, found := u.tx.(storm.Node)
+
+	return tx, found
+}
+
+// BoltNode retrieves the node through which the given db must be accessed: the
+// transaction carried by the given context, if any; otherwise the db itself.
+func BoltNode(ctx context.Context, db *storm.DB) storm.Node {
+	if tx, found := boltTx(ctx); found {
+		return tx
+	}
+
+	return db
+}
+
+// BoltBegin starts a writable transaction on the given db. If the given context
+// already carries a transaction, the retrieved node joins it instead, leaving
+// the commit or rollback to the Transactor that started it.
+func BoltBegin(ctx context.Context, db *storm.DB) (storm.Node, error) {
+	if tx, found := boltTx(ctx); found {
+		return joinedNode{tx}, nil
+	}
+
+	return db.Begin(true)
+}
+
+// joinedNode is a transaction joined by a repository, which must neither commit
+// nor roll back the transaction.
+type joinedNode struct {
+	storm.Node
+}
+
+func (n joinedNode) Commit() error {
+	return nil
+}
+
+func (n joinedNode) Rollback() error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var defaultDir = "../tests/local-repo"
+var defaultDB = "../tests/local-repo/transactiondb"
+
+type entry struct {
+	ID    string
+	Value string
+}
+
+func TestBoltRunCommit(t *testing.T) {
+	db := setup()
+	defer tearDown(db)
+
+	err := NewBolt(db).Run(context.Background(), func(ctx context.Context) error {
+		if err := BoltNode(ctx, db).Save(&entry{ID: "1", Value: "one"}); err != nil {
+			return err
+		}
+
+		tx, err := BoltBegin(ctx, db)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if err := tx.Save(&entry{ID: "2", Value: "two"}); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, count(db))
+}
+
+func TestBoltRunRollback(t *testing.T) {
+	db := setup()
+	defer tearDown(db)
+
+	failure := errors.New("failure")
+	transactor := NewBolt(db)
+	err := transactor.Run(context.Background(), func(ctx context.Context) error {
+		tx, err := BoltBegin(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		// The joined transaction must not be committed by the repository.
+		tx.Save(&entry{ID: "1", Value: "one"})
+		tx.Commit()
+
+		return transactor.Run(ctx, func(ctx context.Context) error {
+			BoltNode(ctx, db).Save(&entry{ID: "2", Value: "two"})
+
+			return failure
+		})
+	})
+
+	assert.Equal(t, failure, err)
+	assert.Equal(t, 0, count(db))
+}
+
+func TestBoltAfterCommit(t *testing.T) {
+	db := setup()
+	defer tearDown(db)
+
+	calls := make([]string, 0)
+	AfterCommit(context.Background(), func() { calls = append(calls, "outside") })
+
+	transactor := NewBolt(db)
+	transactor.Run(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() { calls = append(calls, "committed") })
+		assert.Equal(t, []string{"outside"}, calls)
+
+		return nil
+	})
+
+	transactor.Run(context.Background(), func(ctx context.Context) error {
+		AfterCommit(ctx, func() { calls = append(calls, "rolled back") })
+
+		return errors.New("failure")
+	})
+
+	assert.Equal(t, []string{"outside", "committed"}, calls)
+}
+
+func TestBoltNode(t *testing.T) {
+	db := setup()
+	defer tearDown(db)
+
+	assert.Equal(t, db, BoltNode(context.Background(), db))
+}
+
+func count(db *storm.DB) int {
+	var entries []entry
+	db.All(&entries)
+
+	return len(entries)
+}
+
+func setup() *storm.DB {
+	util.CreateParentFolder(defaultDB)
+
+	db, _ := storm.Open(defaultDB)
+
+	return db
+}
+
+func tearDown(db *storm.DB) {
+	db.Close()
+
+	os.Remove(defaultDB)
+	os.Remove(defaultDir)
+}
@@ -0,0 +1,51 @@
+package transaction
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoTransactor struct {
+	client *mongo.Client
+}
+
+// NewMongo retrieves a Transactor running units of work in session transactions.
+// Transactions require the mongoDB deployment to be a replica set or a sharded
+// cluster.
+//
+// The driver may retry a transaction that failed with a transient error, so the
+// unit of work may be called more than once.
+func NewMongo(client *mongo.Client) Transactor {
+	return &mongoTransactor{client: client}
+}
+
+func (t *mongoTransactor) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if fromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := t.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	var u *unit
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		// The operations join the transaction through the session carried by
+		// the context, so the unit of work needs no storage transaction.
+		var txCtx context.Context
+		txCtx, u = newContext(sessionCtx, nil)
+
+		return nil, fn(txCtx)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	u.committed()
+
+	return nil
+}
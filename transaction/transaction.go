@@ -0,0 +1,63 @@
+/*
+Package transaction implements the units of work spanning several repositories.
+A unit of work is applied all-or-nothing: either all of its changes are
+persisted, either none of them. The transaction of the current unit of work is
+carried along by means of the context, so that the repositories join it.
+*/
+package transaction
+
+import (
+	"context"
+)
+
+// Transactor runs units of work atomically.
+type Transactor interface {
+	// Run calls the given function with a context carrying a new transaction.
+	// The transaction is committed if the function succeeds and rolled back
+	// otherwise. If the given context already carries a transaction, the
+	// function joins it.
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// unit is the unit of work carried by a context.
+type unit struct {
+	// tx is the transaction of the storage, if the storage needs one to be
+	// carried along.
+	tx interface{}
+
+	callbacks []func()
+}
+
+type contextKey struct{}
+
+func newContext(ctx context.Context, tx interface{}) (context.Context, *unit) {
+	u := &unit{tx: tx}
+
+	return context.WithValue(ctx, contextKey{}, u), u
+}
+
+func fromContext(ctx context.Context) *unit {
+	u, _ := ctx.Value(contextKey{}).(*unit)
+
+	return u
+}
+
+func (u *unit) committed() {
+	for _, callback := range u.callbacks {
+		callback()
+	}
+}
+
+// AfterCommit calls the given function once the transaction carried by the
+// given context is committed; the function is never called if the transaction
+// is rolled back. If the context carries no transaction, the function is called
+// right away.
+func AfterCommit(ctx context.Context, fn func()) {
+	u := fromContext(ctx)
+	if u == nil {
+		fn()
+		return
+	}
+
+	u.callbacks = append(u.callbacks, fn)
+}
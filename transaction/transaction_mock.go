@@ -0,0 +1,14 @@
+package transaction
+
+import (
+	"context"
+)
+
+// TransactorMock runs the units of work right away, without any transaction.
+type TransactorMock struct {
+}
+
+// Run mock function.
+func (t *TransactorMock) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,36 @@
+/*
+Package trash implements the trash of the deleted properties and sets.
+
+A deleted entity is moved to the trash, where it is kept for the configured
+retention period. Until then it can be restored; afterwards it is permanently
+removed by a background purge.
+*/
+package trash
+
+import "github.com/rghiorghisor/basic-go-rest-api/model"
+
+// All kinds of entities that can be trashed.
+const (
+	EntityProperty = "property"
+	EntitySet      = "set"
+)
+
+// PropertyEntry creates a trash entry holding the given deleted property.
+func PropertyEntry(prop *model.Property) *model.TrashEntry {
+	return &model.TrashEntry{
+		Namespace: prop.Namespace,
+		Entity:    EntityProperty,
+		Name:      prop.Name,
+		Property:  prop,
+	}
+}
+
+// SetEntry creates a trash entry holding the given deleted set.
+func SetEntry(set *model.PropertySet) *model.TrashEntry {
+	return &model.TrashEntry{
+		Namespace: set.Namespace,
+		Entity:    EntitySet,
+		Name:      set.Name,
+		Set:       set,
+	}
+}
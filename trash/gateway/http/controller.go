@@ -0,0 +1,159 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+)
+
+// Controller that handles the relation between the server and the service.
+type Controller struct {
+	service trash.Service
+}
+
+// New retrieves a brand new contoller wrapping around the given service.
+func New(service trash.Service) server.ControllerWrapper {
+	return server.ControllerWrapper{
+		Controller: &Controller{
+			service: service,
+		},
+	}
+}
+
+// EntryDto defines how a trash entry must be exposed. The values of secret
+// properties are never exposed.
+type EntryDto struct {
+	ID        string       `json:"id"`
+	Entity    string       `json:"entity"`
+	Name      string       `json:"name"`
+	Property  *PropertyDto `json:"property,omitempty"`
+	Set       *SetDto      `json:"set,omitempty"`
+	Sets      []string     `json:"sets,omitempty"`
+	DeletedAt time.Time    `json:"deleted_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// PropertyDto defines how a trashed property must be exposed.
+type PropertyDto struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Value       string            `json:"value"`
+	Secret      bool              `json:"secret,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Overrides   map[string]string `json:"overrides,omitempty"`
+}
+
+// SetDto defines how a trashed set must be exposed.
+type SetDto struct {
+	Name     string   `json:"name"`
+	Values   []string `json:"values"`
+	Includes []string `json:"includes,omitempty"`
+}
+
+type readAllResponseDto struct {
+	Entries       []*EntryDto `json:"entries"`
+	NextPageToken string      `json:"next_page_token,omitempty"`
+	Total         int         `json:"total"`
+}
+
+// ReadAll retrieves a page of the deleted properties and sets that can still
+// be restored.
+func (ctrl *Controller) ReadAll(ctx *gin.Context) {
+	page, err := server.ParsePage(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	entries, info, err := ctrl.service.ReadAll(ctx.Request.Context(), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	dtos := make([]*EntryDto, len(entries))
+	for i, entry := range entries {
+		dtos[i] = toEntry(entry)
+	}
+
+	ctx.JSON(http.StatusOK, &readAllResponseDto{
+		Entries:       dtos,
+		NextPageToken: model.EncodePageToken(info.Next),
+		Total:         info.Total,
+	})
+}
+
+// Restore recreates the property or the set of a single trash entry. The
+// response holds the restored entity; a restored property keeps its id.
+func (ctrl *Controller) Restore(ctx *gin.Context) {
+	entry, err := ctrl.service.Restore(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toEntry(entry))
+}
+
+func toEntry(entry *model.TrashEntry) *EntryDto {
+	dto := &EntryDto{
+		ID:        entry.ID,
+		Entity:    entry.Entity,
+		Name:      entry.Name,
+		Sets:      entry.Sets,
+		DeletedAt: entry.DeletedAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+
+	if entry.Property != nil {
+		dto.Property = toProperty(entry.Property)
+	}
+
+	if entry.Set != nil {
+		dto.Set = &SetDto{Name: entry.Set.Name, Values: entry.Set.Values, Includes: entry.Set.Includes}
+	}
+
+	return dto
+}
+
+func toProperty(prop *model.Property) *PropertyDto {
+	dto := &PropertyDto{
+		ID:          prop.ID,
+		Name:        prop.Name,
+		Description: prop.Description,
+		Type:        string(prop.Type),
+		Value:       prop.Value,
+		Secret:      prop.Secret,
+		Labels:      prop.Labels,
+		Overrides:   prop.Overrides,
+	}
+
+	if !prop.Secret {
+		return dto
+	}
+
+	dto.Value = model.MaskedValue
+	if prop.Overrides != nil {
+		dto.Overrides = make(map[string]string, len(prop.Overrides))
+		for profile := range prop.Overrides {
+			dto.Overrides[profile] = model.MaskedValue
+		}
+	}
+
+	return dto
+}
+
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
+func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
+	for _, api := range server.NamespacedGroups(routerGroup, "/trash") {
+		api.GET("", ctrl.ReadAll)
+		api.POST("/:id/restore", ctrl.Restore)
+	}
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var deletedAt = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func TestReadAll(t *testing.T) {
+	router, service := setup()
+
+	secret := trash.PropertyEntry(&model.Property{ID: "p1", Name: "db.password", Value: "s3cr3t", Secret: true, Overrides: map[string]string{"prod": "other"}})
+	secret.ID = "1"
+	set := trash.SetEntry(&model.PropertySet{Name: "common", Values: []string{"db.password"}})
+	set.ID = "2"
+	for _, entry := range []*model.TrashEntry{secret, set} {
+		entry.DeletedAt = deletedAt
+		entry.ExpiresAt = deletedAt.Add(time.Hour)
+	}
+	service.On("ReadAll", model.Page{Limit: 2}).Return([]*model.TrashEntry{secret, set}, model.PageInfo{Total: 3, Next: "2"}, nil)
+
+	w := perform("GET", "/api/trash?limit=2", router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"entries":[`+
+		`{"id":"1","entity":"property","name":"db.password","property":{"id":"p1","name":"db.password","value":"******","secret":true,"overrides":{"prod":"******"}},"deleted_at":"2020-10-01T12:00:00Z","expires_at":"2020-10-01T13:00:00Z"},`+
+		`{"id":"2","entity":"set","name":"common","set":{"name":"common","values":["db.password"]},"deleted_at":"2020-10-01T12:00:00Z","expires_at":"2020-10-01T13:00:00Z"}`+
+		`],"next_page_token":"Mg","total":3}`, w.Body.String())
+}
+
+func TestRestore(t *testing.T) {
+	router, service := setup()
+
+	entry := trash.PropertyEntry(&model.Property{ID: "p2", Name: "test.name", Value: "value"})
+	entry.ID = "1"
+	entry.DeletedAt = deletedAt
+	entry.ExpiresAt = deletedAt.Add(time.Hour)
+	service.On("Restore", "1").Return(entry, nil)
+
+	w := perform("POST", "/api/trash/1/restore", router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"id":"1","entity":"property","name":"test.name","property":{"id":"p2","name":"test.name","value":"value"},"deleted_at":"2020-10-01T12:00:00Z","expires_at":"2020-10-01T13:00:00Z"}`, w.Body.String())
+}
+
+func TestRestoreFailed(t *testing.T) {
+	router, service := setup()
+
+	service.On("Restore", "missing").Return(nil, apperrors.NewEntityNotFound(model.TrashEntry{}, "missing"))
+	service.On("Restore", "taken").Return(nil, apperrors.NewConflict(reflect.TypeOf(&model.Property{}), "name", "test.name"))
+
+	w := perform("POST", "/api/trash/missing/restore", router)
+	assert.Equal(t, 404, w.Code)
+
+	w = perform("POST", "/api/trash/taken/restore", router)
+	assert.Equal(t, 409, w.Code)
+}
+
+func setup() (r *gin.Engine, serviceMock *trash_service.TrashServiceMock) {
+	router := gin.Default()
+	router.Use(
+		jsonAppErrorHandler(),
+	)
+	api := router.Group("/api")
+
+	service := new(trash_service.TrashServiceMock)
+	controller := New(service).Controller
+	controller.Register(api)
+
+	return router, service
+}
+
+func perform(method string, uri string, router *gin.Engine) (rr *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+
+	req, _ := http.NewRequest(method, uri, new(bytes.Buffer))
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func jsonAppErrorHandler() gin.HandlerFunc {
+	return handle(gin.ErrorTypeAny)
+}
+
+func handle(errType gin.ErrorType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		detectedErrors := c.Errors
+
+		if len(detectedErrors) > 0 {
+			err := detectedErrors[0].Err
+
+			switch err.(type) {
+			case *apperrors.Error:
+				oError := err.(*apperrors.Error)
+				c.AbortWithError(oError.Code, oError)
+			default:
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+
+			return
+		}
+	}
+}
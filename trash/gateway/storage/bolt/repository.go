@@ -0,0 +1,210 @@
+package bolt
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	"github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage"
+)
+
+// TrashRepository is a representation of the trash repository for Bolt DBs.
+type TrashRepository struct {
+	db     *storm.DB
+	cipher *encryption.Cipher
+}
+
+type trashEntryDto struct {
+	ID        string `storm:"id"`
+	Namespace string
+	Entity    string
+	Name      string
+	Payload   []byte
+	Sets      []string
+	DeletedAt time.Time
+	ExpiresAt time.Time
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values of the trashed secret properties.
+func New(db *storm.DB, cipher *encryption.Cipher) storage.Repository {
+	repo := &TrashRepository{
+		db:     db,
+		cipher: cipher,
+	}
+	db.Init(&trashEntryDto{})
+
+	return repo
+}
+
+// Create a new entry based on the provided trash entry. The entry is given a
+// brand new id.
+func (repository TrashRepository) Create(ctx context.Context, entry *model.TrashEntry) error {
+	payload, err := storage.EncodePayload(repository.cipher, entry)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = uuid.New().String()
+	dto := convertToDto(entry)
+	dto.Payload = payload
+
+	return repository.node(ctx).Save(dto)
+}
+
+// ReadAll retrieves the given page of the entries within the given namespace,
+// sorted by id.
+func (repository TrashRepository) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.TrashEntry, model.PageInfo, error) {
+	var dtos []trashEntryDto
+	err := repository.node(ctx).Select(q.Eq("Namespace", namespace)).Find(&dtos)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, model.PageInfo{}, err
+	}
+
+	sort.Slice(dtos, func(i, j int) bool {
+		return page.Less(dtos[i].ID, dtos[j].ID)
+	})
+
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+
+	from, to, info := page.Window(ids)
+
+	result := make([]*model.TrashEntry, 0, to-from)
+	for i := from; i < to; i++ {
+		entry, err := repository.convertToModel(&dtos[i])
+		if err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		result = append(result, entry)
+	}
+
+	return result, info, nil
+}
+
+// FindByID retrieves the entry matching the given id within the given namespace
+// if such an entry exists; otherwise will return a not found error.
+func (repository TrashRepository) FindByID(ctx context.Context, namespace string, id string) (*model.TrashEntry, error) {
+	dto, err := repository.find(repository.node(ctx), namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertToModel(dto)
+}
+
+// Delete the entry with the given id within the given namespace.
+func (repository TrashRepository) Delete(ctx context.Context, namespace string, id string) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dto, err := repository.find(tx, namespace, id)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.DeleteStruct(dto); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteExpired deletes the entries of all namespaces that are expired at the
+// given moment and retrieves the deleted entries.
+func (repository TrashRepository) DeleteExpired(ctx context.Context, at time.Time) ([]*model.TrashEntry, error) {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var dtos []trashEntryDto
+	err = tx.Select(q.Lte("ExpiresAt", at)).Find(&dtos)
+
+	if err == storm.ErrNotFound {
+		return []*model.TrashEntry{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*model.TrashEntry, len(dtos))
+	for i := range dtos {
+		if err := tx.DeleteStruct(&dtos[i]); err != nil {
+			return nil, err
+		}
+
+		if entries[i], err = repository.convertToModel(&dtos[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, tx.Commit()
+}
+
+func (repository TrashRepository) find(node storm.Node, namespace string, id string) (*trashEntryDto, error) {
+	var dto trashEntryDto
+	err := node.One("ID", id, &dto)
+
+	if err == storm.ErrNotFound || (err == nil && dto.Namespace != namespace) {
+		return nil, errors.NewEntityNotFound(model.TrashEntry{}, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+func convertToDto(entry *model.TrashEntry) *trashEntryDto {
+	return &trashEntryDto{
+		ID:        entry.ID,
+		Namespace: entry.Namespace,
+		Entity:    entry.Entity,
+		Name:      entry.Name,
+		Sets:      entry.Sets,
+		DeletedAt: entry.DeletedAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+}
+
+func (repository TrashRepository) convertToModel(dto *trashEntryDto) (*model.TrashEntry, error) {
+	entry := &model.TrashEntry{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		Entity:    dto.Entity,
+		Name:      dto.Name,
+		Sets:      dto.Sets,
+		DeletedAt: dto.DeletedAt,
+		ExpiresAt: dto.ExpiresAt,
+	}
+
+	if err := storage.DecodePayload(repository.cipher, entry, dto.Payload); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository TrashRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
@@ -0,0 +1,128 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var defaultDir = "../../../../tests/local-repo"
+var defaultDB = "../../../../tests/local-repo/trashdb"
+
+func TestCreateAndFind(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	deletedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	entry := trash.PropertyEntry(&model.Property{ID: "1", Namespace: "payments", Name: "db.password", Value: "s3cr3t", Secret: true, Overrides: map[string]string{"prod": "other"}, Revision: 2})
+	entry.DeletedAt = deletedAt
+	entry.ExpiresAt = deletedAt.Add(time.Hour)
+
+	assert.Equal(t, nil, repo.Create(context.Background(), entry))
+	assert.NotEqual(t, "", entry.ID)
+
+	var dto trashEntryDto
+	repo.db.One("ID", entry.ID, &dto)
+	assert.Equal(t, false, bytes.Contains(dto.Payload, []byte("s3cr3t")))
+
+	found, err := repo.FindByID(context.Background(), "payments", entry.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, entry, found)
+
+	_, err = repo.FindByID(context.Background(), "", entry.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.TrashEntry{}, entry.ID), err)
+}
+
+func TestReadAll(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	first := trash.SetEntry(&model.PropertySet{Namespace: "payments", Name: "common", Values: []string{"a"}, Version: 1})
+	second := trash.PropertyEntry(&model.Property{Namespace: "payments", Name: "a", Value: "value"})
+	other := trash.SetEntry(&model.PropertySet{Name: "common"})
+	for _, entry := range []*model.TrashEntry{first, second, other} {
+		repo.Create(context.Background(), entry)
+	}
+
+	entries, info, err := repo.ReadAll(context.Background(), "payments", model.Page{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, info.Total)
+	assert.Equal(t, 2, len(entries))
+
+	for _, entry := range entries {
+		if entry.ID == first.ID {
+			assert.Equal(t, first, entry)
+		} else {
+			assert.Equal(t, second, entry)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	entry := trash.PropertyEntry(&model.Property{Namespace: "payments", Name: "a"})
+	repo.Create(context.Background(), entry)
+
+	err := repo.Delete(context.Background(), "", entry.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.TrashEntry{}, entry.ID), err)
+
+	assert.Equal(t, nil, repo.Delete(context.Background(), "payments", entry.ID))
+
+	_, err = repo.FindByID(context.Background(), "payments", entry.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.TrashEntry{}, entry.ID), err)
+}
+
+func TestDeleteExpired(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	expired := trash.PropertyEntry(&model.Property{Namespace: "payments", Name: "a"})
+	expired.ExpiresAt = now.Add(-time.Minute)
+	expiring := trash.SetEntry(&model.PropertySet{Name: "common"})
+	expiring.ExpiresAt = now
+	kept := trash.PropertyEntry(&model.Property{Namespace: "payments", Name: "b"})
+	kept.ExpiresAt = now.Add(time.Minute)
+	for _, entry := range []*model.TrashEntry{expired, expiring, kept} {
+		repo.Create(context.Background(), entry)
+	}
+
+	deleted, err := repo.DeleteExpired(context.Background(), now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(deleted))
+
+	entries, _, _ := repo.ReadAll(context.Background(), "payments", model.Page{})
+	assert.Equal(t, []*model.TrashEntry{kept}, entries)
+
+	deleted, err = repo.DeleteExpired(context.Background(), now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(deleted))
+}
+
+func setup() *TrashRepository {
+	util.CreateParentFolder(defaultDB)
+
+	db, _ := storm.Open(defaultDB)
+	cipher, _ := encryption.New([]byte("0123456789abcdef0123456789abcdef"))
+
+	return New(db, cipher).(*TrashRepository)
+}
+
+func tearDown(repo *TrashRepository) {
+	repo.db.Close()
+
+	os.Remove(defaultDB)
+	os.Remove(defaultDir)
+}
@@ -0,0 +1,213 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const trashCollection = "trash_collection"
+
+type trashEntryDto struct {
+	ID        string    `bson:"_id"`
+	Namespace string    `bson:"namespace"`
+	Entity    string    `bson:"entity"`
+	Name      string    `bson:"name"`
+	Payload   []byte    `bson:"payload"`
+	Sets      []string  `bson:"sets,omitempty"`
+	DeletedAt time.Time `bson:"deleted_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// TrashRepository is a representation of the trash repository for a mongo DBs.
+type TrashRepository struct {
+	dbCollection *mongo.Collection
+	cipher       *encryption.Cipher
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the values of the trashed secret properties.
+func New(db *mongo.Database, cipher *encryption.Cipher) storage.Repository {
+	return &TrashRepository{
+		dbCollection: db.Collection(trashCollection),
+		cipher:       cipher,
+	}
+}
+
+// Create a new entry based on the provided trash entry. The entry is given a
+// brand new id.
+func (repository TrashRepository) Create(ctx context.Context, entry *model.TrashEntry) error {
+	payload, err := storage.EncodePayload(repository.cipher, entry)
+	if err != nil {
+		return err
+	}
+
+	entry.ID = uuid.New().String()
+	dto := convertToDto(entry)
+	dto.Payload = payload
+
+	_, err = repository.dbCollection.InsertOne(ctx, dto)
+
+	return err
+}
+
+// ReadAll retrieves the given page of the entries within the given namespace,
+// sorted by id.
+func (repository TrashRepository) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.TrashEntry, model.PageInfo, error) {
+	query := bson.M{"namespace": namespace}
+
+	total, err := repository.dbCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	order := 1
+	operator := "$gt"
+	if page.Descending {
+		order = -1
+		operator = "$lt"
+	}
+
+	if page.After != "" {
+		query["_id"] = bson.M{operator: page.After}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: order}})
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit + 1))
+	}
+
+	cursor, err := repository.dbCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := make([]*model.TrashEntry, 0)
+	for cursor.Next(ctx) {
+		dto := new(trashEntryDto)
+		if err := cursor.Decode(dto); err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		entry, err := repository.convertToModel(dto)
+		if err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	info := model.PageInfo{Total: int(total)}
+	if page.Limit > 0 && len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+		info.Next = entries[page.Limit-1].ID
+	}
+
+	return entries, info, nil
+}
+
+// FindByID retrieves the entry matching the given id within the given namespace
+// if such an entry exists; otherwise will return a not found error.
+func (repository TrashRepository) FindByID(ctx context.Context, namespace string, id string) (*model.TrashEntry, error) {
+	dto := new(trashEntryDto)
+	err := repository.dbCollection.FindOne(ctx, bson.M{"_id": id, "namespace": namespace}).Decode(dto)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.TrashEntry{}, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertToModel(dto)
+}
+
+// Delete the entry with the given id within the given namespace.
+func (repository TrashRepository) Delete(ctx context.Context, namespace string, id string) error {
+	result, err := repository.dbCollection.DeleteOne(ctx, bson.M{"_id": id, "namespace": namespace})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.NewEntityNotFound(model.TrashEntry{}, id)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the entries of all namespaces that are expired at the
+// given moment and retrieves the deleted entries.
+func (repository TrashRepository) DeleteExpired(ctx context.Context, at time.Time) ([]*model.TrashEntry, error) {
+	cursor, err := repository.dbCollection.Find(ctx, bson.M{"expires_at": bson.M{"$lte": at}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := make([]*model.TrashEntry, 0)
+	for cursor.Next(ctx) {
+		dto := new(trashEntryDto)
+		if err := cursor.Decode(dto); err != nil {
+			return nil, err
+		}
+
+		deleted, err := repository.dbCollection.DeleteOne(ctx, bson.M{"_id": dto.ID})
+		if err != nil {
+			return nil, err
+		}
+
+		if deleted.DeletedCount == 0 {
+			continue
+		}
+
+		entry, err := repository.convertToModel(dto)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func convertToDto(entry *model.TrashEntry) *trashEntryDto {
+	return &trashEntryDto{
+		ID:        entry.ID,
+		Namespace: entry.Namespace,
+		Entity:    entry.Entity,
+		Name:      entry.Name,
+		Sets:      entry.Sets,
+		DeletedAt: entry.DeletedAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+}
+
+func (repository TrashRepository) convertToModel(dto *trashEntryDto) (*model.TrashEntry, error) {
+	entry := &model.TrashEntry{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		Entity:    dto.Entity,
+		Name:      dto.Name,
+		Sets:      dto.Sets,
+		DeletedAt: dto.DeletedAt,
+		ExpiresAt: dto.ExpiresAt,
+	}
+
+	if err := storage.DecodePayload(repository.cipher, entry, dto.Payload); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+)
+
+// EncodePayload retrieves the form in which the entity held by the given entry
+// must be stored. The values of a secret property are encrypted using the given
+// cipher.
+func EncodePayload(cipher *encryption.Cipher, entry *model.TrashEntry) ([]byte, error) {
+	if entry.Set != nil {
+		return json.Marshal(entry.Set)
+	}
+
+	sealed := *entry.Property
+
+	var err error
+	if sealed.Value, err = property.SealValue(cipher, sealed.Secret, sealed.Value); err != nil {
+		return nil, err
+	}
+
+	if sealed.Overrides, err = property.SealOverrides(cipher, sealed.Secret, sealed.Overrides); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&sealed)
+}
+
+// DecodePayload reverses EncodePayload, filling in the entity of the given entry
+// based on its kind.
+func DecodePayload(cipher *encryption.Cipher, entry *model.TrashEntry, payload []byte) error {
+	if entry.Entity == trash.EntitySet {
+		entry.Set = new(model.PropertySet)
+
+		return json.Unmarshal(payload, entry.Set)
+	}
+
+	prop := new(model.Property)
+	if err := json.Unmarshal(payload, prop); err != nil {
+		return err
+	}
+
+	var err error
+	if prop.Value, err = property.OpenValue(cipher, prop.Secret, prop.Value); err != nil {
+		return err
+	}
+
+	if prop.Overrides, err = property.OpenOverrides(cipher, prop.Secret, prop.Overrides); err != nil {
+		return err
+	}
+
+	entry.Property = prop
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Repository interface defining the functionality of a basic implementations.
+//
+// The values of the trashed secret properties are stored encrypted, like the
+// values of the properties themselves.
+type Repository interface {
+	Create(ctx context.Context, entry *model.TrashEntry) error
+
+	ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.TrashEntry, model.PageInfo, error)
+
+	FindByID(ctx context.Context, namespace string, id string) (*model.TrashEntry, error)
+
+	Delete(ctx context.Context, namespace string, id string) error
+
+	DeleteExpired(ctx context.Context, at time.Time) ([]*model.TrashEntry, error)
+}
@@ -0,0 +1,16 @@
+package trash
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Service defines the use case available for the trash.
+type Service interface {
+	Put(ctx context.Context, entry *model.TrashEntry) error
+
+	ReadAll(ctx context.Context, page model.Page) ([]*model.TrashEntry, model.PageInfo, error)
+
+	Restore(ctx context.Context, id string) (*model.TrashEntry, error)
+}
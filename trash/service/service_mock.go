@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// TrashServiceMock retrieves a new mock for TrashService.
+type TrashServiceMock struct {
+	mock.Mock
+}
+
+// Put mock function.
+func (m *TrashServiceMock) Put(ctx context.Context, entry *model.TrashEntry) error {
+	args := m.Called(entry)
+
+	return args.Error(0)
+}
+
+// ReadAll mock function.
+func (m *TrashServiceMock) ReadAll(ctx context.Context, page model.Page) ([]*model.TrashEntry, model.PageInfo, error) {
+	args := m.Called(page)
+
+	return args.Get(0).([]*model.TrashEntry), args.Get(1).(model.PageInfo), args.Error(2)
+}
+
+// Restore mock function.
+func (m *TrashServiceMock) Restore(ctx context.Context, id string) (*model.TrashEntry, error) {
+	args := m.Called(id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.TrashEntry), args.Error(1)
+}
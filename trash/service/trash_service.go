@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/config"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	property "github.com/rghiorghisor/basic-go-rest-api/property/gateway/storage"
+	propertyset "github.com/rghiorghisor/basic-go-rest-api/propertyset/gateway/storage"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	"github.com/rghiorghisor/basic-go-rest-api/trash/gateway/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+)
+
+// TrashService defines the service handling the trash.
+type TrashService struct {
+	repository storage.Repository
+	properties property.Repository
+	sets       propertyset.Repository
+	transactor transaction.Transactor
+	hub        *watch.Hub
+	auditor    audit.Service
+	retention  time.Duration
+}
+
+// New creates a TrashService and starts purging the expired entries at the
+// configured interval, unless the interval is not positive.
+//
+// As this service needs access to a repository to perform action, it is the
+// responsibility of the service to get the correct repo from the storage parameter.
+// Restorations are recorded by the given auditor and published to the given hub.
+func New(storage *serverstorage.Storage, hub *watch.Hub, auditor audit.Service, configuration *config.TrashConfiguration) trash.Service {
+	service := TrashService{
+		repository: storage.TrashRepository,
+		properties: storage.PropertyRepository,
+		sets:       storage.PropertySetRepository,
+		transactor: storage.Transactor,
+		hub:        hub,
+		auditor:    auditor,
+		retention:  configuration.Retention,
+	}
+
+	if configuration.PurgeInterval > 0 {
+		go service.purge(configuration.PurgeInterval)
+	}
+
+	return service
+}
+
+// Put moves the given deleted entity to the trash, where it is kept for the
+// configured retention period. The entry is written within the given context,
+// i.e. within its transaction, if any.
+func (service TrashService) Put(ctx context.Context, entry *model.TrashEntry) error {
+	entry.DeletedAt = time.Now().UTC()
+	entry.ExpiresAt = entry.DeletedAt.Add(service.retention)
+
+	return service.repository.Create(ctx, entry)
+}
+
+// ReadAll retrieves a page of the entries of the namespace of the given context.
+func (service TrashService) ReadAll(ctx context.Context, page model.Page) ([]*model.TrashEntry, model.PageInfo, error) {
+	return service.repository.ReadAll(ctx, namespace.FromContext(ctx), page)
+}
+
+// Restore recreates the entity held by the entry with the given id and removes
+// the entry from the trash, all-or-nothing. A restored property keeps its id
+// and its history, to which the restoration is added as a new revision, and it
+// is added back to the sets it was removed from by its deletion, if they still
+// exist. The entity cannot be restored while another one has its name.
+func (service TrashService) Restore(ctx context.Context, id string) (*model.TrashEntry, error) {
+	entry, err := service.repository.FindByID(ctx, namespace.FromContext(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Expired(time.Now()) {
+		return nil, errors.NewEntityNotFound(model.TrashEntry{}, id)
+	}
+
+	err = service.transactor.Run(ctx, func(ctx context.Context) error {
+		var err error
+		if entry.Entity == trash.EntitySet {
+			err = service.restoreSet(ctx, entry.Set)
+		} else {
+			err = service.restoreProperty(ctx, entry.Property, entry.Sets)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return service.repository.Delete(ctx, entry.Namespace, entry.ID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (service TrashService) restoreProperty(ctx context.Context, prop *model.Property, sets []string) error {
+	foundProp, _ := service.properties.FindByName(ctx, prop.Namespace, prop.Name)
+	if foundProp != nil {
+		return errors.NewConflict(reflect.TypeOf(foundProp), "name", prop.Name)
+	}
+
+	if err := service.properties.Restore(ctx, prop); err != nil {
+		return err
+	}
+
+	if err := service.auditor.Record(ctx, audit.PropertyChange(audit.ActionRestore, nil, prop)); err != nil {
+		return err
+	}
+
+	service.hub.Publish(ctx, watch.PropertyEvent(watch.Created, prop))
+
+	for _, name := range sets {
+		if err := service.restoreMember(ctx, prop, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restoreMember adds the given property back to the values of the set with the
+// given name, unless the set no longer exists or already names the property.
+func (service TrashService) restoreMember(ctx context.Context, prop *model.Property, name string) error {
+	foundSet, err := service.sets.FindByID(ctx, prop.Namespace, name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, value := range foundSet.Values {
+		if value == prop.Name {
+			return nil
+		}
+	}
+
+	set := *foundSet
+	set.Values = append(append([]string(nil), foundSet.Values...), prop.Name)
+	if err := service.sets.Update(ctx, &set); err != nil {
+		return err
+	}
+
+	if err := service.auditor.Record(ctx, audit.SetChange(audit.ActionUpdate, foundSet, &set)); err != nil {
+		return err
+	}
+
+	service.hub.Publish(ctx, watch.SetEvent(watch.Updated, &set))
+
+	return nil
+}
+
+func (service TrashService) restoreSet(ctx context.Context, set *model.PropertySet) error {
+	if err := service.sets.Create(ctx, set); err != nil {
+		return err
+	}
+
+	if err := service.auditor.Record(ctx, audit.SetChange(audit.ActionRestore, nil, set)); err != nil {
+		return err
+	}
+
+	service.hub.Publish(ctx, watch.SetEvent(watch.Created, set))
+
+	return nil
+}
+
+// purge permanently removes the expired entries, once every given interval.
+func (service TrashService) purge(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		service.purgeExpired(time.Now())
+	}
+}
+
+// purgeExpired removes the entries expired at the given moment in time, along
+// with the history of the properties they hold.
+func (service TrashService) purgeExpired(at time.Time) {
+	entries, err := service.repository.DeleteExpired(context.Background(), at)
+	if err != nil {
+		logger.Main.Error("Cannot purge the trash", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.Entity != trash.EntityProperty {
+			continue
+		}
+
+		if err := service.properties.DeleteHistory(context.Background(), entry.Property.ID); err != nil {
+			logger.Main.Error("Cannot remove the history of a purged property", err)
+		}
+	}
+
+	if len(entries) > 0 {
+		logger.Main.Infof("Purged %d expired trash entries.", len(entries))
+	}
+}
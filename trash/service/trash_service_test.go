@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/audit"
+	"github.com/rghiorghisor/basic-go-rest-api/config"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/tests/fixture"
+	"github.com/rghiorghisor/basic-go-rest-api/trash"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultDB = "../../tests/local-repo/trashservicedb"
+
+type testContext struct {
+	services        *fixture.Services
+	service         TrashService
+	propertyService property.Service
+	setService      propertyset.Service
+}
+
+func TestDeleteAndRestoreProperty(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := namespace.NewContext(context.Background(), "payments")
+	prop := &model.Property{Name: "test.name", Value: "value", Labels: map[string]string{"team": "payments"}}
+	tc.propertyService.Create(ctx, prop)
+	assert.Nil(t, tc.propertyService.Delete(ctx, prop.ID, 0))
+
+	entries, info, err := tc.service.ReadAll(ctx, model.Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, info.Total)
+	assert.Equal(t, trash.EntityProperty, entries[0].Entity)
+	assert.Equal(t, "test.name", entries[0].Name)
+	assert.Equal(t, time.Hour, entries[0].ExpiresAt.Sub(entries[0].DeletedAt))
+
+	entries, _, _ = tc.service.ReadAll(context.Background(), model.Page{})
+	assert.Equal(t, 0, len(entries))
+
+	restored, err := tc.service.Restore(ctx, entryID(ctx, t, tc))
+	assert.Nil(t, err)
+	assert.Equal(t, "value", restored.Property.Value)
+
+	found, err := tc.propertyService.FindByID(ctx, prop.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, "payments", found.Namespace)
+	assert.Equal(t, map[string]string{"team": "payments"}, found.Labels)
+	assert.Equal(t, 2, found.Revision)
+
+	history, _ := tc.services.Storage.PropertyRepository.ReadHistory(ctx, prop.ID)
+	assert.Equal(t, 2, len(history))
+
+	entries, _, _ = tc.service.ReadAll(ctx, model.Page{})
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestRestoreConflict(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "value"}
+	tc.propertyService.Create(ctx, prop)
+	tc.propertyService.Delete(ctx, prop.ID, 0)
+	tc.propertyService.Create(ctx, &model.Property{Name: "test.name", Value: "other"})
+
+	id := entryID(ctx, t, tc)
+	_, err := tc.service.Restore(ctx, id)
+	assert.Equal(t, apperrors.NewConflict(reflect.TypeOf(&model.Property{}), "name", "test.name"), err)
+
+	entries, _, _ := tc.service.ReadAll(ctx, model.Page{})
+	assert.Equal(t, 1, len(entries))
+}
+
+func TestDeleteAndRestoreSet(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	tc.setService.Create(ctx, &model.PropertySet{Name: "common", Values: []string{"a", "b"}})
+	assert.Nil(t, tc.setService.Delete(ctx, "common", 0))
+
+	_, err := tc.setService.FindByID(ctx, "common")
+	assert.True(t, apperrors.IsNotFound(err))
+
+	_, err = tc.service.Restore(ctx, entryID(ctx, t, tc))
+	assert.Nil(t, err)
+
+	found, err := tc.setService.FindByID(ctx, "common")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, found.Values)
+}
+
+func TestRestoreNotFound(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	tc.setService.Create(ctx, &model.PropertySet{Name: "common"})
+	tc.setService.Delete(ctx, "common", 0)
+	id := entryID(ctx, t, tc)
+
+	_, err := tc.service.Restore(namespace.NewContext(ctx, "payments"), id)
+	assert.Equal(t, apperrors.NewEntityNotFound(model.TrashEntry{}, id), err)
+
+	_, err = tc.service.Restore(ctx, "missing")
+	assert.Equal(t, apperrors.NewEntityNotFound(model.TrashEntry{}, "missing"), err)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	tc.setService.Create(ctx, &model.PropertySet{Name: "common"})
+	tc.setService.Delete(ctx, "common", 0)
+
+	tc.service.purgeExpired(time.Now())
+	entries, _, _ := tc.service.ReadAll(ctx, model.Page{})
+	assert.Equal(t, 1, len(entries))
+
+	tc.service.purgeExpired(time.Now().Add(time.Hour))
+	entries, _, _ = tc.service.ReadAll(ctx, model.Page{})
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestPurgeExpiredHistory(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "value"}
+	tc.propertyService.Create(ctx, prop)
+	tc.propertyService.Delete(ctx, prop.ID, 0)
+
+	history, _ := tc.services.Storage.PropertyRepository.ReadHistory(ctx, prop.ID)
+	assert.Equal(t, 1, len(history))
+
+	tc.service.purgeExpired(time.Now().Add(time.Hour))
+	history, _ = tc.services.Storage.PropertyRepository.ReadHistory(ctx, prop.ID)
+	assert.Equal(t, 0, len(history))
+}
+
+func TestDeleteAndRestoreCascaded(t *testing.T) {
+	services := fixture.NewWithIntegrity(defaultDB, New, config.IntegrityCascade)
+	defer services.Close()
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "value"}
+	services.Properties.Create(ctx, prop)
+	services.Properties.Create(ctx, &model.Property{Name: "test.other", Value: "value"})
+	services.Sets.Create(ctx, &model.PropertySet{Name: "common", Values: []string{"test.name", "test.other"}})
+	services.Sets.Create(ctx, &model.PropertySet{Name: "gone", Values: []string{"test.name"}})
+	services.Sets.Create(ctx, &model.PropertySet{Name: "other", Values: []string{"test.other"}})
+
+	assert.Nil(t, services.Properties.Delete(ctx, prop.ID, 0))
+
+	set, _ := services.Sets.FindByID(ctx, "common")
+	assert.Equal(t, []string{"test.other"}, set.Values)
+
+	entries, _, _ := services.Trash.ReadAll(ctx, model.Page{})
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, []string{"common", "gone"}, entries[0].Sets)
+
+	assert.Nil(t, services.Sets.Delete(ctx, "gone", 0))
+
+	_, err := services.Trash.Restore(ctx, entries[0].ID)
+	assert.Nil(t, err)
+
+	set, _ = services.Sets.FindByID(ctx, "common")
+	assert.Equal(t, []string{"test.other", "test.name"}, set.Values)
+
+	set, _ = services.Sets.FindByID(ctx, "other")
+	assert.Equal(t, []string{"test.other"}, set.Values)
+
+	_, err = services.Sets.FindByID(ctx, "gone")
+	assert.True(t, apperrors.IsNotFound(err))
+}
+
+func TestDeleteRolledBack(t *testing.T) {
+	failure := errors.New("failure")
+	services := fixture.NewWithIntegrity(defaultDB, func(storage *serverstorage.Storage, hub *watch.Hub, auditor audit.Service, configuration *config.TrashConfiguration) trash.Service {
+		return failingTrash{Service: New(storage, hub, auditor, configuration), err: failure}
+	}, config.IntegrityCascade)
+	defer services.Close()
+
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "value"}
+	services.Properties.Create(ctx, prop)
+	services.Sets.Create(ctx, &model.PropertySet{Name: "common", Values: []string{"test.name"}})
+
+	assert.Equal(t, failure, services.Properties.Delete(ctx, prop.ID, 0))
+	assert.Equal(t, failure, services.Sets.Delete(ctx, "common", 0))
+
+	_, err := services.Properties.FindByID(ctx, prop.ID)
+	assert.Nil(t, err)
+
+	set, err := services.Sets.FindByID(ctx, "common")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test.name"}, set.Values)
+}
+
+func TestDeleteCascadeRolledBack(t *testing.T) {
+	services := fixture.NewWithIntegrity(defaultDB, New, config.IntegrityCascade)
+	defer services.Close()
+
+	// The 'broken' set can no longer be updated once the set it includes is
+	// deleted, which fails the cascade after the 'common' set is updated.
+	ctx := context.Background()
+	prop := &model.Property{Name: "test.name", Value: "value"}
+	services.Properties.Create(ctx, prop)
+	services.Sets.Create(ctx, &model.PropertySet{Name: "base"})
+	services.Sets.Create(ctx, &model.PropertySet{Name: "broken", Values: []string{"test.name"}, Includes: []string{"base"}})
+	services.Sets.Create(ctx, &model.PropertySet{Name: "a.common", Values: []string{"test.name"}})
+	assert.Nil(t, services.Sets.Delete(ctx, "base", 0))
+
+	assert.NotNil(t, services.Properties.Delete(ctx, prop.ID, 0))
+
+	_, err := services.Properties.FindByID(ctx, prop.ID)
+	assert.Nil(t, err)
+
+	set, _ := services.Sets.FindByID(ctx, "a.common")
+	assert.Equal(t, []string{"test.name"}, set.Values)
+
+	entries, _, _ := services.Trash.ReadAll(ctx, model.Page{})
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, trash.EntitySet, entries[0].Entity)
+}
+
+// failingTrash fails to put any entry.
+type failingTrash struct {
+	trash.Service
+	err error
+}
+
+func (t failingTrash) Put(ctx context.Context, entry *model.TrashEntry) error {
+	return t.err
+}
+
+// entryID retrieves the id of the single entry of the trash.
+func entryID(ctx context.Context, t *testing.T, tc *testContext) string {
+	entries, _, err := tc.service.ReadAll(ctx, model.Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	return entries[0].ID
+}
+
+func setup() *testContext {
+	services := fixture.New(defaultDB, New)
+
+	return &testContext{
+		services:        services,
+		service:         services.Trash.(TrashService),
+		propertyService: services.Properties,
+		setService:      services.Sets,
+	}
+}
+
+func tearDown(tc *testContext) {
+	tc.services.Close()
+}
@@ -0,0 +1,205 @@
+/*
+Package watch implements the notification of the changes made to properties and
+sets. Every change is given an index, increasing by one with each change, that
+identifies the state of the store as seen by the watchers. The index is kept in
+memory, so it starts over when the application is restarted.
+*/
+package watch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+)
+
+// EventType defines the kind of change an event notifies.
+type EventType string
+
+// All kinds of changes that are notified.
+const (
+	Created EventType = "created"
+	Updated EventType = "updated"
+	Deleted EventType = "deleted"
+)
+
+// bufferSize is the number of events a subscription holds for its receiver.
+const bufferSize = 64
+
+// Event notifies a change of either a property or a set.
+type Event struct {
+	// Index is the index of the store after the change.
+	Index uint64
+	Type  EventType
+
+	// Namespace is the namespace of the changed entity.
+	Namespace string
+
+	// Property is the changed property, as it is after the change. Deleted
+	// properties are given as they were before the deletion.
+	Property *model.Property
+
+	// Set is the changed set, as it is after the change. Only the name of the
+	// deleted sets is known.
+	Set *model.PropertySet
+
+	// FormerSets names the sets the changed property was removed from by the
+	// change, directly or through the sets they include, as the renaming and
+	// deletion of the properties may be cascaded to the sets naming them. The
+	// filters by set handle the event as if the property were still a member of
+	// these sets.
+	FormerSets []string
+}
+
+// WasMember checks whether the changed property was a member of the set with the
+// given name before the change removed it from the set (see FormerSets).
+func (event Event) WasMember(set string) bool {
+	for _, name := range event.FormerSets {
+		if name == set {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PropertyEvent retrieves an event notifying a change of the given property.
+func PropertyEvent(typ EventType, prop *model.Property) Event {
+	changed := *prop
+
+	return Event{Type: typ, Namespace: prop.Namespace, Property: &changed}
+}
+
+// SetEvent retrieves an event notifying a change of the given set.
+func SetEvent(typ EventType, set *model.PropertySet) Event {
+	changed := *set
+	changed.Values = append([]string(nil), set.Values...)
+	changed.Includes = append([]string(nil), set.Includes...)
+
+	return Event{Type: typ, Namespace: set.Namespace, Set: &changed}
+}
+
+// Hub dispatches the events to all subscriptions.
+type Hub struct {
+	mutex         sync.Mutex
+	index         uint64
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewHub retrieves a brand new hub, starting at index zero.
+func NewHub() *Hub {
+	return &Hub{subscriptions: make(map[*Subscription]struct{})}
+}
+
+// Index retrieves the current index of the store.
+func (hub *Hub) Index() uint64 {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	return hub.index
+}
+
+// Publish dispatches the given event to the subscriptions. If the given context
+// carries a transaction, the event is dispatched only after the transaction is
+// committed.
+func (hub *Hub) Publish(ctx context.Context, event Event) {
+	transaction.AfterCommit(ctx, func() {
+		hub.publish(event)
+	})
+}
+
+func (hub *Hub) publish(event Event) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	hub.index++
+	event.Index = hub.index
+	for subscription := range hub.subscriptions {
+		if subscription.filter != nil && !subscription.filter(event) {
+			continue
+		}
+
+		select {
+		case subscription.events <- event:
+		default:
+			// The receiver fell behind, so it can no longer be notified of
+			// all changes. Closing the subscription signals that it must
+			// catch up by other means.
+			hub.remove(subscription)
+		}
+	}
+}
+
+// Subscribe retrieves a new subscription receiving the events accepted by the
+// given filter, or all events if the filter is nil. The filter is called for
+// one event at a time, in the order of the events, while the hub dispatches
+// them to all subscriptions, so it must neither block nor read the storage.
+func (hub *Hub) Subscribe(filter func(Event) bool) *Subscription {
+	return hub.SubscribeChecked(filter, nil)
+}
+
+// SubscribeChecked retrieves a new subscription just like Subscribe, whose
+// events are checked by the receiver as well (see Accepts). Unlike the filter,
+// the check may take its time, e.g. to read the storage.
+func (hub *Hub) SubscribeChecked(filter func(Event) bool, check func(Event) bool) *Subscription {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	subscription := &Subscription{
+		hub:    hub,
+		index:  hub.index,
+		filter: filter,
+		check:  check,
+		events: make(chan Event, bufferSize),
+	}
+	hub.subscriptions[subscription] = struct{}{}
+
+	return subscription
+}
+
+func (hub *Hub) remove(subscription *Subscription) {
+	if _, found := hub.subscriptions[subscription]; !found {
+		return
+	}
+
+	delete(hub.subscriptions, subscription)
+	close(subscription.events)
+}
+
+// Subscription receives the events of a hub, starting with the events that
+// follow its creation.
+type Subscription struct {
+	hub    *Hub
+	index  uint64
+	filter func(Event) bool
+	check  func(Event) bool
+	events chan Event
+}
+
+// Index retrieves the index of the store when the subscription was created.
+func (subscription *Subscription) Index() uint64 {
+	return subscription.index
+}
+
+// Events retrieves the channel delivering the events. The channel is closed
+// when the subscription is closed, including when the receiver falls too far
+// behind.
+func (subscription *Subscription) Events() <-chan Event {
+	return subscription.events
+}
+
+// Accepts tells whether the receiver should handle the given event, received
+// from the subscription. The receiver must call it for all events, in the order
+// they are received.
+func (subscription *Subscription) Accepts(event Event) bool {
+	return subscription.check == nil || subscription.check(event)
+}
+
+// Close stops the delivery of events.
+func (subscription *Subscription) Close() {
+	subscription.hub.mutex.Lock()
+	defer subscription.hub.mutex.Unlock()
+
+	subscription.hub.remove(subscription)
+}
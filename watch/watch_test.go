@@ -0,0 +1,74 @@
+package watch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe(nil)
+	sets := hub.Subscribe(func(event Event) bool { return event.Set != nil })
+
+	prop := &model.Property{Name: "test.name", Namespace: "payments"}
+	hub.Publish(context.Background(), PropertyEvent(Created, prop))
+	hub.Publish(context.Background(), SetEvent(Updated, &model.PropertySet{Name: "common"}))
+	prop.Name = "test.other"
+
+	assert.Equal(t, uint64(2), hub.Index())
+	assert.Equal(t, Event{Index: 1, Type: Created, Namespace: "payments", Property: &model.Property{Name: "test.name", Namespace: "payments"}}, <-all.Events())
+	assert.Equal(t, uint64(2), (<-all.Events()).Index)
+	assert.Equal(t, "common", (<-sets.Events()).Set.Name)
+	assert.Equal(t, uint64(0), all.Index())
+	assert.Equal(t, uint64(2), hub.Subscribe(nil).Index())
+}
+
+func TestClose(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe(nil)
+
+	subscription.Close()
+	subscription.Close()
+	hub.Publish(context.Background(), SetEvent(Deleted, &model.PropertySet{Name: "common"}))
+
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+}
+
+func TestFallingBehind(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe(nil)
+
+	for i := 0; i <= bufferSize; i++ {
+		hub.Publish(context.Background(), SetEvent(Updated, &model.PropertySet{Name: "common"}))
+	}
+
+	received := 0
+	for range subscription.Events() {
+		received++
+	}
+
+	assert.Equal(t, bufferSize, received)
+}
+
+func TestSubscribeChecked(t *testing.T) {
+	hub := NewHub()
+	checked := 0
+	subscription := hub.SubscribeChecked(func(event Event) bool { return event.Set != nil }, func(event Event) bool {
+		checked++
+		return event.Set.Name == "common"
+	})
+
+	hub.Publish(context.Background(), PropertyEvent(Created, &model.Property{Name: "test.name"}))
+	hub.Publish(context.Background(), SetEvent(Updated, &model.PropertySet{Name: "other"}))
+	hub.Publish(context.Background(), SetEvent(Updated, &model.PropertySet{Name: "common"}))
+
+	assert.Equal(t, 0, checked)
+	assert.False(t, subscription.Accepts(<-subscription.Events()))
+	assert.True(t, subscription.Accepts(<-subscription.Events()))
+	assert.Equal(t, 2, checked)
+	assert.True(t, hub.Subscribe(nil).Accepts(Event{}))
+}
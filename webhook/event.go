@@ -0,0 +1,46 @@
+/*
+Package webhook implements all functions available for webhook models.
+
+Webhooks notify external endpoints of the changes made to properties and sets.
+Each change is delivered asynchronously, as a POST request whose body is signed
+with the secret of the webhook, and is retried with an exponential backoff. The
+deliveries that fail all attempts are kept as dead letters, which can be
+inspected and replayed.
+*/
+package webhook
+
+import (
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+)
+
+// All event types a webhook can be notified of.
+const (
+	PropertyCreated = "property.created"
+	PropertyUpdated = "property.updated"
+	PropertyDeleted = "property.deleted"
+	SetCreated      = "set.created"
+	SetUpdated      = "set.updated"
+	SetDeleted      = "set.deleted"
+)
+
+var eventTypes = []string{PropertyCreated, PropertyUpdated, PropertyDeleted, SetCreated, SetUpdated, SetDeleted}
+
+// IsValidEventType checks whether the given event type is known.
+func IsValidEventType(event string) bool {
+	for _, eventType := range eventTypes {
+		if eventType == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventType retrieves the event type notifying the given change.
+func EventType(event watch.Event) string {
+	if event.Set != nil {
+		return "set." + string(event.Type)
+	}
+
+	return "property." + string(event.Type)
+}
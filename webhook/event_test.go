@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventType(t *testing.T) {
+	assert.Equal(t, PropertyUpdated, EventType(watch.PropertyEvent(watch.Updated, &model.Property{})))
+	assert.Equal(t, SetDeleted, EventType(watch.SetEvent(watch.Deleted, &model.PropertySet{})))
+}
+
+func TestIsValidEventType(t *testing.T) {
+	assert.Equal(t, true, IsValidEventType(SetCreated))
+	assert.Equal(t, false, IsValidEventType("property"))
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign("key", []byte("The quick brown fox jumps over the lazy dog"))
+
+	assert.Equal(t, "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", signature)
+	assert.Equal(t, true, Verify("key", []byte("The quick brown fox jumps over the lazy dog"), signature))
+	assert.Equal(t, false, Verify("other", []byte("The quick brown fox jumps over the lazy dog"), signature))
+}
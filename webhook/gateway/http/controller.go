@@ -0,0 +1,260 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/server"
+	"github.com/rghiorghisor/basic-go-rest-api/webhook"
+)
+
+// Controller that handles the relation between the server and the service.
+type Controller struct {
+	service webhook.Service
+}
+
+// New retrieves a brand new contoller wrapping around the given service.
+func New(service webhook.Service) server.ControllerWrapper {
+	return server.ControllerWrapper{
+		Controller: &Controller{
+			service: service,
+		},
+	}
+}
+
+// WebhookDto defines how a webhook must be exposed. The secret is never
+// exposed; Signed tells whether the webhook has one.
+type WebhookDto struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+	Set    string   `json:"set,omitempty"`
+	Signed bool     `json:"signed"`
+}
+
+// DeadLetterDto defines how a dead letter must be exposed.
+type DeadLetterDto struct {
+	ID       string          `json:"id"`
+	Event    string          `json:"event"`
+	Payload  json.RawMessage `json:"payload"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	FailedAt time.Time       `json:"failed_at"`
+}
+
+type inputDto struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Set    string   `json:"set"`
+	Secret string   `json:"secret"`
+}
+
+type readAllResponseDto struct {
+	Webhooks      []*WebhookDto `json:"webhooks"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+	Total         int           `json:"total"`
+}
+
+type readDeadLettersResponseDto struct {
+	DeadLetters   []*DeadLetterDto `json:"dead_letters"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
+	Total         int              `json:"total"`
+}
+
+// Create registers a brand new webhook.
+func (ctrl *Controller) Create(ctx *gin.Context) {
+	// Read input (must be JSON valid)
+	dto := new(inputDto)
+	if err := ctx.BindJSON(dto); err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	hook := toModel("", dto)
+
+	// Call service (business logic).
+	err := ctrl.service.Create(ctx.Request.Context(), hook)
+
+	// Respond with either error either success.
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Writer.Header().Set("Location", ctx.Request.URL.Path+"/"+hook.ID)
+	ctx.JSON(http.StatusCreated, toWebhook(hook))
+}
+
+// ReadAll retrieves a page of the registered webhooks.
+func (ctrl *Controller) ReadAll(ctx *gin.Context) {
+	page, err := server.ParsePage(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	hooks, info, err := ctrl.service.ReadAll(ctx.Request.Context(), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	dtos := make([]*WebhookDto, len(hooks))
+	for i, hook := range hooks {
+		dtos[i] = toWebhook(hook)
+	}
+
+	ctx.JSON(http.StatusOK, &readAllResponseDto{
+		Webhooks:      dtos,
+		NextPageToken: model.EncodePageToken(info.Next),
+		Total:         info.Total,
+	})
+}
+
+// Read reads a single webhook based on the provided identifier.
+func (ctrl *Controller) Read(ctx *gin.Context) {
+	hook, err := ctrl.service.FindByID(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toWebhook(hook))
+}
+
+// Update all fields of a single webhook. The secret is kept if none is given.
+func (ctrl *Controller) Update(ctx *gin.Context) {
+	dto := new(inputDto)
+	if err := ctx.BindJSON(dto); err != nil {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	hook := toModel(ctx.Param("id"), dto)
+	if err := ctrl.service.Update(ctx.Request.Context(), hook); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, toWebhook(hook))
+}
+
+// ClearSecret removes the secret of a single webhook, whose deliveries are no
+// longer signed. Updating a webhook without a secret keeps its secret instead.
+func (ctrl *Controller) ClearSecret(ctx *gin.Context) {
+	if err := ctrl.service.ClearSecret(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Delete a single webhook, along with its dead letters.
+func (ctrl *Controller) Delete(ctx *gin.Context) {
+	if err := ctrl.service.Delete(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReadDeadLetters retrieves a page of the deliveries of a single webhook that
+// failed all their attempts.
+func (ctrl *Controller) ReadDeadLetters(ctx *gin.Context) {
+	page, err := server.ParsePage(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	letters, info, err := ctrl.service.ReadDeadLetters(ctx.Request.Context(), ctx.Param("id"), page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	dtos := make([]*DeadLetterDto, len(letters))
+	for i, letter := range letters {
+		dtos[i] = toDeadLetter(letter)
+	}
+
+	ctx.JSON(http.StatusOK, &readDeadLettersResponseDto{
+		DeadLetters:   dtos,
+		NextPageToken: model.EncodePageToken(info.Next),
+		Total:         info.Total,
+	})
+}
+
+// Replay delivers once more a single dead letter. The dead letter is deleted
+// if the delivery succeeds; otherwise the response is a 502 holding the reason
+// of the failure.
+func (ctrl *Controller) Replay(ctx *gin.Context) {
+	if err := ctrl.service.Replay(ctx.Request.Context(), ctx.Param("id"), ctx.Param("letter")); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// DeleteDeadLetter discards a single dead letter.
+func (ctrl *Controller) DeleteDeadLetter(ctx *gin.Context) {
+	if err := ctrl.service.DeleteDeadLetter(ctx.Request.Context(), ctx.Param("id"), ctx.Param("letter")); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func toModel(id string, dto *inputDto) *model.Webhook {
+	return &model.Webhook{
+		ID:     id,
+		URL:    dto.URL,
+		Events: dto.Events,
+		Set:    dto.Set,
+		Secret: dto.Secret,
+	}
+}
+
+func toWebhook(hook *model.Webhook) *WebhookDto {
+	return &WebhookDto{
+		ID:     hook.ID,
+		URL:    hook.URL,
+		Events: hook.Events,
+		Set:    hook.Set,
+		Signed: hook.Secret != "",
+	}
+}
+
+func toDeadLetter(letter *model.DeadLetter) *DeadLetterDto {
+	return &DeadLetterDto{
+		ID:       letter.ID,
+		Event:    letter.Event,
+		Payload:  letter.Payload,
+		Attempts: letter.Attempts,
+		Error:    letter.Error,
+		FailedAt: letter.FailedAt,
+	}
+}
+
+// Register this controller to the provided group. The routes are available for
+// the default namespace as well as for any explicit namespace.
+func (ctrl *Controller) Register(routerGroup *gin.RouterGroup) {
+	for _, api := range server.NamespacedGroups(routerGroup, "/webhook") {
+		api.POST("", ctrl.Create)
+		api.GET("", ctrl.ReadAll)
+		api.GET("/:id", ctrl.Read)
+		api.PUT("/:id", ctrl.Update)
+		api.DELETE("/:id", ctrl.Delete)
+		api.DELETE("/:id/secret", ctrl.ClearSecret)
+		api.GET("/:id/deadletter", ctrl.ReadDeadLetters)
+		api.POST("/:id/deadletter/:letter/replay", ctrl.Replay)
+		api.DELETE("/:id/deadletter/:letter", ctrl.DeleteDeadLetter)
+	}
+}
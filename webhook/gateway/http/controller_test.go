@@ -0,0 +1,257 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreate(t *testing.T) {
+	router, service := setup()
+
+	hook := &model.Webhook{URL: "http://localhost/hook", Events: []string{"property.created"}, Secret: "s3cr3t"}
+	service.On("Create", hook).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Webhook).ID = "123"
+	})
+
+	body := []byte(`{"url":"http://localhost/hook","events":["property.created"],"secret":"s3cr3t"}`)
+	w := perform("POST", "/api/webhook", body, router)
+
+	assert.Equal(t, 201, w.Code)
+	assert.Equal(t, "/api/webhook/123", w.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":"123","url":"http://localhost/hook","events":["property.created"],"signed":true}`, w.Body.String())
+}
+
+func TestCreateInvalid(t *testing.T) {
+	router, service := setup()
+
+	hook := &model.Webhook{URL: "localhost"}
+	service.On("Create", hook).Return(apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), "'url' has invalid value 'localhost'."))
+
+	w := perform("POST", "/api/webhook", []byte(`{"url":"localhost"}`), router)
+	assert.Equal(t, 400, w.Code)
+
+	w = perform("POST", "/api/webhook", []byte(`{"url":`), router)
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestReadAll(t *testing.T) {
+	router, service := setup()
+
+	hooks := []*model.Webhook{{ID: "1", URL: "http://localhost/a"}, {ID: "2", URL: "http://localhost/b", Set: "common"}}
+	service.On("ReadAll", model.Page{Limit: 2}).Return(hooks, model.PageInfo{Total: 3, Next: "2"}, nil)
+
+	w := perform("GET", "/api/webhook?limit=2", nil, router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"webhooks":[{"id":"1","url":"http://localhost/a","signed":false},{"id":"2","url":"http://localhost/b","set":"common","signed":false}],"next_page_token":"Mg","total":3}`, w.Body.String())
+}
+
+func TestRead(t *testing.T) {
+	router, service := setup()
+
+	service.On("FindByID", "123").Return(&model.Webhook{ID: "123", URL: "http://localhost/hook"}, nil)
+	service.On("FindByID", "missing").Return(nil, apperrors.NewEntityNotFound(model.Webhook{}, "missing"))
+
+	w := perform("GET", "/api/webhook/123", nil, router)
+	assert.Equal(t, 200, w.Code)
+
+	w = perform("GET", "/api/webhook/missing", nil, router)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestUpdate(t *testing.T) {
+	router, service := setup()
+
+	hook := &model.Webhook{ID: "123", URL: "http://localhost/other"}
+	service.On("Update", hook).Return(nil)
+
+	w := perform("PUT", "/api/webhook/123", []byte(`{"url":"http://localhost/other"}`), router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"id":"123","url":"http://localhost/other","signed":false}`, w.Body.String())
+}
+
+func TestClearSecret(t *testing.T) {
+	router, service := setup()
+
+	service.On("ClearSecret", "123").Return(nil)
+	service.On("ClearSecret", "missing").Return(apperrors.NewEntityNotFound(model.Webhook{}, "missing"))
+
+	w := perform("DELETE", "/api/webhook/123/secret", nil, router)
+	assert.Equal(t, 204, w.Code)
+
+	w = perform("DELETE", "/api/webhook/missing/secret", nil, router)
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestDelete(t *testing.T) {
+	router, service := setup()
+
+	service.On("Delete", "123").Return(nil)
+
+	w := perform("DELETE", "/api/webhook/123", nil, router)
+
+	assert.Equal(t, 204, w.Code)
+}
+
+func TestReadDeadLetters(t *testing.T) {
+	router, service := setup()
+
+	failedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	letters := []*model.DeadLetter{{ID: "1", WebhookID: "123", Event: "set.deleted", Payload: []byte(`{"event":"set.deleted"}`), Attempts: 5, Error: "unexpected status 500", FailedAt: failedAt}}
+	service.On("ReadDeadLetters", "123", model.Page{}).Return(letters, model.PageInfo{Total: 1}, nil)
+
+	w := perform("GET", "/api/webhook/123/deadletter", nil, router)
+
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"dead_letters":[{"id":"1","event":"set.deleted","payload":{"event":"set.deleted"},"attempts":5,"error":"unexpected status 500","failed_at":"2020-10-01T12:00:00Z"}],"total":1}`, w.Body.String())
+}
+
+func TestReplay(t *testing.T) {
+	router, service := setup()
+
+	service.On("Replay", "123", "1").Return(nil)
+	service.On("Replay", "123", "2").Return(apperrors.NewDeliveryFailed(model.DeadLetter{}, "2", "unexpected status 500"))
+
+	w := perform("POST", "/api/webhook/123/deadletter/1/replay", nil, router)
+	assert.Equal(t, 204, w.Code)
+
+	w = perform("POST", "/api/webhook/123/deadletter/2/replay", nil, router)
+	assert.Equal(t, 502, w.Code)
+}
+
+func TestDeleteDeadLetter(t *testing.T) {
+	router, service := setup()
+
+	service.On("DeleteDeadLetter", "123", "1").Return(nil)
+
+	w := perform("DELETE", "/api/webhook/123/deadletter/1", nil, router)
+
+	assert.Equal(t, 204, w.Code)
+}
+
+func setup() (r *gin.Engine, serviceMock *WebhookServiceMock) {
+	router := gin.Default()
+	router.Use(
+		jsonAppErrorHandler(),
+	)
+	api := router.Group("/api")
+
+	service := new(WebhookServiceMock)
+	controller := New(service).Controller
+	controller.Register(api)
+
+	return router, service
+}
+
+func perform(method string, uri string, body []byte, router *gin.Engine) (rr *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+
+	var content bytes.Buffer
+	if body != nil {
+		content = *bytes.NewBuffer(body)
+	}
+
+	req, _ := http.NewRequest(method, uri, &content)
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func jsonAppErrorHandler() gin.HandlerFunc {
+	return handle(gin.ErrorTypeAny)
+}
+
+func handle(errType gin.ErrorType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		detectedErrors := c.Errors
+
+		if len(detectedErrors) > 0 {
+			err := detectedErrors[0].Err
+
+			switch err.(type) {
+			case *apperrors.Error:
+				oError := err.(*apperrors.Error)
+				c.AbortWithError(oError.Code, oError)
+			default:
+				c.AbortWithError(http.StatusInternalServerError, err)
+			}
+
+			return
+		}
+	}
+}
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) Create(ctx context.Context, hook *model.Webhook) error {
+	args := m.Called(hook)
+
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ReadAll(ctx context.Context, page model.Page) ([]*model.Webhook, model.PageInfo, error) {
+	args := m.Called(page)
+
+	return args.Get(0).([]*model.Webhook), args.Get(1).(model.PageInfo), args.Error(2)
+}
+
+func (m *WebhookServiceMock) FindByID(ctx context.Context, id string) (*model.Webhook, error) {
+	args := m.Called(id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *WebhookServiceMock) Update(ctx context.Context, hook *model.Webhook) error {
+	args := m.Called(hook)
+
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ClearSecret(ctx context.Context, id string) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) Delete(ctx context.Context, id string) error {
+	args := m.Called(id)
+
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ReadDeadLetters(ctx context.Context, webhookID string, page model.Page) ([]*model.DeadLetter, model.PageInfo, error) {
+	args := m.Called(webhookID, page)
+
+	return args.Get(0).([]*model.DeadLetter), args.Get(1).(model.PageInfo), args.Error(2)
+}
+
+func (m *WebhookServiceMock) Replay(ctx context.Context, webhookID string, id string) error {
+	args := m.Called(webhookID, id)
+
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) DeleteDeadLetter(ctx context.Context, webhookID string, id string) error {
+	args := m.Called(webhookID, id)
+
+	return args.Error(0)
+}
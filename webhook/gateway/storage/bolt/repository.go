@@ -0,0 +1,363 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/transaction"
+	"github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage"
+)
+
+// WebhookRepository is a representation of the webhook repository for Bolt DBs.
+type WebhookRepository struct {
+	db     *storm.DB
+	cipher *encryption.Cipher
+}
+
+type webhookDto struct {
+	ID        string `storm:"id"`
+	Namespace string
+	URL       string
+	Events    []string
+	Set       string
+	Secret    string
+	Sealed    bool
+}
+
+type deadLetterDto struct {
+	ID        string `storm:"id"`
+	Namespace string
+	WebhookID string `storm:"index"`
+	Event     string
+	Payload   []byte
+	Attempts  int
+	Error     string
+	FailedAt  time.Time
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the secrets of the webhooks; the secrets stored in plain text
+// are encrypted right away, if a cipher is given.
+func New(db *storm.DB, cipher *encryption.Cipher) storage.Repository {
+	repo := &WebhookRepository{
+		db:     db,
+		cipher: cipher,
+	}
+	db.Init(&webhookDto{})
+	db.Init(&deadLetterDto{})
+
+	if cipher != nil {
+		repo.sealSecrets()
+	}
+
+	return repo
+}
+
+// sealSecrets encrypts the secrets stored before they were sealed.
+func (repository WebhookRepository) sealSecrets() {
+	var dtos []webhookDto
+	err := repository.db.Select(q.Eq("Sealed", false), q.Not(q.Eq("Secret", ""))).Find(&dtos)
+
+	if err != nil && err != storm.ErrNotFound {
+		logger.Main.Error("Cannot read the webhook secrets to be encrypted.", err)
+		return
+	}
+
+	for i := range dtos {
+		dto := &dtos[i]
+		secret, err := storage.SealSecret(repository.cipher, dto.Secret)
+		if err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot encrypt the secret of the webhook '%s'.", dto.ID), err)
+			continue
+		}
+
+		dto.Secret = secret
+		dto.Sealed = true
+		if err := repository.db.Save(dto); err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot encrypt the secret of the webhook '%s'.", dto.ID), err)
+		}
+	}
+}
+
+// Create a new entry based on the provided webhook. The webhook is given a
+// brand new id.
+func (repository WebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	dto, err := repository.convertToDto(webhook)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = uuid.New().String()
+	dto.ID = webhook.ID
+
+	return repository.node(ctx).Save(dto)
+}
+
+// ReadAll retrieves the given page of the webhooks within the given namespace,
+// sorted by id.
+func (repository WebhookRepository) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.Webhook, model.PageInfo, error) {
+	var dtos []webhookDto
+	err := repository.node(ctx).Select(q.Eq("Namespace", namespace)).Find(&dtos)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, model.PageInfo{}, err
+	}
+
+	sort.Slice(dtos, func(i, j int) bool {
+		return page.Less(dtos[i].ID, dtos[j].ID)
+	})
+
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+
+	from, to, info := page.Window(ids)
+
+	result := make([]*model.Webhook, 0, to-from)
+	for i := from; i < to; i++ {
+		webhook, err := repository.convertToModel(&dtos[i])
+		if err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		result = append(result, webhook)
+	}
+
+	return result, info, nil
+}
+
+// FindByID retrieves the webhook matching the given id within the given
+// namespace if such a webhook exists; otherwise will return a not found error.
+func (repository WebhookRepository) FindByID(ctx context.Context, namespace string, id string) (*model.Webhook, error) {
+	dto, err := repository.find(repository.node(ctx), namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertToModel(dto)
+}
+
+// Update all fields of the given webhook.
+func (repository WebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := repository.find(tx, webhook.Namespace, webhook.ID); err != nil {
+		return err
+	}
+
+	dto, err := repository.convertToDto(webhook)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Save(dto); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete the webhook with the given id within the given namespace, along with
+// its dead letters.
+func (repository WebhookRepository) Delete(ctx context.Context, namespace string, id string) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dto, err := repository.find(tx, namespace, id)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.DeleteStruct(dto); err != nil {
+		return err
+	}
+
+	err = tx.Select(q.Eq("WebhookID", id)).Delete(new(deadLetterDto))
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateDeadLetter adds the given dead letter. The dead letter is given a brand
+// new id.
+func (repository WebhookRepository) CreateDeadLetter(ctx context.Context, letter *model.DeadLetter) error {
+	letter.ID = uuid.New().String()
+
+	return repository.node(ctx).Save(convertDeadLetterToDto(letter))
+}
+
+// ReadDeadLetters retrieves the given page of the dead letters of the webhook
+// with the given id within the given namespace, sorted by id.
+func (repository WebhookRepository) ReadDeadLetters(ctx context.Context, namespace string, webhookID string, page model.Page) ([]*model.DeadLetter, model.PageInfo, error) {
+	var dtos []deadLetterDto
+	err := repository.node(ctx).Select(q.Eq("Namespace", namespace), q.Eq("WebhookID", webhookID)).Find(&dtos)
+
+	if err != nil && err != storm.ErrNotFound {
+		return nil, model.PageInfo{}, err
+	}
+
+	sort.Slice(dtos, func(i, j int) bool {
+		return page.Less(dtos[i].ID, dtos[j].ID)
+	})
+
+	ids := make([]string, len(dtos))
+	for i, dto := range dtos {
+		ids[i] = dto.ID
+	}
+
+	from, to, info := page.Window(ids)
+
+	result := make([]*model.DeadLetter, 0, to-from)
+	for i := from; i < to; i++ {
+		result = append(result, convertDeadLetterToModel(&dtos[i]))
+	}
+
+	return result, info, nil
+}
+
+// FindDeadLetter retrieves the dead letter matching the given id within the
+// given namespace if such a dead letter exists; otherwise will return a not
+// found error.
+func (repository WebhookRepository) FindDeadLetter(ctx context.Context, namespace string, id string) (*model.DeadLetter, error) {
+	dto, err := repository.findDeadLetter(repository.node(ctx), namespace, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertDeadLetterToModel(dto), nil
+}
+
+// DeleteDeadLetter deletes the dead letter with the given id within the given
+// namespace.
+func (repository WebhookRepository) DeleteDeadLetter(ctx context.Context, namespace string, id string) error {
+	tx, err := transaction.BoltBegin(ctx, repository.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dto, err := repository.findDeadLetter(tx, namespace, id)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.DeleteStruct(dto); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repository WebhookRepository) find(node storm.Node, namespace string, id string) (*webhookDto, error) {
+	var dto webhookDto
+	err := node.One("ID", id, &dto)
+
+	if err == storm.ErrNotFound || (err == nil && dto.Namespace != namespace) {
+		return nil, errors.NewEntityNotFound(model.Webhook{}, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+func (repository WebhookRepository) findDeadLetter(node storm.Node, namespace string, id string) (*deadLetterDto, error) {
+	var dto deadLetterDto
+	err := node.One("ID", id, &dto)
+
+	if err == storm.ErrNotFound || (err == nil && dto.Namespace != namespace) {
+		return nil, errors.NewEntityNotFound(model.DeadLetter{}, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto, nil
+}
+
+func (repository WebhookRepository) convertToDto(webhook *model.Webhook) (*webhookDto, error) {
+	secret, err := storage.SealSecret(repository.cipher, webhook.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookDto{
+		ID:        webhook.ID,
+		Namespace: webhook.Namespace,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Set:       webhook.Set,
+		Secret:    secret,
+		Sealed:    secret != "",
+	}, nil
+}
+
+func (repository WebhookRepository) convertToModel(dto *webhookDto) (*model.Webhook, error) {
+	secret, err := storage.OpenSecret(repository.cipher, dto.Secret, dto.Sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Webhook{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		URL:       dto.URL,
+		Events:    dto.Events,
+		Set:       dto.Set,
+		Secret:    secret,
+	}, nil
+}
+
+func convertDeadLetterToDto(letter *model.DeadLetter) *deadLetterDto {
+	return &deadLetterDto{
+		ID:        letter.ID,
+		Namespace: letter.Namespace,
+		WebhookID: letter.WebhookID,
+		Event:     letter.Event,
+		Payload:   letter.Payload,
+		Attempts:  letter.Attempts,
+		Error:     letter.Error,
+		FailedAt:  letter.FailedAt,
+	}
+}
+
+func convertDeadLetterToModel(dto *deadLetterDto) *model.DeadLetter {
+	return &model.DeadLetter{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		WebhookID: dto.WebhookID,
+		Event:     dto.Event,
+		Payload:   dto.Payload,
+		Attempts:  dto.Attempts,
+		Error:     dto.Error,
+		FailedAt:  dto.FailedAt,
+	}
+}
+
+// node retrieves the node through which the db must be accessed within the
+// given context, i.e. the transaction carried by the context, if any.
+func (repository WebhookRepository) node(ctx context.Context) storm.Node {
+	return transaction.BoltNode(ctx, repository.db)
+}
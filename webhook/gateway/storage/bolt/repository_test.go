@@ -0,0 +1,194 @@
+package bolt
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+var defaultDir = "../../../../tests/local-repo"
+var defaultDB = "../../../../tests/local-repo/webhooksdb"
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+func TestCreate(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	webhook := &model.Webhook{Namespace: "payments", URL: "http://localhost/hook", Events: []string{"property.created"}, Set: "common", Secret: "s3cr3t"}
+
+	err := repo.Create(context.Background(), webhook)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, "", webhook.ID)
+
+	found, err := repo.FindByID(context.Background(), "payments", webhook.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, webhook, found)
+}
+
+func TestCreateSecret(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	webhook := &model.Webhook{Namespace: "payments", URL: "http://localhost/hook", Secret: "s3cr3t"}
+	repo.Create(context.Background(), webhook)
+
+	var stored webhookDto
+	repo.db.One("ID", webhook.ID, &stored)
+	assert.NotEqual(t, "s3cr3t", stored.Secret)
+	assert.Equal(t, true, stored.Sealed)
+
+	withoutKey := New(repo.db, nil)
+	err := withoutKey.Create(context.Background(), &model.Webhook{Namespace: "payments", URL: "http://localhost/hook", Secret: "s3cr3t"})
+	assert.Equal(t, errors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), "'secret' requires an encryption key to be configured."), err)
+}
+
+func TestSealSecrets(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	repo.db.Save(&webhookDto{ID: "legacy", Namespace: "payments", URL: "http://localhost/hook", Secret: "s3cr3t"})
+	repo.db.Save(&webhookDto{ID: "unsigned", Namespace: "payments", URL: "http://localhost/hook"})
+
+	cipher, _ := encryption.New(key)
+	migrated := New(repo.db, cipher)
+
+	var stored webhookDto
+	repo.db.One("ID", "legacy", &stored)
+	assert.NotEqual(t, "s3cr3t", stored.Secret)
+	assert.Equal(t, true, stored.Sealed)
+
+	found, err := migrated.FindByID(context.Background(), "payments", "legacy")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "s3cr3t", found.Secret)
+
+	found, _ = migrated.FindByID(context.Background(), "payments", "unsigned")
+	assert.Equal(t, "", found.Secret)
+}
+
+func TestFindByIDOtherNamespace(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	webhook := &model.Webhook{Namespace: "payments", URL: "http://localhost/hook"}
+	repo.Create(context.Background(), webhook)
+
+	_, err := repo.FindByID(context.Background(), "default", webhook.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.Webhook{}, webhook.ID), err)
+}
+
+func TestReadAll(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	for i := 0; i < 3; i++ {
+		repo.Create(context.Background(), &model.Webhook{Namespace: "payments", URL: "http://localhost/hook"})
+	}
+	repo.Create(context.Background(), &model.Webhook{Namespace: "default", URL: "http://localhost/hook"})
+
+	page, info, err := repo.ReadAll(context.Background(), "payments", model.Page{Limit: 2})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(page))
+	assert.Equal(t, 3, info.Total)
+	assert.Equal(t, page[1].ID, info.Next)
+	assert.Equal(t, true, page[0].ID < page[1].ID)
+
+	rest, info, err := repo.ReadAll(context.Background(), "payments", model.Page{After: info.Next})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(rest))
+	assert.Equal(t, "", info.Next)
+}
+
+func TestUpdate(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	webhook := &model.Webhook{Namespace: "payments", URL: "http://localhost/hook"}
+	repo.Create(context.Background(), webhook)
+
+	webhook.URL = "http://localhost/other"
+	err := repo.Update(context.Background(), webhook)
+	assert.Equal(t, nil, err)
+
+	found, _ := repo.FindByID(context.Background(), "payments", webhook.ID)
+	assert.Equal(t, "http://localhost/other", found.URL)
+
+	err = repo.Update(context.Background(), &model.Webhook{ID: "missing", Namespace: "payments"})
+	assert.Equal(t, errors.NewEntityNotFound(model.Webhook{}, "missing"), err)
+}
+
+func TestDeadLetters(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	webhook := &model.Webhook{Namespace: "payments", URL: "http://localhost/hook"}
+	repo.Create(context.Background(), webhook)
+
+	failedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	letter := &model.DeadLetter{Namespace: "payments", WebhookID: webhook.ID, Event: "property.created", Payload: []byte(`{}`), Attempts: 5, Error: "Unexpected status 500", FailedAt: failedAt}
+	other := &model.DeadLetter{Namespace: "payments", WebhookID: "other", Event: "property.created", Payload: []byte(`{}`), FailedAt: failedAt}
+
+	assert.Equal(t, nil, repo.CreateDeadLetter(context.Background(), letter))
+	assert.Equal(t, nil, repo.CreateDeadLetter(context.Background(), other))
+
+	letters, info, err := repo.ReadDeadLetters(context.Background(), "payments", webhook.ID, model.Page{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, info.Total)
+	assert.Equal(t, []*model.DeadLetter{letter}, letters)
+
+	found, err := repo.FindDeadLetter(context.Background(), "payments", letter.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, letter, found)
+
+	assert.Equal(t, nil, repo.DeleteDeadLetter(context.Background(), "payments", letter.ID))
+
+	_, err = repo.FindDeadLetter(context.Background(), "payments", letter.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.DeadLetter{}, letter.ID), err)
+}
+
+func TestDelete(t *testing.T) {
+	repo := setup()
+	defer tearDown(repo)
+
+	webhook := &model.Webhook{Namespace: "payments", URL: "http://localhost/hook"}
+	repo.Create(context.Background(), webhook)
+
+	letter := &model.DeadLetter{Namespace: "payments", WebhookID: webhook.ID}
+	repo.CreateDeadLetter(context.Background(), letter)
+
+	err := repo.Delete(context.Background(), "payments", webhook.ID)
+	assert.Equal(t, nil, err)
+
+	_, err = repo.FindByID(context.Background(), "payments", webhook.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.Webhook{}, webhook.ID), err)
+
+	_, err = repo.FindDeadLetter(context.Background(), "payments", letter.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.DeadLetter{}, letter.ID), err)
+
+	err = repo.Delete(context.Background(), "payments", webhook.ID)
+	assert.Equal(t, errors.NewEntityNotFound(model.Webhook{}, webhook.ID), err)
+}
+
+func setup() *WebhookRepository {
+	util.CreateParentFolder(defaultDB)
+
+	db, _ := storm.Open(defaultDB)
+	cipher, _ := encryption.New(key)
+
+	return New(db, cipher).(*WebhookRepository)
+}
+
+func tearDown(repo *WebhookRepository) {
+	repo.db.Close()
+
+	os.Remove(defaultDB)
+	os.Remove(defaultDir)
+}
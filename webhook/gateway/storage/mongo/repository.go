@@ -0,0 +1,394 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	webhookCollection    = "webhook_collection"
+	deadLetterCollection = "webhook_dead_letter_collection"
+)
+
+type webhookDto struct {
+	ID        string   `bson:"_id"`
+	Namespace string   `bson:"namespace"`
+	URL       string   `bson:"url"`
+	Events    []string `bson:"events,omitempty"`
+	Set       string   `bson:"set,omitempty"`
+	Secret    string   `bson:"secret,omitempty"`
+	Sealed    bool     `bson:"sealed,omitempty"`
+}
+
+type deadLetterDto struct {
+	ID        string    `bson:"_id"`
+	Namespace string    `bson:"namespace"`
+	WebhookID string    `bson:"webhook_id"`
+	Event     string    `bson:"event"`
+	Payload   []byte    `bson:"payload"`
+	Attempts  int       `bson:"attempts"`
+	Error     string    `bson:"error"`
+	FailedAt  time.Time `bson:"failed_at"`
+}
+
+// WebhookRepository is a representation of the webhook repository for a mongo
+// DBs.
+type WebhookRepository struct {
+	dbCollection           *mongo.Collection
+	deadLetterDbCollection *mongo.Collection
+	cipher                 *encryption.Cipher
+}
+
+// New retrieves a new repository object ready to be used. The given cipher is
+// used to encrypt the secrets of the webhooks; the secrets stored in plain text
+// are encrypted right away, if a cipher is given.
+func New(db *mongo.Database, cipher *encryption.Cipher) storage.Repository {
+	repo := &WebhookRepository{
+		dbCollection:           db.Collection(webhookCollection),
+		deadLetterDbCollection: db.Collection(deadLetterCollection),
+		cipher:                 cipher,
+	}
+
+	if cipher != nil {
+		repo.sealSecrets()
+	}
+
+	return repo
+}
+
+// sealSecrets encrypts the secrets stored before they were sealed.
+func (repository WebhookRepository) sealSecrets() {
+	ctx := context.Background()
+	cursor, err := repository.dbCollection.Find(ctx, bson.M{"sealed": bson.M{"$ne": true}, "secret": bson.M{"$nin": bson.A{nil, ""}}})
+	if err != nil {
+		logger.Main.Error("Cannot read the webhook secrets to be encrypted.", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		dto := new(webhookDto)
+		if err := cursor.Decode(dto); err != nil {
+			logger.Main.Error("Cannot read the webhook secrets to be encrypted.", err)
+			return
+		}
+
+		secret, err := storage.SealSecret(repository.cipher, dto.Secret)
+		if err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot encrypt the secret of the webhook '%s'.", dto.ID), err)
+			continue
+		}
+
+		_, err = repository.dbCollection.UpdateOne(ctx,
+			bson.M{"_id": dto.ID, "secret": dto.Secret},
+			bson.M{"$set": bson.M{"secret": secret, "sealed": true}})
+		if err != nil {
+			logger.Main.Error(fmt.Sprintf("Cannot encrypt the secret of the webhook '%s'.", dto.ID), err)
+		}
+	}
+}
+
+// Create a new entry based on the provided webhook. The webhook is given a
+// brand new id.
+func (repository WebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	dto, err := repository.convertToDto(webhook)
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = uuid.New().String()
+	dto.ID = webhook.ID
+
+	_, err = repository.dbCollection.InsertOne(ctx, dto)
+
+	return err
+}
+
+// ReadAll retrieves the given page of the webhooks within the given namespace,
+// sorted by id.
+func (repository WebhookRepository) ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.Webhook, model.PageInfo, error) {
+	var dtos []*webhookDto
+	info, err := readPage(ctx, repository.dbCollection, bson.M{"namespace": namespace}, page, func(cursor *mongo.Cursor) (string, error) {
+		dto := new(webhookDto)
+		if err := cursor.Decode(dto); err != nil {
+			return "", err
+		}
+
+		dtos = append(dtos, dto)
+
+		return dto.ID, nil
+	})
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	result := make([]*model.Webhook, 0, len(dtos))
+	for _, dto := range dtos {
+		webhook, err := repository.convertToModel(dto)
+		if err != nil {
+			return nil, model.PageInfo{}, err
+		}
+
+		result = append(result, webhook)
+	}
+
+	return result[:pageSize(len(result), page)], info, nil
+}
+
+// FindByID retrieves the webhook matching the given id within the given
+// namespace if such a webhook exists; otherwise will return a not found error.
+func (repository WebhookRepository) FindByID(ctx context.Context, namespace string, id string) (*model.Webhook, error) {
+	dto := new(webhookDto)
+	err := repository.dbCollection.FindOne(ctx, bson.M{"_id": id, "namespace": namespace}).Decode(dto)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.Webhook{}, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.convertToModel(dto)
+}
+
+// Update all fields of the given webhook.
+func (repository WebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	dto, err := repository.convertToDto(webhook)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": webhook.ID, "namespace": webhook.Namespace}
+
+	result, err := repository.dbCollection.ReplaceOne(ctx, filter, dto)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.NewEntityNotFound(model.Webhook{}, webhook.ID)
+	}
+
+	return nil
+}
+
+// Delete the webhook with the given id within the given namespace, along with
+// its dead letters.
+func (repository WebhookRepository) Delete(ctx context.Context, namespace string, id string) error {
+	result, err := repository.dbCollection.DeleteOne(ctx, bson.M{"_id": id, "namespace": namespace})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.NewEntityNotFound(model.Webhook{}, id)
+	}
+
+	_, err = repository.deadLetterDbCollection.DeleteMany(ctx, bson.M{"webhook_id": id})
+
+	return err
+}
+
+// CreateDeadLetter adds the given dead letter. The dead letter is given a brand
+// new id.
+func (repository WebhookRepository) CreateDeadLetter(ctx context.Context, letter *model.DeadLetter) error {
+	letter.ID = uuid.New().String()
+
+	_, err := repository.deadLetterDbCollection.InsertOne(ctx, convertDeadLetterToDto(letter))
+
+	return err
+}
+
+// ReadDeadLetters retrieves the given page of the dead letters of the webhook
+// with the given id within the given namespace, sorted by id.
+func (repository WebhookRepository) ReadDeadLetters(ctx context.Context, namespace string, webhookID string, page model.Page) ([]*model.DeadLetter, model.PageInfo, error) {
+	var dtos []*deadLetterDto
+	filter := bson.M{"namespace": namespace, "webhook_id": webhookID}
+	info, err := readPage(ctx, repository.deadLetterDbCollection, filter, page, func(cursor *mongo.Cursor) (string, error) {
+		dto := new(deadLetterDto)
+		if err := cursor.Decode(dto); err != nil {
+			return "", err
+		}
+
+		dtos = append(dtos, dto)
+
+		return dto.ID, nil
+	})
+	if err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	result := make([]*model.DeadLetter, 0, len(dtos))
+	for _, dto := range dtos {
+		result = append(result, convertDeadLetterToModel(dto))
+	}
+
+	return result[:pageSize(len(result), page)], info, nil
+}
+
+// FindDeadLetter retrieves the dead letter matching the given id within the
+// given namespace if such a dead letter exists; otherwise will return a not
+// found error.
+func (repository WebhookRepository) FindDeadLetter(ctx context.Context, namespace string, id string) (*model.DeadLetter, error) {
+	dto := new(deadLetterDto)
+	err := repository.deadLetterDbCollection.FindOne(ctx, bson.M{"_id": id, "namespace": namespace}).Decode(dto)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.NewEntityNotFound(model.DeadLetter{}, id)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return convertDeadLetterToModel(dto), nil
+}
+
+// DeleteDeadLetter deletes the dead letter with the given id within the given
+// namespace.
+func (repository WebhookRepository) DeleteDeadLetter(ctx context.Context, namespace string, id string) error {
+	result, err := repository.deadLetterDbCollection.DeleteOne(ctx, bson.M{"_id": id, "namespace": namespace})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.NewEntityNotFound(model.DeadLetter{}, id)
+	}
+
+	return nil
+}
+
+// readPage decodes, by means of the given function, the documents of the given
+// page among the documents matching the given filter, sorted by id. One more
+// document than the page limit is decoded, if available, to find out whether
+// there is a next page.
+func readPage(ctx context.Context, collection *mongo.Collection, filter bson.M, page model.Page, decode func(*mongo.Cursor) (string, error)) (model.PageInfo, error) {
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return model.PageInfo{}, err
+	}
+
+	order := 1
+	operator := "$gt"
+	if page.Descending {
+		order = -1
+		operator = "$lt"
+	}
+
+	if page.After != "" {
+		filter["_id"] = bson.M{operator: page.After}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: order}})
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit + 1))
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return model.PageInfo{}, err
+	}
+	defer cursor.Close(ctx)
+
+	info := model.PageInfo{Total: int(total)}
+	count := 0
+	for cursor.Next(ctx) {
+		id, err := decode(cursor)
+		if err != nil {
+			return model.PageInfo{}, err
+		}
+
+		count++
+		if count == page.Limit {
+			info.Next = id
+		}
+	}
+
+	if count <= page.Limit {
+		info.Next = ""
+	}
+
+	return info, nil
+}
+
+// pageSize retrieves the number of the decoded documents that belong to the
+// given page.
+func pageSize(decoded int, page model.Page) int {
+	if page.Limit > 0 && decoded > page.Limit {
+		return page.Limit
+	}
+
+	return decoded
+}
+
+func (repository WebhookRepository) convertToDto(webhook *model.Webhook) (*webhookDto, error) {
+	secret, err := storage.SealSecret(repository.cipher, webhook.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookDto{
+		ID:        webhook.ID,
+		Namespace: webhook.Namespace,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Set:       webhook.Set,
+		Secret:    secret,
+		Sealed:    secret != "",
+	}, nil
+}
+
+func (repository WebhookRepository) convertToModel(dto *webhookDto) (*model.Webhook, error) {
+	secret, err := storage.OpenSecret(repository.cipher, dto.Secret, dto.Sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Webhook{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		URL:       dto.URL,
+		Events:    dto.Events,
+		Set:       dto.Set,
+		Secret:    secret,
+	}, nil
+}
+
+func convertDeadLetterToDto(letter *model.DeadLetter) *deadLetterDto {
+	return &deadLetterDto{
+		ID:        letter.ID,
+		Namespace: letter.Namespace,
+		WebhookID: letter.WebhookID,
+		Event:     letter.Event,
+		Payload:   letter.Payload,
+		Attempts:  letter.Attempts,
+		Error:     letter.Error,
+		FailedAt:  letter.FailedAt,
+	}
+}
+
+func convertDeadLetterToModel(dto *deadLetterDto) *model.DeadLetter {
+	return &model.DeadLetter{
+		ID:        dto.ID,
+		Namespace: dto.Namespace,
+		WebhookID: dto.WebhookID,
+		Event:     dto.Event,
+		Payload:   dto.Payload,
+		Attempts:  dto.Attempts,
+		Error:     dto.Error,
+		FailedAt:  dto.FailedAt,
+	}
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Repository interface defining the functionality of a basic implementations.
+//
+// Webhooks and dead letters are identified by generated ids and belong to the
+// namespace they were created in. Deleting a webhook deletes its dead letters.
+type Repository interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+
+	ReadAll(ctx context.Context, namespace string, page model.Page) ([]*model.Webhook, model.PageInfo, error)
+
+	FindByID(ctx context.Context, namespace string, id string) (*model.Webhook, error)
+
+	Update(ctx context.Context, webhook *model.Webhook) error
+
+	Delete(ctx context.Context, namespace string, id string) error
+
+	CreateDeadLetter(ctx context.Context, letter *model.DeadLetter) error
+
+	ReadDeadLetters(ctx context.Context, namespace string, webhookID string, page model.Page) ([]*model.DeadLetter, model.PageInfo, error)
+
+	FindDeadLetter(ctx context.Context, namespace string, id string) (*model.DeadLetter, error)
+
+	DeleteDeadLetter(ctx context.Context, namespace string, id string) error
+}
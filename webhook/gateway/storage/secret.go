@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"reflect"
+
+	"github.com/rghiorghisor/basic-go-rest-api/encryption"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// SealSecret encrypts the given webhook secret, if any, so that it can be
+// stored.
+func SealSecret(cipher *encryption.Cipher, secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+
+	sealed, err := cipher.Encrypt(secret)
+	if err == encryption.ErrNoKey {
+		return "", errors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), "'secret' requires an encryption key to be configured.")
+	}
+
+	return sealed, err
+}
+
+// OpenSecret reverses SealSecret, retrieving the actual secret of a stored
+// webhook. The secrets stored before they were sealed are retrieved as they are.
+func OpenSecret(cipher *encryption.Cipher, secret string, sealed bool) (string, error) {
+	if !sealed || secret == "" {
+		return secret, nil
+	}
+
+	return cipher.Decrypt(secret)
+}
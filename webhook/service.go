@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+)
+
+// Service defines the use case available for webhooks.
+type Service interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+
+	ReadAll(ctx context.Context, page model.Page) ([]*model.Webhook, model.PageInfo, error)
+
+	FindByID(ctx context.Context, id string) (*model.Webhook, error)
+
+	Update(ctx context.Context, webhook *model.Webhook) error
+
+	ClearSecret(ctx context.Context, id string) error
+
+	Delete(ctx context.Context, id string) error
+
+	ReadDeadLetters(ctx context.Context, webhookID string, page model.Page) ([]*model.DeadLetter, model.PageInfo, error)
+
+	Replay(ctx context.Context, webhookID string, id string) error
+
+	DeleteDeadLetter(ctx context.Context, webhookID string, id string) error
+}
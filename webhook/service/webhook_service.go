@@ -0,0 +1,462 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/logger"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/namespace"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	serverstorage "github.com/rghiorghisor/basic-go-rest-api/server/storage"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+	"github.com/rghiorghisor/basic-go-rest-api/webhook"
+	"github.com/rghiorghisor/basic-go-rest-api/webhook/gateway/storage"
+)
+
+// maxAttempts is the number of times a delivery is attempted before it is kept
+// as a dead letter.
+const maxAttempts = 5
+
+// initialBackoff is the time waited after the first failed attempt. The time is
+// doubled after each subsequent failed attempt.
+const initialBackoff = time.Second
+
+// deliveryTimeout bounds the time of a single delivery attempt.
+const deliveryTimeout = 10 * time.Second
+
+// deliveryWorkers is the number of deliveries performed at the same time.
+const deliveryWorkers = 8
+
+// queueSize bounds the number of deliveries waiting for a worker. The deliveries
+// that do not fit are kept as dead letters right away.
+const queueSize = 1024
+
+// WebhookService defines the service handling webhook operations, as well as
+// the delivery of the changes to the webhooks.
+type WebhookService struct {
+	repository storage.Repository
+	setService propertyset.Service
+	client     *http.Client
+	attempts   int
+	backoff    time.Duration
+	queue      chan pendingDelivery
+}
+
+// pendingDelivery is a payload waiting to be delivered to a webhook.
+type pendingDelivery struct {
+	hook    *model.Webhook
+	event   string
+	payload []byte
+}
+
+// New creates a WebhookService.
+//
+// As this service needs access to a repository to perform action, it is the
+// responsibility of the service to get the correct repo from the storage parameter.
+// The service delivers to the webhooks all changes published to the given hub,
+// for as long as the application runs, by means of a fixed number of workers.
+func New(storage *serverstorage.Storage, setService propertyset.Service, hub *watch.Hub) webhook.Service {
+	service := WebhookService{
+		repository: storage.WebhookRepository,
+		setService: setService,
+		client:     &http.Client{Timeout: deliveryTimeout},
+		attempts:   maxAttempts,
+		backoff:    initialBackoff,
+		queue:      make(chan pendingDelivery, queueSize),
+	}
+
+	for i := 0; i < deliveryWorkers; i++ {
+		go service.work()
+	}
+	go service.dispatch(hub, hub.Subscribe(nil))
+
+	return service
+}
+
+// Create validates a new webhook and adds it to the repository. The webhook is
+// placed in the namespace of the given context.
+func (service WebhookService) Create(ctx context.Context, hook *model.Webhook) error {
+	hook.Namespace = namespace.FromContext(ctx)
+	if err := validate(hook); err != nil {
+		return err
+	}
+
+	return service.repository.Create(ctx, hook)
+}
+
+// ReadAll retrieves the given page of the webhooks of the namespace of the given
+// context.
+func (service WebhookService) ReadAll(ctx context.Context, page model.Page) ([]*model.Webhook, model.PageInfo, error) {
+	return service.repository.ReadAll(ctx, namespace.FromContext(ctx), page)
+}
+
+// FindByID retrieves the webhook matching the given id if such a webhook exists
+// within the namespace of the given context; otherwise will return a not found
+// error.
+func (service WebhookService) FindByID(ctx context.Context, id string) (*model.Webhook, error) {
+	return service.repository.FindByID(ctx, namespace.FromContext(ctx), id)
+}
+
+// Update all fields of the given webhook. The webhook cannot be moved to
+// another namespace. As the secret is never retrieved, the stored secret is
+// kept unless the given webhook has a new one (see ClearSecret).
+func (service WebhookService) Update(ctx context.Context, hook *model.Webhook) error {
+	hook.Namespace = namespace.FromContext(ctx)
+	if err := validate(hook); err != nil {
+		return err
+	}
+
+	if hook.Secret == "" {
+		found, err := service.repository.FindByID(ctx, hook.Namespace, hook.ID)
+		if err != nil {
+			return err
+		}
+
+		hook.Secret = found.Secret
+	}
+
+	return service.repository.Update(ctx, hook)
+}
+
+// ClearSecret removes the secret of the webhook with the given id, so that its
+// deliveries are no longer signed.
+func (service WebhookService) ClearSecret(ctx context.Context, id string) error {
+	hook, err := service.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	hook.Secret = ""
+
+	return service.repository.Update(ctx, hook)
+}
+
+// Delete the webhook with the given id, along with its dead letters.
+func (service WebhookService) Delete(ctx context.Context, id string) error {
+	return service.repository.Delete(ctx, namespace.FromContext(ctx), id)
+}
+
+// ReadDeadLetters retrieves the given page of the dead letters of the webhook
+// with the given id.
+func (service WebhookService) ReadDeadLetters(ctx context.Context, webhookID string, page model.Page) ([]*model.DeadLetter, model.PageInfo, error) {
+	if _, err := service.FindByID(ctx, webhookID); err != nil {
+		return nil, model.PageInfo{}, err
+	}
+
+	return service.repository.ReadDeadLetters(ctx, namespace.FromContext(ctx), webhookID, page)
+}
+
+// Replay delivers once more the dead letter with the given id of the webhook
+// with the given id, to the current URL of the webhook. The delivery is
+// attempted only once, while the caller waits; the dead letter is deleted if
+// the delivery succeeds.
+func (service WebhookService) Replay(ctx context.Context, webhookID string, id string) error {
+	hook, letter, err := service.findDeadLetter(ctx, webhookID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := service.send(hook, letter.Event, letter.Payload); err != nil {
+		return errors.NewDeliveryFailed(reflect.TypeOf(model.DeadLetter{}), id, err.Error())
+	}
+
+	return service.repository.DeleteDeadLetter(ctx, hook.Namespace, id)
+}
+
+// DeleteDeadLetter discards the dead letter with the given id of the webhook
+// with the given id.
+func (service WebhookService) DeleteDeadLetter(ctx context.Context, webhookID string, id string) error {
+	hook, _, err := service.findDeadLetter(ctx, webhookID, id)
+	if err != nil {
+		return err
+	}
+
+	return service.repository.DeleteDeadLetter(ctx, hook.Namespace, id)
+}
+
+func (service WebhookService) findDeadLetter(ctx context.Context, webhookID string, id string) (*model.Webhook, *model.DeadLetter, error) {
+	hook, err := service.FindByID(ctx, webhookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	letter, err := service.repository.FindDeadLetter(ctx, hook.Namespace, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if letter.WebhookID != hook.ID {
+		return nil, nil, errors.NewEntityNotFound(model.DeadLetter{}, id)
+	}
+
+	return hook, letter, nil
+}
+
+func validate(hook *model.Webhook) error {
+	if hook.URL == "" {
+		return errors.NewInvalidEntityEmpty(reflect.TypeOf(model.Webhook{}), "url")
+	}
+
+	target, err := url.Parse(hook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), fmt.Sprintf("'url' has invalid value '%s'.", hook.URL))
+	}
+
+	for _, event := range hook.Events {
+		if !webhook.IsValidEventType(event) {
+			return errors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), fmt.Sprintf("'events' has unknown value '%s'.", event))
+		}
+	}
+
+	return nil
+}
+
+// dispatch delivers the events of the given subscription to the matching
+// webhooks. The subscription is taken before the dispatcher starts, so that no
+// change made meanwhile is missed. If the dispatcher falls behind, the events it
+// missed are lost and it starts over with a new subscription to the given hub.
+func (service WebhookService) dispatch(hub *watch.Hub, subscription *watch.Subscription) {
+	for {
+		for event := range subscription.Events() {
+			service.notify(event)
+		}
+
+		logger.Main.Warn("Webhook deliveries fell behind the changes. Some changes were not delivered.")
+		subscription = hub.Subscribe(nil)
+	}
+}
+
+// notify starts the delivery of the given event to all webhooks of its
+// namespace that accept it. All webhooks are sent the same payload.
+func (service WebhookService) notify(event watch.Event) {
+	ctx := namespace.NewContext(context.Background(), event.Namespace)
+
+	hooks, _, err := service.repository.ReadAll(ctx, event.Namespace, model.Page{})
+	if err != nil {
+		logger.Main.Error("Cannot read webhooks", err)
+		return
+	}
+
+	eventType := webhook.EventType(event)
+	sets := make(map[string]*model.Membership)
+
+	var payload []byte
+	for _, hook := range hooks {
+		if !hook.Accepts(eventType) || !service.matches(ctx, hook, event, sets) {
+			continue
+		}
+
+		if payload == nil {
+			if payload, err = json.Marshal(newPayload(eventType, event)); err != nil {
+				logger.Main.Error("Cannot create webhook payload", err)
+				return
+			}
+		}
+
+		service.enqueue(hook, eventType, payload)
+	}
+}
+
+// enqueue hands the given payload over to the workers, unless too many
+// deliveries are already waiting, in which case the payload is kept as a dead
+// letter, so that it can still be replayed.
+func (service WebhookService) enqueue(hook *model.Webhook, event string, payload []byte) {
+	select {
+	case service.queue <- pendingDelivery{hook: hook, event: event, payload: payload}:
+	default:
+		logger.Main.Warn(fmt.Sprintf("Cannot deliver '%s' to webhook (id='%s'): too many pending deliveries.", event, hook.ID))
+		service.keepDeadLetter(hook, event, payload, 0, "too many pending deliveries")
+	}
+}
+
+// work performs the queued deliveries, one at a time, for as long as the
+// application runs.
+func (service WebhookService) work() {
+	for pending := range service.queue {
+		service.deliver(pending.hook, pending.event, pending.payload)
+	}
+}
+
+// matches checks whether the given event concerns the set of the given webhook,
+// if any, including the properties the change removed from the set. The members of the sets are looked up once per event, by means of the
+// given cache.
+func (service WebhookService) matches(ctx context.Context, hook *model.Webhook, event watch.Event, sets map[string]*model.Membership) bool {
+	if hook.Set == "" {
+		return true
+	}
+
+	if event.Set != nil {
+		return event.Set.Name == hook.Set
+	}
+
+	if event.WasMember(hook.Set) {
+		return true
+	}
+
+	members, found := sets[hook.Set]
+	if !found {
+		values, err := service.setService.FindValuesByID(ctx, hook.Set)
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Main.Error("Cannot read the set of a webhook", err)
+		}
+
+		members = model.NewMembership(values)
+		sets[hook.Set] = members
+	}
+
+	return members.Contains(event.Property.Name)
+}
+
+// deliver sends the given payload to the given webhook, retrying with an
+// exponential backoff. The payload is kept as a dead letter if all attempts
+// fail.
+func (service WebhookService) deliver(hook *model.Webhook, event string, payload []byte) {
+	backoff := service.backoff
+
+	var err error
+	for attempt := 1; attempt <= service.attempts; attempt++ {
+		if err = service.send(hook, event, payload); err == nil {
+			return
+		}
+
+		if attempt < service.attempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	logger.Main.Warn(fmt.Sprintf("Cannot deliver '%s' to webhook (id='%s') after %d attempts: %s", event, hook.ID, service.attempts, err))
+	service.keepDeadLetter(hook, event, payload, service.attempts, err.Error())
+}
+
+// keepDeadLetter stores the given payload, which could not be delivered after
+// the given number of attempts, failing with the given error.
+func (service WebhookService) keepDeadLetter(hook *model.Webhook, event string, payload []byte, attempts int, failure string) {
+	letter := &model.DeadLetter{
+		Namespace: hook.Namespace,
+		WebhookID: hook.ID,
+		Event:     event,
+		Payload:   payload,
+		Attempts:  attempts,
+		Error:     failure,
+		FailedAt:  time.Now().UTC(),
+	}
+
+	if err := service.repository.CreateDeadLetter(context.Background(), letter); err != nil {
+		logger.Main.Error("Cannot store webhook dead letter", err)
+	}
+}
+
+// send performs a single delivery attempt, which succeeds only if the webhook
+// responds with a 2xx status.
+func (service WebhookService) send(hook *model.Webhook, event string, payload []byte) error {
+	request, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhook.EventHeader, event)
+	if hook.Secret != "" {
+		request.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, payload))
+	}
+
+	response, err := service.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// Drain the body, so that the connection can be reused.
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+type payloadDto struct {
+	ID        string       `json:"id"`
+	Event     string       `json:"event"`
+	Namespace string       `json:"namespace"`
+	Timestamp time.Time    `json:"timestamp"`
+	Property  *propertyDto `json:"property,omitempty"`
+	Set       *setDto      `json:"set,omitempty"`
+}
+
+type propertyDto struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Type        string            `json:"type,omitempty"`
+	Value       string            `json:"value"`
+	Secret      bool              `json:"secret,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Overrides   map[string]string `json:"overrides,omitempty"`
+	ExpiresAt   *time.Time        `json:"expires_at,omitempty"`
+	Revision    int               `json:"revision,omitempty"`
+}
+
+type setDto struct {
+	Name     string   `json:"name"`
+	Values   []string `json:"values,omitempty"`
+	Includes []string `json:"includes,omitempty"`
+	Version  int      `json:"version,omitempty"`
+}
+
+// newPayload retrieves the payload delivering the given event. Its id is unique
+// to the event, so that receivers can recognize replayed deliveries. The values
+// of secret properties are masked.
+func newPayload(eventType string, event watch.Event) *payloadDto {
+	payload := &payloadDto{
+		ID:        uuid.New().String(),
+		Event:     eventType,
+		Namespace: event.Namespace,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if prop := event.Property; prop != nil {
+		payload.Property = &propertyDto{
+			ID:          prop.ID,
+			Name:        prop.Name,
+			Description: prop.Description,
+			Type:        string(prop.Type),
+			Value:       prop.Value,
+			Secret:      prop.Secret,
+			Labels:      prop.Labels,
+			Overrides:   prop.Overrides,
+			Revision:    prop.Revision,
+		}
+
+		if !prop.ExpiresAt.IsZero() {
+			payload.Property.ExpiresAt = &prop.ExpiresAt
+		}
+
+		if prop.Secret {
+			payload.Property.Value = model.MaskedValue
+			payload.Property.Overrides = make(map[string]string, len(prop.Overrides))
+			for profile := range prop.Overrides {
+				payload.Property.Overrides[profile] = model.MaskedValue
+			}
+		}
+	}
+
+	if set := event.Set; set != nil {
+		payload.Set = &setDto{Name: set.Name, Values: set.Values, Includes: set.Includes, Version: set.Version}
+	}
+
+	return payload
+}
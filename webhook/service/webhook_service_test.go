@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rghiorghisor/basic-go-rest-api/config"
+	apperrors "github.com/rghiorghisor/basic-go-rest-api/errors"
+	"github.com/rghiorghisor/basic-go-rest-api/model"
+	"github.com/rghiorghisor/basic-go-rest-api/property"
+	"github.com/rghiorghisor/basic-go-rest-api/propertyset"
+	"github.com/rghiorghisor/basic-go-rest-api/tests/fixture"
+	trash_service "github.com/rghiorghisor/basic-go-rest-api/trash/service"
+	"github.com/rghiorghisor/basic-go-rest-api/watch"
+	"github.com/rghiorghisor/basic-go-rest-api/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+var defaultDB = "../../tests/local-repo/webhookservicedb"
+
+type testContext struct {
+	services        *fixture.Services
+	service         webhook.Service
+	propertyService property.Service
+	setService      propertyset.Service
+	receiver        *receiver
+}
+
+// receiver records the deliveries it is sent, failing them while told to.
+type receiver struct {
+	server   *httptest.Server
+	requests chan *delivery
+	failing  int32
+	received int32
+}
+
+type delivery struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+func TestDeliver(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	hook := &model.Webhook{URL: tc.receiver.server.URL, Events: []string{webhook.PropertyCreated}, Secret: "s3cr3t"}
+	assert.Nil(t, tc.service.Create(ctx, hook))
+
+	prop := &model.Property{Name: "test.name", Value: "value"}
+	tc.propertyService.Create(ctx, prop)
+	prop.Value = "other"
+	tc.propertyService.Update(ctx, prop)
+	tc.propertyService.Create(ctx, &model.Property{Name: "test.other", Value: "value"})
+
+	deliveries := tc.receiver.collect(t, 2)
+	assert.ElementsMatch(t, []string{"property.created test.name", "property.created test.other"}, keys(deliveries))
+
+	first := deliveries["property.created test.name"]
+	assert.Equal(t, webhook.Sign("s3cr3t", first.body), first.signature)
+
+	payload := new(payloadDto)
+	json.Unmarshal(first.body, payload)
+	assert.NotEqual(t, "", payload.ID)
+	assert.Equal(t, "", payload.Namespace)
+	assert.Equal(t, "value", payload.Property.Value)
+}
+
+func TestDeliverSet(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	tc.service.Create(ctx, &model.Webhook{URL: tc.receiver.server.URL, Set: "common"})
+
+	tc.setService.Create(ctx, &model.PropertySet{Name: "other", Values: []string{"test.a"}})
+	tc.setService.Create(ctx, &model.PropertySet{Name: "common", Values: []string{"test.a"}})
+	tc.propertyService.Create(ctx, &model.Property{Name: "test.b", Value: "value"})
+	tc.propertyService.Create(ctx, &model.Property{Name: "test.a", Value: "value"})
+
+	deliveries := tc.receiver.collect(t, 2)
+	assert.ElementsMatch(t, []string{"set.created common", "property.created test.a"}, keys(deliveries))
+	assert.Empty(t, deliveries["set.created common"].signature)
+}
+
+func TestDeliverSetCascade(t *testing.T) {
+	tc := setupIntegrity(config.IntegrityCascade)
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	tc.service.Create(ctx, &model.Webhook{URL: tc.receiver.server.URL, Set: "all"})
+
+	tc.setService.Create(ctx, &model.PropertySet{Name: "common", Values: []string{"test.a", "test.b"}})
+	tc.setService.Create(ctx, &model.PropertySet{Name: "all", Includes: []string{"common"}})
+	a := &model.Property{Name: "test.a", Value: "value"}
+	b := &model.Property{Name: "test.b", Value: "value"}
+	tc.propertyService.Create(ctx, a)
+	tc.propertyService.Create(ctx, b)
+	assert.Equal(t, 3, len(tc.receiver.collect(t, 3)))
+
+	// The property changes are cascaded to the 'common' set, before they are
+	// published, so the properties are no longer members of the 'all' set.
+	assert.Nil(t, tc.propertyService.Delete(ctx, a.ID, 0))
+	b.Name = "test.c"
+	b.Revision = 0
+	assert.Nil(t, tc.propertyService.Update(ctx, b))
+
+	deliveries := tc.receiver.collect(t, 2)
+	assert.ElementsMatch(t, []string{"property.deleted test.a", "property.updated test.c"}, keys(deliveries))
+}
+
+func TestDeadLetter(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	hook := &model.Webhook{URL: tc.receiver.server.URL}
+	tc.service.Create(ctx, hook)
+
+	atomic.StoreInt32(&tc.receiver.failing, 1)
+	tc.propertyService.Create(ctx, &model.Property{Name: "test.name", Value: "value"})
+
+	letters := waitForDeadLetters(t, tc.service, hook.ID)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&tc.receiver.received))
+	assert.Equal(t, 1, len(letters))
+	assert.Equal(t, webhook.PropertyCreated, letters[0].Event)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.Equal(t, "unexpected status 500", letters[0].Error)
+
+	err := tc.service.Replay(ctx, hook.ID, letters[0].ID)
+	assert.Equal(t, apperrors.NewDeliveryFailed(reflect.TypeOf(model.DeadLetter{}), letters[0].ID, "unexpected status 500"), err)
+
+	atomic.StoreInt32(&tc.receiver.failing, 0)
+	assert.Nil(t, tc.service.Replay(ctx, hook.ID, letters[0].ID))
+
+	// Drain the failed attempts, then check the replayed delivery.
+	for i := 0; i < 4; i++ {
+		tc.receiver.next(t)
+	}
+	assert.Equal(t, letters[0].Payload, tc.receiver.next(t).body)
+
+	letters, _, _ = tc.service.ReadDeadLetters(ctx, hook.ID, model.Page{})
+	assert.Equal(t, 0, len(letters))
+}
+
+func TestDeliverQueueFull(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	hook := &model.Webhook{URL: tc.receiver.server.URL}
+	tc.service.Create(ctx, hook)
+
+	// Without any room in the queue, the delivery is kept as a dead letter
+	// without being attempted.
+	service := tc.service.(WebhookService)
+	service.queue = make(chan pendingDelivery)
+	service.enqueue(hook, webhook.PropertyCreated, []byte("{}"))
+
+	letters, _, err := service.ReadDeadLetters(ctx, hook.ID, model.Page{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(letters))
+	assert.Equal(t, 0, letters[0].Attempts)
+	assert.Equal(t, "too many pending deliveries", letters[0].Error)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&tc.receiver.received))
+}
+
+func TestDeadLetterOtherWebhook(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	hook := &model.Webhook{URL: tc.receiver.server.URL}
+	other := &model.Webhook{URL: tc.receiver.server.URL}
+	tc.service.Create(ctx, hook)
+	tc.service.Create(ctx, other)
+
+	atomic.StoreInt32(&tc.receiver.failing, 1)
+	tc.service.Delete(ctx, other.ID)
+	tc.propertyService.Create(ctx, &model.Property{Name: "test.name", Value: "value"})
+
+	letters := waitForDeadLetters(t, tc.service, hook.ID)
+
+	err := tc.service.DeleteDeadLetter(ctx, other.ID, letters[0].ID)
+	assert.Equal(t, apperrors.NewEntityNotFound(model.Webhook{}, other.ID), err)
+
+	assert.Nil(t, tc.service.DeleteDeadLetter(ctx, hook.ID, letters[0].ID))
+}
+
+func TestCreateInvalid(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+
+	err := tc.service.Create(ctx, &model.Webhook{})
+	assert.Equal(t, apperrors.NewInvalidEntityEmpty(reflect.TypeOf(model.Webhook{}), "url"), err)
+
+	err = tc.service.Create(ctx, &model.Webhook{URL: "localhost/hook"})
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), "'url' has invalid value 'localhost/hook'."), err)
+
+	err = tc.service.Create(ctx, &model.Webhook{URL: "http://localhost/hook", Events: []string{"property.read"}})
+	assert.Equal(t, apperrors.NewInvalidEntityCustom(reflect.TypeOf(model.Webhook{}), "'events' has unknown value 'property.read'."), err)
+}
+
+func TestUpdateSecret(t *testing.T) {
+	tc := setup()
+	defer tearDown(tc)
+
+	ctx := context.Background()
+	hook := &model.Webhook{URL: "http://localhost/hook", Secret: "s3cr3t"}
+	tc.service.Create(ctx, hook)
+
+	err := tc.service.Update(ctx, &model.Webhook{ID: hook.ID, URL: "http://localhost/other"})
+	assert.Nil(t, err)
+
+	found, _ := tc.service.FindByID(ctx, hook.ID)
+	assert.Equal(t, "http://localhost/other", found.URL)
+	assert.Equal(t, "s3cr3t", found.Secret)
+
+	err = tc.service.ClearSecret(ctx, hook.ID)
+	assert.Nil(t, err)
+
+	found, _ = tc.service.FindByID(ctx, hook.ID)
+	assert.Equal(t, "http://localhost/other", found.URL)
+	assert.Equal(t, "", found.Secret)
+
+	err = tc.service.Update(ctx, &model.Webhook{ID: "missing", URL: "http://localhost/other"})
+	assert.Equal(t, apperrors.NewEntityNotFound(model.Webhook{}, "missing"), err)
+}
+
+func TestNewPayloadSecret(t *testing.T) {
+	prop := &model.Property{Name: "test.name", Value: "value", Secret: true, Overrides: map[string]string{"prod": "other"}}
+
+	payload := newPayload(webhook.PropertyUpdated, watch.PropertyEvent(watch.Updated, prop))
+
+	assert.Equal(t, model.MaskedValue, payload.Property.Value)
+	assert.Equal(t, map[string]string{"prod": model.MaskedValue}, payload.Property.Overrides)
+	assert.Equal(t, "other", prop.Overrides["prod"])
+}
+
+func keys(deliveries map[string]*delivery) []string {
+	result := make([]string, 0, len(deliveries))
+	for key := range deliveries {
+		result = append(result, key)
+	}
+
+	return result
+}
+
+func waitForDeadLetters(t *testing.T, service webhook.Service, webhookID string) []*model.DeadLetter {
+	for i := 0; i < 200; i++ {
+		letters, _, err := service.ReadDeadLetters(context.Background(), webhookID, model.Page{})
+		assert.Nil(t, err)
+
+		if len(letters) > 0 {
+			return letters
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("no dead letter was stored")
+
+	return nil
+}
+
+func (r *receiver) next(t *testing.T) *delivery {
+	select {
+	case request := <-r.requests:
+		return request
+	case <-time.After(2 * time.Second):
+		t.Fatal("no delivery was received")
+	}
+
+	return nil
+}
+
+// collect receives the given number of deliveries, by their event type and the
+// name of the changed entity.
+func (r *receiver) collect(t *testing.T, count int) map[string]*delivery {
+	deliveries := make(map[string]*delivery, count)
+	for i := 0; i < count; i++ {
+		request := r.next(t)
+
+		payload := new(payloadDto)
+		json.Unmarshal(request.body, payload)
+
+		name := ""
+		if payload.Property != nil {
+			name = payload.Property.Name
+		}
+		if payload.Set != nil {
+			name = payload.Set.Name
+		}
+
+		deliveries[request.event+" "+name] = request
+	}
+
+	return deliveries
+}
+
+func newReceiver() *receiver {
+	r := &receiver{requests: make(chan *delivery, 16)}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		atomic.AddInt32(&r.received, 1)
+
+		r.requests <- &delivery{
+			event:     request.Header.Get(webhook.EventHeader),
+			signature: request.Header.Get(webhook.SignatureHeader),
+			body:      body,
+		}
+
+		if atomic.LoadInt32(&r.failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+
+	return r
+}
+
+func setup() *testContext {
+	return setupIntegrity(config.IntegrityReport)
+}
+
+func setupIntegrity(integrity string) *testContext {
+	services := fixture.NewWithIntegrity(defaultDB, trash_service.New, integrity)
+
+	service := WebhookService{
+		repository: services.Storage.WebhookRepository,
+		setService: services.Sets,
+		client:     &http.Client{Timeout: time.Second},
+		attempts:   3,
+		backoff:    time.Millisecond,
+		queue:      make(chan pendingDelivery, queueSize),
+	}
+	for i := 0; i < deliveryWorkers; i++ {
+		go service.work()
+	}
+	go service.dispatch(services.Hub, services.Hub.Subscribe(nil))
+
+	return &testContext{
+		services:        services,
+		service:         service,
+		propertyService: services.Properties,
+		setService:      services.Sets,
+		receiver:        newReceiver(),
+	}
+}
+
+func tearDown(tc *testContext) {
+	tc.receiver.server.Close()
+	tc.services.Close()
+}
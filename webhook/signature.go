@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Headers of the delivered requests.
+const (
+	// EventHeader holds the event type of the delivered change.
+	EventHeader = "X-Webhook-Event"
+
+	// SignatureHeader holds the signature of the request body, as retrieved by
+	// Sign. The header is missing if the webhook has no secret.
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Sign retrieves the signature of the given payload: the hex encoded HMAC-SHA256
+// of the payload, keyed by the given secret and prefixed by "sha256=".
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks whether the given signature is the signature of the given
+// payload, using a constant time comparison.
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}